@@ -0,0 +1,32 @@
+package main
+
+import (
+	"cmp"
+	"log/slog"
+)
+
+// reverseEntries returns the entries that undo plan: renames go back from tobe
+// to asis and archive and unarchive swap. Topic changes cannot be undone, since
+// the previous topic is not known, and are left out with a note.
+func reverseEntries(plan []planEntry) []planEntry {
+	reverse := make([]planEntry, 0, len(plan))
+	for _, e := range plan {
+		r := planEntry{Owner: e.Owner, Source: e.Source, ChannelID: e.ChannelID, Workspace: e.Workspace}
+		switch e.Action {
+		case actionRename:
+			r.Action, r.Asis, r.Tobe, r.Rearchive, r.PinnedNote = actionRename, e.Tobe, e.Asis, e.Rearchive, e.PinnedNote
+		case actionArchive:
+			r.Action, r.Asis = actionUnarchive, e.Asis
+		case actionUnarchive:
+			// A channel renamed on unarchive is archived under its new name.
+			r.Action, r.Asis = actionArchive, cmp.Or(e.Tobe, e.Asis)
+		case actionCreate:
+			r.Action, r.Asis = actionArchive, e.Asis
+		default:
+			slog.Warn(e.At()+"cannot roll back this action, ignoring", "action", e.Action)
+			continue
+		}
+		reverse = append(reverse, r)
+	}
+	return reverse
+}
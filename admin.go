@@ -0,0 +1,84 @@
+package main
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/kiddikn/slack-channel-renamer/slackops"
+)
+
+// fetchRenamedAdmin searches the org for the new name of every rename in
+// x.Renamed, for the verification pass in -admin mode, rather than listing
+// every conversation in the org. Only channels found under their new name are
+// returned.
+func (x *executor) fetchRenamedAdmin(ctx context.Context) (map[string]channelInfo, error) {
+	channels := make(map[string]channelInfo)
+	for _, e := range x.Renamed {
+		if _, ok := channels[e.Tobe]; ok {
+			continue
+		}
+		matches, err := slackops.FindAdmin(ctx, x.Client, x.Stats, e.Tobe, "")
+		if err != nil {
+			return nil, err
+		}
+		if len(matches) > 0 {
+			channels[e.Tobe] = slackops.AdminChannel(matches[0])
+		}
+	}
+	return channels, nil
+}
+
+// resolveAdminChannels builds the channel map for -admin mode by searching the
+// org for every asis and tobe name in the plan, instead of listing the
+// conversations the token can see. Names that match more than one channel in
+// the org, or in the workspace teamID when it is set, are reported as
+// ambiguous with the workspace of each match.
+func resolveAdminChannels(ctx context.Context, client slackops.API, stats *slackops.Stats, plan []planEntry, teamID string) (map[string]channelInfo, []string) {
+	var names []string
+	var errs []string
+	for _, e := range plan {
+		if e.ChannelID != "" && e.Asis == e.ChannelID {
+			errs = append(errs, e.At()+fmt.Sprintf("channel IDs cannot be resolved in -admin mode, use the channel name instead of %s", e.ChannelID))
+			continue
+		}
+		if e.Action == actionSetTopic || e.Topic != "" || e.Purpose != "" {
+			errs = append(errs, e.At()+"topics and purposes cannot be set in -admin mode")
+		}
+		if e.Action == actionMerge {
+			errs = append(errs, e.At()+"channels cannot be merged in -admin mode")
+		}
+		if e.Action == actionCreate || e.Action == actionInvite {
+			errs = append(errs, e.At()+fmt.Sprintf("%s rows are not supported in -admin mode", e.Action))
+		}
+		names = append(names, e.Asis)
+		if e.Tobe != "" {
+			names = append(names, e.Tobe)
+		}
+	}
+	slices.Sort(names)
+	names = slices.Compact(names)
+
+	channels := make(map[string]channelInfo)
+	for _, name := range names {
+		matches, err := slackops.FindAdmin(ctx, client, stats, name, teamID)
+		if err != nil {
+			errs = append(errs, err.Error())
+			continue
+		}
+		switch len(matches) {
+		case 0:
+		case 1:
+			channels[name] = slackops.AdminChannel(matches[0])
+		default:
+			where := make([]string, 0, len(matches))
+			for _, c := range matches {
+				where = append(where, c.ID+" in "+cmp.Or(slackops.AdminTeam(c), "unknown workspace"))
+			}
+			errs = append(errs, fmt.Sprintf("channel name %q matches %d channels in the org (%s); pass -team-id to pick a workspace", name, len(matches), strings.Join(where, ", ")))
+		}
+	}
+	return channels, errs
+}
@@ -0,0 +1,34 @@
+package main
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/kiddikn/slack-channel-renamer/slackops"
+	"github.com/slack-go/slack"
+)
+
+func TestResolveAdminChannels(t *testing.T) {
+	channel := func(id, name, team string) *slack.Channel {
+		ch := &slack.Channel{}
+		ch.ID, ch.Name, ch.ContextTeamID = id, name, team
+		return ch
+	}
+	w := &simWorkspace{channels: []*slack.Channel{
+		channel("C1", "proj-alpha", "T1"),
+		channel("C2", "proj-alpha", "T2"),
+		channel("C3", "proj-beta", "T2"),
+	}}
+	plan := []planEntry{
+		{Action: actionRename, Asis: "proj-alpha", Tobe: "alpha"},
+		{Action: actionRename, Asis: "proj-beta", Tobe: "beta"},
+	}
+	channels, errs := resolveAdminChannels(context.Background(), w, &slackops.Stats{}, plan, "")
+	if len(errs) != 1 || !strings.Contains(errs[0], "C1 in T1, C2 in T2") {
+		t.Errorf("errs = %q, want proj-alpha reported as ambiguous with its workspaces", errs)
+	}
+	if ch := channels["proj-beta"]; ch.ID != "C3" || ch.TeamID != "T2" {
+		t.Errorf("proj-beta = %+v, want C3 in T2", ch)
+	}
+}
@@ -0,0 +1,207 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/kiddikn/slack-channel-renamer/slackops"
+	"github.com/slack-go/slack"
+)
+
+// defaultAnnouncement is the message posted in a renamed channel.
+const defaultAnnouncement = "This channel was renamed from #{{.OldName}} to #{{.NewName}}{{with .Reason}} as part of {{.}}{{end}}."
+
+// announceOptions tells the members of renamed channels, and optionally their
+// creators, what happened to the channel.
+type announceOptions struct {
+	inChannel bool
+	dmCreator bool
+	reason    string
+	message   string
+	// pinOldName pins a note with the channel's previous name in it, for
+	// members searching for the old name.
+	pinOldName bool
+}
+
+func (o *announceOptions) register(fs *flag.FlagSet) {
+	fs.BoolVar(&o.inChannel, "announce", false, "post a message in each renamed channel saying what it was renamed from")
+	fs.BoolVar(&o.dmCreator, "announce-dm-creator", false, "send the announcement to each renamed channel's creator as a direct message")
+	fs.StringVar(&o.reason, "announce-reason", "", `why the channels were renamed, for the announcement (e.g. "the Q3 naming cleanup")`)
+	fs.StringVar(&o.message, "announce-message", defaultAnnouncement, "Go template of the announcement; can use {{.OldName}}, {{.NewName}} and {{.Reason}}")
+	fs.BoolVar(&o.pinOldName, "pin-old-name", false, "pin a note with the previous name in each renamed channel; rollback unpins it (needs chat:write and pins:write)")
+}
+
+func (o announceOptions) enabled() bool {
+	return o.inChannel || o.dmCreator
+}
+
+// announcementData is what -announce-message can refer to.
+type announcementData struct {
+	OldName string
+	NewName string
+	Reason  string
+}
+
+// compile parses the announcement template.
+func (o announceOptions) compile() (*template.Template, error) {
+	t, err := template.New("announcement").Option("missingkey=error").Parse(o.message)
+	if err != nil {
+		return nil, fmt.Errorf("parse -announce-message: %w", err)
+	}
+	return t, nil
+}
+
+// renamedChannel is a channel whose renames in a run all succeeded.
+type renamedChannel struct {
+	id, oldName, newName string
+	creator              string
+}
+
+// renamedChannels returns, in plan order, every channel the run renamed
+// without a failure, from its name before the run to its name after it, so
+// that a rename through a temporary name is announced once.
+func (x *executor) renamedChannels(channels map[string]channelInfo) []renamedChannel {
+	var order []string
+	byID := make(map[string]*renamedChannel)
+	failed := make(map[string]bool)
+	for _, r := range x.Results {
+		if r.Action != actionRename || r.ChannelID == "" {
+			continue
+		}
+		if r.Status != resultOK {
+			failed[r.ChannelID] = true
+			continue
+		}
+		c, ok := byID[r.ChannelID]
+		if !ok {
+			c = &renamedChannel{id: r.ChannelID, oldName: r.Asis, creator: channels[r.Asis].Creator}
+			byID[r.ChannelID] = c
+			order = append(order, r.ChannelID)
+		}
+		c.newName = r.Tobe
+	}
+	var renamed []renamedChannel
+	for _, id := range order {
+		if c := byID[id]; !failed[id] && c.oldName != c.newName {
+			renamed = append(renamed, *c)
+		}
+	}
+	return renamed
+}
+
+// announce posts the announcement in every channel the run renamed and/or
+// sends it to the channel's creator. Failures are logged and do not fail the run.
+func (x *executor) announce(o announceOptions, tmpl *template.Template, channels map[string]channelInfo) {
+	sent := 0
+	for _, c := range x.renamedChannels(channels) {
+		var text strings.Builder
+		if err := tmpl.Execute(&text, announcementData{OldName: c.oldName, NewName: c.newName, Reason: o.reason}); err != nil {
+			slog.Warn("failed to render the announcement", "channel_id", c.id, "err", err)
+			continue
+		}
+		attrs := []any{"channel_id", c.id, "asis", c.oldName, "tobe", c.newName}
+		if o.inChannel {
+			err := slackops.Call(cmdCtx, x.Stats, fmt.Sprintf("announcing the rename in %s", c.newName), func(ctx context.Context) error {
+				_, _, err := x.Client.PostMessageContext(ctx, c.id, slack.MsgOptionText(text.String(), false))
+				return err
+			}, attrs...)
+			if err != nil {
+				slog.Warn("failed to announce the rename in the channel", append(attrs, "err", err)...)
+			} else {
+				sent++
+			}
+		}
+		if o.dmCreator && c.creator != "" {
+			dm := fmt.Sprintf("A channel you created, <#%s>, was renamed:\n>%s", c.id, text.String())
+			if err := x.directMessage(c.creator, dm, attrs); err != nil {
+				slog.Warn("failed to tell the channel's creator about the rename", append(attrs, "creator", c.creator, "err", err)...)
+			} else {
+				sent++
+			}
+		}
+	}
+	slog.Info(x.label+"sent rename announcements", "messages", sent)
+}
+
+// directMessage opens a DM with user and posts text in it.
+func (x *executor) directMessage(user, text string, attrs []any) error {
+	var dm *slack.Channel
+	err := slackops.Call(cmdCtx, x.Stats, "opening a DM with "+user, func(ctx context.Context) error {
+		var err error
+		dm, _, _, err = x.Client.OpenConversationContext(ctx, &slack.OpenConversationParameters{Users: []string{user}})
+		return err
+	}, attrs...)
+	if err != nil {
+		return err
+	}
+	return slackops.Call(cmdCtx, x.Stats, "messaging "+user, func(ctx context.Context) error {
+		_, _, err := x.Client.PostMessageContext(ctx, dm.ID, slack.MsgOptionText(text, false))
+		return err
+	}, attrs...)
+}
+
+// pinOldNames posts a note naming the previous name in every channel the run
+// renamed and pins it, so that members looking for the old name can tell they
+// are in the right place. The note's timestamp is kept on the last rename of
+// the channel in x.Changed, for the rollback file to unpin it. Failures are
+// logged and do not fail the run.
+func (x *executor) pinOldNames(channels map[string]channelInfo) {
+	pinned := 0
+	date := time.Now().Format(time.DateOnly)
+	for _, c := range x.renamedChannels(channels) {
+		attrs := []any{"channel_id", c.id, "asis", c.oldName, "tobe", c.newName}
+		text := fmt.Sprintf(":pushpin: This channel was called #%s until %s, when it was renamed to #%s.", c.oldName, date, c.newName)
+		var ts string
+		err := slackops.Call(cmdCtx, x.Stats, fmt.Sprintf("posting the old name in %s", c.newName), func(ctx context.Context) error {
+			var err error
+			_, ts, err = x.Client.PostMessageContext(ctx, c.id, slack.MsgOptionText(text, false))
+			return err
+		}, attrs...)
+		if err == nil {
+			err = slackops.Call(cmdCtx, x.Stats, fmt.Sprintf("pinning the old name in %s", c.newName), func(ctx context.Context) error {
+				return x.Client.AddPinContext(ctx, c.id, slack.NewRefToMessage(c.id, ts))
+			}, attrs...)
+		}
+		if err != nil {
+			slog.Warn("failed to pin the old name in the channel", append(attrs, "err", err)...)
+			continue
+		}
+		pinned++
+		for i := len(x.Changed) - 1; i >= 0; i-- {
+			if e := x.Changed[i]; e.Action == actionRename && e.Tobe == c.newName {
+				x.Changed[i].PinnedNote = ts
+				break
+			}
+		}
+	}
+	slog.Info(x.label+"pinned the old names", "channels", pinned)
+}
+
+// unpinOldNames unpins the notes -pin-old-name pinned in the channels that
+// the entries of a rollback renamed back. Failures are logged and do not fail
+// the run.
+func (x *executor) unpinOldNames(changed []planEntry) {
+	for _, e := range changed {
+		if e.Action != actionRename || e.PinnedNote == "" || e.ChannelID == "" {
+			continue
+		}
+		attrs := []any{"channel_id", e.ChannelID, "asis", e.Asis, "tobe", e.Tobe}
+		err := slackops.Call(cmdCtx, x.Stats, fmt.Sprintf("unpinning the old name in %s", e.Tobe), func(ctx context.Context) error {
+			err := x.Client.RemovePinContext(ctx, e.ChannelID, slack.NewRefToMessage(e.ChannelID, e.PinnedNote))
+			var se slack.SlackErrorResponse
+			if errors.As(err, &se) && se.Err == "no_pin" {
+				err = nil
+			}
+			return err
+		}, attrs...)
+		if err != nil {
+			slog.Warn("failed to unpin the old name; unpin it by hand", append(attrs, "ts", e.PinnedNote, "err", err)...)
+		}
+	}
+}
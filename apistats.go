@@ -0,0 +1,239 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"github.com/kiddikn/slack-channel-renamer/plan"
+	"github.com/kiddikn/slack-channel-renamer/slackops"
+)
+
+// apiRecorders are the runs currently recording their Slack API calls;
+// metricsTransport reports every call to each of them.
+var apiRecorders = &apiRecorderSet{}
+
+type apiRecorderSet struct {
+	mu     sync.Mutex
+	active []*apiRecorder
+}
+
+// start records the calls made from now until the returned function is
+// called. Calls made meanwhile by anything else in the process, such as a
+// validation while 'serve' applies a plan, are recorded too.
+func (s *apiRecorderSet) start() (*apiRecorder, func()) {
+	r := &apiRecorder{calls: make(map[string]*apiCalls)}
+	s.mu.Lock()
+	s.active = append(s.active, r)
+	s.mu.Unlock()
+	return r, func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		s.active = slices.DeleteFunc(s.active, func(a *apiRecorder) bool { return a == r })
+	}
+}
+
+// call records a call to method made with ctx.
+func (s *apiRecorderSet) call(ctx context.Context, method string, took time.Duration, rateLimited bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, r := range s.active {
+		r.of(plan.FileOf(ctx)).call(method, took, rateLimited)
+	}
+}
+
+// retry records a retry of a call made with ctx, after a rate limit or a
+// transient error.
+func (s *apiRecorderSet) retry(ctx context.Context, rateLimited bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, r := range s.active {
+		r.of(plan.FileOf(ctx)).retry(rateLimited)
+	}
+}
+
+// apiRecorder holds the Slack API calls of one run, by the plan file of the
+// entry that made them, for the run's API statistics.
+type apiRecorder struct {
+	mu    sync.Mutex
+	calls map[string]*apiCalls
+}
+
+// of returns the calls recorded for the plan file, or for none with "".
+func (r *apiRecorder) of(file string) *apiCalls {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	c := r.calls[file]
+	if c == nil {
+		c = &apiCalls{latency: make(map[string][]time.Duration), rateLimited: make(map[string]int)}
+		r.calls[file] = c
+	}
+	return c
+}
+
+// apiCalls holds the latency of calls by method, and how often they were
+// retried.
+type apiCalls struct {
+	mu                                 sync.Mutex
+	latency                            map[string][]time.Duration
+	rateLimited                        map[string]int
+	rateLimitRetries, transientRetries int64
+}
+
+func (c *apiCalls) call(method string, took time.Duration, rateLimited bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.latency[method] = append(c.latency[method], took)
+	if rateLimited {
+		c.rateLimited[method]++
+	}
+}
+
+func (c *apiCalls) retry(rateLimited bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if rateLimited {
+		c.rateLimitRetries++
+	} else {
+		c.transientRetries++
+	}
+}
+
+// apiStats sums up a run's Slack API calls: how long the run took, how many
+// calls it made to each method, how often they were retried and how long
+// they took, so that -concurrency and -rate can be tuned to the workspace.
+type apiStats struct {
+	Seconds          float64 `json:"seconds"`
+	Calls            int     `json:"calls"`
+	RateLimited      int     `json:"rate_limited"`
+	RateLimitRetries int64   `json:"rate_limit_retries"`
+	TransientRetries int64   `json:"transient_retries"`
+	// P50 and P95 are the median and 95th percentile latency of the calls,
+	// in milliseconds.
+	P50       float64         `json:"p50_ms"`
+	P95       float64         `json:"p95_ms"`
+	Endpoints []endpointStats `json:"endpoints,omitempty"`
+}
+
+type endpointStats struct {
+	Method      string  `json:"method"`
+	Calls       int     `json:"calls"`
+	RateLimited int     `json:"rate_limited"`
+	P50         float64 `json:"p50_ms"`
+	P95         float64 `json:"p95_ms"`
+}
+
+// stats returns the statistics of all the calls recorded so far, for a run
+// that took took, with the retry counts of its sessions. Methods are listed
+// by call count, most called first.
+func (r *apiRecorder) stats(took time.Duration, rateLimitRetries, transientRetries int64) apiStats {
+	r.mu.Lock()
+	all := slices.Collect(maps.Values(r.calls))
+	r.mu.Unlock()
+	s := summarizeCalls(took, all)
+	s.RateLimitRetries, s.TransientRetries = rateLimitRetries, transientRetries
+	return s
+}
+
+// planStats returns the statistics of the calls made by the entries of one
+// plan file, in a run that took took. Calls made outside the entries, such
+// as listing the channels or the verification pass, count towards no plan.
+func (r *apiRecorder) planStats(file string, took time.Duration) apiStats {
+	r.mu.Lock()
+	c := r.calls[file]
+	r.mu.Unlock()
+	if c == nil {
+		return apiStats{Seconds: took.Seconds()}
+	}
+	s := summarizeCalls(took, []*apiCalls{c})
+	c.mu.Lock()
+	s.RateLimitRetries, s.TransientRetries = c.rateLimitRetries, c.transientRetries
+	c.mu.Unlock()
+	return s
+}
+
+// summarizeCalls returns the statistics of the calls, without retry counts.
+func summarizeCalls(took time.Duration, calls []*apiCalls) apiStats {
+	latency := make(map[string][]time.Duration)
+	rateLimited := make(map[string]int)
+	for _, c := range calls {
+		c.mu.Lock()
+		for method, l := range c.latency {
+			latency[method] = append(latency[method], l...)
+		}
+		for method, n := range c.rateLimited {
+			rateLimited[method] += n
+		}
+		c.mu.Unlock()
+	}
+	s := apiStats{Seconds: took.Seconds()}
+	var all []time.Duration
+	for _, method := range slices.Sorted(maps.Keys(latency)) {
+		calls := slices.Sorted(slices.Values(latency[method]))
+		s.Endpoints = append(s.Endpoints, endpointStats{
+			Method:      method,
+			Calls:       len(calls),
+			RateLimited: rateLimited[method],
+			P50:         percentile(calls, 50),
+			P95:         percentile(calls, 95),
+		})
+		s.Calls += len(calls)
+		s.RateLimited += rateLimited[method]
+		all = append(all, calls...)
+	}
+	slices.SortStableFunc(s.Endpoints, func(a, b endpointStats) int { return b.Calls - a.Calls })
+	slices.Sort(all)
+	s.P50, s.P95 = percentile(all, 50), percentile(all, 95)
+	return s
+}
+
+// percentile returns the nearest-rank p-th percentile of the sorted
+// durations, in milliseconds, or 0 for none.
+func percentile(sorted []time.Duration, p int) float64 {
+	if len(sorted) == 0 {
+		return 0
+	}
+	i := max((p*len(sorted)+99)/100-1, 0)
+	return float64(sorted[i].Microseconds()) / 1000
+}
+
+// retryCounts sums the retries of the runs' sessions.
+func retryCounts(runs []workspaceRun) (rateLimit, transient int64) {
+	seen := make(map[*slackops.Stats]bool)
+	for _, r := range runs {
+		if seen[r.stats] {
+			continue
+		}
+		seen[r.stats] = true
+		rateLimit += r.stats.RateLimitRetries.Load()
+		transient += r.stats.TransientRetries.Load()
+	}
+	return rateLimit, transient
+}
+
+// printAPIStats prints the run's API statistics after its summaries, with a
+// line per method. A run that made no calls, such as a simulation, prints
+// nothing.
+func printAPIStats(w io.Writer, s apiStats) {
+	if s.Calls == 0 {
+		return
+	}
+	fmt.Fprintf(w, "api: %d calls in %v, %d rate limited, %d rate-limit retries, %d transient retries, p50 %s, p95 %s\n",
+		s.Calls, time.Duration(s.Seconds*float64(time.Second)).Round(time.Millisecond), s.RateLimited, s.RateLimitRetries, s.TransientRetries, formatMillis(s.P50), formatMillis(s.P95))
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "  METHOD\tCALLS\tRATE LIMITED\tP50\tP95")
+	for _, e := range s.Endpoints {
+		fmt.Fprintf(tw, "  %s\t%d\t%d\t%s\t%s\n", e.Method, e.Calls, e.RateLimited, formatMillis(e.P50), formatMillis(e.P95))
+	}
+	tw.Flush()
+}
+
+// formatMillis formats a latency in milliseconds as a duration.
+func formatMillis(ms float64) string {
+	return time.Duration(ms * float64(time.Millisecond)).Round(time.Millisecond).String()
+}
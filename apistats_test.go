@@ -0,0 +1,90 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kiddikn/slack-channel-renamer/plan"
+)
+
+type statusTransport int
+
+func (t statusTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	rec := httptest.NewRecorder()
+	rec.WriteHeader(int(t))
+	return rec.Result(), nil
+}
+
+func TestAPIRecorderStats(t *testing.T) {
+	r, stop := apiRecorders.start()
+	ctx := plan.WithFile(context.Background(), "a.csv")
+	for i := 1; i <= 20; i++ {
+		apiRecorders.call(ctx, "conversations.rename", time.Duration(i)*time.Millisecond, i == 20)
+	}
+	apiRecorders.retry(ctx, true)
+	apiRecorders.call(context.Background(), "conversations.list", 500*time.Millisecond, false)
+	stop()
+	apiRecorders.call(ctx, "conversations.rename", time.Second, false)
+
+	s := r.stats(3*time.Second, 1, 2)
+	if s.Calls != 21 || s.RateLimited != 1 || s.RateLimitRetries != 1 || s.TransientRetries != 2 || s.Seconds != 3 {
+		t.Errorf("stats = %+v", s)
+	}
+	if s.P50 != 11 || s.P95 != 20 {
+		t.Errorf("p50 = %g, p95 = %g, want 11 and 20", s.P50, s.P95)
+	}
+	if len(s.Endpoints) != 2 || s.Endpoints[0].Method != "conversations.rename" {
+		t.Fatalf("endpoints = %+v, want conversations.rename first", s.Endpoints)
+	}
+	if e := s.Endpoints[0]; e.Calls != 20 || e.RateLimited != 1 || e.P50 != 10 || e.P95 != 19 {
+		t.Errorf("conversations.rename = %+v", e)
+	}
+	if e := s.Endpoints[1]; e.Calls != 1 || e.P50 != 500 || e.P95 != 500 {
+		t.Errorf("conversations.list = %+v", e)
+	}
+
+	// The plan's statistics leave out the listing, made outside any entry.
+	if p := r.planStats("a.csv", 3*time.Second); p.Calls != 20 || p.RateLimitRetries != 1 || p.TransientRetries != 0 || len(p.Endpoints) != 1 {
+		t.Errorf("a.csv stats = %+v", p)
+	}
+	if p := r.planStats("b.csv", time.Second); p.Calls != 0 || p.Seconds != 1 {
+		t.Errorf("b.csv stats = %+v, want no calls", p)
+	}
+}
+
+func TestMetricsTransportRecordsCalls(t *testing.T) {
+	r, stop := apiRecorders.start()
+	defer stop()
+	client := &http.Client{Transport: metricsTransport{base: statusTransport(http.StatusTooManyRequests)}}
+	req := httptest.NewRequestWithContext(plan.WithFile(t.Context(), "a.csv"), "GET", "http://slack.test/api/conversations.info", nil)
+	req.RequestURI = ""
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	s := r.planStats("a.csv", time.Second)
+	if len(s.Endpoints) != 1 || s.Endpoints[0].Method != "conversations.info" || s.Endpoints[0].RateLimited != 1 {
+		t.Errorf("a.csv endpoints = %+v, want one rate-limited conversations.info", s.Endpoints)
+	}
+}
+
+func TestPrintAPIStats(t *testing.T) {
+	var b strings.Builder
+	printAPIStats(&b, apiStats{})
+	if b.Len() != 0 {
+		t.Errorf("a run without calls printed %q", b.String())
+	}
+	printAPIStats(&b, apiStats{Seconds: 1.5, Calls: 3, RateLimitRetries: 1, P50: 120, P95: 480, Endpoints: []endpointStats{
+		{Method: "conversations.rename", Calls: 3, RateLimited: 1, P50: 120, P95: 480},
+	}})
+	for _, want := range []string{"api: 3 calls in 1.5s", "1 rate-limit retries", "p50 120ms, p95 480ms", "conversations.rename"} {
+		if !strings.Contains(b.String(), want) {
+			t.Errorf("output lacks %q:\n%s", want, b.String())
+		}
+	}
+}
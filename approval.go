@@ -0,0 +1,277 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/kiddikn/slack-channel-renamer/slackops"
+	"github.com/slack-go/slack"
+	"github.com/slack-go/slack/socketmode"
+)
+
+const (
+	// approvalPoll is how often the approval message's reactions are read.
+	approvalPoll = 10 * time.Second
+	// approvalPlanLimit keeps the plan listing inside a section block's
+	// 3000-character limit.
+	approvalPlanLimit = 2800
+
+	approveAction = "approve"
+	denyAction    = "deny"
+)
+
+// Reactions that approve or deny a plan.
+var (
+	approveReactions = []string{"white_check_mark", "heavy_check_mark"}
+	denyReactions    = []string{"x", "no_entry"}
+)
+
+var errNotApproved = errors.New("plan was not approved, nothing was changed")
+
+// approvalOptions makes apply post the plan to an approvals channel and wait
+// for one of the approvers to approve it before anything changes.
+type approvalOptions struct {
+	channel   string
+	approvers []string
+	timeout   time.Duration
+}
+
+func (o *approvalOptions) register(fs *flag.FlagSet) {
+	fs.StringVar(&o.channel, "approval-channel", "", "post the plan to this channel (name or ID) and wait for an approver to approve it before applying")
+	fs.Func("approver", "user ID allowed to approve the plan (repeatable, or comma-separated)", func(s string) error {
+		for id := range strings.SplitSeq(s, ",") {
+			if id = strings.TrimSpace(id); id != "" {
+				o.approvers = append(o.approvers, id)
+			}
+		}
+		return nil
+	})
+	fs.DurationVar(&o.timeout, "approval-timeout", time.Hour, "give up waiting for approval after this long")
+}
+
+// check reports an error for settings the approval step cannot use.
+func (o *approvalOptions) check() error {
+	if o.channel != "" && len(o.approvers) == 0 {
+		return errors.New("-approval-channel needs at least one -approver")
+	}
+	return nil
+}
+
+// approvalDecision is an approver's answer to the approval request.
+type approvalDecision struct {
+	approved bool
+	user     string
+}
+
+// request posts the plan of runs to the approvals channel, which lives in
+// the first run's workspace, and waits for a decision. Approvals come from a
+// ✅ reaction or, when SLACK_APP_TOKEN enables Socket Mode, a button click.
+// The token's own user cannot approve its plan.
+func (o approvalOptions) request(runs []workspaceRun, source string) error {
+	r := runs[0]
+	var self string
+	err := slackops.Call(cmdCtx, r.stats, "identifying the token's user", func(ctx context.Context) error {
+		resp, err := r.client.AuthTestContext(ctx)
+		if err == nil {
+			self = resp.UserID
+		}
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("approval: %w", err)
+	}
+	allowed := func(user string) bool {
+		return user != self && slices.Contains(o.approvers, user)
+	}
+
+	channelID := o.channel
+	if ch, ok := r.channels[strings.TrimPrefix(o.channel, "#")]; ok {
+		channelID = ch.ID
+	}
+	appToken := os.Getenv("SLACK_APP_TOKEN")
+	var ts string
+	err = slackops.Call(cmdCtx, r.stats, "posting the approval request", func(ctx context.Context) error {
+		var err error
+		_, ts, err = r.client.PostMessageContext(ctx, channelID,
+			slack.MsgOptionText("Channel rename approval requested", false),
+			slack.MsgOptionBlocks(o.blocks(runs, source, self, appToken != "")...))
+		return err
+	}, "channel_id", channelID)
+	if err != nil {
+		return fmt.Errorf("post approval request: %w", err)
+	}
+	slog.Info("waiting for approval", "channel", o.channel, "ts", ts, "timeout", o.timeout)
+
+	ctx, cancel := context.WithTimeout(context.Background(), o.timeout)
+	defer cancel()
+	decisions := make(chan approvalDecision, 2)
+	if appToken != "" {
+		go listenForButtons(ctx, appToken, ts, allowed, decisions)
+	}
+	go pollReactions(ctx, r.session, channelID, ts, allowed, decisions)
+
+	var d approvalDecision
+	var reply string
+	select {
+	case d = <-decisions:
+		reply = fmt.Sprintf(":x: Denied by <@%s>. Nothing was changed.", d.user)
+		if d.approved {
+			reply = fmt.Sprintf(":white_check_mark: Approved by <@%s>. Applying now.", d.user)
+		}
+	case <-ctx.Done():
+		reply = fmt.Sprintf(":hourglass: No decision within %v. Nothing was changed.", o.timeout)
+	}
+	err = slackops.Call(cmdCtx, r.stats, "replying to the approval request", func(ctx context.Context) error {
+		_, _, err := r.client.PostMessageContext(ctx, channelID, slack.MsgOptionText(reply, false), slack.MsgOptionTS(ts))
+		return err
+	}, "channel_id", channelID)
+	if err != nil {
+		slog.Warn("failed to reply to the approval request", "err", err)
+	}
+	if !d.approved {
+		if d.user != "" {
+			slog.Error("plan denied", "by", d.user)
+		}
+		return errNotApproved
+	}
+	slog.Info("plan approved", "by", d.user)
+	return nil
+}
+
+// blocks lays out the approval request: who asked, the plan and how to answer.
+func (o approvalOptions) blocks(runs []workspaceRun, source, self string, buttons bool) []slack.Block {
+	plan, total := planListing(runs)
+	var approvers []string
+	for _, u := range o.approvers {
+		approvers = append(approvers, "<@"+u+">")
+	}
+	howTo := "React with :white_check_mark: to approve or :x: to deny."
+	if buttons {
+		howTo = "Click a button, or react with :white_check_mark: to approve or :x: to deny."
+	}
+
+	blocks := []slack.Block{
+		slack.NewHeaderBlock(slack.NewTextBlockObject(slack.PlainTextType, "Channel rename approval requested", false, false)),
+		slack.NewSectionBlock(slack.NewTextBlockObject(slack.MarkdownType,
+			fmt.Sprintf("*Requested by:* <@%s>\n*Source:* %s\n*Entries:* %d", self, source, total), false, false), nil, nil),
+		slack.NewSectionBlock(slack.NewTextBlockObject(slack.MarkdownType, "```"+plan+"```", false, false), nil, nil),
+		slack.NewContextBlock("", slack.NewTextBlockObject(slack.MarkdownType,
+			howTo+" Approvers: "+strings.Join(approvers, ", "), false, false)),
+	}
+	if buttons {
+		blocks = append(blocks, slack.NewActionBlock("approval",
+			slack.NewButtonBlockElement(approveAction, approveAction, slack.NewTextBlockObject(slack.PlainTextType, "Approve", false, false)).WithStyle(slack.StylePrimary),
+			slack.NewButtonBlockElement(denyAction, denyAction, slack.NewTextBlockObject(slack.PlainTextType, "Deny", false, false)).WithStyle(slack.StyleDanger),
+		))
+	}
+	return blocks
+}
+
+// planListing lists the entries of runs, one per line, as far as they fit in
+// a section block, and returns the listing with the number of entries.
+func planListing(runs []workspaceRun) (string, int) {
+	var plan strings.Builder
+	total, listed := 0, 0
+	for _, r := range runs {
+		for _, e := range r.plan {
+			total++
+			line := r.label() + e.String() + "\n"
+			if plan.Len()+len(line) > approvalPlanLimit {
+				continue
+			}
+			plan.WriteString(line)
+			listed++
+		}
+	}
+	if listed < total {
+		fmt.Fprintf(&plan, "... and %d more\n", total-listed)
+	}
+	return plan.String(), total
+}
+
+// pollReactions reads the reactions on the approval message every
+// approvalPoll until an allowed user has approved or denied it. A denial
+// wins over an approval seen in the same poll.
+func pollReactions(ctx context.Context, s *session, channelID, ts string, allowed func(string) bool, decisions chan<- approvalDecision) {
+	tick := time.NewTicker(approvalPoll)
+	defer tick.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-tick.C:
+		}
+		var item slack.ReactedItem
+		err := slackops.Call(cmdCtx, s.stats, "reading approval reactions", func(ctx context.Context) error {
+			var err error
+			item, err = s.client.GetReactionsContext(ctx, slack.NewRefToMessage(channelID, ts), slack.GetReactionsParameters{Full: true})
+			return err
+		}, "channel_id", channelID)
+		if err != nil {
+			slog.Warn("failed to read approval reactions", "err", err)
+			continue
+		}
+		var approvedBy string
+		for _, reaction := range item.Reactions {
+			for _, user := range reaction.Users {
+				switch {
+				case !allowed(user):
+				case slices.Contains(denyReactions, reaction.Name):
+					decisions <- approvalDecision{user: user}
+					return
+				case slices.Contains(approveReactions, reaction.Name):
+					approvedBy = user
+				}
+			}
+		}
+		if approvedBy != "" {
+			decisions <- approvalDecision{approved: true, user: approvedBy}
+			return
+		}
+	}
+}
+
+// listenForButtons connects with Socket Mode and waits for an allowed user
+// to click Approve or Deny on the approval message with timestamp ts.
+func listenForButtons(ctx context.Context, appToken, ts string, allowed func(string) bool, decisions chan<- approvalDecision) {
+	client := socketmode.New(slack.New("", append(slackOptions(), slack.OptionAppLevelToken(appToken))...))
+	go func() {
+		if err := client.RunContext(ctx); err != nil && ctx.Err() == nil {
+			slog.Warn("Socket Mode stopped, waiting for reactions only", "err", err)
+		}
+	}()
+	for {
+		var evt socketmode.Event
+		select {
+		case <-ctx.Done():
+			return
+		case evt = <-client.Events:
+		}
+		switch evt.Type {
+		case socketmode.EventTypeInvalidAuth:
+			slog.Warn("SLACK_APP_TOKEN was rejected, waiting for reactions only")
+			return
+		case socketmode.EventTypeInteractive:
+			if evt.Request != nil {
+				client.Ack(*evt.Request)
+			}
+			cb, ok := evt.Data.(slack.InteractionCallback)
+			if !ok || cb.Type != slack.InteractionTypeBlockActions || cb.Container.MessageTs != ts || !allowed(cb.User.ID) {
+				continue
+			}
+			for _, a := range cb.ActionCallback.BlockActions {
+				if a.ActionID == approveAction || a.ActionID == denyAction {
+					decisions <- approvalDecision{approved: a.ActionID == approveAction, user: cb.User.ID}
+					return
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,273 @@
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"log/slog"
+	"maps"
+	"os"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/kiddikn/slack-channel-renamer/plan"
+	"github.com/kiddikn/slack-channel-renamer/slackops"
+	"github.com/slack-go/slack"
+)
+
+// inactiveChannel is a channel 'audit inactive' found idle.
+type inactiveChannel struct {
+	Workspace    string    `json:"workspace,omitempty"`
+	Name         string    `json:"name"`
+	ID           string    `json:"id"`
+	Members      int       `json:"members"`
+	LastActivity time.Time `json:"last_activity"`
+	IdleDays     int       `json:"idle_days"`
+}
+
+// inactiveOptions selects the channels 'audit inactive' reports.
+type inactiveOptions struct {
+	idle       time.Duration
+	maxMembers int
+	match      *regexp.Regexp
+	now        time.Time
+}
+
+// parseIdle reads -idle: a Go duration, or a number of days or weeks such as
+// 90d or 12w.
+func parseIdle(s string) (time.Duration, error) {
+	for suffix, unit := range map[string]time.Duration{"d": 24 * time.Hour, "w": 7 * 24 * time.Hour} {
+		if n, ok := strings.CutSuffix(s, suffix); ok {
+			v, err := strconv.Atoi(n)
+			if err != nil || v <= 0 {
+				return 0, fmt.Errorf("invalid idle time %q", s)
+			}
+			return time.Duration(v) * unit, nil
+		}
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil || d <= 0 {
+		return 0, fmt.Errorf("invalid idle time %q (want e.g. 90d, 12w or 2160h)", s)
+	}
+	return d, nil
+}
+
+// parseSlackTS returns the time of a message timestamp such as
+// "1700000000.000100".
+func parseSlackTS(ts string) (time.Time, error) {
+	sec, frac, _ := strings.Cut(ts, ".")
+	s, err := strconv.ParseInt(sec, 10, 64)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid message timestamp %q", ts)
+	}
+	us, _ := strconv.ParseInt((frac + "000000")[:6], 10, 64)
+	return time.Unix(s, us*1000), nil
+}
+
+// lastActivity returns the time of the newest message in ch, or when it was
+// created if it has none.
+func (s *session) lastActivity(ctx context.Context, name string, ch channelInfo) (time.Time, error) {
+	var resp *slack.GetConversationHistoryResponse
+	err := slackops.Call(ctx, s.stats, "reading the history of "+name, func(ctx context.Context) error {
+		var err error
+		resp, err = s.client.GetConversationHistoryContext(ctx, &slack.GetConversationHistoryParameters{ChannelID: ch.ID, Limit: 1})
+		return err
+	}, "channel", name, "channel_id", ch.ID)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if len(resp.Messages) == 0 {
+		return ch.Created, nil
+	}
+	return parseSlackTS(resp.Messages[0].Timestamp)
+}
+
+// inactiveChannels returns the session's unarchived channels, other than
+// #general, whose newest message is older than opts.idle, in name order.
+// Channels whose history cannot be read are logged and left out.
+func (s *session) inactiveChannels(ctx context.Context, opts inactiveOptions) ([]inactiveChannel, error) {
+	channels, err := s.listChannels(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var idle []inactiveChannel
+	for _, name := range slices.Sorted(maps.Keys(channels)) {
+		ch := channels[name]
+		if ch.IsArchived || ch.IsGeneral {
+			continue
+		}
+		if opts.maxMembers > 0 && ch.NumMembers > opts.maxMembers {
+			continue
+		}
+		if opts.match != nil && !opts.match.MatchString(name) {
+			continue
+		}
+		last, err := s.lastActivity(ctx, name, ch)
+		if err != nil {
+			slog.Warn(s.label()+"skipping a channel whose history cannot be read", "channel", name, "err", err)
+			continue
+		}
+		if age := opts.now.Sub(last); age >= opts.idle {
+			idle = append(idle, inactiveChannel{
+				Workspace:    s.workspace,
+				Name:         name,
+				ID:           ch.ID,
+				Members:      ch.NumMembers,
+				LastActivity: last.UTC(),
+				IdleDays:     int(age / (24 * time.Hour)),
+			})
+		}
+	}
+	return idle, nil
+}
+
+// archivedName returns the name 'audit inactive -generate rename' gives an
+// idle channel, cut to Slack's 80 characters.
+func archivedName(prefix, name string) string {
+	tobe := prefix + name
+	if r := []rune(tobe); len(r) > 80 {
+		tobe = string(r[:80])
+	}
+	return tobe
+}
+
+// writeInactivePlan writes a plan that archives the idle channels, or renames
+// them with prefix. Channels already carrying prefix are left out of a rename
+// plan.
+func writeInactivePlan(w io.Writer, idle []inactiveChannel, generate, prefix string, withWorkspace bool) (int, error) {
+	header := []string{"asis", "tobe", "action"}
+	if withWorkspace {
+		header = append(header, "workspace")
+	}
+	cw := csv.NewWriter(w)
+	cw.Write(header)
+	n := 0
+	for _, c := range idle {
+		row := []string{c.Name, "", actionArchive}
+		if generate == "rename" {
+			if strings.HasPrefix(c.Name, prefix) {
+				continue
+			}
+			row = []string{c.Name, archivedName(prefix, c.Name), actionRename}
+		}
+		if withWorkspace {
+			row = append(row, c.Workspace)
+		}
+		cw.Write(row)
+		n++
+	}
+	cw.Flush()
+	return n, cw.Error()
+}
+
+func printInactive(w io.Writer, idle []inactiveChannel) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "WORKSPACE\tCHANNEL\tMEMBERS\tLAST ACTIVITY\tIDLE DAYS")
+	for _, c := range idle {
+		fmt.Fprintf(tw, "%s\t%s\t%d\t%s\t%d\n", c.Workspace, c.Name, c.Members, c.LastActivity.Format(time.DateOnly), c.IdleDays)
+	}
+	return tw.Flush()
+}
+
+func cmdAudit(args []string) int {
+	if len(args) == 0 {
+		newFlagSet("audit").Usage()
+		return 2
+	}
+	switch args[0] {
+	case "inactive":
+		return cmdAuditInactive(args[1:])
+	case "duplicates":
+		return cmdAuditDuplicates(args[1:])
+	}
+	fmt.Fprintf(os.Stderr, "unknown audit command %q (want inactive or duplicates)\n", args[0])
+	return 2
+}
+
+// cmdAuditInactive runs 'audit inactive', which reports the channels nobody
+// has posted in for a while and can write a plan archiving or renaming them.
+func cmdAuditInactive(args []string) int {
+	fs := newFlagSet("audit")
+	var channelOpts channelOptions
+	channelOpts.register(fs)
+	var ws workspaceOptions
+	ws.register(fs)
+	opts := inactiveOptions{idle: 90 * 24 * time.Hour}
+	fs.Func("idle", "report channels without a message for this long, e.g. 90d, 12w or 2160h (default 90d)", func(s string) error {
+		var err error
+		opts.idle, err = parseIdle(s)
+		return err
+	})
+	fs.IntVar(&opts.maxMembers, "max-members", 0, "only report channels with at most this many members (0 for any)")
+	match := fs.String("match", "", "only report channels whose name matches this regular expression")
+	generate := fs.String("generate", "", "write a plan instead of the report: 'archive' archives the idle channels, 'rename' renames them with -prefix")
+	prefix := fs.String("prefix", "zz-archived-", "with -generate rename, the prefix of the new names")
+	out := fs.String("out", "", "write to this file instead of stdout")
+	registerOutput(fs)
+	parseFlags(fs, args)
+	if *generate != "" && *generate != "archive" && *generate != "rename" {
+		slog.Error("-generate must be archive or rename", "generate", *generate)
+		return 2
+	}
+	if *generate == "rename" && !plan.ChannelNameRe.MatchString(archivedName(*prefix, "x")) {
+		slog.Error("-prefix does not make valid channel names", "prefix", *prefix)
+		return 2
+	}
+	if *match != "" {
+		re, err := regexp.Compile(*match)
+		if err != nil {
+			slog.Error("invalid -match", "err", err)
+			return 2
+		}
+		opts.match = re
+	}
+
+	sessions, err := ws.newSessions(channelOpts)
+	if err != nil {
+		slog.Error(err.Error())
+		return 1
+	}
+	opts.now = time.Now()
+	var idle []inactiveChannel
+	for _, s := range sessions {
+		found, err := s.inactiveChannels(cmdCtx, opts)
+		if err != nil {
+			slog.Error(err.Error())
+			return 1
+		}
+		idle = append(idle, found...)
+	}
+
+	w := io.Writer(os.Stdout)
+	if *out != "" {
+		f, err := os.Create(*out)
+		if err != nil {
+			slog.Error("failed to create output file", "file", *out, "err", err)
+			return 1
+		}
+		defer f.Close()
+		w = f
+	}
+	switch {
+	case *generate != "":
+		n, err := writeInactivePlan(w, idle, *generate, *prefix, ws.config != "")
+		if err != nil {
+			slog.Error("failed to write plan", "err", err)
+			return 1
+		}
+		slog.Info("generated rows for idle channels", "action", *generate, "count", n)
+	case output == outputJSON:
+		report.Inactive = idle
+	default:
+		if err := printInactive(w, idle); err != nil {
+			slog.Error("failed to write report", "err", err)
+			return 1
+		}
+	}
+	return 0
+}
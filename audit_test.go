@@ -0,0 +1,127 @@
+package main
+
+import (
+	"bytes"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/kiddikn/slack-channel-renamer/plan"
+	"github.com/kiddikn/slack-channel-renamer/slackops"
+	"github.com/slack-go/slack"
+)
+
+func TestInactiveChannels(t *testing.T) {
+	now := time.Date(2026, 10, 14, 0, 0, 0, 0, time.UTC)
+	w := newFakeWorkspace("general", "busy", "quiet", "empty", "broken")
+	w.channels[0].IsGeneral = true
+	w.channels[3].Created = slack.JSONTime(now.AddDate(-1, 0, 0).Unix())
+	w.latest = map[string]string{
+		"C1": "1000.000100",
+		"C2": "1791936000.000200",
+		"C3": "1700000000.000300",
+	}
+	w.fail["history C5"] = slack.SlackErrorResponse{Err: "not_in_channel"}
+	s := &session{client: w, stats: &slackops.Stats{}}
+
+	idle, err := s.inactiveChannels(t.Context(), inactiveOptions{idle: 90 * 24 * time.Hour, now: now})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(idle) != 2 || idle[0].Name != "empty" || idle[1].Name != "quiet" || idle[0].IdleDays != 365 {
+		t.Fatalf("idle = %+v, want empty and quiet", idle)
+	}
+
+	var buf bytes.Buffer
+	if _, err := writeInactivePlan(&buf, append(idle, inactiveChannel{Name: "zz-archived-old"}), "rename", "zz-archived-", false); err != nil {
+		t.Fatal(err)
+	}
+	if want := "asis,tobe,action\nempty,zz-archived-empty,rename\nquiet,zz-archived-quiet,rename\n"; buf.String() != want {
+		t.Errorf("plan = %q, want %q", buf.String(), want)
+	}
+}
+
+func TestParseIdle(t *testing.T) {
+	for in, want := range map[string]time.Duration{"90d": 90 * 24 * time.Hour, "2w": 14 * 24 * time.Hour, "36h": 36 * time.Hour} {
+		if got, err := parseIdle(in); err != nil || got != want {
+			t.Errorf("parseIdle(%q) = %v, %v, want %v", in, got, err, want)
+		}
+	}
+	for _, in := range []string{"", "0d", "-3d", "soon"} {
+		if _, err := parseIdle(in); err == nil {
+			t.Errorf("parseIdle(%q) did not fail", in)
+		}
+	}
+}
+
+func TestAddChannelStats(t *testing.T) {
+	w := newFakeWorkspace("big", "fresh")
+	w.channels[0].NumMembers, w.channels[0].Creator = 4000, "U0456EFGH"
+	w.channels[0].Created = slack.JSONTime(time.Date(2021, 4, 1, 9, 0, 0, 0, time.UTC).Unix())
+	w.latest = map[string]string{"C1": "1791936000.000100"}
+	s := &session{client: w, stats: &slackops.Stats{}}
+	channels, err := s.listChannels(t.Context())
+	if err != nil {
+		t.Fatal(err)
+	}
+	runs := []workspaceRun{{session: s, channels: channels, plan: []planEntry{
+		{Action: actionRename, Asis: "big", Tobe: "huge"},
+		{Action: actionSetTopic, Asis: "big", Topic: "Everyone"},
+		{Action: actionCreate, Asis: "fresh-start"},
+	}}}
+	addChannelStats(t.Context(), runs)
+	plan := runs[0].plan
+	if plan[2].Stats != nil {
+		t.Errorf("create entry has stats %v", plan[2].Stats)
+	}
+	want := "4000 members, created 2021-04-01 by U0456EFGH, last message 2026-10-14"
+	if plan[0].Stats == nil || plan[0].Stats.String() != want {
+		t.Fatalf("stats = %v, want %q", plan[0].Stats, want)
+	}
+	if n := slices.Index(w.calls, "[history C1]"); n < 0 || slices.Contains(w.calls[n+1:], "[history C1]") {
+		t.Errorf("calls = %q, want the history of C1 read once", w.calls)
+	}
+}
+
+func TestFindDuplicates(t *testing.T) {
+	created := func(days int) time.Time { return time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC).AddDate(0, 0, days) }
+	channels := map[string]channelInfo{
+		"general":          {ID: "C0", IsGeneral: true},
+		"proj-alpha":       {ID: "C1", NumMembers: 40, Created: created(0)},
+		"proj_alpha-2":     {ID: "C2", NumMembers: 3, Created: created(10)},
+		"proj-aplha":       {ID: "C3", NumMembers: 40, Created: created(5)},
+		"design-crit":      {ID: "C4", NumMembers: 8, Topic: "weekly design critique for the mobile app"},
+		"mobile-reviews":   {ID: "C5", NumMembers: 9, Topic: "Weekly design critique for the mobile app!"},
+		"ops":              {ID: "C6"},
+		"dev":              {ID: "C7"},
+		"proj-alpha-old-x": {ID: "C8", IsArchived: true},
+	}
+	clusters := findDuplicates(channels, duplicateOptions{maxDistance: 2})
+	var got [][]string
+	for _, c := range clusters {
+		names := []string{c.Keep}
+		for _, ch := range c.Channels {
+			names = append(names, ch.Name)
+		}
+		got = append(got, names)
+	}
+	want := [][]string{
+		{"mobile-reviews", "design-crit", "mobile-reviews"},
+		{"proj-alpha", "proj-alpha", "proj-aplha", "proj_alpha-2"},
+	}
+	if !slices.EqualFunc(got, want, slices.Equal) {
+		t.Errorf("clusters = %q, want %q", got, want)
+	}
+
+	var buf bytes.Buffer
+	if _, err := writeDuplicatePlan(&buf, clusters[1:], false); err != nil {
+		t.Fatal(err)
+	}
+	plan, err := plan.LoadCSV("dups.csv", buf.Bytes(), plan.Options{})
+	if err != nil {
+		t.Fatalf("draft plan does not load: %v\n%s", err, buf.String())
+	}
+	if len(plan) != 2 || plan[0].Action != actionMerge || plan[0].Tobe != "proj-alpha" {
+		t.Errorf("plan = %v", plan)
+	}
+}
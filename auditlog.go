@@ -0,0 +1,186 @@
+package main
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/user"
+	"sync"
+	"time"
+)
+
+// auditLog is the -audit-log file, or nil when no audit log is kept.
+var auditLog *auditWriter
+
+// auditWriter appends one JSON line per decision of the command to the audit
+// log: its invocation, the validation outcome, every Slack API call, every
+// entry's result and its exit. The file is only ever appended to, and each
+// line is written with a single write, so concurrent commands sharing one
+// log do not interleave.
+type auditWriter struct {
+	mu   sync.Mutex
+	f    *os.File
+	run  string
+	opr  string
+	errd bool
+
+	// historyRun and actors are filled in once a run starts: its number in
+	// the history database and the Slack user of each workspace's token.
+	historyRun uint64
+	actors     map[string]string
+}
+
+// The events of the audit log.
+const (
+	auditCommand    = "command"
+	auditValidation = "validation"
+	auditRunStarted = "run_started"
+	auditAPICall    = "api_call"
+	auditEntry      = "entry"
+	auditExit       = "exit"
+)
+
+// auditRecord is one line of the audit log.
+type auditRecord struct {
+	Time       time.Time `json:"time"`
+	Event      string    `json:"event"`
+	Run        string    `json:"run"`
+	HistoryRun uint64    `json:"history_run,omitempty"`
+	// Operator is the operating system user and host running the command;
+	// Actor is the Slack user of the workspace's token.
+	Operator  string `json:"operator"`
+	Workspace string `json:"workspace,omitempty"`
+	Actor     string `json:"actor,omitempty"`
+
+	Args       []string          `json:"args,omitempty"`
+	Validation *validationReport `json:"validation,omitempty"`
+	Source     string            `json:"source,omitempty"`
+	Call       *auditCall        `json:"call,omitempty"`
+	Entry      *entryResult      `json:"entry,omitempty"`
+	ExitCode   *int              `json:"exit_code,omitempty"`
+}
+
+// auditCall is one attempt at a Slack API call.
+type auditCall struct {
+	Op       string         `json:"op"`
+	Attempt  int            `json:"attempt"`
+	Duration string         `json:"duration"`
+	Error    string         `json:"error,omitempty"`
+	Attrs    map[string]any `json:"attrs,omitempty"`
+}
+
+// openAuditLog opens path for -audit-log, creating it if needed, and records
+// the command line.
+func openAuditLog(path string) error {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o600)
+	if err != nil {
+		return fmt.Errorf("open audit log: %w", err)
+	}
+	b := make([]byte, 4)
+	rand.Read(b)
+	name := "unknown"
+	if u, err := user.Current(); err == nil {
+		name = u.Username
+	}
+	host, _ := os.Hostname()
+	auditLog = &auditWriter{
+		f:      f,
+		run:    time.Now().UTC().Format("20060102T150405Z") + "-" + hex.EncodeToString(b),
+		opr:    name + "@" + host,
+		actors: make(map[string]string),
+	}
+	auditLog.write(auditRecord{Event: auditCommand, Args: os.Args[1:]})
+	return nil
+}
+
+// write completes r with the run and operator and appends it. A failure to
+// write is logged once; the command goes on.
+func (a *auditWriter) write(r auditRecord) {
+	if a == nil {
+		return
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	r.Time = time.Now().UTC()
+	r.Run, r.Operator, r.HistoryRun = a.run, a.opr, a.historyRun
+	if r.Actor == "" {
+		r.Actor = a.actors[r.Workspace]
+	}
+	b, err := json.Marshal(r)
+	if err == nil {
+		_, err = a.f.Write(append(b, '\n'))
+	}
+	if err != nil && !a.errd {
+		a.errd = true
+		slog.Error("failed to write the audit log", "file", a.f.Name(), "err", err)
+	}
+}
+
+// validation records the outcome of validating the plan.
+func (a *auditWriter) validation(errs, skipped []string) {
+	if a == nil {
+		return
+	}
+	a.write(auditRecord{Event: auditValidation, Validation: &validationReport{Passed: len(errs) == 0, Errors: errs, Skipped: skipped}})
+}
+
+// runStarted records the start of a run of source, numbered historyRun in
+// the history database, and the Slack user acting on each workspace.
+func (a *auditWriter) runStarted(runs []workspaceRun, source string, historyRun uint64) {
+	if a == nil {
+		return
+	}
+	a.mu.Lock()
+	a.historyRun = historyRun
+	for _, r := range runs {
+		if r.auth != nil {
+			a.actors[r.workspace] = r.auth.User
+		}
+	}
+	a.mu.Unlock()
+	for _, r := range runs {
+		a.write(auditRecord{Event: auditRunStarted, Workspace: r.workspace, Source: source})
+	}
+}
+
+// call records one attempt at a Slack API call; attrs are its slog fields.
+func (a *auditWriter) call(op string, attempt int, took time.Duration, err error, attrs []any) {
+	if a == nil {
+		return
+	}
+	c := &auditCall{Op: op, Attempt: attempt, Duration: took.Round(time.Millisecond).String()}
+	if err != nil {
+		c.Error = err.Error()
+	}
+	for i := 0; i+1 < len(attrs); i += 2 {
+		if c.Attrs == nil {
+			c.Attrs = make(map[string]any)
+		}
+		c.Attrs[fmt.Sprint(attrs[i])] = attrs[i+1]
+	}
+	workspace, _ := c.Attrs["workspace"].(string)
+	a.write(auditRecord{Event: auditAPICall, Workspace: workspace, Call: c})
+}
+
+// entry records an entry's result.
+func (a *auditWriter) entry(r entryResult) {
+	if a == nil {
+		return
+	}
+	a.write(auditRecord{Event: auditEntry, Workspace: r.Workspace, Entry: &r})
+}
+
+// close records the command's exit code and syncs the file.
+func (a *auditWriter) close(code int) {
+	if a == nil {
+		return
+	}
+	a.write(auditRecord{Event: auditExit, ExitCode: &code})
+	if err := a.f.Sync(); err != nil {
+		slog.Error("failed to sync the audit log", "file", a.f.Name(), "err", err)
+	}
+	a.f.Close()
+}
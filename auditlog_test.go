@@ -0,0 +1,74 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestAuditLog(t *testing.T) {
+	dir := t.TempDir()
+	snapshot := filepath.Join(dir, "snapshot.json")
+	if err := os.WriteFile(snapshot, []byte(`[{"name":"alpha","id":"C1"},{"name":"beta","id":"C2"}]`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, "audit.jsonl")
+	if err := openAuditLog(path); err != nil {
+		t.Fatal(err)
+	}
+	defer func() { auditLog = nil }()
+
+	sessions, err := (workspaceOptions{simulate: snapshot}).newSessions(channelOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	plan := []planEntry{{Action: actionRename, Asis: "alpha", Tobe: "alpha-2"}}
+	runs, errs, skipped, err := validateRuns(sessions, workspaceOptions{}, plan, prepareOptions{})
+	if err != nil || !reportValidation(errs, skipped) {
+		t.Fatalf("validateRuns: %v %q", err, errs)
+	}
+	if _, err := executeRuns(runs, runOptions{verb: "rename", pool: poolOptions{concurrency: 1, perMinute: 60}}); err != nil {
+		t.Fatal(err)
+	}
+	auditLog.close(0)
+
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	var events []string
+	var run string
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		var r auditRecord
+		if err := json.Unmarshal(sc.Bytes(), &r); err != nil {
+			t.Fatalf("line %q: %v", sc.Text(), err)
+		}
+		if run == "" {
+			run = r.Run
+		}
+		if r.Run != run || r.Operator == "" {
+			t.Errorf("line %q does not carry the run %s and the operator", sc.Text(), run)
+		}
+		events = append(events, r.Event)
+		switch r.Event {
+		case auditValidation:
+			if !r.Validation.Passed {
+				t.Errorf("validation = %+v, want passed", r.Validation)
+			}
+		case auditEntry:
+			if r.Entry.Asis != "alpha" || r.Entry.Status != resultOK {
+				t.Errorf("entry = %+v, want alpha renamed", r.Entry)
+			}
+		}
+	}
+	for _, want := range []string{auditCommand, auditValidation, auditRunStarted, auditAPICall, auditEntry, auditExit} {
+		if !slices.Contains(events, want) {
+			t.Errorf("events = %q, missing %s", events, want)
+		}
+	}
+}
@@ -0,0 +1,242 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/slack-go/slack"
+)
+
+const (
+	slackAuthorizeURL = "https://slack.com/oauth/v2/authorize"
+	// loginTimeout bounds how long 'auth login' waits for the browser.
+	loginTimeout = 5 * time.Minute
+)
+
+// Values of 'auth login -store'.
+const (
+	storeKeyring = "keyring"
+	storeFile    = "file"
+)
+
+// defaultUserScopes are the user token scopes rename runs need; see the
+// scope table in the README. -include-private adds the groups: ones.
+var (
+	defaultUserScopes = []string{"channels:read", "channels:write", "channels:write.invites", "chat:write", "reactions:read", "im:write"}
+	privateUserScopes = []string{"groups:read", "groups:write", "groups:write.invites"}
+)
+
+func cmdAuth(args []string) int {
+	if len(args) == 0 {
+		newFlagSet("auth").Usage()
+		return 2
+	}
+	switch args[0] {
+	case "login":
+		return cmdAuthLogin(args[1:])
+	}
+	fmt.Fprintf(os.Stderr, "unknown auth command %q (want login)\n", args[0])
+	return 2
+}
+
+// cmdAuthLogin runs the OAuth v2 flow of the Slack app named by its client
+// ID and secret: it opens the authorize page in a browser, catches the
+// redirect on localhost, exchanges the code for a user token and stores the
+// token for the workspace it belongs to.
+func cmdAuthLogin(args []string) int {
+	fs := newFlagSet("auth")
+	clientIDEnv := fs.String("client-id-env", "SLACK_CLIENT_ID", "environment variable holding the Slack app's client ID")
+	clientSecretEnv := fs.String("client-secret-env", "SLACK_CLIENT_SECRET", "environment variable holding the Slack app's client secret")
+	port := fs.Int("port", 8977, "localhost port of the redirect URL, http://localhost:PORT/callback, which must be listed in the app's Redirect URLs")
+	var scopes stringList
+	fs.Var(&scopes, "scope", "user token scope to request instead of the default ones (repeatable)")
+	includePrivate := fs.Bool("include-private", false, "also request the scopes for private channels")
+	team := fs.String("team", "", "ID of the workspace to install into, skipping Slack's workspace picker")
+	store := fs.String("store", storeKeyring, "where to keep the token: keyring (the OS keyring) or file (a file in the user config directory)")
+	noBrowser := fs.Bool("no-browser", false, "print the authorize URL instead of opening a browser")
+	parseFlags(fs, args)
+
+	if *store != storeKeyring && *store != storeFile {
+		slog.Error(fmt.Sprintf("-store must be %s or %s, got %q", storeKeyring, storeFile, *store))
+		return 2
+	}
+	clientID, clientSecret := os.Getenv(*clientIDEnv), os.Getenv(*clientSecretEnv)
+	if clientID == "" || clientSecret == "" {
+		slog.Error(fmt.Sprintf("set %s and %s to the app's client ID and secret (Basic Information > App Credentials)", *clientIDEnv, *clientSecretEnv))
+		return 2
+	}
+	if len(scopes) == 0 {
+		scopes = append(scopes, defaultUserScopes...)
+		if *includePrivate {
+			scopes = append(scopes, privateUserScopes...)
+		}
+	}
+
+	ctx, stop := interruptContext(cmdCtx)
+	defer stop()
+	ctx, cancel := context.WithTimeout(ctx, loginTimeout)
+	defer cancel()
+
+	resp, err := oauthLogin(ctx, clientID, clientSecret, *port, scopes, *team, !*noBrowser)
+	if err != nil {
+		slog.Error("login failed", "err", err)
+		return 1
+	}
+	token := resp.AuthedUser.AccessToken
+	if token == "" {
+		slog.Error("Slack returned no user token; are the scopes user token scopes?")
+		return 1
+	}
+	if resp.AuthedUser.ExpiresIn > 0 {
+		slog.Warn("token rotation is on for the app, so the token expires; turn it off to keep using it", "expires_in", time.Duration(resp.AuthedUser.ExpiresIn)*time.Second)
+	}
+
+	ref, err := storeToken(ctx, *store, resp.Team, token)
+	if err != nil {
+		slog.Error("failed to store the token", "err", err)
+		return 1
+	}
+	fmt.Printf("logged in to %s (%s) with scopes %s\n", resp.Team.Name, resp.Team.ID, resp.AuthedUser.Scope)
+	fmt.Printf("use the token with:\n  export SLACK_TOKEN_REF=%s\nor, in a -config file:\n  token_ref: %s\n", ref, ref)
+	return 0
+}
+
+// oauthLogin sends the user to Slack's authorize page and returns the
+// response to the code the redirect brings back.
+func oauthLogin(ctx context.Context, clientID, clientSecret string, port int, scopes []string, team string, openBrowser bool) (*slack.OAuthV2Response, error) {
+	ln, err := net.Listen("tcp", fmt.Sprintf("127.0.0.1:%d", port))
+	if err != nil {
+		return nil, fmt.Errorf("listen for the redirect: %w", err)
+	}
+	redirect := fmt.Sprintf("http://localhost:%d/callback", port)
+	b := make([]byte, 16)
+	rand.Read(b)
+	state := hex.EncodeToString(b)
+
+	q := url.Values{"client_id": {clientID}, "user_scope": {strings.Join(scopes, ",")}, "redirect_uri": {redirect}, "state": {state}}
+	if team != "" {
+		q.Set("team", team)
+	}
+	authorize := slackAuthorizeURL + "?" + q.Encode()
+
+	type result struct {
+		code string
+		err  error
+	}
+	got := make(chan result, 1)
+	mux := http.NewServeMux()
+	mux.HandleFunc("/callback", func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		var res result
+		switch {
+		case q.Get("state") != state:
+			http.Error(w, "state mismatch", http.StatusBadRequest)
+			return // not our redirect; keep waiting
+		case q.Get("error") != "":
+			res.err = fmt.Errorf("authorization denied: %s", q.Get("error"))
+		default:
+			res.code = q.Get("code")
+		}
+		if res.err != nil {
+			fmt.Fprintln(w, "Login failed; see the terminal.")
+		} else {
+			fmt.Fprintln(w, "Logged in. You can close this tab and return to the terminal.")
+		}
+		select {
+		case got <- res:
+		default:
+		}
+	})
+	srv := &http.Server{Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+	go srv.Serve(ln)
+	defer srv.Close()
+
+	fmt.Printf("open this URL to authorize the app:\n  %s\n", authorize)
+	if openBrowser {
+		if err := browse(authorize); err != nil {
+			slog.Warn("could not open a browser; open the URL yourself", "err", err)
+		}
+	}
+	var res result
+	select {
+	case <-ctx.Done():
+		return nil, fmt.Errorf("no redirect came back: %w", ctx.Err())
+	case res = <-got:
+	}
+	if res.err != nil {
+		return nil, res.err
+	}
+	resp, err := slack.GetOAuthV2ResponseContext(ctx, netClient(), clientID, clientSecret, res.code, redirect)
+	if err != nil {
+		return nil, fmt.Errorf("oauth.v2.access: %w", err)
+	}
+	return resp, nil
+}
+
+// browse opens url in the default browser.
+func browse(url string) error {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		cmd = exec.Command("open", url)
+	case "windows":
+		cmd = exec.Command("rundll32", "url.dll,FileProtocolHandler", url)
+	default:
+		cmd = exec.Command("xdg-open", url)
+	}
+	return cmd.Start()
+}
+
+// storeToken keeps token for team in the keyring or a file and returns the
+// reference that reads it back (see resolveTokenRef). Tokens are keyed by
+// team ID, which unlike the name never changes.
+func storeToken(ctx context.Context, store string, team slack.OAuthV2ResponseTeam, token string) (string, error) {
+	if team.ID == "" {
+		return "", errors.New("Slack did not say which workspace the token is for")
+	}
+	if store == storeFile {
+		dir, err := os.UserConfigDir()
+		if err != nil {
+			return "", err
+		}
+		dir = filepath.Join(dir, "slack-channel-renamer", "tokens")
+		if err := os.MkdirAll(dir, 0o700); err != nil {
+			return "", err
+		}
+		path := filepath.Join(dir, team.ID+".token")
+		if err := os.WriteFile(path, []byte(token+"\n"), 0o600); err != nil {
+			return "", err
+		}
+		return "file://" + path, nil
+	}
+
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		// security takes the password only as an argument, so it shows in
+		// the process list for as long as the command runs.
+		cmd = exec.CommandContext(ctx, "security", "add-generic-password", "-U", "-s", keyringService, "-a", team.ID, "-l", "Slack token for "+team.Name, "-w", token)
+	case "windows":
+		return "", errors.New("the OS keyring is not supported on Windows; use -store file")
+	default:
+		cmd = exec.CommandContext(ctx, "secret-tool", "store", "--label", "Slack token for "+team.Name, "service", keyringService, "account", team.ID)
+		cmd.Stdin = strings.NewReader(token)
+	}
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return "", fmt.Errorf("%s: %w: %s", cmd.Args[0], err, strings.TrimSpace(string(out)))
+	}
+	return "keyring://" + team.ID, nil
+}
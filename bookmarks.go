@@ -0,0 +1,111 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"strings"
+
+	"github.com/kiddikn/slack-channel-renamer/slackops"
+	"github.com/slack-go/slack"
+)
+
+// oldNameRe matches name as a whole channel name in text, with or without
+// its #, and not as part of a longer name such as name-archive.
+func oldNameRe(name string) *regexp.Regexp {
+	return regexp.MustCompile(`(^|[^\p{L}\p{N}_-])(#?)` + regexp.QuoteMeta(name) + `($|[^\p{L}\p{N}_-])`)
+}
+
+// replaceOldName replaces every mention of the channel name oldName in text
+// with newName, keeping any # in front of it.
+func replaceOldName(text, oldName, newName string) string {
+	re := oldNameRe(oldName)
+	// Mentions separated by a single character share it, so each pass
+	// replaces every other one.
+	for re.MatchString(text) {
+		text = re.ReplaceAllString(text, "${1}${2}"+strings.ReplaceAll(newName, "$", "$$")+"${3}")
+	}
+	return text
+}
+
+// updateBookmarks rewrites the bookmarks of every channel the run renamed
+// whose titles name the channel's old name, and points out a channel canvas
+// whose title does, since canvases cannot be retitled through the API the
+// tool uses. Each edit is added to the result of the channel's last rename.
+// Failures are logged and do not fail the run.
+func (x *executor) updateBookmarks(channels map[string]channelInfo) {
+	edited := 0
+	for _, c := range x.renamedChannels(channels) {
+		attrs := []any{"channel_id", c.id, "asis", c.oldName, "tobe", c.newName}
+		var edits []string
+		var bookmarks []slack.Bookmark
+		err := slackops.Call(cmdCtx, x.Stats, fmt.Sprintf("listing the bookmarks of %s", c.newName), func(ctx context.Context) error {
+			var err error
+			bookmarks, err = x.Client.ListBookmarksContext(ctx, c.id)
+			return err
+		}, attrs...)
+		if err != nil {
+			slog.Warn("failed to list the channel's bookmarks", append(attrs, "err", err)...)
+		}
+		for _, b := range bookmarks {
+			title := replaceOldName(b.Title, c.oldName, c.newName)
+			if title == b.Title {
+				continue
+			}
+			err := slackops.Call(cmdCtx, x.Stats, fmt.Sprintf("updating a bookmark of %s", c.newName), func(ctx context.Context) error {
+				_, err := x.Client.EditBookmarkContext(ctx, c.id, b.ID, slack.EditBookmarkParameters{Title: &title})
+				return err
+			}, append(attrs, "bookmark_id", b.ID)...)
+			if err != nil {
+				slog.Warn("failed to update a bookmark", append(attrs, "bookmark_id", b.ID, "err", err)...)
+				edits = append(edits, fmt.Sprintf("bookmark %q not updated: %v", b.Title, err))
+				continue
+			}
+			slog.Info("updated a bookmark naming the old channel name", append(attrs, "bookmark_id", b.ID, "title", title)...)
+			edits = append(edits, fmt.Sprintf("bookmark %q -> %q", b.Title, title))
+			edited++
+		}
+		if title := x.canvasTitle(c.id, attrs); title != "" && oldNameRe(c.oldName).MatchString(title) {
+			slog.Warn("the channel canvas's title names the old channel name; retitle it by hand", append(attrs, "canvas_title", title)...)
+			edits = append(edits, fmt.Sprintf("canvas %q still names #%s; retitle it by hand", title, c.oldName))
+		}
+		x.addEdits(c, edits)
+	}
+	slog.Info(x.label+"updated bookmarks naming old channel names", "bookmarks", edited)
+}
+
+// canvasTitle returns the title of the channel's canvas, or "" if it has
+// none or it cannot be read.
+func (x *executor) canvasTitle(id string, attrs []any) string {
+	var info *slack.Channel
+	err := slackops.Call(cmdCtx, x.Stats, "looking up the channel canvas", func(ctx context.Context) error {
+		var err error
+		info, err = x.Client.GetConversationInfoContext(ctx, &slack.GetConversationInfoInput{ChannelID: id})
+		return err
+	}, attrs...)
+	if err != nil || info.Properties == nil || info.Properties.Canvas.FileId == "" {
+		return ""
+	}
+	var file *slack.File
+	err = slackops.Call(cmdCtx, x.Stats, "reading the channel canvas", func(ctx context.Context) error {
+		var err error
+		file, _, _, err = x.Client.GetFileInfoContext(ctx, info.Properties.Canvas.FileId, 0, 0)
+		return err
+	}, attrs...)
+	if err != nil {
+		slog.Debug("failed to read the channel canvas", append(attrs, "err", err)...)
+		return ""
+	}
+	return file.Title
+}
+
+// addEdits adds edits to the result of the rename that gave c its new name.
+func (x *executor) addEdits(c renamedChannel, edits []string) {
+	for i := len(x.Results) - 1; i >= 0 && len(edits) > 0; i-- {
+		if r := &x.Results[i]; r.Action == actionRename && r.ChannelID == c.id && r.Tobe == c.newName {
+			r.Edits = append(r.Edits, edits...)
+			return
+		}
+	}
+}
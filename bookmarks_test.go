@@ -0,0 +1,54 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/slack-go/slack"
+)
+
+func TestReplaceOldName(t *testing.T) {
+	for _, c := range []struct{ text, want string }{
+		{"#eng-old runbook", "#eng-new runbook"},
+		{"eng-old", "eng-new"},
+		{"see eng-old, eng-old", "see eng-new, eng-new"},
+		{"eng-old-archive notes", "eng-old-archive notes"},
+		{"https://wiki/eng-oldies", "https://wiki/eng-oldies"},
+	} {
+		if got := replaceOldName(c.text, "eng-old", "eng-new"); got != c.want {
+			t.Errorf("replaceOldName(%q) = %q, want %q", c.text, got, c.want)
+		}
+	}
+}
+
+func TestUpdateBookmarks(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "snapshot.json")
+	if err := os.WriteFile(path, []byte(`[{"name":"eng-old","id":"C1"}]`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	sessions, err := (workspaceOptions{simulate: path}).newSessions(channelOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	w := sessions[0].client.(*simWorkspace)
+	w.bookmarks = map[string][]slack.Bookmark{"C1": {
+		{ID: "B1", Title: "#eng-old runbook"},
+		{ID: "B2", Title: "On-call schedule"},
+	}}
+
+	runs, errs, _, err := validateRuns(sessions, workspaceOptions{}, []planEntry{{Action: actionRename, Asis: "eng-old", Tobe: "eng-new"}}, prepareOptions{})
+	if err != nil || len(errs) > 0 {
+		t.Fatalf("validateRuns: %v %q", err, errs)
+	}
+	res, err := executeRuns(runs, runOptions{verb: "rename", updateBookmarks: true, pool: poolOptions{concurrency: 1, perMinute: 60}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := w.bookmarks["C1"]; got[0].Title != "#eng-new runbook" || got[1].Title != "On-call schedule" {
+		t.Errorf("bookmarks = %+v", got)
+	}
+	if edits := res.results[0].Edits; len(edits) != 1 || edits[0] != `bookmark "#eng-old runbook" -> "#eng-new runbook"` {
+		t.Errorf("edits = %q", edits)
+	}
+}
@@ -0,0 +1,375 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"os"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/kiddikn/slack-channel-renamer/plan"
+	"github.com/kiddikn/slack-channel-renamer/slackops"
+	"github.com/slack-go/slack"
+	"github.com/slack-go/slack/slackevents"
+	"github.com/slack-go/slack/socketmode"
+)
+
+// Block action IDs of the buttons on a plan posted by the bot.
+const (
+	botApplyAction   = "apply_plan"
+	botDiscardAction = "discard_plan"
+)
+
+// planBot answers a slash command in Socket Mode: it validates the plan the
+// command points at, posts it with Apply and Discard buttons, and applies it
+// when an approver clicks Apply. Plans and runs go through a server, so only
+// one run applies changes at a time.
+type planBot struct {
+	srv       *server
+	session   *session // reads plan files and posts messages
+	socket    *socketmode.Client
+	command   string
+	approvers []string
+
+	// function is the callback ID of the workflow step the bot runs, or
+	// empty to run none.
+	function string
+}
+
+func cmdBot(args []string) int {
+	fs := newFlagSet("bot")
+	command := fs.String("command", "/rename-plan", "slash command to answer")
+	function := fs.String("workflow-function", "rename_channel", "callback ID of the workflow step that renames a channel (empty to disable)")
+	var approvers []string
+	fs.Func("approver", "user ID allowed to apply or discard a posted plan (repeatable, or comma-separated)", func(s string) error {
+		for id := range strings.SplitSeq(s, ",") {
+			if id = strings.TrimSpace(id); id != "" {
+				approvers = append(approvers, id)
+			}
+		}
+		return nil
+	})
+	var opts serverOptions
+	opts.register(fs)
+	parseFlags(fs, args)
+	if err := opts.check(); err != nil {
+		slog.Error(err.Error())
+		return 2
+	}
+	if len(approvers) == 0 {
+		slog.Error("bot needs at least one -approver")
+		return 2
+	}
+	appToken := os.Getenv("SLACK_APP_TOKEN")
+	if appToken == "" {
+		slog.Error("set SLACK_APP_TOKEN to an app-level token with connections:write for Socket Mode")
+		return 2
+	}
+	srv, sessions, err := opts.newServer()
+	if err != nil {
+		slog.Error(err.Error())
+		return 1
+	}
+	b := &planBot{
+		srv:       srv,
+		session:   sessions[0],
+		socket:    socketmode.New(slack.New("", append(slackOptions(), slack.OptionAppLevelToken(appToken))...)),
+		command:   *command,
+		approvers: approvers,
+		function:  *function,
+	}
+
+	ctx, stop := interruptContext(cmdCtx)
+	defer stop()
+	err = b.run(ctx)
+	// The run in progress saw the same signal and stops starting entries.
+	srv.wg.Wait()
+	if err != nil {
+		slog.Error(err.Error())
+		return 1
+	}
+	return 0
+}
+
+// run handles Socket Mode events until ctx is done.
+func (b *planBot) run(ctx context.Context) error {
+	errc := make(chan error, 1)
+	go func() { errc <- b.socket.RunContext(ctx) }()
+	slog.Info("waiting for slash commands", "command", b.command)
+	for {
+		var evt socketmode.Event
+		select {
+		case <-ctx.Done():
+			return nil
+		case err := <-errc:
+			if ctx.Err() != nil {
+				return nil
+			}
+			return fmt.Errorf("socket mode: %w", err)
+		case evt = <-b.socket.Events:
+		}
+		switch evt.Type {
+		case socketmode.EventTypeInvalidAuth:
+			return errors.New("SLACK_APP_TOKEN was rejected")
+		case socketmode.EventTypeSlashCommand:
+			cmd, ok := evt.Data.(slack.SlashCommand)
+			if !ok || cmd.Command != b.command {
+				b.socket.Ack(*evt.Request)
+				continue
+			}
+			b.socket.Ack(*evt.Request, map[string]string{"response_type": "ephemeral", "text": "Validating the plan..."})
+			go b.postPlan(cmd)
+		case socketmode.EventTypeEventsAPI:
+			b.socket.Ack(*evt.Request)
+			ev, ok := evt.Data.(slackevents.EventsAPIEvent)
+			if !ok || b.function == "" {
+				continue
+			}
+			if fn, ok := ev.InnerEvent.Data.(*slackevents.FunctionExecutedEvent); ok && fn.Function.CallbackID == b.function {
+				go b.runFunction(ctx, fn)
+			}
+		case socketmode.EventTypeInteractive:
+			b.socket.Ack(*evt.Request)
+			cb, ok := evt.Data.(slack.InteractionCallback)
+			if !ok || cb.Type != slack.InteractionTypeBlockActions {
+				continue
+			}
+			for _, a := range cb.ActionCallback.BlockActions {
+				if a.ActionID == botApplyAction || a.ActionID == botDiscardAction {
+					go b.decide(cb, a.ActionID, a.Value)
+				}
+			}
+		}
+	}
+}
+
+// postPlan reads, validates and posts the plan of a slash command.
+func (b *planBot) postPlan(cmd slack.SlashCommand) {
+	name, data, err := b.readPlan(cmd)
+	if err != nil {
+		b.tell(cmd.ChannelID, cmd.UserID, ":warning: "+err.Error())
+		return
+	}
+	load, err := planInput{}.loaderFor(name)
+	if err != nil {
+		b.tell(cmd.ChannelID, cmd.UserID, ":warning: "+err.Error())
+		return
+	}
+	entries, err := load(name, data, plan.Options{})
+	if err != nil {
+		b.tell(cmd.ChannelID, cmd.UserID, ":warning: "+err.Error())
+		return
+	}
+	p := b.srv.addPlan(name, entries)
+	runs, v, err := b.srv.validate(p)
+	if err != nil {
+		b.tell(cmd.ChannelID, cmd.UserID, fmt.Sprintf(":warning: cannot validate %s: %v", name, err))
+		return
+	}
+	err = slackops.Call(cmdCtx, b.session.stats, "posting the plan", func(ctx context.Context) error {
+		_, _, err := b.session.client.PostMessageContext(ctx, cmd.ChannelID,
+			slack.MsgOptionText("Channel rename plan "+name, false),
+			slack.MsgOptionBlocks(b.planBlocks(p, runs, v, cmd.UserID)...))
+		return err
+	}, "channel_id", cmd.ChannelID)
+	if err != nil {
+		slog.Error("failed to post the plan", "plan", p.ID, "err", err)
+	}
+}
+
+// readPlan returns the plan a slash command points at: a file given by its
+// permalink, the CSV typed after the command, or else the latest plan file
+// the user shared in the channel.
+func (b *planBot) readPlan(cmd slack.SlashCommand) (string, []byte, error) {
+	text := strings.TrimSpace(cmd.Text)
+	if text != "" && !strings.HasPrefix(text, "<http") && !strings.HasPrefix(text, "http") {
+		return "inline.csv", []byte(text + "\n"), nil
+	}
+	var file *slack.File
+	if text != "" {
+		id, err := permalinkFileID(strings.Trim(text, "<>"))
+		if err != nil {
+			return "", nil, err
+		}
+		err = slackops.Call(cmdCtx, b.session.stats, "looking up the plan file", func(ctx context.Context) error {
+			var err error
+			file, _, _, err = b.session.client.GetFileInfoContext(ctx, id, 0, 0)
+			return err
+		}, "file_id", id)
+		if err != nil {
+			return "", nil, fmt.Errorf("look up file %s: %w", id, err)
+		}
+	} else {
+		var files []slack.File
+		err := slackops.Call(cmdCtx, b.session.stats, "listing shared files", func(ctx context.Context) error {
+			var err error
+			files, _, err = b.session.client.GetFilesContext(ctx, slack.GetFilesParameters{User: cmd.UserID, Channel: cmd.ChannelID, Count: 20})
+			return err
+		}, "channel_id", cmd.ChannelID)
+		if err != nil {
+			return "", nil, fmt.Errorf("list files: %w", err)
+		}
+		i := slices.IndexFunc(files, func(f slack.File) bool {
+			_, err := (planInput{}).loaderFor(f.Name)
+			return err == nil
+		})
+		if i < 0 {
+			return "", nil, fmt.Errorf("share a plan file in this channel first, or pass its link or the CSV to %s", b.command)
+		}
+		file = &files[i]
+	}
+	if file.Size > maxPlanUpload {
+		return "", nil, fmt.Errorf("%s is larger than %d bytes", file.Name, maxPlanUpload)
+	}
+	var buf bytes.Buffer
+	err := slackops.Call(cmdCtx, b.session.stats, "downloading the plan file", func(ctx context.Context) error {
+		buf.Reset()
+		return b.session.client.GetFileContext(ctx, file.URLPrivateDownload, &buf)
+	}, "file_id", file.ID)
+	if err != nil {
+		return "", nil, fmt.Errorf("download %s: %w", file.Name, err)
+	}
+	return file.Name, buf.Bytes(), nil
+}
+
+// permalinkFileID returns the file ID in a Slack file permalink such as
+// https://acme.slack.com/files/U012AB3CD/F0123ABCD/plan.csv.
+func permalinkFileID(link string) (string, error) {
+	u, err := url.Parse(link)
+	if err != nil {
+		return "", fmt.Errorf("plan link: %w", err)
+	}
+	for part := range strings.SplitSeq(path.Clean(u.Path), "/") {
+		if len(part) > 1 && part[0] == 'F' && strings.ToUpper(part) == part {
+			return part, nil
+		}
+	}
+	return "", fmt.Errorf("%s is not a link to a Slack file", link)
+}
+
+// planBlocks lays out a posted plan: who shared it, whether it validates,
+// the entries and, for a valid plan, the buttons.
+func (b *planBot) planBlocks(p *servedPlan, runs []workspaceRun, v *validationReport, user string) []slack.Block {
+	plan, total := planListing(runs)
+	blocks := []slack.Block{
+		slack.NewHeaderBlock(slack.NewTextBlockObject(slack.PlainTextType, "Channel rename plan", false, false)),
+		slack.NewSectionBlock(slack.NewTextBlockObject(slack.MarkdownType,
+			fmt.Sprintf("*Shared by:* <@%s>\n*Source:* %s\n*Entries:* %d", user, p.Name, total), false, false), nil, nil),
+	}
+	if total > 0 {
+		blocks = append(blocks, slack.NewSectionBlock(slack.NewTextBlockObject(slack.MarkdownType, "```"+plan+"```", false, false), nil, nil))
+	}
+	if !v.Passed {
+		errs := truncateList(v.Errors, 10)
+		return append(blocks, slack.NewSectionBlock(slack.NewTextBlockObject(slack.MarkdownType,
+			":x: *Validation failed:*\n• "+strings.Join(errs, "\n• "), false, false), nil, nil))
+	}
+	if total == 0 {
+		return append(blocks, slack.NewContextBlock("", slack.NewTextBlockObject(slack.MarkdownType, "Nothing to change.", false, false)))
+	}
+	var approvers []string
+	for _, u := range b.approvers {
+		approvers = append(approvers, "<@"+u+">")
+	}
+	return append(blocks,
+		slack.NewContextBlock("", slack.NewTextBlockObject(slack.MarkdownType,
+			":white_check_mark: Validation passed. Approvers: "+strings.Join(approvers, ", "), false, false)),
+		slack.NewActionBlock("plan",
+			slack.NewButtonBlockElement(botApplyAction, p.ID, slack.NewTextBlockObject(slack.PlainTextType, "Apply", false, false)).WithStyle(slack.StylePrimary),
+			slack.NewButtonBlockElement(botDiscardAction, p.ID, slack.NewTextBlockObject(slack.PlainTextType, "Discard", false, false)).WithStyle(slack.StyleDanger),
+		))
+}
+
+// decide applies or discards the plan planID after an approver clicked one
+// of its buttons, replacing the buttons with who decided.
+func (b *planBot) decide(cb slack.InteractionCallback, action, planID string) {
+	channelID, user := cb.Channel.ID, cb.User.ID
+	if !slices.Contains(b.approvers, user) {
+		b.tell(channelID, user, "Only the approvers can apply or discard a plan.")
+		return
+	}
+	b.srv.mu.Lock()
+	p := b.srv.plans[planID]
+	if action == botDiscardAction {
+		delete(b.srv.plans, planID)
+	}
+	b.srv.mu.Unlock()
+	if p == nil {
+		b.tell(channelID, user, "This plan is no longer known, post it again.")
+		return
+	}
+	if action == botDiscardAction {
+		b.replaceButtons(cb, fmt.Sprintf(":wastebasket: Discarded by <@%s>.", user))
+		return
+	}
+	run, err := b.srv.start(p)
+	if err != nil {
+		b.tell(channelID, user, ":hourglass: "+err.Error()+", try again once it has finished.")
+		return
+	}
+	slog.Info("plan apply started from Slack", "plan", p.ID, "run", run.ID, "by", user)
+	b.replaceButtons(cb, fmt.Sprintf(":arrows_counterclockwise: Applying, started by <@%s>.", user))
+	<-run.done
+
+	b.srv.mu.Lock()
+	reply := runReply(run)
+	b.srv.mu.Unlock()
+	err = slackops.Call(cmdCtx, b.session.stats, "reporting the run", func(ctx context.Context) error {
+		_, _, err := b.session.client.PostMessageContext(ctx, channelID, slack.MsgOptionText(reply, false), slack.MsgOptionTS(cb.Message.Timestamp))
+		return err
+	}, "channel_id", channelID)
+	if err != nil {
+		slog.Warn("failed to report the run", "run", run.ID, "err", err)
+	}
+}
+
+// runReply describes how a finished run went.
+func runReply(run *servedRun) string {
+	emoji := map[string]string{runSucceeded: ":white_check_mark:", runInterrupted: ":double_vertical_bar:"}[run.Status]
+	if emoji == "" {
+		emoji = ":x:"
+	}
+	msg := fmt.Sprintf("%s Run %s: %d succeeded, %d failed, %d skipped", emoji, run.Status, run.Summary.Succeeded, run.Summary.Failed, run.Summary.Skipped)
+	if run.Summary.NotStarted > 0 {
+		msg += fmt.Sprintf(", %d not started", run.Summary.NotStarted)
+	}
+	if run.Error != "" {
+		msg += "\n" + run.Error
+	}
+	if run.RollbackFile != "" {
+		msg += fmt.Sprintf("\nUndo with `rollback -plan-file %s`.", run.RollbackFile)
+	}
+	return msg
+}
+
+// replaceButtons swaps the action block of the message cb was clicked on for
+// a note.
+func (b *planBot) replaceButtons(cb slack.InteractionCallback, note string) {
+	blocks := slices.DeleteFunc(slices.Clone(cb.Message.Blocks.BlockSet), func(bl slack.Block) bool {
+		return bl.BlockType() == slack.MBTAction
+	})
+	blocks = append(blocks, slack.NewContextBlock("", slack.NewTextBlockObject(slack.MarkdownType, note, false, false)))
+	err := slackops.Call(cmdCtx, b.session.stats, "updating the plan message", func(ctx context.Context) error {
+		_, _, _, err := b.session.client.UpdateMessageContext(ctx, cb.Channel.ID, cb.Message.Timestamp, slack.MsgOptionBlocks(blocks...))
+		return err
+	}, "channel_id", cb.Channel.ID)
+	if err != nil {
+		slog.Warn("failed to update the plan message", "err", err)
+	}
+}
+
+// tell sends user a message only they can see in channelID.
+func (b *planBot) tell(channelID, user, text string) {
+	err := slackops.Call(cmdCtx, b.session.stats, "replying to the command", func(ctx context.Context) error {
+		_, err := b.session.client.PostEphemeralContext(ctx, channelID, user, slack.MsgOptionText(text, false))
+		return err
+	}, "channel_id", channelID)
+	if err != nil {
+		slog.Warn("failed to reply", "user", user, "err", err)
+	}
+}
@@ -0,0 +1,42 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+
+	"github.com/kiddikn/slack-channel-renamer/runner"
+)
+
+// botMembershipErrors reports the entries a bot token cannot carry out
+// because they touch a channel the bot is not in and cannot join. Public
+// channels are joined when the entry runs, and the count is logged.
+func botMembershipErrors(plan []planEntry, channels map[string]channelInfo) []string {
+	var errs []string
+	joins := make(map[string]bool)
+	for _, e := range plan {
+		if e.Action == actionCreate {
+			// The bot is a member of the channels it creates.
+			continue
+		}
+		names := []string{e.Asis}
+		if e.Action == actionMerge {
+			names = append(names, e.Tobe)
+		}
+		for _, name := range names {
+			ch := channels[name]
+			switch {
+			case ch.IsMember:
+			case ch.IsPrivate:
+				errs = append(errs, e.At()+fmt.Sprintf("%v of private channel %q; invite it there first", runner.ErrBotNotMember, name))
+			case ch.IsArchived && e.Action == actionUnarchive:
+				errs = append(errs, e.At()+fmt.Sprintf("%v of archived channel %q and cannot join it to unarchive it; use a user token", runner.ErrBotNotMember, name))
+			default:
+				joins[ch.ID] = true
+			}
+		}
+	}
+	if len(joins) > 0 {
+		slog.Info("the bot will join the public channels it is not in", "channels", len(joins))
+	}
+	return errs
+}
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"maps"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// channelCache is the file -cache-ttl keeps a workspace's channel list in.
+type channelCache struct {
+	Fetched  time.Time              `json:"fetched"`
+	Channels map[string]channelInfo `json:"channels"`
+}
+
+// listChannels returns the workspace's channels like fetchChannels, but from
+// the on-disk cache when -cache-ttl is set and the cached list is young
+// enough. Only the listing that validation, export, generate and lint start
+// from is cached: the stale check and the verification pass always ask
+// Slack, so a stale cache can fail a row but not misreport one. Under -watch
+// the list is fetched once and a copy of it returned from then on.
+func (s *session) listChannels(ctx context.Context) (map[string]channelInfo, error) {
+	if s.listed != nil {
+		return maps.Clone(s.listed), nil
+	}
+	channels, err := s.listChannelsOnce(ctx)
+	if err == nil && s.keepList {
+		s.listed = maps.Clone(channels)
+	}
+	return channels, err
+}
+
+// listChannelsOnce does the work of listChannels for a list not kept by -watch.
+func (s *session) listChannelsOnce(ctx context.Context) (map[string]channelInfo, error) {
+	if s.channelOpts.cacheTTL <= 0 || s.simulated || s.channelOpts.admin {
+		return s.fetchChannels(ctx)
+	}
+	path, err := s.channelCachePath(ctx)
+	if err != nil {
+		slog.Warn(s.label()+"not caching the channel list", "err", err)
+		return s.fetchChannels(ctx)
+	}
+	if !s.channelOpts.refresh {
+		if c, err := readChannelCache(path); err == nil && time.Since(c.Fetched) < s.channelOpts.cacheTTL {
+			slog.Info(s.label()+"using the cached channel list (pass -refresh to fetch it again)", "count", len(c.Channels), "age", time.Since(c.Fetched).Round(time.Second), "cache", path)
+			return c.Channels, nil
+		}
+	}
+	channels, err := s.fetchChannels(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := writeChannelCache(path, channels); err != nil {
+		slog.Warn(s.label()+"failed to cache the channel list", "err", err)
+	}
+	return channels, nil
+}
+
+// channelCachePath returns the cache file of the session's channel list. It
+// is keyed by team and user ID, since what a token sees depends on who it
+// belongs to, and by the listing options.
+func (s *session) channelCachePath(ctx context.Context) (string, error) {
+	if s.auth == nil {
+		info, err := s.authTest(ctx)
+		if err != nil {
+			return "", err
+		}
+		s.auth = &info
+	}
+	if s.auth.TeamID == "" || s.auth.UserID == "" {
+		return "", errors.New("auth.test did not say which team and user the token belongs to")
+	}
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	name := s.auth.TeamID + "-" + s.auth.UserID
+	if s.channelOpts.includePrivate {
+		name += "-private"
+	}
+	if s.channelOpts.teamID != "" {
+		name += "-" + s.channelOpts.teamID
+	}
+	return filepath.Join(dir, "slack-channel-renamer", "channels", name+".json"), nil
+}
+
+func readChannelCache(path string) (*channelCache, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var c channelCache
+	if err := json.Unmarshal(b, &c); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", path, err)
+	}
+	return &c, nil
+}
+
+// writeChannelCache writes the cache file readable only by its owner, as it
+// lists private channels too.
+func writeChannelCache(path string, channels map[string]channelInfo) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
+	}
+	b, err := json.Marshal(channelCache{Fetched: time.Now(), Channels: channels})
+	if err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, b, 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// dropChannelCache removes the session's cached channel list once a run has
+// changed channels, so that the next command does not plan against the old
+// names.
+func (s *session) dropChannelCache() {
+	s.listed = nil
+	if s.channelOpts.cacheTTL <= 0 || s.auth == nil {
+		return
+	}
+	path, err := s.channelCachePath(cmdCtx)
+	if err == nil {
+		err = os.Remove(path)
+	}
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		slog.Warn(s.label()+"failed to remove the cached channel list", "err", err)
+	}
+}
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestListChannelsCache(t *testing.T) {
+	serveAuthTest(t, "channels:read")
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	w := newFakeWorkspace("alpha", "beta")
+	s := newSession("", "xoxp-test", channelOptions{cacheTTL: time.Hour})
+	s.client = w
+	ctx := context.Background()
+
+	if _, err := s.listChannels(ctx); err != nil {
+		t.Fatal(err)
+	}
+	w.channels[0].Name = "gamma"
+	channels, err := s.listChannels(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := channels["alpha"]; !ok {
+		t.Errorf("second listing = %v, want the cached one with alpha", channels)
+	}
+
+	s.channelOpts.refresh = true
+	if channels, _ = s.listChannels(ctx); channels["gamma"].ID != "C1" {
+		t.Errorf("-refresh listing = %v, want gamma", channels)
+	}
+
+	s.channelOpts.refresh = false
+	w.channels[0].Name = "delta"
+	s.dropChannelCache()
+	if channels, _ = s.listChannels(ctx); channels["delta"].ID != "C1" {
+		t.Errorf("listing after a run = %v, want delta", channels)
+	}
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/kiddikn/slack-channel-renamer/plan"
+	"github.com/kiddikn/slack-channel-renamer/runner"
+)
+
+// errCanaryStopped is the results-file error of the entries left out because
+// the canary was not confirmed.
+var errCanaryStopped = errors.New("canary not confirmed")
+
+// canaryOptions makes apply run the first entries of a plan on their own,
+// verify them and wait for a go-ahead before running the rest, to limit the
+// damage a mechanical mistake in a large plan can do.
+type canaryOptions struct {
+	size int
+	wait time.Duration
+}
+
+func (o *canaryOptions) register(fs *flag.FlagSet) {
+	fs.IntVar(&o.size, "canary", 0, "apply the first N entries, verify them and ask before applying the rest (0 = no canary)")
+	fs.DurationVar(&o.wait, "canary-wait", 0, "instead of asking, carry on this long after a canary that went through (e.g. 10m)")
+}
+
+// canarySplit returns the first entries up to size, and the rest.
+// The renames of a swap or chain (see plan.Units) are kept on the same side,
+// so the canary may hold a few more entries than size.
+func canarySplit(entries []planEntry, size int) (canary, rest []planEntry) {
+	units := plan.Units(entries)
+	in := make(map[int]bool)
+	for i := range entries {
+		if len(canary) >= size && !in[units[i]] {
+			rest = append(rest, entries[i])
+			continue
+		}
+		in[units[i]] = true
+		canary = append(canary, entries[i])
+	}
+	return canary, rest
+}
+
+// applyCanary applies the canary of entries, checks that its renames stuck
+// and, once the operator confirms on in or o.wait has passed, applies the
+// rest. A canary with failures is never carried on from without asking. It
+// returns the number of failures.
+func (x *executor) applyCanary(ctx context.Context, o canaryOptions, channels map[string]channelInfo, entries []planEntry, fetch func(context.Context) (map[string]channelInfo, error), in *bufio.Reader) int {
+	canary, rest := canarySplit(entries, o.size)
+	slog.Info(x.label+"applying the canary", "entries", len(canary), "remaining", len(rest))
+	failures := x.applyEntries(ctx, channels, canary)
+	if ctx.Err() != nil {
+		return failures + x.applyEntries(ctx, channels, rest)
+	}
+	failures += x.verifyPass(ctx, fetch, channels)
+	// The renames checked here are not checked again by the run's own pass.
+	x.Renamed = nil
+	if len(rest) == 0 {
+		return failures
+	}
+
+	fmt.Printf("%scanary: %d entries, %d failed\n", x.label, len(canary), failures)
+	carryOn := false
+	switch {
+	case o.wait > 0 && failures == 0:
+		slog.Info(x.label+"canary went through, carrying on", "after", o.wait)
+		select {
+		case <-ctx.Done():
+		case <-time.After(o.wait):
+			carryOn = true
+		}
+	case o.wait > 0:
+		slog.Error(x.label + "canary failed, stopping")
+	default:
+		carryOn = confirm(in, fmt.Sprintf("apply the remaining %d entries? [y/N]: ", len(rest)))
+	}
+	if ctx.Err() != nil {
+		return failures + x.applyEntries(ctx, channels, rest)
+	}
+	if !carryOn {
+		fmt.Printf("%scanary: the remaining %d entries were not applied\n", x.label, len(rest))
+		x.Stats.Skipped += len(rest)
+		for _, e := range rest {
+			r := runner.NewResult(e, channels[e.Asis], resultSkipped, errCanaryStopped, time.Time{}, time.Time{})
+			x.Results = append(x.Results, r)
+			live.entry(r)
+			auditLog.entry(r)
+		}
+		return failures
+	}
+	return failures + x.applyEntries(ctx, channels, rest)
+}
@@ -0,0 +1,20 @@
+package main
+
+import "testing"
+
+func TestCanarySplit(t *testing.T) {
+	plan := []planEntry{
+		{Action: actionRename, Asis: "a", Tobe: "b"},
+		{Action: actionRename, Asis: "c", Tobe: "d"},
+		{Action: actionRename, Asis: "d", Tobe: "e"}, // chained with c -> d
+		{Action: actionRename, Asis: "f", Tobe: "g"},
+	}
+	canary, rest := canarySplit(plan, 2)
+	if len(canary) != 3 || len(rest) != 1 || rest[0].Asis != "f" {
+		t.Errorf("canarySplit = %v, %v; want the chain kept in the canary", canary, rest)
+	}
+	canary, rest = canarySplit(plan, 10)
+	if len(canary) != 4 || len(rest) != 0 {
+		t.Errorf("canarySplit larger than the plan = %v, %v", canary, rest)
+	}
+}
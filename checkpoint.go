@@ -0,0 +1,141 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/kiddikn/slack-channel-renamer/runner"
+)
+
+// defaultStateFile is where apply records the progress of the current run.
+const defaultStateFile = ".slack-channel-renamer.state.json"
+
+// Row statuses recorded in the state file.
+const (
+	rowDone   = "done"
+	rowFailed = "failed"
+)
+
+// checkpoint is the state file apply rewrites after every row, so that an
+// interrupted run can be resumed with -resume without repeating the rows that
+// already went through.
+type checkpoint struct {
+	path string
+
+	Source  string          `json:"source"`
+	Updated time.Time       `json:"updated"`
+	Rows    []checkpointRow `json:"rows"`
+
+	index map[string]int
+}
+
+type checkpointRow struct {
+	Workspace string `json:"workspace,omitempty"`
+	Action    string `json:"action"`
+	Channel   string `json:"channel"`
+	Tobe      string `json:"tobe,omitempty"`
+	Status    string `json:"status"`
+	Error     string `json:"error,omitempty"`
+}
+
+// checkpointRowFor identifies an entry by what it does rather than where it
+// was read from. Rows with an ID are keyed by the ID, since ID lookup may
+// replace asis with the channel's live name.
+func checkpointRowFor(e planEntry) checkpointRow {
+	channel := e.Asis
+	if e.ChannelID != "" {
+		channel = e.ChannelID
+	}
+	return checkpointRow{Workspace: e.Workspace, Action: e.Action, Channel: channel, Tobe: e.Tobe}
+}
+
+func (r checkpointRow) key() string {
+	return r.Workspace + "\x00" + r.Action + "\x00" + r.Channel + "\x00" + r.Tobe
+}
+
+// newCheckpoint starts an empty state file for a run of source.
+func newCheckpoint(path, source string) *checkpoint {
+	return &checkpoint{path: path, Source: source, index: make(map[string]int)}
+}
+
+// loadCheckpoint reads the state file left by a previous run.
+func loadCheckpoint(path string) (*checkpoint, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read state file: %w", err)
+	}
+	cp := &checkpoint{path: path, index: make(map[string]int)}
+	if err := json.Unmarshal(b, cp); err != nil {
+		return nil, fmt.Errorf("parse state file %q: %w", path, err)
+	}
+	for i, r := range cp.Rows {
+		cp.index[r.key()] = i
+	}
+	return cp, nil
+}
+
+// completed reports whether e went through in a previous run.
+func (cp *checkpoint) completed(e planEntry) bool {
+	i, ok := cp.index[checkpointRowFor(e).key()]
+	return ok && cp.Rows[i].Status == rowDone
+}
+
+// pending returns the entries of plan that did not go through in a previous
+// run, and the number that did.
+func (cp *checkpoint) pending(plan []planEntry) ([]planEntry, int) {
+	var pending []planEntry
+	for _, e := range plan {
+		if !cp.completed(e) {
+			pending = append(pending, e)
+		}
+	}
+	return pending, len(plan) - len(pending)
+}
+
+// record stores the outcome of one entry and rewrites the state file.
+func (cp *checkpoint) record(e planEntry, err error) error {
+	if cp == nil {
+		return nil
+	}
+	row := checkpointRowFor(e)
+	row.Status = rowDone
+	if !runner.ChangedChannel(err) {
+		row.Status, row.Error = rowFailed, err.Error()
+	}
+	if i, ok := cp.index[row.key()]; ok {
+		cp.Rows[i] = row
+	} else {
+		cp.index[row.key()] = len(cp.Rows)
+		cp.Rows = append(cp.Rows, row)
+	}
+	cp.Updated = time.Now().UTC().Truncate(time.Second)
+	return cp.save()
+}
+
+// save writes the state file to a temporary name and renames it into place,
+// so that a crash never leaves a truncated file behind.
+func (cp *checkpoint) save() error {
+	b, err := json.MarshalIndent(cp, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode state: %w", err)
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(cp.path), ".state-*.tmp")
+	if err != nil {
+		return fmt.Errorf("create temp file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(append(b, '\n')); err != nil {
+		tmp.Close()
+		return fmt.Errorf("write %q: %w", tmp.Name(), err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("close %q: %w", tmp.Name(), err)
+	}
+	if err := os.Rename(tmp.Name(), cp.path); err != nil {
+		return fmt.Errorf("rename to %q: %w", cp.path, err)
+	}
+	return nil
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// chunkPacer splits a run into chunks of entries with a pause between them,
+// so that a large plan does not flood clients and integrations with
+// channel_rename events all at once.
+type chunkPacer struct {
+	size  int
+	pause time.Duration
+
+	mu      sync.Mutex
+	started int
+}
+
+// newChunkPacer returns a pacer for chunks of size entries, or nil, which
+// never pauses, if size is 0.
+func newChunkPacer(size int, pause time.Duration) *chunkPacer {
+	if size <= 0 {
+		return nil
+	}
+	return &chunkPacer{size: size, pause: pause}
+}
+
+// wait is called before an entry starts. It pauses first when the entry
+// begins a new chunk, and returns ctx's error if ctx is done first. The
+// pause starts once the last entry of the previous chunk has started; with
+// -concurrency above 1, a few of them may still be running.
+func (c *chunkPacer) wait(ctx context.Context) error {
+	if c == nil {
+		return nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.started > 0 && c.started%c.size == 0 && c.pause > 0 {
+		slog.Info("chunk done, pausing", "chunk", c.started/c.size, "entries", c.started, "resumes", time.Now().Add(c.pause).Format(time.RFC3339))
+		timer := time.NewTimer(c.pause)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+	c.started++
+	return nil
+}
@@ -0,0 +1,2110 @@
+package main
+
+import (
+	"bufio"
+	"cmp"
+	"context"
+	"encoding/csv"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"maps"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"syscall"
+	"text/template"
+	"time"
+
+	"github.com/kiddikn/slack-channel-renamer/plan"
+	"github.com/kiddikn/slack-channel-renamer/runner"
+	"github.com/kiddikn/slack-channel-renamer/slackops"
+	"github.com/slack-go/slack"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"golang.org/x/time/rate"
+)
+
+// errValidation is returned by preparePlan after validation errors have been printed.
+var errValidation = errors.New("validation failed")
+
+// stdin reads the answers to every prompt of a run.
+var stdin = bufio.NewReader(os.Stdin)
+
+type command struct {
+	name    string
+	usage   string
+	summary string
+	run     func(args []string) int
+}
+
+func commands() []command {
+	return []command{
+		{"validate", "validate [flags]", "load the plan, fetch channels and report validation errors", cmdValidate},
+		{"plan", "plan [flags]", "validate and print the plan without changing anything", cmdPlan},
+		{"apply", "apply [flags]", "validate and execute the plan", cmdApply},
+		{"archive", "archive -plan LIST [flags]", "validate and archive the channels listed one per line", cmdArchive},
+		{"unarchive", "unarchive -plan LIST [flags]", "validate and unarchive the channels listed one per line, renaming them if asked", cmdUnarchive},
+		{"create", "create -plan FILE [flags]", "create the channels listed in a CSV of names, topics, purposes, privacy and members", cmdCreate},
+		{"set-topic", "set-topic -plan LIST [flags]", "set the topics and purposes listed in a CSV of channels, where they differ", cmdSetTopic},
+		{"invite", "invite -plan LIST [flags]", "invite the users listed by ID or email address to their channels", cmdInvite},
+		{"status", "status -plan FILE [flags]", "report whether each plan row is pending, applied, conflicted or stale in the live workspace", cmdStatus},
+		{"rollback", "rollback [flags]", "undo a plan: rename tobe back to asis and reverse archive/unarchive", cmdRollback},
+		{"restore", "restore -snapshot FILE [flags]", "put channels back to the names, topics, purposes and archived states of a snapshot", cmdRestore},
+		{"export", "export [flags]", "write the current channel list as CSV or JSON", cmdExport},
+		{"generate", "generate -match RE -replace REPL [flags]", "write a plan CSV renaming every live channel that matches a regex", cmdGenerate},
+		{"normalize", "normalize [-apply [-- apply flags]]", "rename the live channels whose names are not in Slack's usual form, writing a plan or applying it", cmdNormalize},
+		{"transform", "transform -add-prefix P | -strip-prefix P | -add-suffix S | -strip-suffix S [-match RE] [-apply [-- apply flags]]", "add or strip a prefix or suffix on the matching live channels, writing a plan or applying it", cmdTransform},
+		{"audit", "audit inactive | duplicates [flags]", "report channels idle longer than a threshold or looking like duplicates, or write a plan archiving, renaming or merging them", cmdAudit},
+		{"suggest", "suggest -rules FILE [-policy FILE] [flags]", "write a plan CSV proposing new names, from rename rules and a glossary, for the channels breaking a naming policy", cmdSuggest},
+		{"lint", "lint -policy FILE [flags]", "check live channel names and planned names against a naming policy", cmdLint},
+		{"diff", "diff [flags] old.csv new.csv | export.csv", "compare two mapping files, or an earlier export with the live workspace", cmdDiff},
+		{"history", "history list | show <channel> | revert <run-id> [flags]", "list recorded runs, show a channel's changes or revert a run", cmdHistory},
+		{"serve", "serve [flags]", "serve a REST API to upload, validate and apply plans and poll their runs", cmdServe},
+		{"bot", "bot -approver USER [flags]", "answer a slash command in Socket Mode: validate and post plans, apply them on approval", cmdBot},
+		{"auth", "auth login [flags]", "get a user token through the app's OAuth flow and store it in the keyring or a file", cmdAuth},
+		{"completion", "completion bash|zsh|fish", "print a shell completion script", cmdCompletion},
+		{"docs", "docs man", "print a man page covering every command and flag", cmdDocs},
+		{"schema", "schema plan|config|api", "print the JSON Schema of JSON and YAML plans or of the -config file, for editors, or the OpenAPI description of serve", cmdSchema},
+		{"version", "version [flags]", "print the version, commit and build of this binary", cmdVersion},
+	}
+}
+
+// runCLI dispatches args to a subcommand and returns the process exit code.
+func runCLI(args []string) int {
+	if len(args) == 0 {
+		printUsage(os.Stderr)
+		return 2
+	}
+	switch args[0] {
+	case "help", "-h", "-help", "--help":
+		printUsage(os.Stdout)
+		return 0
+	}
+	for _, c := range commands() {
+		if c.name == args[0] {
+			defer setupTracing()()
+			var span trace.Span
+			cmdCtx, span = tracer.Start(parentContext(), c.name)
+			code := c.run(args[1:])
+			span.SetAttributes(attribute.Int("exit_code", code))
+			if code != 0 {
+				span.SetStatus(codes.Error, fmt.Sprintf("exit code %d", code))
+			}
+			span.End()
+			if err := flushReport(os.Stdout); err != nil {
+				slog.Error("failed to write report", "err", err)
+				code = 1
+			}
+			auditLog.close(code)
+			return code
+		}
+	}
+	fmt.Fprintf(os.Stderr, "unknown command %q\n\n", args[0])
+	printUsage(os.Stderr)
+	return 2
+}
+
+func printUsage(w io.Writer) {
+	fmt.Fprintln(w, "usage: slack-channel-renamer <command> [flags]")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "commands:")
+	for _, c := range commands() {
+		fmt.Fprintf(w, "  %-10s %s\n", c.name, c.summary)
+	}
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "run 'slack-channel-renamer <command> -h' for the flags of a command")
+}
+
+// newFlagSet returns a FlagSet whose usage message includes the command's synopsis.
+func newFlagSet(name string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ExitOnError)
+	fs.StringVar(&settingsPath, "settings", "", "read flag values from this YAML file (default $RENAMER_SETTINGS, or renamer.yaml in the working or user config directory)")
+	registerLogging(fs)
+	registerRetry(fs)
+	registerEgress(fs)
+	fs.Usage = func() {
+		for _, c := range commands() {
+			if c.name == name {
+				fmt.Fprintf(fs.Output(), "usage: slack-channel-renamer %s\n\n%s\n\n", c.usage, c.summary)
+			}
+		}
+		fs.PrintDefaults()
+	}
+	return fs
+}
+
+// session holds the Slack client and per-run counters for one workspace.
+type session struct {
+	// workspace is the -config workspace name, or empty without -config.
+	workspace string
+	client    slackops.API
+	token     string
+	// bot is set when token is a bot token (xoxb-).
+	bot bool
+	// team, when set, is the Slack workspace (name, domain or ID) the
+	// token must belong to.
+	team string
+	// simulated is set for the sessions of -simulate, whose client is a
+	// simWorkspace.
+	simulated bool
+	// targeted is set when the plan's channels were looked up by ID rather
+	// than by listing the workspace; see canTarget.
+	targeted bool
+	// auth is what auth.test said about the token, once it has been asked.
+	auth        *authInfo
+	stats       *slackops.Stats
+	channelOpts channelOptions
+	// keepList is set by -watch: the first channel list fetched is kept in
+	// listed and handed out again by listChannels, and preflight checks the
+	// plan against auth instead of calling auth.test again.
+	keepList bool
+	listed   map[string]channelInfo
+}
+
+// channelOptions controls which conversations are fetched from Slack.
+type channelOptions struct {
+	includePrivate bool
+	admin          bool
+	// cacheTTL and refresh control the on-disk channel list cache.
+	cacheTTL time.Duration
+	refresh  bool
+	// fullList lists the workspace even for plans whose channels could be
+	// looked up by ID.
+	fullList bool
+	// teamID keeps an Enterprise Grid listing to the channels of one
+	// workspace of the org.
+	teamID string
+}
+
+func (o *channelOptions) register(fs *flag.FlagSet) {
+	fs.BoolVar(&o.includePrivate, "include-private", false, "also act on private channels visible to the token (needs groups:read and groups:write)")
+	fs.BoolVar(&o.admin, "admin", false, "use the Enterprise Grid admin.conversations APIs to find and change channels anywhere in the org")
+	fs.DurationVar(&o.cacheTTL, "cache-ttl", 0, "reuse the channel list fetched within this long (e.g. 30m) from the on-disk cache (0 = always fetch)")
+	fs.BoolVar(&o.fullList, "full-list", false, "list every channel even when each plan row has a channel ID, so that taken target names are found by validation rather than by Slack")
+	fs.StringVar(&o.teamID, "team-id", "", "on Enterprise Grid, only match channels of this workspace (team ID, e.g. T0123ABCD) of the org")
+	fs.BoolVar(&o.refresh, "refresh", false, "fetch the channel list even when -cache-ttl has a fresh copy, and cache the result")
+}
+
+// list returns the options of the channel listing.
+func (o channelOptions) list() slackops.ListOptions {
+	return slackops.ListOptions{IncludePrivate: o.includePrivate, TeamID: o.teamID}
+}
+
+func newSession(workspace, token string, channelOpts channelOptions) *session {
+	return &session{
+		workspace:   workspace,
+		client:      slack.New(token, slackOptions()...),
+		token:       token,
+		bot:         strings.HasPrefix(token, "xoxb-"),
+		stats:       &slackops.Stats{Start: time.Now()},
+		channelOpts: channelOpts,
+	}
+}
+
+func (s *session) executor(verify bool) *executor {
+	x := &executor{}
+	x.Runner = runner.New(s.client, runner.Options{
+		Stats:          s.stats,
+		Verify:         verify,
+		Admin:          s.channelOpts.admin,
+		Bot:            s.bot,
+		List:           s.channelOpts.list(),
+		Concurrency:    1,
+		Limiter:        rate.NewLimiter(rate.Every(sleepBetween), 1),
+		Wait:           x.waitTurn,
+		RenamedOutside: x.renamedElsewhere,
+		Finished:       x.recordOutcome,
+		Observe:        x.report,
+	})
+	return x
+}
+
+// actor returns the name of the user behind the session's token, as recorded
+// in the history, or an empty string if auth.test fails.
+func (s *session) actor() string {
+	var resp *slack.AuthTestResponse
+	err := slackops.Call(cmdCtx, s.stats, "identifying the token's user", func(ctx context.Context) error {
+		var err error
+		resp, err = s.client.AuthTestContext(ctx)
+		return err
+	})
+	if err != nil {
+		slog.Warn(s.label()+"failed to identify the token's user for the history", "err", err)
+		return ""
+	}
+	return resp.User
+}
+
+// label prefixes per-workspace messages when more than one workspace may be involved.
+func (s *session) label() string {
+	if s.workspace == "" {
+		return ""
+	}
+	return "workspace " + s.workspace + ": "
+}
+
+// fetchChannels fetches the channels selected by the session's channel options.
+func (s *session) fetchChannels(ctx context.Context) (map[string]channelInfo, error) {
+	ctx, span := tracer.Start(slackops.Fetching(ctx), "fetch channels", trace.WithAttributes(attribute.String("workspace", s.workspace), attribute.Bool("admin", s.channelOpts.admin)))
+	var channels map[string]channelInfo
+	var err error
+	if s.channelOpts.admin {
+		channels, err = slackops.ListAdminChannels(ctx, s.client, s.stats, s.channelOpts.teamID)
+	} else {
+		channels, err = slackops.ListChannels(ctx, s.client, s.stats, s.channelOpts.list())
+	}
+	if err != nil {
+		err = fmt.Errorf("%sfailed to fetch channels: %w", s.label(), err)
+		slackops.EndSpan(span, err)
+		return nil, err
+	}
+	span.SetAttributes(attribute.Int("channels", len(channels)))
+	span.End()
+	slog.Info(s.label()+"fetched channels", "count", len(channels))
+	return channels, nil
+}
+
+// lookupChannels finds the channels the plan refers to. Resolved plan files
+// must match the workspace exactly; IDs in a hand-written plan are looked up
+// so renamed channels still resolve. In -admin mode only the names in the plan
+// are searched for, since listing a whole org is slow, and a plan whose rows
+// all carry IDs has just those channels looked up (see canTarget).
+func (s *session) lookupChannels(entries []planEntry, resolved bool) ([]planEntry, map[string]channelInfo, []string, error) {
+	ctx, span := tracer.Start(slackops.Fetching(cmdCtx), "look up channels", trace.WithAttributes(attribute.String("workspace", s.workspace), attribute.Int("entries", len(entries))))
+	defer span.End()
+	var channels map[string]channelInfo
+	var idErrs []string
+	if s.channelOpts.admin {
+		channels, idErrs = resolveAdminChannels(ctx, s.client, s.stats, entries, s.channelOpts.teamID)
+		slog.Info(s.label()+"found channels in the org", "count", len(channels))
+	} else if s.targeted {
+		var found []planEntry
+		found, channels, idErrs = s.targetedChannels(ctx, entries)
+		if !resolved {
+			return found, channels, idErrs, nil
+		}
+		// The channels that could not be looked up are reported already;
+		// check the names of the others against the plan below.
+		var known []planEntry
+		for _, e := range entries {
+			if slices.ContainsFunc(found, func(f planEntry) bool { return f.ChannelID == e.ChannelID }) {
+				known = append(known, e)
+			}
+		}
+		entries = known
+	} else {
+		var err error
+		channels, err = s.listChannels(ctx)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		if !resolved {
+			entries, idErrs = plan.ResolveIDs(ctx, s.client, s.stats, entries, channels, s.channelOpts.list())
+			return entries, channels, idErrs, nil
+		}
+	}
+	if resolved {
+		entries = plan.CollapseRenames(entries)
+		idErrs = append(idErrs, checkPlanIDs(entries, channels)...)
+	}
+	return entries, channels, idErrs, nil
+}
+
+// notFoundHint explains where validation looked for a channel it could not find.
+func (s *session) notFoundHint() string {
+	switch {
+	case s.channelOpts.admin:
+		return " in the org"
+	case s.bot && s.channelOpts.includePrivate:
+		return " (a bot token only sees the private channels the bot has been invited to)"
+	case s.channelOpts.includePrivate:
+		return " (private channels are only visible to their members)"
+	default:
+		return " among public channels (pass -include-private if it is private)"
+	}
+}
+
+// flushMetrics writes the metrics file for all sessions if one was requested.
+func flushMetrics(path string, sessions []*session) {
+	if path == "" {
+		return
+	}
+	if err := writeMetricsFile(path, sessions); err != nil {
+		slog.Error("failed to write metrics file", "err", err)
+	}
+}
+
+// workspaceRun is the validated part of a plan that runs against one workspace.
+type workspaceRun struct {
+	*session
+	plan     []planEntry
+	channels map[string]channelInfo
+}
+
+// loadPlan loads the plan from a resolved plan file when planFile is set,
+// otherwise from the files named by the -plan flags, and applies the row
+// filters.
+func loadPlan(in planInput, planFile string) (plan []planEntry, err error) {
+	_, span := tracer.Start(cmdCtx, "load plan")
+	defer func() {
+		span.SetAttributes(attribute.Int("entries", len(plan)))
+		slackops.EndSpan(span, err)
+	}()
+	if planFile != "" {
+		plan, err = readPlanFile(planFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load plan file: %w", err)
+		}
+		slog.Info("loaded resolved entries", "count", len(plan), "file", planFile)
+		return in.filter.apply(plan)
+	}
+	plan, files, err := in.load()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load plan: %w", err)
+	}
+	slog.Info("loaded rename entries", "count", len(plan), "files", strings.Join(files, ", "))
+	return in.filter.apply(plan)
+}
+
+// prepareOptions tells preparePlan how to read and validate the plan.
+type prepareOptions struct {
+	// resolved is set for plans read from a plan file, whose channel IDs are
+	// checked against the live names.
+	resolved bool
+	// historyDB is the history database whose renames are skipped as already
+	// made, or empty.
+	historyDB string
+	// onConflict is what to do with renames whose target name is taken.
+	onConflict conflictPolicy
+	// protect lists the channels no entry may touch.
+	protect protectOptions
+	// autoFix rewrites target names to the form Slack would store them in.
+	autoFix bool
+	// normalizeUnicode rewrites target names to Unicode NFKC form.
+	normalizeUnicode bool
+	// includeArchived renames archived channels by unarchiving them.
+	includeArchived bool
+	// includeShared lets entries change channels shared beyond the
+	// workspace, which are otherwise skipped.
+	includeShared bool
+	// holdNotDue leaves out the entries whose not_before time has not come,
+	// for apply; plan and validate keep them.
+	holdNotDue bool
+}
+
+// preparePlan splits the plan by workspace, fetches each workspace's channels
+// and validates its entries. Validation errors from every workspace are
+// reported together. The returned runs hold the entries that will be executed,
+// i.e. the plan without skipped entries.
+func preparePlan(sessions []*session, ws workspaceOptions, plan []planEntry, opts prepareOptions) ([]workspaceRun, error) {
+	runs, errs, skipped, err := validateRuns(sessions, ws, plan, opts)
+	if err != nil {
+		return nil, err
+	}
+	ghActions.validation(errs, runs)
+	if !reportValidation(errs, skipped) {
+		return nil, errValidation
+	}
+	return runs, nil
+}
+
+// validateRuns does the work of preparePlan without reporting: it returns the
+// runs along with the validation errors and skipped entries of every workspace.
+func validateRuns(sessions []*session, ws workspaceOptions, all []planEntry, opts prepareOptions) (runs []workspaceRun, errs, skipped []string, err error) {
+	past, err := loadRenameHistory(opts.historyDB)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	protected, err := opts.protect.compile()
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	split, errs := splitByWorkspace(all, sessions, ws.workspace)
+	runs = make([]workspaceRun, 0, len(sessions))
+	for i, s := range sessions {
+		var problems []string
+		if !s.simulated {
+			problems, err = s.preflight(split[i])
+		}
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		if len(problems) > 0 {
+			// Fetching channels with a token that cannot do the job would
+			// only add opaque errors.
+			for _, p := range problems {
+				errs = append(errs, s.label()+p)
+			}
+			continue
+		}
+		s.targeted = s.canTarget(split[i], opts.onConflict)
+		entries, channels, idErrs, err := s.lookupChannels(split[i], opts.resolved)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		entries = matchUnicodeForms(entries, channels)
+		entries = plan.MarkChannelTeams(entries, channels, s.channelOpts.teamID != "" || s.channelOpts.admin)
+		if opts.includeArchived {
+			entries = plan.MarkArchivedRenames(entries, channels)
+		}
+		entries, tmplErrs := expandTemplates(entries, time.Now())
+		idErrs = append(idErrs, tmplErrs...)
+		if opts.normalizeUnicode {
+			entries = normalizeUnicode(entries)
+		}
+		if opts.autoFix {
+			entries = autoFixNames(entries)
+		}
+		entries, skip := opts.onConflict.resolveConflicts(entries, channels)
+		entries, sharedSkip := plan.SkipShared(entries, channels, opts.includeShared)
+		skip = append(skip, sharedSkip...)
+		if opts.holdNotDue {
+			var held []string
+			entries, held = holdUntilDue(entries, channels, time.Now())
+			skip = append(skip, held...)
+		}
+		active, valErrs, valSkip := plan.Validate(entries, channels, s.notFoundHint(), past)
+		skip = append(skip, valSkip...)
+		valErrs = append(valErrs, protected.check(active, channels)...)
+		if s.bot {
+			valErrs = append(valErrs, botMembershipErrors(active, channels)...)
+		}
+		for _, e := range append(idErrs, valErrs...) {
+			errs = append(errs, s.label()+e)
+		}
+		for _, e := range skip {
+			skipped = append(skipped, s.label()+e)
+		}
+		s.stats.Skipped = len(skip)
+		runs = append(runs, workspaceRun{session: s, plan: active, channels: channels})
+	}
+	return runs, errs, skipped, nil
+}
+
+// reportValidation prints validation errors to stderr and skipped entries to
+// stdout. It reports whether validation passed.
+func reportValidation(errs, skipped []string) bool {
+	auditLog.validation(errs, skipped)
+	if output == outputJSON {
+		report.Validation = &validationReport{Passed: len(errs) == 0, Errors: errs, Skipped: skipped}
+		if report.Validation.Errors == nil {
+			report.Validation.Errors = []string{}
+		}
+		if report.Validation.Skipped == nil {
+			report.Validation.Skipped = []string{}
+		}
+		return len(errs) == 0
+	}
+	if len(errs) > 0 {
+		fmt.Fprintln(os.Stderr, "validation errors:")
+		for _, e := range errs {
+			fmt.Fprintf(os.Stderr, "  - %s\n", e)
+		}
+		return false
+	}
+	slog.Info("validation passed")
+	if len(skipped) > 0 {
+		fmt.Println("skipped entries:")
+		for _, s := range skipped {
+			fmt.Printf("  - %s\n", s)
+		}
+	}
+	return true
+}
+
+// countEntries returns the number of entries across all runs.
+func countEntries(runs []workspaceRun) int {
+	n := 0
+	for _, r := range runs {
+		n += len(r.plan)
+	}
+	return n
+}
+
+// dryRunExitCode is the exit status of a dry run: with -detailed-exitcode it
+// is 2 when the runs still have entries to carry out, as with 'terraform plan
+// -detailed-exitcode', and 0 otherwise.
+func dryRunExitCode(runs []workspaceRun, detailed bool) int {
+	if detailed && countEntries(runs) > 0 {
+		return 2
+	}
+	return 0
+}
+
+// runOptions controls how executeRuns applies the validated runs.
+type runOptions struct {
+	verb       string // "rename", "rollback" or "revert", for the progress log
+	verify     bool
+	verifyPass bool
+	staleCheck bool
+	byGroup    bool
+	// interactive asks before each plan row.
+	interactive bool
+	// canary applies the first entries and waits for a go-ahead.
+	canary canaryOptions
+
+	// announce tells the members of renamed channels about the rename;
+	// announcement is its compiled message template.
+	announce     announceOptions
+	announcement *template.Template
+	// updateBookmarks rewrites bookmarks naming renamed channels' old names.
+	updateBookmarks bool
+	// references finds, with "report", or rewrites, with "update", #old-name
+	// mentions of renamed channels in every channel's topic and purpose.
+	references string
+
+	// webhook sends the run's lifecycle events.
+	webhook webhookOptions
+
+	// observe, when set, is called with the outcome of every entry as it
+	// finishes, for 'serve' to report progress.
+	observe func(entryResult)
+
+	// history is the history database path, or empty to record nothing;
+	// source describes the run in it.
+	history string
+	source  string
+
+	// checkpoint, when set, records each entry's outcome for apply -resume.
+	checkpoint *checkpoint
+
+	// lock is the lock taken on each workspace for the run.
+	lock lockOptions
+
+	// watch follows renames made outside the run.
+	watch watchOptions
+
+	pool poolOptions
+}
+
+// poolOptions controls how many entries run at once and how fast they start.
+type poolOptions struct {
+	concurrency int
+	perMinute   float64
+	progress    bool
+
+	// adaptive paces entries to budget a minute, adjusted to rate limits.
+	adaptive bool
+	budget   int
+
+	// window, when set, is the only time entries may start; windowWait
+	// waits for it when the run starts outside it, instead of refusing.
+	window     *execWindow
+	windowWait bool
+
+	// chunkSize entries run between pauses of chunkPause.
+	chunkSize  int
+	chunkPause time.Duration
+
+	// onError and maxFailures stop a run once entries keep failing.
+	onError     string
+	maxFailures int
+}
+
+func (o *poolOptions) register(fs *flag.FlagSet) {
+	fs.IntVar(&o.concurrency, "concurrency", 1, "number of entries to execute at once")
+	fs.Float64Var(&o.perMinute, "rate", float64(time.Minute/sleepBetween), "start at most this many entries per minute, shared by all workers")
+	fs.BoolVar(&o.adaptive, "adaptive-rate", false, "start up to -rate-budget entries in any minute, at once for small runs, and slow down when Slack rate-limits")
+	fs.IntVar(&o.budget, "rate-budget", defaultRateBudget, "with -adaptive-rate, the most entries started in any minute (Slack's Tier 2 allows about 20)")
+	fs.BoolVar(&o.progress, "progress", true, "show a progress bar with an ETA on a terminal, or log progress every 30s otherwise")
+	fs.Func("window", "only start entries within this weekly window, e.g. 'Mon-Fri 19:00-23:00 Asia/Tokyo'; a run pauses while it is closed", func(v string) error {
+		w, err := parseWindow(v)
+		o.window = w
+		return err
+	})
+	fs.BoolVar(&o.windowWait, "window-wait", true, "when started outside -window, wait for it to open instead of refusing to run")
+	fs.IntVar(&o.chunkSize, "chunk-size", 0, "run entries in chunks of this many, pausing for -chunk-pause in between (0 = one chunk)")
+	fs.DurationVar(&o.chunkPause, "chunk-pause", 0, "pause this long between chunks of -chunk-size entries (e.g. 10m)")
+	fs.StringVar(&o.onError, "on-error", onErrorContinue, "what to do when an entry fails: continue or fail-fast (stop at the first failure)")
+	fs.IntVar(&o.maxFailures, "max-failures", 0, "stop the run once this many entries have failed (0 = no limit)")
+}
+
+// checkWindow refuses to start a run outside -window unless -window-wait
+// lets it wait.
+func (o *poolOptions) checkWindow(now time.Time) error {
+	if o.window == nil || o.windowWait || o.window.contains(now) {
+		return nil
+	}
+	return fmt.Errorf("outside the execution window %q, which next opens at %s (leave -window-wait on to wait for it)",
+		o.window.spec, o.window.nextOpen(now).Format(time.RFC3339))
+}
+
+// check reports an error for settings the worker pool cannot use.
+func (o *poolOptions) check() error {
+	if o.concurrency < 1 {
+		return fmt.Errorf("-concurrency must be at least 1, got %d", o.concurrency)
+	}
+	if o.perMinute <= 0 {
+		return fmt.Errorf("-rate must be positive, got %g", o.perMinute)
+	}
+	if o.adaptive && o.budget < 1 {
+		return fmt.Errorf("-rate-budget must be at least 1, got %d", o.budget)
+	}
+	if o.chunkSize < 0 || o.chunkPause < 0 {
+		return fmt.Errorf("-chunk-size and -chunk-pause cannot be negative")
+	}
+	if o.chunkPause > 0 && o.chunkSize == 0 {
+		return fmt.Errorf("-chunk-pause needs -chunk-size")
+	}
+	switch {
+	case o.onError != onErrorContinue && o.onError != onErrorFailFast:
+		return fmt.Errorf("-on-error must be %s or %s, got %q", onErrorContinue, onErrorFailFast, o.onError)
+	case o.maxFailures < 0:
+		return fmt.Errorf("-max-failures cannot be negative, got %d", o.maxFailures)
+	case o.onError == onErrorFailFast && o.maxFailures > 1:
+		return fmt.Errorf("-on-error %s stops at the first failure; it cannot be used with -max-failures %d", onErrorFailFast, o.maxFailures)
+	}
+	return nil
+}
+
+// failureLimit returns the limit that stops a run, cancelling it with abort,
+// or nil if failures never stop it.
+func (o *poolOptions) failureLimit(abort context.CancelCauseFunc) *failureLimit {
+	limit := o.maxFailures
+	if o.onError == onErrorFailFast {
+		limit = 1
+	}
+	if limit == 0 {
+		return nil
+	}
+	return &failureLimit{max: limit, abort: abort}
+}
+
+// apply configures x's workers. Each workspace has its own rate limits, so
+// every executor gets its own limiter.
+func (o *poolOptions) apply(x *executor) {
+	x.Concurrency = o.concurrency
+	x.Limiter = rate.NewLimiter(rate.Limit(o.perMinute/60), 1)
+	x.progress = o.progress
+	x.window = o.window
+	x.chunks = newChunkPacer(o.chunkSize, o.chunkPause)
+	if o.adaptive {
+		// The budget takes the place of -rate.
+		x.Limiter = rate.NewLimiter(rate.Inf, 1)
+		x.pacer = newAdaptivePacer(o.budget)
+	}
+}
+
+// runResult is the outcome of executeRuns.
+type runResult struct {
+	failures int
+	// changed holds the entries that changed a channel, with their channel IDs.
+	changed []planEntry
+	// pending holds the entries an interrupt kept from starting.
+	pending     []planEntry
+	interrupted bool
+	// aborted is set when -max-failures or -on-error fail-fast stopped the run.
+	aborted bool
+	// results holds the outcome of every entry, for -results-file.
+	results []entryResult
+	// api sums up the run's Slack API calls, and planAPI those the entries
+	// of each plan file made.
+	api     apiStats
+	planAPI map[string]apiStats
+}
+
+// exitCode is 130 for an interrupted run, as for a shell job killed by
+// SIGINT, 1 if any entry failed and 0 otherwise.
+func (r runResult) exitCode() int {
+	switch {
+	case r.interrupted:
+		return 130
+	case r.failures > 0:
+		return 1
+	}
+	return 0
+}
+
+// executeRuns applies each workspace's plan in turn and prints its summary.
+// The first SIGINT or SIGTERM stops new entries from starting, lets in-flight
+// calls finish and prints which entries were left pending.
+func executeRuns(runs []workspaceRun, opts runOptions) (runResult, error) {
+	var res runResult
+	started := time.Now()
+	if err := opts.pool.checkWindow(started); err != nil {
+		return res, err
+	}
+	if len(runs) > 0 && runs[0].simulated {
+		// A simulation changes nothing outside its snapshot.
+		opts.history, opts.checkpoint, opts.webhook, opts.watch = "", nil, webhookOptions{}, watchOptions{}
+	}
+	unlock, err := acquireLocks(cmdCtx, runs, opts.lock, opts.source)
+	if err != nil {
+		return res, err
+	}
+	defer unlock()
+	recorder, stopRecording := apiRecorders.start()
+	defer stopRecording()
+	rateLimitRetries, transientRetries := retryCounts(runs)
+	var store *historyStore
+	var run historyRun
+	if opts.history != "" {
+		var err error
+		if store, err = openHistory(opts.history); err != nil {
+			return res, err
+		}
+		defer store.Close()
+		if run, err = store.startRun(opts.source); err != nil {
+			return res, err
+		}
+		slog.Info("recording changes", "run", run.ID, "history_db", opts.history)
+	}
+	var watch *renameWatch
+	if opts.watch.enabled {
+		watchCtx, stopWatch := context.WithCancel(cmdCtx)
+		defer stopWatch()
+		watch = startRenameWatch(watchCtx, runs)
+	}
+
+	live.runStarted(runs)
+	auditLog.runStarted(runs, opts.source, run.ID)
+	defer live.runFinished()
+	hooks := opts.webhook.start(run.ID, opts.verb, opts.source)
+	hooks.send(webhookEvent{Event: eventRunStarted, Entries: countEntries(runs)})
+
+	ctx, span := tracer.Start(cmdCtx, opts.verb+" run", trace.WithAttributes(
+		attribute.String("source", opts.source), attribute.Int("entries", countEntries(runs)), attribute.Int64("history_run", int64(run.ID))))
+	defer span.End()
+	ctx, stop := interruptContext(ctx)
+	defer stop()
+	ctx, abort := context.WithCancelCause(ctx)
+	defer abort(nil)
+	limit := opts.pool.failureLimit(abort)
+
+	prompter := &rowPrompter{in: stdin}
+	canaryDone := false
+	for _, r := range runs {
+		if ctx.Err() != nil {
+			res.pending = append(res.pending, r.plan...)
+			res.results = append(res.results, runner.PendingResults(r.plan, r.channels)...)
+			continue
+		}
+		slog.Info(r.label()+"starting "+opts.verb, "entries", len(r.plan))
+		x := r.executor(opts.verify)
+		x.checkpoint = opts.checkpoint
+		x.StaleCheck = opts.staleCheck
+		x.watch = watch
+		opts.pool.apply(x)
+		if r.simulated {
+			// There are no rate limits to keep to.
+			x.Limiter = rate.NewLimiter(rate.Inf, 1)
+			x.pacer = nil
+		}
+		// Prompts would be drawn over by the progress bar.
+		x.progress = x.progress && !opts.byGroup && !opts.interactive
+		x.label = r.label()
+		x.webhook = hooks
+		x.observe = opts.observe
+		x.failureLimit = limit
+		if store != nil {
+			x.history = &historyRecorder{store: store, run: run.ID, actor: r.actor(), workspace: r.workspace}
+		}
+		fetch := r.fetchChannels
+		switch {
+		case r.channelOpts.admin:
+			fetch = x.fetchRenamedAdmin
+		case r.targeted:
+			fetch = func(ctx context.Context) (map[string]channelInfo, error) { return x.fetchRenamedByID(ctx, r.channels) }
+		}
+		switch {
+		case opts.byGroup:
+			res.failures += applyByGroup(ctx, x, r.channels, r.plan, stdin)
+		case opts.interactive:
+			res.failures += applyInteractively(ctx, x, r.channels, r.plan, prompter)
+		case opts.canary.size > 0 && !canaryDone:
+			// Only the first workspace's plan starts with a canary.
+			canaryDone = true
+			res.failures += x.applyCanary(ctx, opts.canary, r.channels, r.plan, fetch, stdin)
+		default:
+			res.failures += x.applyEntries(ctx, r.channels, r.plan)
+		}
+		if opts.verifyPass {
+			res.failures += x.verifyPass(ctx, fetch, r.channels)
+		}
+		if opts.announce.enabled() {
+			x.announce(opts.announce, opts.announcement, r.channels)
+		}
+		if opts.announce.pinOldName {
+			x.pinOldNames(r.channels)
+		}
+		if opts.updateBookmarks {
+			x.updateBookmarks(r.channels)
+		}
+		if opts.references != "" {
+			x.updateReferences(opts.references, r)
+		}
+		if output == outputTable {
+			printResultsTable(os.Stdout, x.Results)
+		}
+		printSummary(r.workspace, r.stats)
+		if len(x.Changed) > 0 {
+			r.dropChannelCache()
+		}
+		changed := resolveIDs(x.Changed, r.channels)
+		if opts.verb == "rollback" {
+			x.unpinOldNames(changed)
+		}
+		res.changed = append(res.changed, changed...)
+		res.pending = append(res.pending, x.Pending...)
+		res.results = append(res.results, x.Results...)
+	}
+
+	res.aborted = errors.Is(context.Cause(ctx), errTooManyFailures)
+	res.interrupted = ctx.Err() != nil && !res.aborted
+	rl, tr := retryCounts(runs)
+	took := time.Since(started)
+	res.api = recorder.stats(took, rl-rateLimitRetries, tr-transientRetries)
+	res.planAPI = make(map[string]apiStats)
+	for _, r := range res.results {
+		if _, ok := res.planAPI[r.Entry.File]; !ok {
+			res.planAPI[r.Entry.File] = recorder.planStats(r.Entry.File, took)
+		}
+	}
+	if output == outputJSON {
+		report.Results = append(report.Results, res.results...)
+		report.Interrupted = res.interrupted
+		report.Aborted = res.aborted
+		report.API = &res.api
+	} else {
+		printAPIStats(os.Stdout, res.api)
+	}
+	hooks.complete(res.results, res.interrupted)
+	ghActions.results(newRunReport(opts.source, started, res))
+	if (res.interrupted || res.aborted) && output != outputJSON {
+		how := "interrupted"
+		if res.aborted {
+			how = fmt.Sprintf("stopped after %d failures", limit.failures)
+		}
+		fmt.Printf("%s: %d changed, %d failed, %d not started\n", how, len(res.changed), res.failures, len(res.pending))
+		if len(res.pending) > 0 {
+			fmt.Println("not started:")
+			for _, e := range res.pending {
+				fmt.Printf("  - %s%s\n", e.At(), e)
+			}
+		}
+	}
+	watch.finish(cmdCtx, opts.watch.after, store)
+	return res, nil
+}
+
+// interruptContext returns a context derived from parent that is cancelled by
+// the first SIGINT or SIGTERM. Signal handling is then reset, so a second one
+// quits at once.
+func interruptContext(parent context.Context) (context.Context, func()) {
+	ctx, cancel := context.WithCancel(parent)
+	sigs := make(chan os.Signal, 1)
+	signal.Notify(sigs, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		select {
+		case sig := <-sigs:
+			signal.Stop(sigs)
+			slog.Warn("waiting for in-flight calls, no new entries will start (repeat to quit now)", "signal", sig)
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+	return ctx, func() {
+		signal.Stop(sigs)
+		cancel()
+	}
+}
+
+// planLimits holds the -max-renames safety cap shared by plan and apply.
+type planLimits struct {
+	maxRenames int
+	force      bool
+}
+
+func (l *planLimits) register(fs *flag.FlagSet) {
+	fs.IntVar(&l.maxRenames, "max-renames", 0, "abort if the plan contains more than this many operations (0 = no limit)")
+	fs.BoolVar(&l.force, "force", false, "proceed even if the plan exceeds -max-renames")
+}
+
+// check reports an error if the plan exceeds the cap and -force is not set.
+func (l *planLimits) check(n int) error {
+	if l.maxRenames <= 0 || n <= l.maxRenames {
+		return nil
+	}
+	if !l.force {
+		return fmt.Errorf("plan contains %d operations, exceeding -max-renames %d (pass -force to override)", n, l.maxRenames)
+	}
+	slog.Warn("plan exceeds -max-renames; continuing because -force is set", "operations", n, "max_renames", l.maxRenames)
+	return nil
+}
+
+// printRuns prints each workspace's plan, under a heading when -config is used.
+func printRuns(runs []workspaceRun, byGroup bool) {
+	if output != outputPlain {
+		reportPlan(os.Stdout, runs)
+		return
+	}
+	for _, r := range runs {
+		if r.workspace != "" {
+			fmt.Printf("workspace %s:\n", r.workspace)
+		}
+		printPlan(r.plan, byGroup)
+	}
+}
+
+// printReversePlan prints the plan of a rollback or revert in execution order.
+func printReversePlan(runs []workspaceRun, verb string) {
+	if output != outputPlain {
+		reportPlan(os.Stdout, runs)
+		return
+	}
+	for _, r := range runs {
+		fmt.Printf("%s%s plan:\n", r.label(), verb)
+		for _, entry := range r.plan {
+			printEntry(entry)
+		}
+	}
+}
+
+func printPlan(entries []planEntry, byGroup bool) {
+	if byGroup {
+		for _, g := range groupByOwner(entries) {
+			printGroupPlan(g)
+		}
+		return
+	}
+	for _, action := range plan.ActionOrder {
+		var n int
+		for _, entry := range entries {
+			if entry.Action == action {
+				if n == 0 {
+					fmt.Printf("%s plan:\n", action)
+				}
+				printEntry(entry)
+				n++
+			}
+		}
+	}
+}
+
+// printEntry prints one plan line, followed by any topic or purpose change.
+func printEntry(entry planEntry) {
+	fmt.Printf("  %s\n", entry)
+	if entry.Topic != "" {
+		fmt.Printf("      topic: %s\n", change(entry.Action, entry.OldTopic, entry.Topic))
+	}
+	if entry.Purpose != "" {
+		fmt.Printf("      purpose: %s\n", change(entry.Action, entry.OldPurpose, entry.Purpose))
+	}
+	if entry.Members != "" {
+		fmt.Printf("      members: %s\n", strings.Join(plan.MemberList(entry.Members), ", "))
+	}
+	if entry.Stats != nil {
+		fmt.Printf("      channel: %s\n", entry.Stats)
+	}
+	if entry.HighImpact != "" {
+		fmt.Printf("      ! high impact: %s, needs confirmation\n", entry.HighImpact)
+	}
+	if entry.ChannelTeam != "" {
+		fmt.Printf("      workspace: %s\n", entry.ChannelTeam)
+	}
+	if entry.Shared != "" {
+		fmt.Printf("      visible beyond the workspace: %s\n", entry.Shared)
+	}
+}
+
+// change describes a set-topic entry's new value next to the one it replaces.
+func change(action, old, v string) string {
+	if action != actionSetTopic {
+		return v
+	}
+	return fmt.Sprintf("%q -> %q", old, v)
+}
+
+func cmdValidate(args []string) int {
+	fs := newFlagSet("validate")
+	var channelOpts channelOptions
+	channelOpts.register(fs)
+	var ws workspaceOptions
+	ws.register(fs)
+	var in planInput
+	in.register(fs)
+	history := fs.String("history-db", defaultHistoryDB, "skip renames this history database shows were already made (empty to disable)")
+	var onConflict conflictPolicy
+	onConflict.register(fs)
+	var protect protectOptions
+	protect.register(fs)
+	autoFix := fs.Bool("auto-fix", false, "rewrite target names to the form Slack would store them in (lowercase, spaces to hyphens, illegal characters removed)")
+	nfkc := fs.Bool("normalize-unicode", false, "rewrite target names to Unicode NFKC form (full-width letters and digits become ASCII)")
+	includeArchived := fs.Bool("include-archived", false, "also rename archived channels, unarchiving each for the rename and archiving it again")
+	includeShared := fs.Bool("include-shared", false, "also change channels shared with other organizations (Slack Connect) or workspaces, who see every change")
+	watch := fs.Bool("watch", false, "validate again each time a plan file changes, keeping the channel list between runs, until interrupted")
+	registerOutput(fs)
+	parseFlags(fs, args)
+	if *watch {
+		if err := in.checkWatch(); err != nil {
+			slog.Error(err.Error())
+			return 2
+		}
+	}
+
+	sessions, err := ws.newSessions(channelOpts)
+	if err != nil {
+		slog.Error(err.Error())
+		return 1
+	}
+	validate := func() int {
+		plan, err := loadPlan(in, "")
+		if err != nil {
+			slog.Error(err.Error())
+			return 1
+		}
+		if _, err := preparePlan(sessions, ws, plan, prepareOptions{historyDB: *history, onConflict: onConflict, protect: protect, autoFix: *autoFix, normalizeUnicode: *nfkc, includeArchived: *includeArchived, includeShared: *includeShared}); err != nil {
+			if !errors.Is(err, errValidation) {
+				slog.Error(err.Error())
+			}
+			return 1
+		}
+		return 0
+	}
+	if *watch {
+		ctx, stop := signal.NotifyContext(cmdCtx, os.Interrupt, syscall.SIGTERM)
+		defer stop()
+		return watchPlan(ctx, in, sessions, validate)
+	}
+	return validate()
+}
+
+func cmdPlan(args []string) int {
+	fs := newFlagSet("plan")
+	var channelOpts channelOptions
+	channelOpts.register(fs)
+	var ws workspaceOptions
+	ws.register(fs)
+	var in planInput
+	in.register(fs)
+	out := fs.String("out", "", "write the resolved plan with a checksum to this file for a later 'apply -plan-file'")
+	byGroup := fs.Bool("by-group", false, "print the plan grouped by the owner column")
+	perPlan := fs.Bool("per-plan", false, "also summarize the changes of each plan file, when several are given")
+	var highImpact highImpactOptions
+	highImpact.register(fs)
+	stats := fs.Bool("stats", false, "print the member count, creator, creation date and last message of each row's channel next to it (one API call per channel)")
+	detailedExit := fs.Bool("detailed-exitcode", false, "exit with 2 when the plan has changes to make, 0 when there are none and 1 on errors")
+	metricsFile := fs.String("metrics-file", "", "write run metrics in Prometheus textfile format to this path")
+	history := fs.String("history-db", defaultHistoryDB, "skip renames this history database shows were already made (empty to disable)")
+	var limits planLimits
+	limits.register(fs)
+	var onConflict conflictPolicy
+	onConflict.register(fs)
+	var protect protectOptions
+	protect.register(fs)
+	autoFix := fs.Bool("auto-fix", false, "rewrite target names to the form Slack would store them in (lowercase, spaces to hyphens, illegal characters removed)")
+	nfkc := fs.Bool("normalize-unicode", false, "rewrite target names to Unicode NFKC form (full-width letters and digits become ASCII)")
+	includeArchived := fs.Bool("include-archived", false, "also rename archived channels, unarchiving each for the rename and archiving it again")
+	includeShared := fs.Bool("include-shared", false, "also change channels shared with other organizations (Slack Connect) or workspaces, who see every change")
+	watch := fs.Bool("watch", false, "print the plan again each time a plan file changes, keeping the channel list between runs, until interrupted")
+	registerOutput(fs)
+	parseFlags(fs, args)
+	if *watch {
+		if err := in.checkWatch(); err != nil {
+			slog.Error(err.Error())
+			return 2
+		}
+	}
+
+	sessions, err := ws.newSessions(channelOpts)
+	if err != nil {
+		slog.Error(err.Error())
+		return 1
+	}
+	defer flushMetrics(*metricsFile, sessions)
+
+	dryRun := func() int {
+		plan, err := loadPlan(in, "")
+		if err != nil {
+			slog.Error(err.Error())
+			return 1
+		}
+		runs, err := preparePlan(sessions, ws, plan, prepareOptions{historyDB: *history, onConflict: onConflict, protect: protect, autoFix: *autoFix, normalizeUnicode: *nfkc, includeArchived: *includeArchived, includeShared: *includeShared})
+		if err != nil {
+			if !errors.Is(err, errValidation) {
+				slog.Error(err.Error())
+			}
+			return 1
+		}
+		if *stats {
+			addChannelStats(cmdCtx, runs)
+		}
+		highImpact.mark(runs)
+		printRuns(runs, *byGroup)
+		if *perPlan {
+			printPlanFileSummaries(runs)
+		}
+		n := countEntries(runs)
+		if err := limits.check(n); err != nil {
+			slog.Error(err.Error())
+			return 1
+		}
+
+		if *out != "" {
+			var resolved []planEntry
+			teams := make(map[string]string)
+			for _, r := range runs {
+				resolved = append(resolved, stateOf(resolveIDs(r.plan, r.channels), r.channels)...)
+				if r.auth != nil {
+					teams[r.workspace] = r.auth.TeamID
+				}
+			}
+			if err := writePlanFile(*out, in.describe(), resolved, teams); err != nil {
+				slog.Error("failed to write plan file", "err", err)
+				return 1
+			}
+			slog.Info("wrote resolved plan", "entries", n, "file", *out)
+		}
+		return dryRunExitCode(runs, *detailedExit)
+	}
+	if *watch {
+		ctx, stop := signal.NotifyContext(cmdCtx, os.Interrupt, syscall.SIGTERM)
+		defer stop()
+		return watchPlan(ctx, in, sessions, dryRun)
+	}
+	return dryRun()
+}
+
+func cmdApply(args []string) int {
+	return applyPlan("apply", "", args)
+}
+
+// applyPlan runs the command name, which is apply or, when listAction is set, a
+// command like 'archive' that applies listAction to the channels listed in its
+// -plan files.
+func applyPlan(name, listAction string, args []string) int {
+	fs := newFlagSet(name)
+	var channelOpts channelOptions
+	channelOpts.register(fs)
+	var ws workspaceOptions
+	ws.register(fs)
+	in := planInput{listAction: listAction}
+	in.register(fs)
+	planFile := fs.String("plan-file", "", "execute a resolved plan written by 'plan -out' instead of reading the CSV")
+	var dryRun, detailedExit *bool
+	if listAction != "" {
+		dryRun = fs.Bool("dry-run", false, "print the plan without changing anything")
+		detailedExit = fs.Bool("detailed-exitcode", false, "with -dry-run, exit with 2 when there are changes to make, 0 when there are none and 1 on errors")
+	}
+	verify := fs.Bool("verify", false, "re-read each channel after renaming and fail if its name does not match")
+	verifyPass := fs.Bool("verify-pass", true, "re-fetch the channels once the run is done and fail renames whose channel does not carry the new name")
+	staleCheck := fs.Bool("stale-check", true, "re-read each channel just before renaming it and skip it if it no longer has its planned name")
+	byGroup := fs.Bool("by-group", false, "apply the plan one owner group at a time, confirming each group")
+	perPlan := fs.Bool("per-plan", false, "summarize each plan file on its own and write -results-file and -report once per plan file, named after it")
+	stats := fs.Bool("stats", false, "print the member count, creator, creation date and last message of each row's channel next to it (one API call per channel)")
+	interactive := fs.Bool("interactive", false, "ask y/n/a(ll)/q(uit) before applying each plan row")
+	var canary canaryOptions
+	canary.register(fs)
+	var confirmOpts confirmOptions
+	confirmOpts.register(fs)
+	var highImpact highImpactOptions
+	highImpact.register(fs)
+	var approval approvalOptions
+	approval.register(fs)
+	var notify notifyOptions
+	notify.register(fs)
+	var email emailOptions
+	email.register(fs)
+	var announce announceOptions
+	announce.register(fs)
+	updateBookmarks := fs.Bool("update-bookmarks", false, "after the run, rewrite the old name to the new one in the titles of renamed channels' bookmarks (needs bookmarks:read and bookmarks:write)")
+	references := fs.String("references", "", "after the run, find #old-name mentions of renamed channels in every channel's topic and purpose: report lists them, update rewrites them")
+	review := fs.Bool("review", false, "review the validated plan in a terminal UI, turn rows off and apply only the approved ones")
+	metricsFile := fs.String("metrics-file", "", "write run metrics in Prometheus textfile format to this path")
+	metricsAddr := fs.String("metrics-addr", "", "serve Prometheus metrics on /metrics at this address (e.g. :9090) while the run lasts")
+	rollbackDir := fs.String("rollback-dir", ".", "write a reverse plan of the changes made to this directory for 'rollback -plan-file' (empty to disable)")
+	snapshotDir := fs.String("snapshot-dir", ".", "before applying, write the name, topic, purpose and archived state of every channel the plan changes to this directory for 'restore' (empty to disable)")
+	history := fs.String("history-db", defaultHistoryDB, "record every change in this history database and skip renames it shows were already made (empty to disable)")
+	resultsFile := fs.String("results-file", "", "write each entry's status, error, timestamps and resulting channel to this CSV (or .json) file")
+	reportFile := fs.String("report", "", "write a shareable report of the run, with every entry's names before and after, failures and timing, to this .html or .md file")
+	stateFile := fs.String("state-file", defaultStateFile, "record each entry's outcome in this file as the run progresses (empty to disable)")
+	resume := fs.Bool("resume", false, "skip the entries that -state-file says completed in an interrupted run")
+	var pool poolOptions
+	pool.register(fs)
+	var webhook webhookOptions
+	webhook.register(fs)
+	var lock lockOptions
+	lock.register(fs)
+	var watch watchOptions
+	watch.register(fs)
+	var limits planLimits
+	limits.register(fs)
+	var onConflict conflictPolicy
+	onConflict.register(fs)
+	var protect protectOptions
+	protect.register(fs)
+	autoFix := fs.Bool("auto-fix", false, "rewrite target names to the form Slack would store them in (lowercase, spaces to hyphens, illegal characters removed)")
+	nfkc := fs.Bool("normalize-unicode", false, "rewrite target names to Unicode NFKC form (full-width letters and digits become ASCII)")
+	includeArchived := fs.Bool("include-archived", false, "also rename archived channels, unarchiving each for the rename and archiving it again")
+	includeShared := fs.Bool("include-shared", false, "also change channels shared with other organizations (Slack Connect) or workspaces, who see every change")
+	schedule := fs.String("schedule", "", "keep running and apply the plan, read again each time, whenever this cron expression matches (e.g. '0 2 * * 6')")
+	registerOutput(fs)
+	parseFlags(fs, args)
+	if err := pool.check(); err != nil {
+		slog.Error(err.Error())
+		return 2
+	}
+	if err := webhook.check(); err != nil {
+		slog.Error(err.Error())
+		return 2
+	}
+	if err := email.check(); err != nil {
+		slog.Error(err.Error())
+		return 2
+	}
+	if err := checkReferencesMode(*references); err != nil {
+		slog.Error(err.Error())
+		return 2
+	}
+	if *reportFile != "" {
+		if _, err := reportFormat(*reportFile); err != nil {
+			slog.Error(err.Error())
+			return 2
+		}
+	}
+	if *planFile != "" && len(in.paths) > 0 {
+		slog.Error("-plan and -plan-file cannot be used together")
+		return 2
+	}
+	if listAction != "" && (len(in.paths) == 0 || *planFile != "" || in.sheet.id != "") {
+		slog.Error(name + " reads the channels to " + listAction + " from -plan files, not -plan-file or -sheet-id")
+		return 2
+	}
+	if *schedule != "" {
+		if *byGroup || *interactive || *review || *resume || confirmOpts.aboveRows > 0 || confirmOpts.aboveMembers > 0 || canary.size > 0 && canary.wait == 0 || output == outputJSON {
+			slog.Error("-schedule cannot be used with -by-group, -interactive, -review, -resume, -confirm-above-*, -canary without -canary-wait or -output json")
+			return 2
+		}
+		// The listener outlives the runs, which are started without the flag.
+		if err := serveMetrics(*metricsAddr); err != nil {
+			slog.Error(err.Error())
+			return 1
+		}
+		// Both are given empty, so that a settings file does not set them
+		// again.
+		rest := append([]string{"-schedule=", "-metrics-addr="}, dropFlag(dropFlag(args, "schedule"), "metrics-addr")...)
+		return runScheduled(*schedule, func() int { return applyPlan(name, listAction, rest) })
+	}
+	if err := approval.check(); err != nil {
+		slog.Error(err.Error())
+		return 2
+	}
+	announcement, err := announce.compile()
+	if err != nil {
+		slog.Error(err.Error())
+		return 2
+	}
+	if *resume && *stateFile == "" {
+		slog.Error("-resume needs a -state-file")
+		return 2
+	}
+	if ws.simulate != "" {
+		if *resume || approval.channel != "" {
+			slog.Error("-simulate cannot be used with -resume or -approval-channel")
+			return 2
+		}
+		// Nor is anything written for a simulated run to be resumed, rolled
+		// back or announced from.
+		*stateFile, *rollbackDir, *snapshotDir, notify = "", "", "", notifyOptions{}
+		slog.Info("simulating the run; no history, state file, rollback plan, channel snapshot, webhook or notification is written", "snapshot", ws.simulate)
+	}
+	if *byGroup && output == outputJSON {
+		slog.Error("-by-group cannot be used with -output json")
+		return 2
+	}
+	if *review && (*byGroup || output == outputJSON) {
+		slog.Error("-review cannot be used with -by-group or -output json")
+		return 2
+	}
+	if *interactive && (*byGroup || output == outputJSON) {
+		slog.Error("-interactive cannot be used with -by-group or -output json")
+		return 2
+	}
+	if canary.size > 0 && (*byGroup || *interactive || canary.wait == 0 && output == outputJSON) {
+		slog.Error("-canary cannot be used with -by-group or -interactive, nor with -output json unless -canary-wait is set")
+		return 2
+	}
+
+	if err := serveMetrics(*metricsAddr); err != nil {
+		slog.Error(err.Error())
+		return 1
+	}
+	sessions, err := ws.newSessions(channelOpts)
+	if err != nil {
+		slog.Error(err.Error())
+		return 1
+	}
+	defer flushMetrics(*metricsFile, sessions)
+
+	plan, err := loadPlan(in, *planFile)
+	if err != nil {
+		slog.Error(err.Error())
+		return 1
+	}
+	source := in.describe()
+	if *planFile != "" {
+		source = *planFile
+	}
+
+	var cp *checkpoint
+	switch {
+	case *resume:
+		if cp, err = loadCheckpoint(*stateFile); err != nil {
+			slog.Error(err.Error())
+			return 1
+		}
+		var done int
+		plan, done = cp.pending(plan)
+		slog.Info("resuming: skipping entries completed in a previous run", "state_file", *stateFile, "skipped", done)
+	case *stateFile != "":
+		if _, err := os.Stat(*stateFile); err == nil {
+			slog.Warn("replacing the state file left by an earlier run (pass -resume to continue that run instead)", "state_file", *stateFile)
+		}
+		cp = newCheckpoint(*stateFile, source)
+	}
+
+	prepOpts := prepareOptions{resolved: *planFile != "", historyDB: *history, onConflict: onConflict, protect: protect, autoFix: *autoFix, normalizeUnicode: *nfkc, includeArchived: *includeArchived, includeShared: *includeShared, holdNotDue: true}
+	runs, err := preparePlan(sessions, ws, plan, prepOpts)
+	if err != nil {
+		if !errors.Is(err, errValidation) {
+			slog.Error(err.Error())
+		}
+		return 1
+	}
+	if *review {
+		approved, err := reviewPlan(runs)
+		if err != nil {
+			slog.Error(err.Error())
+			return 1
+		}
+		// Validate the approved rows again, against a fresh channel list, so
+		// that chains and cycles are worked out for the subset.
+		plan = slices.DeleteFunc(plan, func(e planEntry) bool { return !approved[e.Source] })
+		if len(plan) == 0 {
+			slog.Info("no entries approved, nothing to do")
+			return 0
+		}
+		slog.Info("applying the approved entries", "count", len(plan))
+		if runs, err = preparePlan(sessions, ws, plan, prepOpts); err != nil {
+			if !errors.Is(err, errValidation) {
+				slog.Error(err.Error())
+			}
+			return 1
+		}
+	}
+	if *stats {
+		addChannelStats(cmdCtx, runs)
+	}
+	highImpact.mark(runs)
+	printRuns(runs, *byGroup)
+	if *perPlan {
+		printPlanFileSummaries(runs)
+	}
+	if err := limits.check(countEntries(runs)); err != nil {
+		slog.Error(err.Error())
+		return 1
+	}
+	if dryRun != nil && *dryRun {
+		return dryRunExitCode(runs, *detailedExit)
+	}
+	if err := confirmOpts.confirm(runs, stdin); err != nil {
+		slog.Error(err.Error())
+		return 1
+	}
+	if err := highImpact.confirm(runs, stdin); err != nil {
+		slog.Error(err.Error())
+		return 1
+	}
+	if approval.channel != "" && countEntries(runs) > 0 {
+		if err := approval.request(runs, source); err != nil {
+			slog.Error(err.Error())
+			return 1
+		}
+	}
+
+	if *snapshotDir != "" && countEntries(runs) > 0 {
+		if channelOpts.admin {
+			slog.Warn("no channel snapshot is written in -admin mode, whose listings have no topics or purposes")
+		} else {
+			path, err := writeChannelSnapshot(*snapshotDir, runs, *references == referencesUpdate)
+			if err != nil {
+				slog.Error("failed to write the channel snapshot", "err", err)
+				return 1
+			}
+			if path != "" {
+				slog.Info(fmt.Sprintf("wrote a snapshot of the plan's channels (put them back with 'restore -snapshot %s')", path), "file", path)
+			}
+		}
+	}
+
+	verb := cmp.Or(listAction, "rename")
+	started := time.Now()
+	res, err := executeRuns(runs, runOptions{verb: verb, verify: *verify, verifyPass: *verifyPass, staleCheck: *staleCheck, byGroup: *byGroup, interactive: *interactive, canary: canary, announce: announce, announcement: announcement, updateBookmarks: *updateBookmarks, references: *references, history: *history, source: source, checkpoint: cp, webhook: webhook, pool: pool, lock: lock, watch: watch})
+	if err != nil {
+		slog.Error(err.Error())
+		return 1
+	}
+	summary := newRunReport(source, started, res)
+	if cp != nil && (res.interrupted || res.aborted) {
+		slog.Info("progress saved; rerun with -resume to continue", "state_file", *stateFile)
+	}
+	if cp != nil && res.exitCode() == 0 {
+		// Nothing is left to resume.
+		if err := os.Remove(*stateFile); err != nil && !errors.Is(err, os.ErrNotExist) {
+			slog.Error("failed to remove state file", "err", err)
+		}
+	}
+
+	if *perPlan {
+		if err := writePlanFileArtifacts(res, started, *resultsFile, *reportFile); err != nil {
+			slog.Error(err.Error())
+			return 1
+		}
+	} else {
+		if *resultsFile != "" {
+			if err := writeResultsFile(*resultsFile, res.results, res.api); err != nil {
+				slog.Error("failed to write results file", "err", err)
+				return 1
+			}
+			slog.Info("wrote results", "entries", len(res.results), "file", *resultsFile)
+		}
+		if *reportFile != "" {
+			if err := writeRunReport(*reportFile, summary); err != nil {
+				slog.Error("failed to write the run report", "err", err)
+				return 1
+			}
+			slog.Info("wrote the run report", "file", *reportFile)
+		}
+	}
+	if *rollbackDir != "" && len(res.changed) > 0 {
+		path, err := writeRollbackFile(*rollbackDir, source, res.changed)
+		if err != nil {
+			slog.Error("failed to write rollback file", "err", err)
+			return 1
+		}
+		slog.Info(fmt.Sprintf("wrote rollback plan (undo with 'rollback -plan-file %s')", path), "file", path)
+	}
+	if notify.enabled() {
+		if err := notify.send(runs, notify.summaryText(res, source, *resultsFile)); err != nil {
+			slog.Error("failed to send the run summary", "err", err)
+		}
+	}
+	if email.enabled() {
+		if err := email.send(summary); err != nil {
+			slog.Error("failed to email the run report", "err", err)
+		} else {
+			slog.Info("emailed the run report", "to", email.to)
+		}
+	}
+	return res.exitCode()
+}
+
+func cmdRollback(args []string) int {
+	fs := newFlagSet("rollback")
+	var channelOpts channelOptions
+	channelOpts.register(fs)
+	var ws workspaceOptions
+	ws.register(fs)
+	var in planInput
+	in.register(fs)
+	planFile := fs.String("plan-file", "", "replay a reverse plan written by 'apply' instead of reversing the CSV")
+	dryRun := fs.Bool("dry-run", false, "print the rollback plan without renaming anything")
+	detailedExit := fs.Bool("detailed-exitcode", false, "with -dry-run, exit with 2 when there are changes to make, 0 when there are none and 1 on errors")
+	verify := fs.Bool("verify", false, "re-read each channel after renaming and fail if its name does not match")
+	verifyPass := fs.Bool("verify-pass", true, "re-fetch the channels once the run is done and fail renames whose channel does not carry the new name")
+	staleCheck := fs.Bool("stale-check", true, "re-read each channel just before renaming it and skip it if it no longer has its planned name")
+	history := fs.String("history-db", defaultHistoryDB, "record every change in this history database and skip renames it shows were already made (empty to disable)")
+	metricsFile := fs.String("metrics-file", "", "write run metrics in Prometheus textfile format to this path")
+	metricsAddr := fs.String("metrics-addr", "", "serve Prometheus metrics on /metrics at this address (e.g. :9090) while the run lasts")
+	var pool poolOptions
+	pool.register(fs)
+	var webhook webhookOptions
+	webhook.register(fs)
+	var lock lockOptions
+	lock.register(fs)
+	var protect protectOptions
+	protect.register(fs)
+	registerOutput(fs)
+	parseFlags(fs, args)
+	if err := pool.check(); err != nil {
+		slog.Error(err.Error())
+		return 2
+	}
+	if err := webhook.check(); err != nil {
+		slog.Error(err.Error())
+		return 2
+	}
+	if *planFile != "" && len(in.paths) > 0 {
+		slog.Error("-plan and -plan-file cannot be used together")
+		return 2
+	}
+
+	if err := serveMetrics(*metricsAddr); err != nil {
+		slog.Error(err.Error())
+		return 1
+	}
+	sessions, err := ws.newSessions(channelOpts)
+	if err != nil {
+		slog.Error(err.Error())
+		return 1
+	}
+	defer flushMetrics(*metricsFile, sessions)
+
+	var reverse []planEntry
+	if *planFile != "" {
+		// Reverse plans written by apply are already reversed.
+		if reverse, err = loadPlan(in, *planFile); err != nil {
+			slog.Error(err.Error())
+			return 1
+		}
+	} else {
+		plan, files, err := in.load()
+		if err != nil {
+			slog.Error("failed to load plan", "err", err)
+			return 1
+		}
+		if plan, err = in.filter.apply(plan); err != nil {
+			slog.Error(err.Error())
+			return 1
+		}
+		plan, tmplErrs := expandUndatedTemplates(plan, time.Now())
+		if len(tmplErrs) > 0 {
+			reportValidation(tmplErrs, nil)
+			return 1
+		}
+		reverse = reverseEntries(plan)
+		slog.Info("loaded rename entries to roll back", "count", len(reverse), "files", strings.Join(files, ", "))
+	}
+
+	runs, err := preparePlan(sessions, ws, reverse, prepareOptions{resolved: *planFile != "", historyDB: *history, protect: protect})
+	if err != nil {
+		if !errors.Is(err, errValidation) {
+			slog.Error(err.Error())
+		}
+		return 1
+	}
+
+	printReversePlan(runs, "rollback")
+	if *dryRun {
+		return dryRunExitCode(runs, *detailedExit)
+	}
+
+	source := "rollback of " + in.describe()
+	if *planFile != "" {
+		source = *planFile
+	}
+	res, err := executeRuns(runs, runOptions{verb: "rollback", verify: *verify, verifyPass: *verifyPass, staleCheck: *staleCheck, history: *history, source: source, webhook: webhook, pool: pool, lock: lock})
+	if err != nil {
+		slog.Error(err.Error())
+		return 1
+	}
+	return res.exitCode()
+}
+
+func cmdExport(args []string) int {
+	fs := newFlagSet("export")
+	var channelOpts channelOptions
+	channelOpts.register(fs)
+	var ws workspaceOptions
+	ws.register(fs)
+	out := fs.String("out", "", "write to this file instead of stdout")
+	format := fs.String("format", "", "csv or json (default: json for a -out ending in .json, csv otherwise)")
+	lastActivity := fs.Bool("last-activity", false, "add the time of each unarchived channel's newest message (one API call per channel)")
+	parseFlags(fs, args)
+	if *format == "" {
+		*format = "csv"
+		if strings.EqualFold(filepath.Ext(*out), ".json") {
+			*format = "json"
+		}
+	}
+	if *format != "csv" && *format != "json" {
+		slog.Error(fmt.Sprintf("unknown export format %q (want csv or json)", *format))
+		return 2
+	}
+
+	sessions, err := ws.newSessions(channelOpts)
+	if err != nil {
+		slog.Error(err.Error())
+		return 1
+	}
+	var rows []exportRow
+	for _, s := range sessions {
+		channels, err := s.listChannels(cmdCtx)
+		if err != nil {
+			slog.Error(err.Error())
+			return 1
+		}
+		sessionRows := exportRows(s.workspace, channels)
+		if *lastActivity {
+			s.addLastActivity(cmdCtx, sessionRows)
+		}
+		rows = append(rows, sessionRows...)
+	}
+
+	w := io.Writer(os.Stdout)
+	if *out != "" {
+		f, err := os.Create(*out)
+		if err != nil {
+			slog.Error("failed to create output file", "file", *out, "err", err)
+			return 1
+		}
+		defer f.Close()
+		w = f
+	}
+	if err := writeExport(w, *format, rows, *lastActivity, ws.config != ""); err != nil {
+		slog.Error("failed to write export", "err", err)
+		return 1
+	}
+	if *out != "" {
+		slog.Info("exported channels", "count", len(rows), "file", *out)
+	}
+	return 0
+}
+
+func cmdGenerate(args []string) int {
+	fs := newFlagSet("generate")
+	var channelOpts channelOptions
+	channelOpts.register(fs)
+	var ws workspaceOptions
+	ws.register(fs)
+	match := fs.String("match", "", "regular expression selecting the channels to rename, e.g. '^proj-(.*)$'")
+	replace := fs.String("replace", "", "new name for each match; $1, ${name} etc. expand to submatches, e.g. 'project-$1'")
+	includeArchived := fs.Bool("include-archived", false, "also rename archived channels")
+	out := fs.String("out", "", "write to this file instead of stdout")
+	parseFlags(fs, args)
+	if *match == "" || *replace == "" {
+		slog.Error("generate needs -match and -replace")
+		return 2
+	}
+	re, err := regexp.Compile(*match)
+	if err != nil {
+		slog.Error("invalid -match", "err", err)
+		return 2
+	}
+
+	sessions, err := ws.newSessions(channelOpts)
+	if err != nil {
+		slog.Error(err.Error())
+		return 1
+	}
+	rowsBySession := make([][][2]string, len(sessions))
+	for i, s := range sessions {
+		channels, err := s.listChannels(cmdCtx)
+		if err != nil {
+			slog.Error(err.Error())
+			return 1
+		}
+		rowsBySession[i] = generateRows(channels, re, *replace, *includeArchived)
+	}
+
+	if err := writeGeneratedPlan(*out, sessions, rowsBySession, ws.config != ""); err != nil {
+		slog.Error(err.Error())
+		return 1
+	}
+	return 0
+}
+
+// writeGeneratedPlan writes rowsBySession, the asis/tobe rows computed for
+// each session, as a plan CSV to path or, if path is empty, stdout. With
+// withWorkspace the rows name their workspace.
+func writeGeneratedPlan(path string, sessions []*session, rowsBySession [][][2]string, withWorkspace bool) error {
+	w := io.Writer(os.Stdout)
+	if path != "" {
+		f, err := os.Create(path)
+		if err != nil {
+			return fmt.Errorf("create output file %q: %w", path, err)
+		}
+		defer f.Close()
+		w = f
+	}
+
+	header := []string{"asis", "tobe"}
+	if withWorkspace {
+		header = append(header, "workspace")
+	}
+	cw := csv.NewWriter(w)
+	cw.Write(header)
+	n := 0
+	for i, rows := range rowsBySession {
+		for _, r := range rows {
+			row := r[:]
+			if withWorkspace {
+				row = append(row, sessions[i].workspace)
+			}
+			cw.Write(row)
+			n++
+		}
+	}
+	cw.Flush()
+	if err := cw.Error(); err != nil {
+		return fmt.Errorf("write plan: %w", err)
+	}
+	slog.Info("generated rename rows", "count", n)
+	return nil
+}
+
+func cmdTransform(args []string) int {
+	fs := newFlagSet("transform")
+	var channelOpts channelOptions
+	channelOpts.register(fs)
+	var ws workspaceOptions
+	ws.register(fs)
+	var xf transformOptions
+	xf.register(fs)
+	includeArchived := fs.Bool("include-archived", false, "also rename archived channels, unarchiving each for the rename and archiving it again")
+	out := fs.String("out", "", "write the plan to this file instead of stdout; with -apply, keep it there")
+	apply := fs.Bool("apply", false, "apply the renames, passing the flags after -- to apply, instead of only writing the plan")
+	parseFlags(fs, args)
+	if err := xf.check(); err != nil {
+		slog.Error(err.Error())
+		return 2
+	}
+
+	sessions, err := ws.newSessions(channelOpts)
+	if err != nil {
+		slog.Error(err.Error())
+		return 1
+	}
+	rowsBySession := make([][][2]string, len(sessions))
+	for i, s := range sessions {
+		channels, err := s.listChannels(cmdCtx)
+		if err != nil {
+			slog.Error(err.Error())
+			return 1
+		}
+		rowsBySession[i] = xf.rows(channels, *includeArchived)
+	}
+	return emitGeneratedPlan(fs, args, transformFlags, sessions, rowsBySession, ws.config != "", *out, *apply)
+}
+
+// emitGeneratedPlan writes the rows a command such as transform computed as a
+// plan CSV to out or stdout or, with apply, applies them. apply then runs with
+// the flags the command was given, minus ownFlags and -apply, followed by the
+// flags after --, as 'apply -schedule' runs its plan; out keeps the plan.
+func emitGeneratedPlan(fs *flag.FlagSet, args, ownFlags []string, sessions []*session, rowsBySession [][][2]string, withWorkspace bool, out string, apply bool) int {
+	if !apply {
+		if err := writeGeneratedPlan(out, sessions, rowsBySession, withWorkspace); err != nil {
+			slog.Error(err.Error())
+			return 1
+		}
+		return 0
+	}
+
+	path := out
+	if path == "" {
+		dir, err := os.MkdirTemp("", fs.Name())
+		if err != nil {
+			slog.Error("failed to create a directory for the plan", "err", err)
+			return 1
+		}
+		defer os.RemoveAll(dir)
+		path = filepath.Join(dir, fs.Name()+".csv")
+	}
+	if err := writeGeneratedPlan(path, sessions, rowsBySession, withWorkspace); err != nil {
+		slog.Error(err.Error())
+		return 1
+	}
+	applyArgs := []string{"-plan", path}
+	for _, a := range args[:len(args)-len(fs.Args())] {
+		if a == "--" {
+			break
+		}
+		applyArgs = append(applyArgs, a)
+	}
+	for _, name := range append(ownFlags, "out") {
+		applyArgs = dropFlag(applyArgs, name)
+	}
+	applyArgs = slices.DeleteFunc(applyArgs, func(a string) bool {
+		bare, _, _ := strings.Cut(strings.TrimLeft(a, "-"), "=")
+		return bare == "apply"
+	})
+	return applyPlan("apply", "", append(applyArgs, fs.Args()...))
+}
+
+func cmdNormalize(args []string) int {
+	fs := newFlagSet("normalize")
+	var channelOpts channelOptions
+	channelOpts.register(fs)
+	var ws workspaceOptions
+	ws.register(fs)
+	includeArchived := fs.Bool("include-archived", false, "also rename archived channels, unarchiving each for the rename and archiving it again")
+	out := fs.String("out", "", "write the plan to this file instead of stdout; with -apply, keep it there")
+	apply := fs.Bool("apply", false, "apply the renames, passing the flags after -- to apply, instead of only writing the plan")
+	parseFlags(fs, args)
+
+	sessions, err := ws.newSessions(channelOpts)
+	if err != nil {
+		slog.Error(err.Error())
+		return 1
+	}
+	rowsBySession := make([][][2]string, len(sessions))
+	for i, s := range sessions {
+		channels, err := s.listChannels(cmdCtx)
+		if err != nil {
+			slog.Error(err.Error())
+			return 1
+		}
+		rowsBySession[i] = normalizeRows(channels, *includeArchived)
+	}
+	return emitGeneratedPlan(fs, args, nil, sessions, rowsBySession, ws.config != "", *out, *apply)
+}
+
+func cmdLint(args []string) int {
+	fs := newFlagSet("lint")
+	var channelOpts channelOptions
+	channelOpts.register(fs)
+	var ws workspaceOptions
+	ws.register(fs)
+	var in planInput
+	in.register(fs)
+	policyFile := fs.String("policy", "", "YAML file of naming rules (teams, banned_words, max_length, separators)")
+	live := fs.Bool("live", true, "check the names of the live channels")
+	includeArchived := fs.Bool("include-archived", false, "with -live, also check archived channels")
+	parseFlags(fs, args)
+	if *policyFile == "" {
+		slog.Error("lint needs -policy")
+		return 2
+	}
+	policy, err := loadLintPolicy(*policyFile)
+	if err != nil {
+		slog.Error("failed to load policy", "err", err)
+		return 1
+	}
+
+	violations := 0
+	report := func(subject string, problems []string, suggestion string) {
+		if len(problems) == 0 {
+			return
+		}
+		fmt.Printf("%s: %s (suggested: %s)\n", subject, strings.Join(problems, "; "), suggestion)
+		violations++
+	}
+
+	// Planned names are only checked when a plan is given explicitly.
+	if len(in.paths) > 0 || in.sheet.id != "" {
+		plan, err := loadPlan(in, "")
+		if err != nil {
+			slog.Error(err.Error())
+			return 1
+		}
+		for _, e := range plan {
+			if e.Action == actionRename {
+				problems, suggestion := policy.check(e.Tobe, e.Owner)
+				report(fmt.Sprintf("%s%s", e.At(), e), problems, suggestion)
+			}
+		}
+	}
+
+	if *live {
+		sessions, err := ws.newSessions(channelOpts)
+		if err != nil {
+			slog.Error(err.Error())
+			return 1
+		}
+		for _, s := range sessions {
+			channels, err := s.listChannels(cmdCtx)
+			if err != nil {
+				slog.Error(err.Error())
+				return 1
+			}
+			for _, name := range slices.Sorted(maps.Keys(channels)) {
+				if channels[name].IsArchived && !*includeArchived {
+					continue
+				}
+				problems, suggestion := policy.check(name, "")
+				report(s.label()+"#"+name, problems, suggestion)
+			}
+		}
+	}
+
+	slog.Info("lint done", "violations", violations)
+	if violations > 0 {
+		return 1
+	}
+	return 0
+}
+
+func cmdDiff(args []string) int {
+	fs := newFlagSet("diff")
+	var in planInput
+	in.registerFormat(fs)
+	var channelOpts channelOptions
+	channelOpts.register(fs)
+	var ws workspaceOptions
+	ws.register(fs)
+	parseFlags(fs, args)
+	switch fs.NArg() {
+	case 1:
+		// An export is compared with the live workspace.
+		sessions, err := ws.newSessions(channelOpts)
+		if err != nil {
+			slog.Error(err.Error())
+			return 2
+		}
+		return runExportDiff(fs.Arg(0), sessions, ws.config != "", channelOpts.includePrivate)
+	case 2:
+		return runDiff(in, fs.Arg(0), fs.Arg(1))
+	}
+	fs.Usage()
+	return 2
+}
+
+func cmdHistory(args []string) int {
+	if len(args) == 0 {
+		newFlagSet("history").Usage()
+		return 2
+	}
+	switch args[0] {
+	case "list", "show":
+		return cmdHistoryQuery(args[0], args[1:])
+	case "revert":
+		return cmdHistoryRevert(args[1:])
+	}
+	fmt.Fprintf(os.Stderr, "unknown history command %q (want list, show or revert)\n", args[0])
+	return 2
+}
+
+// cmdHistoryQuery runs 'history list' and 'history show <channel>'.
+func cmdHistoryQuery(sub string, args []string) int {
+	fs := newFlagSet("history")
+	path := fs.String("history-db", defaultHistoryDB, "history database written by apply and rollback")
+	registerOutput(fs)
+	parseFlags(fs, args)
+	if sub == "show" && fs.NArg() != 1 {
+		fs.Usage()
+		return 2
+	}
+
+	if _, err := os.Stat(*path); errors.Is(err, os.ErrNotExist) {
+		slog.Error("no history recorded yet", "history_db", *path)
+		return 1
+	}
+	h, err := openHistory(*path)
+	if err != nil {
+		slog.Error(err.Error())
+		return 1
+	}
+	defer h.Close()
+
+	if sub == "list" {
+		err = printHistoryRuns(os.Stdout, h)
+	} else {
+		err = printChannelHistory(os.Stdout, h, fs.Arg(0))
+	}
+	if err != nil {
+		slog.Error("failed to read history", "err", err)
+		return 1
+	}
+	return 0
+}
+
+// cmdHistoryRevert undoes the changes of a recorded run, newest first, and
+// records the revert as a run of its own.
+func cmdHistoryRevert(args []string) int {
+	fs := newFlagSet("history")
+	var channelOpts channelOptions
+	channelOpts.register(fs)
+	var ws workspaceOptions
+	ws.register(fs)
+	path := fs.String("history-db", defaultHistoryDB, "history database written by apply and rollback")
+	dryRun := fs.Bool("dry-run", false, "print the revert plan without changing anything")
+	detailedExit := fs.Bool("detailed-exitcode", false, "with -dry-run, exit with 2 when there are changes to make, 0 when there are none and 1 on errors")
+	verify := fs.Bool("verify", false, "re-read each channel after renaming and fail if its name does not match")
+	verifyPass := fs.Bool("verify-pass", true, "re-fetch the channels once the run is done and fail renames whose channel does not carry the new name")
+	staleCheck := fs.Bool("stale-check", true, "re-read each channel just before renaming it and skip it if it no longer has its planned name")
+	metricsFile := fs.String("metrics-file", "", "write run metrics in Prometheus textfile format to this path")
+	metricsAddr := fs.String("metrics-addr", "", "serve Prometheus metrics on /metrics at this address (e.g. :9090) while the run lasts")
+	var pool poolOptions
+	pool.register(fs)
+	var webhook webhookOptions
+	webhook.register(fs)
+	var lock lockOptions
+	lock.register(fs)
+	var protect protectOptions
+	protect.register(fs)
+	registerOutput(fs)
+	parseFlags(fs, args)
+	if err := pool.check(); err != nil {
+		slog.Error(err.Error())
+		return 2
+	}
+	if err := webhook.check(); err != nil {
+		slog.Error(err.Error())
+		return 2
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return 2
+	}
+	id, err := strconv.ParseUint(fs.Arg(0), 10, 64)
+	if err != nil {
+		slog.Error(fmt.Sprintf("invalid run ID %q", fs.Arg(0)))
+		return 2
+	}
+
+	if _, err := os.Stat(*path); errors.Is(err, os.ErrNotExist) {
+		slog.Error("no history recorded yet", "history_db", *path)
+		return 1
+	}
+	h, err := openHistory(*path)
+	if err != nil {
+		slog.Error(err.Error())
+		return 1
+	}
+	run, err := h.run(id)
+	var changes []historyChange
+	if err == nil {
+		changes, err = h.runChanges(id)
+	}
+	// The store is reopened to record the revert.
+	h.Close()
+	if err != nil {
+		slog.Error("failed to read history", "err", err)
+		return 1
+	}
+	forward := make([]planEntry, 0, len(changes))
+	for _, c := range changes {
+		forward = append(forward, c.entry())
+	}
+	slices.Reverse(forward)
+	reverse := reverseEntries(forward)
+	slog.Info("loaded changes to revert", "count", len(reverse), "run", run.ID, "source", run.Source)
+
+	if err := serveMetrics(*metricsAddr); err != nil {
+		slog.Error(err.Error())
+		return 1
+	}
+	sessions, err := ws.newSessions(channelOpts)
+	if err != nil {
+		slog.Error(err.Error())
+		return 1
+	}
+	defer flushMetrics(*metricsFile, sessions)
+
+	runs, err := preparePlan(sessions, ws, reverse, prepareOptions{resolved: true, historyDB: *path, protect: protect})
+	if err != nil {
+		if !errors.Is(err, errValidation) {
+			slog.Error(err.Error())
+		}
+		return 1
+	}
+	printReversePlan(runs, "revert")
+	if *dryRun {
+		return dryRunExitCode(runs, *detailedExit)
+	}
+
+	res, err := executeRuns(runs, runOptions{verb: "revert", verify: *verify, verifyPass: *verifyPass, staleCheck: *staleCheck, history: *path, source: fmt.Sprintf("revert of run %d", run.ID), webhook: webhook, pool: pool, lock: lock})
+	if err != nil {
+		slog.Error(err.Error())
+		return 1
+	}
+	return res.exitCode()
+}
@@ -0,0 +1,202 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+)
+
+// subcommands lists the commands that take a subcommand before their flags.
+var subcommands = map[string][]string{
+	"audit":   {"inactive"},
+	"auth":    {"login"},
+	"history": {"list", "show", "revert"},
+}
+
+// describing makes parseFlags hand its flag set to flagsOf instead of
+// parsing, so that a command's flags can be listed without running it.
+var describing bool
+
+type describedFlags struct{ fs *flag.FlagSet }
+
+// flagsOf returns the flags of command c, followed by args, in name order.
+func flagsOf(c command, args ...string) (flags []*flag.Flag) {
+	defer func() {
+		describing = false
+		if r := recover(); r != nil {
+			d, ok := r.(describedFlags)
+			if !ok {
+				panic(r)
+			}
+			d.fs.VisitAll(func(f *flag.Flag) { flags = append(flags, f) })
+		}
+	}()
+	describing = true
+	c.run(args)
+	return nil
+}
+
+// commandFlags returns the flags of c and of all its subcommands, without
+// repeats.
+func commandFlags(c command) []*flag.Flag {
+	subs := subcommands[c.name]
+	if len(subs) == 0 {
+		return flagsOf(c)
+	}
+	var flags []*flag.Flag
+	for _, sub := range subs {
+		for _, f := range flagsOf(c, sub) {
+			if !slices.ContainsFunc(flags, func(g *flag.Flag) bool { return g.Name == f.Name }) {
+				flags = append(flags, f)
+			}
+		}
+	}
+	slices.SortFunc(flags, func(a, b *flag.Flag) int { return strings.Compare(a.Name, b.Name) })
+	return flags
+}
+
+func isBoolFlag(f *flag.Flag) bool {
+	b, ok := f.Value.(interface{ IsBoolFlag() bool })
+	return ok && b.IsBoolFlag()
+}
+
+// flagSummary returns the first line of a flag's usage.
+func flagSummary(f *flag.Flag) string {
+	_, usage := flag.UnquoteUsage(f)
+	line, _, _ := strings.Cut(usage, "\n")
+	return line
+}
+
+func cmdCompletion(args []string) int {
+	fs := newFlagSet("completion")
+	parseFlags(fs, args)
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return 2
+	}
+	var write func(io.Writer, []command)
+	switch fs.Arg(0) {
+	case "bash":
+		write = writeBashCompletion
+	case "zsh":
+		write = writeZshCompletion
+	case "fish":
+		write = writeFishCompletion
+	default:
+		fmt.Fprintf(os.Stderr, "unknown shell %q (want bash, zsh or fish)\n", fs.Arg(0))
+		return 2
+	}
+	write(os.Stdout, commands())
+	return 0
+}
+
+func writeBashCompletion(w io.Writer, cmds []command) {
+	var names []string
+	for _, c := range cmds {
+		names = append(names, c.name)
+	}
+	fmt.Fprintf(w, `# bash completion for slack-channel-renamer
+# Load with: source <(slack-channel-renamer completion bash)
+_slack_channel_renamer() {
+	local cur=${COMP_WORDS[COMP_CWORD]} cmd=${COMP_WORDS[1]} subs="" opts=""
+	if [[ $COMP_CWORD -eq 1 ]]; then
+		COMPREPLY=($(compgen -W %q -- "$cur"))
+		return
+	fi
+	case $cmd in
+`, strings.Join(names, " "))
+	for _, c := range cmds {
+		var opts []string
+		for _, f := range commandFlags(c) {
+			opts = append(opts, "-"+f.Name)
+		}
+		fmt.Fprintf(w, "\t%s) subs=%q opts=%q ;;\n", c.name, strings.Join(subcommands[c.name], " "), strings.Join(opts, " "))
+	}
+	fmt.Fprint(w, `	esac
+	if [[ $COMP_CWORD -eq 2 && -n $subs ]]; then
+		COMPREPLY=($(compgen -W "$subs" -- "$cur"))
+	elif [[ $cur == -* ]]; then
+		COMPREPLY=($(compgen -W "$opts" -- "$cur"))
+	else
+		COMPREPLY=($(compgen -f -- "$cur"))
+	fi
+}
+complete -o filenames -F _slack_channel_renamer slack-channel-renamer
+`)
+}
+
+// zshQuote quotes s for a single-quoted zsh word inside an _arguments
+// description, where brackets and colons are special.
+func zshQuote(s string) string {
+	s = strings.NewReplacer(`\`, `\\`, "[", `\[`, "]", `\]`, ":", `\:`).Replace(s)
+	return strings.ReplaceAll(s, "'", `'\''`)
+}
+
+func writeZshCompletion(w io.Writer, cmds []command) {
+	fmt.Fprint(w, `#compdef slack-channel-renamer
+# zsh completion for slack-channel-renamer
+# Install as _slack-channel-renamer in a directory on $fpath.
+_slack_channel_renamer() {
+	local -a commands
+	commands=(
+`)
+	for _, c := range cmds {
+		fmt.Fprintf(w, "\t\t'%s:%s'\n", c.name, strings.ReplaceAll(c.summary, "'", `'\''`))
+	}
+	fmt.Fprint(w, `	)
+	if (( CURRENT == 2 )); then
+		_describe command commands
+		return
+	fi
+	shift words
+	(( CURRENT-- ))
+	case $words[1] in
+`)
+	for _, c := range cmds {
+		fmt.Fprintf(w, "\t%s)\n\t\t_arguments", c.name)
+		if subs := subcommands[c.name]; len(subs) > 0 {
+			fmt.Fprintf(w, " '1:subcommand:(%s)'", strings.Join(subs, " "))
+		}
+		for _, f := range commandFlags(c) {
+			spec := fmt.Sprintf("-%s[%s]", f.Name, zshQuote(flagSummary(f)))
+			if !isBoolFlag(f) {
+				spec += ":value:_files"
+			}
+			fmt.Fprintf(w, " \\\n\t\t\t'%s'", spec)
+		}
+		fmt.Fprint(w, " \\\n\t\t\t'*:file:_files' ;;\n")
+	}
+	fmt.Fprint(w, `	esac
+}
+_slack_channel_renamer "$@"
+`)
+}
+
+// fishQuote quotes s as a single-quoted fish string.
+func fishQuote(s string) string {
+	return "'" + strings.NewReplacer(`\`, `\\`, "'", `\'`).Replace(s) + "'"
+}
+
+func writeFishCompletion(w io.Writer, cmds []command) {
+	fmt.Fprint(w, "# fish completion for slack-channel-renamer\n# Load with: slack-channel-renamer completion fish | source\n")
+	const prog = "complete -c slack-channel-renamer"
+	for _, c := range cmds {
+		fmt.Fprintf(w, "%s -f -n __fish_use_subcommand -a %s -d %s\n", prog, c.name, fishQuote(c.summary))
+	}
+	for _, c := range cmds {
+		cond := fishQuote("__fish_seen_subcommand_from " + c.name)
+		if subs := subcommands[c.name]; len(subs) > 0 {
+			fmt.Fprintf(w, "%s -f -n %s -a %s\n", prog, cond, fishQuote(strings.Join(subs, " ")))
+		}
+		for _, f := range commandFlags(c) {
+			arg := ""
+			if !isBoolFlag(f) {
+				arg = " -r"
+			}
+			fmt.Fprintf(w, "%s -n %s -o %s%s -d %s\n", prog, cond, f.Name, arg, fishQuote(flagSummary(f)))
+		}
+	}
+}
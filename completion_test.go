@@ -0,0 +1,21 @@
+package main
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestCommandFlags(t *testing.T) {
+	for _, c := range commands() {
+		var names []string
+		for _, f := range commandFlags(c) {
+			names = append(names, f.Name)
+		}
+		if !slices.Contains(names, "log-level") {
+			t.Errorf("%s: flags %q lack the global -log-level", c.name, names)
+		}
+		if c.name == "history" && !slices.Contains(names, "dry-run") {
+			t.Errorf("history: flags %q lack revert's -dry-run", names)
+		}
+	}
+}
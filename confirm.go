@@ -0,0 +1,218 @@
+package main
+
+import (
+	"bufio"
+	"cmp"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/kiddikn/slack-channel-renamer/slackops"
+)
+
+// errNotConfirmed is returned when the operator does not type the expected
+// workspace name.
+var errNotConfirmed = errors.New("confirmation did not match, nothing was changed")
+
+// confirmOptions makes apply ask for the workspace name to be typed before
+// a plan that is large or touches big channels, so that the full master
+// mapping is not applied by accident.
+type confirmOptions struct {
+	aboveRows    int
+	aboveMembers int
+}
+
+func (o *confirmOptions) register(fs *flag.FlagSet) {
+	fs.IntVar(&o.aboveRows, "confirm-above-rows", 0, "ask for the workspace name to be typed when a workspace's plan has more than this many entries (0 = never)")
+	fs.IntVar(&o.aboveMembers, "confirm-above-members", 0, "ask for the workspace name to be typed when the plan touches a channel with more than this many members (0 = never)")
+}
+
+// reasons lists why r needs typed confirmation, if it does.
+func (o confirmOptions) reasons(r workspaceRun) []string {
+	var reasons []string
+	if o.aboveRows > 0 && len(r.plan) > o.aboveRows {
+		reasons = append(reasons, fmt.Sprintf("the plan has %d entries, more than -confirm-above-rows %d", len(r.plan), o.aboveRows))
+	}
+	if o.aboveMembers <= 0 {
+		return reasons
+	}
+	type big struct {
+		name    string
+		members int
+	}
+	var bigs []big
+	var unknown []string
+	seen := make(map[string]bool)
+	for _, e := range r.plan {
+		names := []string{e.Asis}
+		if e.Action == actionMerge {
+			names = append(names, e.Tobe)
+		}
+		for _, name := range names {
+			ch, ok := r.channels[name]
+			if !ok || seen[ch.ID] {
+				continue
+			}
+			seen[ch.ID] = true
+			switch {
+			case ch.NumMembers == 0:
+				// Slack leaves the count out for some channels; a channel
+				// whose size is not known might be a big one.
+				unknown = append(unknown, "#"+name)
+			case ch.NumMembers > o.aboveMembers:
+				bigs = append(bigs, big{name, ch.NumMembers})
+			}
+		}
+	}
+	if len(unknown) > 0 {
+		reasons = append(reasons, fmt.Sprintf("the member count of %d channels is not known: %s",
+			len(unknown), strings.Join(truncateList(unknown, 5), ", ")))
+	}
+	if len(bigs) == 0 {
+		return reasons
+	}
+	slices.SortFunc(bigs, func(a, b big) int { return cmp.Compare(b.members, a.members) })
+	var list []string
+	for _, b := range bigs {
+		list = append(list, fmt.Sprintf("#%s (%d)", b.name, b.members))
+	}
+	return append(reasons, fmt.Sprintf("it touches %d channels with more than %d members: %s",
+		len(bigs), o.aboveMembers, strings.Join(truncateList(list, 5), ", ")))
+}
+
+// truncateList keeps the first n items of list, followed by a count of the rest.
+func truncateList(list []string, n int) []string {
+	if len(list) <= n {
+		return list
+	}
+	return append(slices.Clone(list[:n]), fmt.Sprintf("and %d more", len(list)-n))
+}
+
+// confirm asks, for every run that crosses a threshold, for its workspace
+// name to be typed on in.
+func (o confirmOptions) confirm(runs []workspaceRun, in *bufio.Reader) error {
+	for _, r := range runs {
+		reasons := o.reasons(r)
+		if len(reasons) == 0 {
+			continue
+		}
+		name, err := r.workspaceName()
+		if err != nil {
+			return fmt.Errorf("confirmation needs the workspace name: %w", err)
+		}
+		fmt.Printf("%sconfirmation required:\n", r.label())
+		for _, reason := range reasons {
+			fmt.Printf("  - %s\n", reason)
+		}
+		fmt.Printf("type the workspace name (%s) to continue: ", name)
+		line, _ := in.ReadString('\n')
+		if strings.TrimSpace(line) != name {
+			return errNotConfirmed
+		}
+	}
+	return nil
+}
+
+// workspaceName returns the -config name of the session's workspace, or the
+// Slack team name without -config.
+func (s *session) workspaceName() (string, error) {
+	if s.workspace != "" {
+		return s.workspace, nil
+	}
+	var team string
+	err := slackops.Call(cmdCtx, s.stats, "identifying the token's workspace", func(ctx context.Context) error {
+		resp, err := s.client.AuthTestContext(ctx)
+		if err == nil {
+			team = resp.Team
+		}
+		return err
+	})
+	return team, err
+}
+
+// highImpactOptions holds -max-members-without-confirm: each plan row that
+// touches a channel with more members than it is pointed out in the plan and
+// must be confirmed before apply changes it, with -confirm-channel or by
+// typing the channel's name.
+type highImpactOptions struct {
+	maxMembers int
+	approved   stringList
+}
+
+func (o *highImpactOptions) register(fs *flag.FlagSet) {
+	fs.IntVar(&o.maxMembers, "max-members-without-confirm", 0, "require confirmation of each row that touches a channel with more than this many members (0 = never)")
+	fs.Var(&o.approved, "confirm-channel", "confirm the rows touching this channel over -max-members-without-confirm without a prompt (repeatable)")
+}
+
+// bigChannel is a channel over -max-members-without-confirm.
+type bigChannel struct {
+	name    string
+	members int
+}
+
+func (b bigChannel) String() string {
+	return fmt.Sprintf("#%s (%d members)", b.name, b.members)
+}
+
+// bigChannels returns the channels over the threshold that e touches; a
+// merge touches the channel it goes into too.
+func (o highImpactOptions) bigChannels(e planEntry, channels map[string]channelInfo) []bigChannel {
+	if o.maxMembers <= 0 {
+		return nil
+	}
+	names := []string{e.Asis}
+	if e.Action == actionMerge {
+		names = append(names, e.Tobe)
+	}
+	var bigs []bigChannel
+	for _, name := range names {
+		if ch, ok := channels[name]; ok && ch.NumMembers > o.maxMembers {
+			bigs = append(bigs, bigChannel{name, ch.NumMembers})
+		}
+	}
+	return bigs
+}
+
+// mark notes on each entry of runs the channels over the threshold it
+// touches, for the plan to point out.
+func (o highImpactOptions) mark(runs []workspaceRun) {
+	for _, r := range runs {
+		for i, e := range r.plan {
+			var list []string
+			for _, b := range o.bigChannels(e, r.channels) {
+				list = append(list, b.String())
+			}
+			r.plan[i].HighImpact = strings.Join(list, ", ")
+		}
+	}
+}
+
+// confirm asks for the name of every channel over the threshold that
+// -confirm-channel did not confirm to be typed on in, once per channel, and
+// returns errNotConfirmed at the first that does not match.
+func (o highImpactOptions) confirm(runs []workspaceRun, in *bufio.Reader) error {
+	confirmed := make(map[string]bool)
+	for _, name := range o.approved {
+		confirmed[strings.TrimPrefix(name, "#")] = true
+	}
+	for _, r := range runs {
+		for _, e := range r.plan {
+			for _, b := range o.bigChannels(e, r.channels) {
+				if confirmed[b.name] {
+					continue
+				}
+				fmt.Printf("%s%s touches %s, more than -max-members-without-confirm %d\n", r.label(), e, b, o.maxMembers)
+				fmt.Printf("type the channel name (%s) to continue: ", b.name)
+				line, _ := in.ReadString('\n')
+				if strings.TrimPrefix(strings.TrimSpace(line), "#") != b.name {
+					return fmt.Errorf("%w (pass -confirm-channel %s to confirm it without a prompt)", errNotConfirmed, b.name)
+				}
+				confirmed[b.name] = true
+			}
+		}
+	}
+	return nil
+}
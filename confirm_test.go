@@ -0,0 +1,44 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestHighImpactConfirm(t *testing.T) {
+	channels := map[string]channelInfo{
+		"general": {ID: "C1", NumMembers: 10000},
+		"eng":     {ID: "C2", NumMembers: 400},
+		"small":   {ID: "C3", NumMembers: 3},
+	}
+	runs := func() []workspaceRun {
+		return []workspaceRun{{session: &session{}, channels: channels, plan: []planEntry{
+			{Action: actionRename, Asis: "general", Tobe: "all-hands"},
+			{Action: actionMerge, Asis: "small", Tobe: "eng"},
+			{Action: actionArchive, Asis: "small"},
+		}}}
+	}
+	o := highImpactOptions{maxMembers: 100}
+
+	r := runs()
+	o.mark(r)
+	want := []string{"#general (10000 members)", "#eng (400 members)", ""}
+	for i, e := range r[0].plan {
+		if e.HighImpact != want[i] {
+			t.Errorf("entry %s: highImpact = %q, want %q", e, e.HighImpact, want[i])
+		}
+	}
+
+	if err := o.confirm(runs(), bufio.NewReader(strings.NewReader("general\n#eng\n"))); err != nil {
+		t.Errorf("typed names: %v", err)
+	}
+	if err := o.confirm(runs(), bufio.NewReader(strings.NewReader("general\n"))); !errors.Is(err, errNotConfirmed) {
+		t.Errorf("missing answer: err = %v, want errNotConfirmed", err)
+	}
+	o.approved = stringList{"general", "eng"}
+	if err := o.confirm(runs(), bufio.NewReader(strings.NewReader(""))); err != nil {
+		t.Errorf("-confirm-channel: %v", err)
+	}
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log/slog"
+
+	"github.com/kiddikn/slack-channel-renamer/plan"
+)
+
+// conflictPolicy is what validation does with a rename whose target name is
+// held by another channel that the plan does not move away.
+type conflictPolicy string
+
+const (
+	conflictFail          conflictPolicy = "fail"
+	conflictSkip          conflictPolicy = "skip"
+	conflictSuffix        conflictPolicy = "suffix"
+	conflictArchiveTarget conflictPolicy = "archive-target"
+)
+
+func (p *conflictPolicy) register(fs *flag.FlagSet) {
+	*p = conflictFail
+	fs.Func("on-conflict", "what to do when a rename's target name is taken: fail, skip, suffix (append -2, -3, ...) or archive-target (default fail)", func(s string) error {
+		switch v := conflictPolicy(s); v {
+		case conflictFail, conflictSkip, conflictSuffix, conflictArchiveTarget:
+			*p = v
+			return nil
+		}
+		return fmt.Errorf("unknown conflict policy %q (want fail, skip, suffix or archive-target)", s)
+	})
+}
+
+// resolveConflicts rewrites the renames of plan whose target name is taken
+// according to p: skip drops them, suffix renames to the first free name-N
+// instead, and archive-target adds an archive of the target channel before
+// the rename. Skipping a rename can leave the target of another taken, so
+// skip repeats until nothing more is skipped. It returns the rewritten plan
+// and the skipped entries. With fail the plan is returned as is, for
+// validation to report.
+func (p conflictPolicy) resolveConflicts(plan []planEntry, channels map[string]channelInfo) ([]planEntry, []string) {
+	if p == "" || p == conflictFail {
+		return plan, nil
+	}
+	if p == conflictSkip {
+		var skipped []string
+		for {
+			out, more := p.resolveOnce(plan, channels)
+			if len(more) == 0 {
+				return out, skipped
+			}
+			plan, skipped = out, append(skipped, more...)
+		}
+	}
+	return p.resolveOnce(plan, channels)
+}
+
+func (p conflictPolicy) resolveOnce(entries []planEntry, channels map[string]channelInfo) ([]planEntry, []string) {
+	freed := make(map[string]bool)
+	taken := make(map[string]bool)
+	for _, e := range entries {
+		if (e.Action == actionRename && e.Asis != e.Tobe) || e.Action == actionArchive {
+			freed[e.Asis] = true
+		}
+		if e.Action == actionRename {
+			taken[e.Tobe] = true
+		}
+	}
+	conflicts := func(e planEntry) bool {
+		existing, exists := channels[e.Tobe]
+		return e.Action == actionRename && e.Asis != e.Tobe && exists && !existing.IsArchived && !freed[e.Tobe]
+	}
+
+	out := make([]planEntry, 0, len(entries))
+	var skipped []string
+	for _, e := range entries {
+		if !conflicts(e) {
+			out = append(out, e)
+			continue
+		}
+		switch p {
+		case conflictSkip:
+			skipped = append(skipped, e.At()+fmt.Sprintf("target channel %q already exists, skipping", e.Tobe))
+			continue
+		case conflictSuffix:
+			tobe := plan.SuffixedName(e.Tobe, func(name string) bool {
+				_, exists := channels[name]
+				return !exists && !taken[name]
+			})
+			slog.Info(e.At()+"target channel already exists, using a suffixed name", "asis", e.Asis, "tobe", e.Tobe, "instead", tobe)
+			taken[tobe] = true
+			e.Tobe = tobe
+		case conflictArchiveTarget:
+			slog.Info(e.At()+"target channel already exists, archiving it first", "tobe", e.Tobe)
+			out = append(out, planEntry{Action: actionArchive, Asis: e.Tobe, Owner: e.Owner, Source: e.Source, Workspace: e.Workspace})
+			freed[e.Tobe] = true
+		}
+		out = append(out, e)
+	}
+	return out, skipped
+}
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestResolveConflicts(t *testing.T) {
+	channels := map[string]channelInfo{
+		"a":       {ID: "C1"},
+		"b":       {ID: "C2"},
+		"taken":   {ID: "C3"},
+		"taken-2": {ID: "C4"},
+		"old":     {ID: "C5", IsArchived: true},
+	}
+	rename := func(asis, tobe string) planEntry { return planEntry{Action: actionRename, Asis: asis, Tobe: tobe} }
+	for _, tc := range []struct {
+		policy  conflictPolicy
+		plan    []planEntry
+		want    []string // the resulting entries, as printed
+		skipped int
+	}{
+		{conflictFail, []planEntry{rename("a", "taken")}, []string{"a -> taken"}, 0},
+		{conflictSkip, []planEntry{rename("a", "taken"), rename("b", "c")}, []string{"b -> c"}, 1},
+		// Skipping b -> taken keeps b, so a -> b is taken too.
+		{conflictSkip, []planEntry{rename("a", "b"), rename("b", "taken")}, nil, 2},
+		{conflictSuffix, []planEntry{rename("a", "taken"), rename("b", "taken-3")}, []string{"a -> taken-4", "b -> taken-3"}, 0},
+		{conflictArchiveTarget, []planEntry{rename("a", "taken")}, []string{"archive taken", "a -> taken"}, 0},
+		// Archived channels and names the plan frees are not conflicts.
+		{conflictSkip, []planEntry{rename("a", "old"), rename("b", "a")}, []string{"a -> old", "b -> a"}, 0},
+	} {
+		out, skipped := tc.policy.resolveConflicts(slices.Clone(tc.plan), channels)
+		var got []string
+		for _, e := range out {
+			got = append(got, e.String())
+		}
+		if !slices.Equal(got, tc.want) || len(skipped) != tc.skipped {
+			t.Errorf("%s %v: got %q with %d skipped, want %q with %d", tc.policy, tc.plan, got, len(skipped), tc.want, tc.skipped)
+		}
+	}
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/kiddikn/slack-channel-renamer/plan"
+)
+
+// loadCreateList reads the channel list of 'create': a CSV with a name
+// column and optional topic, purpose, private, members, owner and workspace
+// columns. members lists user IDs or email addresses separated by spaces or
+// semicolons.
+func loadCreateList(path string, data []byte, opts plan.Options) ([]planEntry, error) {
+	data, err := plan.DecodeText(data, opts.Encoding)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	r := csv.NewReader(bytes.NewReader(data))
+	r.Comma = opts.Delimiter
+	if r.Comma == 0 {
+		r.Comma = plan.DetectDelimiter(data)
+	}
+	r.Comment = '#'
+	r.FieldsPerRecord = -1
+	r.TrimLeadingSpace = true
+
+	hdr, err := r.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("%s is empty", path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("parse %s: %w", path, err)
+	}
+	cols := make(map[string]int)
+	for i, col := range hdr {
+		cols[strings.ToLower(strings.TrimSpace(col))] = i
+	}
+	if _, ok := cols["name"]; !ok {
+		return nil, fmt.Errorf("%s: header must have a 'name' column, got: %v", path, hdr)
+	}
+	column := func(row []string, name string) string {
+		if i, ok := cols[name]; ok && i < len(row) {
+			return strings.TrimSpace(row[i])
+		}
+		return ""
+	}
+
+	var entries []planEntry
+	for {
+		row, err := r.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("parse %s: %w", path, err)
+		}
+		line, _ := r.FieldPos(0)
+		entry := planEntry{
+			Action:    actionCreate,
+			Asis:      strings.TrimPrefix(column(row, "name"), "#"),
+			Topic:     column(row, "topic"),
+			Purpose:   column(row, "purpose"),
+			Members:   column(row, "members"),
+			Owner:     column(row, "owner"),
+			Workspace: column(row, "workspace"),
+			Source:    fmt.Sprintf("%s:%d", path, line),
+		}
+		if entry.Private, err = parsePrivacy(column(row, "private")); err != nil {
+			return nil, fmt.Errorf("%s: %w", entry.Source, err)
+		}
+		if err := entry.Check(); err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+	if len(entries) == 0 {
+		return nil, fmt.Errorf("%s has no data rows", path)
+	}
+	return entries, nil
+}
+
+// parsePrivacy reads the private column: empty, "public", "no" or a false
+// boolean for a public channel, "private", "yes" or a true boolean for a
+// private one.
+func parsePrivacy(v string) (bool, error) {
+	switch strings.ToLower(v) {
+	case "", "public", "no":
+		return false, nil
+	case "private", "yes":
+		return true, nil
+	}
+	private, err := strconv.ParseBool(v)
+	if err != nil {
+		return false, fmt.Errorf("private must be public, private, true or false, got %q", v)
+	}
+	return private, nil
+}
+
+func cmdCreate(args []string) int {
+	return applyPlan("create", actionCreate, args)
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/kiddikn/slack-channel-renamer/plan"
+)
+
+func TestCreate(t *testing.T) {
+	list := "name,topic,private,members\nalpha,,,\nnew-team,Team chat,private,U0123456789;ana@example.com\n"
+	entries, err := loadCreateList("create.csv", []byte(list), plan.Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 || !entries[1].Private || entries[1].String() != "create new-team (private)" {
+		t.Fatalf("plan = %+v", entries)
+	}
+	if _, err := loadCreateList("bad.csv", []byte("name,members\nx,ana\n"), plan.Options{}); err == nil {
+		t.Error("a member that is neither an ID nor an address loaded without error")
+	}
+
+	path := filepath.Join(t.TempDir(), "snapshot.json")
+	if err := os.WriteFile(path, []byte(`[{"name":"alpha","id":"C1"}]`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	sessions, err := (workspaceOptions{simulate: path}).newSessions(channelOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	runs, errs, skipped, err := validateRuns(sessions, workspaceOptions{}, entries, prepareOptions{})
+	if err != nil || len(errs) > 0 || len(skipped) != 1 {
+		t.Fatalf("validateRuns: %v %q, skipped %q", err, errs, skipped)
+	}
+	res, err := executeRuns(runs, runOptions{verb: "create", pool: poolOptions{concurrency: 1, perMinute: 60}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.failures != 0 || len(res.changed) != 1 || res.changed[0].ChannelID == "" {
+		t.Fatalf("failures = %d, changed = %+v", res.failures, res.changed)
+	}
+
+	w := sessions[0].client.(*simWorkspace)
+	ch, err := w.channel(res.changed[0].ChannelID)
+	if err != nil || ch.Name != "new-team" || !ch.IsPrivate || ch.Topic.Value != "Team chat" {
+		t.Fatalf("created channel = %+v, %v", ch, err)
+	}
+	if members := w.members[ch.ID]; !slices.Contains(members, "U0123456789") || len(members) != 3 {
+		t.Errorf("members = %q, want the creator and the two invited users", members)
+	}
+}
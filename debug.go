@@ -0,0 +1,132 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/slack-go/slack"
+)
+
+// debugAPI is set by -debug: every Slack API call is logged at debug level.
+var debugAPI bool
+
+var (
+	// apiURL is the base URL of the Slack Web API, changed with -api-url or
+	// SLACK_API_URL to point the tool at a mock server, a staging gateway or
+	// an egress proxy that fronts slack.com.
+	apiURL = slack.APIURL
+	// slackTransport carries every Slack API request. Tests replace it to
+	// talk to a fake Slack without touching http.DefaultTransport.
+	slackTransport = netTransport
+)
+
+// setAPIURL sets apiURL, which must be an absolute http(s) URL. A trailing
+// slash is added, as slack-go appends method names to it.
+func setAPIURL(s string) error {
+	u, err := url.Parse(s)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("API URL %q: want an http(s) URL such as https://slack.com/api/", s)
+	}
+	if !strings.HasSuffix(s, "/") {
+		s += "/"
+	}
+	apiURL = s
+	return nil
+}
+
+// redacted replaces secrets in logged requests.
+const redacted = "REDACTED"
+
+// slackOptions returns the client options for a session. Every request is
+// timed by metricsTransport. With -debug, slack-go's own response dumps go to
+// the debug log and every request also passes through debugTransport.
+func slackOptions() []slack.Option {
+	if !debugAPI {
+		return []slack.Option{slack.OptionAPIURL(apiURL), slack.OptionHTTPClient(slackHTTPClient())}
+	}
+	return []slack.Option{
+		slack.OptionAPIURL(apiURL),
+		slack.OptionDebug(true),
+		slack.OptionLog(slog.NewLogLogger(slog.Default().Handler(), slog.LevelDebug)),
+		slack.OptionHTTPClient(slackHTTPClient()),
+	}
+}
+
+// slackHTTPClient returns the HTTP client of Slack API calls, for the few
+// made without slack-go.
+func slackHTTPClient() *http.Client {
+	if !debugAPI {
+		return &http.Client{Transport: metricsTransport{base: slackTransport}}
+	}
+	return &http.Client{Transport: metricsTransport{base: debugTransport{base: slackTransport}}}
+}
+
+// debugTransport logs each Slack API request with its parameters, the
+// response status, the call's latency and any rate-limit headers. Tokens are
+// redacted, and the Authorization header is never logged.
+type debugTransport struct {
+	base http.RoundTripper
+}
+
+func (t debugTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	params, err := requestParams(req)
+	if err != nil {
+		return nil, err
+	}
+	attrs := []any{"method", req.Method, "endpoint", strings.TrimPrefix(req.URL.Path, "/api/"), "params", params}
+
+	start := time.Now()
+	resp, err := t.base.RoundTrip(req)
+	attrs = append(attrs, "latency", time.Since(start).Round(time.Millisecond))
+	if err != nil {
+		slog.Debug("Slack API call failed", append(attrs, "err", err)...)
+		return nil, err
+	}
+	attrs = append(attrs, "status", resp.StatusCode)
+	for name, values := range resp.Header {
+		if name == "Retry-After" || strings.HasPrefix(strings.ToLower(name), "x-ratelimit") {
+			attrs = append(attrs, strings.ToLower(name), strings.Join(values, ","))
+		}
+	}
+	slog.Debug("Slack API call", attrs...)
+	return resp, nil
+}
+
+// requestParams returns the parameters of req, from its query string or its
+// form or JSON body, with tokens redacted. The body is read and replaced.
+func requestParams(req *http.Request) (string, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return redactValues(req.URL.Query()).Encode(), nil
+	}
+	body, err := io.ReadAll(req.Body)
+	req.Body.Close()
+	if err != nil {
+		return "", err
+	}
+	req.Body = io.NopCloser(bytes.NewReader(body))
+	if strings.HasPrefix(req.Header.Get("Content-Type"), "application/x-www-form-urlencoded") {
+		values, err := url.ParseQuery(string(body))
+		if err == nil {
+			return redactValues(values).Encode(), nil
+		}
+	}
+	if strings.HasPrefix(req.Header.Get("Content-Type"), "application/json") {
+		return string(body), nil
+	}
+	return "<" + req.Header.Get("Content-Type") + " body>", nil
+}
+
+func redactValues(values url.Values) url.Values {
+	for key := range values {
+		if key == "token" {
+			values[key] = []string{redacted}
+		}
+	}
+	return values
+}
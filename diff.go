@@ -0,0 +1,284 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// diffPlans compares two rename plans keyed by asis and writes the added, removed
+// and changed entries to w in sorted order. It reports whether the plans differ.
+func diffPlans(w io.Writer, oldPlan, newPlan []planEntry) bool {
+	oldByAsis := make(map[string]string, len(oldPlan))
+	for _, e := range oldPlan {
+		oldByAsis[diffKey(e)] = diffTarget(e)
+	}
+	newByAsis := make(map[string]string, len(newPlan))
+	for _, e := range newPlan {
+		newByAsis[diffKey(e)] = diffTarget(e)
+	}
+
+	var added, removed, changed []string
+	for _, asis := range slices.Sorted(maps.Keys(newByAsis)) {
+		oldTobe, ok := oldByAsis[asis]
+		switch {
+		case !ok:
+			added = append(added, fmt.Sprintf("+ %s -> %s", asis, newByAsis[asis]))
+		case oldTobe != newByAsis[asis]:
+			changed = append(changed, fmt.Sprintf("~ %s: %s -> %s", asis, oldTobe, newByAsis[asis]))
+		}
+	}
+	for _, asis := range slices.Sorted(maps.Keys(oldByAsis)) {
+		if _, ok := newByAsis[asis]; !ok {
+			removed = append(removed, fmt.Sprintf("- %s -> %s", asis, oldByAsis[asis]))
+		}
+	}
+
+	return writeSections(w, []diffSection{{"added", added}, {"removed", removed}, {"changed", changed}})
+}
+
+// diffSection is a titled list of differences.
+type diffSection struct {
+	title string
+	lines []string
+}
+
+// writeSections writes each non-empty section, or "no differences" when all
+// are empty. It reports whether any line was written.
+func writeSections(w io.Writer, sections []diffSection) bool {
+	n := 0
+	for _, s := range sections {
+		if len(s.lines) == 0 {
+			continue
+		}
+		fmt.Fprintf(w, "%s:\n", s.title)
+		for _, l := range s.lines {
+			fmt.Fprintf(w, "  %s\n", l)
+		}
+		n += len(s.lines)
+	}
+	if n == 0 {
+		fmt.Fprintln(w, "no differences")
+		return false
+	}
+	return true
+}
+
+// diffKey identifies the channel an entry acts on. The same name may exist in
+// several workspaces, so the workspace is part of the key when set.
+func diffKey(e planEntry) string {
+	if e.Workspace != "" {
+		return e.Workspace + "/" + e.Asis
+	}
+	return e.Asis
+}
+
+// diffTarget is what an entry does to its channel: the new name for renames,
+// or the action in parentheses otherwise.
+func diffTarget(e planEntry) string {
+	if e.Action == actionRename {
+		return e.Tobe
+	}
+	return "(" + e.Action + ")"
+}
+
+// runDiff loads both mapping files and prints their differences. It never contacts Slack.
+// The exit code is 1 when the files differ and 2 when either file cannot be loaded.
+func runDiff(in planInput, oldPath, newPath string) int {
+	in.paths = stringList{oldPath}
+	oldPlan, _, err := in.load()
+	if err != nil {
+		slog.Error("failed to load plan", "err", err)
+		return 2
+	}
+	in.paths = stringList{newPath}
+	newPlan, _, err := in.load()
+	if err != nil {
+		slog.Error("failed to load plan", "err", err)
+		return 2
+	}
+	if diffPlans(os.Stdout, oldPlan, newPlan) {
+		return 1
+	}
+	return 0
+}
+
+// readExport reads a channel list written by 'export', as CSV or, for a
+// .json file or one starting with '[', JSON.
+func readExport(path string) ([]exportRow, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read export: %w", err)
+	}
+	if strings.EqualFold(filepath.Ext(path), ".json") || bytes.HasPrefix(bytes.TrimSpace(b), []byte("[")) {
+		var rows []exportRow
+		if err := json.Unmarshal(b, &rows); err != nil {
+			return nil, fmt.Errorf("parse %s: %w", path, err)
+		}
+		return rows, nil
+	}
+
+	r := csv.NewReader(bytes.NewReader(b))
+	r.FieldsPerRecord = -1
+	hdr, err := r.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("%s is empty", path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("parse %s: %w", path, err)
+	}
+	cols := make(map[string]int)
+	for i, col := range hdr {
+		cols[strings.ToLower(strings.TrimSpace(col))] = i
+	}
+	if _, ok := cols["name"]; !ok {
+		return nil, fmt.Errorf("%s: header must have name and id columns, got: %v", path, hdr)
+	}
+	if _, ok := cols["id"]; !ok {
+		return nil, fmt.Errorf("%s: header must have name and id columns, got: %v", path, hdr)
+	}
+	column := func(row []string, name string) string {
+		if i, ok := cols[name]; ok && i < len(row) {
+			return strings.TrimSpace(row[i])
+		}
+		return ""
+	}
+	var rows []exportRow
+	for {
+		row, err := r.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("parse %s: %w", path, err)
+		}
+		line, _ := r.FieldPos(0)
+		e := exportRow{Name: column(row, "name"), ID: column(row, "id"), Workspace: column(row, "workspace")}
+		for name, v := range map[string]*bool{"archived": &e.Archived, "private": &e.Private} {
+			if s := column(row, name); s != "" {
+				if *v, err = strconv.ParseBool(s); err != nil {
+					return nil, fmt.Errorf("%s:%d: %s must be true or false, got %q", path, line, name, s)
+				}
+			}
+		}
+		if s := column(row, "created"); s != "" {
+			if e.Created, err = time.Parse(time.RFC3339, s); err != nil {
+				return nil, fmt.Errorf("%s:%d: created must be an RFC 3339 time, got %q", path, line, s)
+			}
+		}
+		if e.ID == "" {
+			return nil, fmt.Errorf("%s:%d: no channel ID", path, line)
+		}
+		rows = append(rows, e)
+	}
+	return rows, nil
+}
+
+// liveRows returns the rows an export of channels would have, including the
+// archived channels whose name an active one has taken.
+func liveRows(workspace string, channels map[string]channelInfo) []exportRow {
+	rows := exportRows(workspace, channels)
+	for name, ch := range channels {
+		if ch.ArchivedTwin != "" {
+			rows = append(rows, exportRow{Name: name, ID: ch.ArchivedTwin, Archived: true, Workspace: workspace})
+		}
+	}
+	return rows
+}
+
+// diffExport compares an earlier export with the live channels, matching
+// channels by workspace and ID, and writes the channels renamed, created,
+// archived, unarchived and deleted since to w. It reports whether anything
+// changed.
+func diffExport(w io.Writer, old, live []exportRow) bool {
+	key := func(r exportRow) string { return r.Workspace + "/" + r.ID }
+	name := func(r exportRow) string {
+		if r.Workspace != "" {
+			return r.Workspace + "/" + r.Name
+		}
+		return r.Name
+	}
+	before := make(map[string]exportRow, len(old))
+	for _, r := range old {
+		before[key(r)] = r
+	}
+	after := make(map[string]exportRow, len(live))
+	for _, r := range live {
+		after[key(r)] = r
+	}
+
+	var renamed, created, archived, unarchived, deleted []string
+	for _, k := range slices.Sorted(maps.Keys(after)) {
+		r := after[k]
+		was, ok := before[k]
+		if !ok {
+			created = append(created, fmt.Sprintf("+ %s (%s)", name(r), r.ID))
+			continue
+		}
+		if was.Name != r.Name {
+			renamed = append(renamed, fmt.Sprintf("~ %s -> %s (%s)", name(was), r.Name, r.ID))
+		}
+		switch {
+		case r.Archived && !was.Archived:
+			archived = append(archived, fmt.Sprintf("- %s (%s)", name(r), r.ID))
+		case !r.Archived && was.Archived:
+			unarchived = append(unarchived, fmt.Sprintf("+ %s (%s)", name(r), r.ID))
+		}
+	}
+	for _, k := range slices.Sorted(maps.Keys(before)) {
+		if _, ok := after[k]; !ok {
+			deleted = append(deleted, fmt.Sprintf("- %s (%s)", name(before[k]), before[k].ID))
+		}
+	}
+	return writeSections(w, []diffSection{
+		{"renamed", renamed}, {"created", created}, {"archived", archived},
+		{"unarchived", unarchived}, {"deleted", deleted},
+	})
+}
+
+// runExportDiff compares the export at path with the live channels of
+// sessions. Rows of workspaces not among sessions, and private channels when
+// they are not listed, are left out of the comparison. The exit code is 1
+// when anything changed and 2 when the export cannot be read or the channels
+// cannot be listed.
+func runExportDiff(path string, sessions []*session, withWorkspace, includePrivate bool) int {
+	old, err := readExport(path)
+	if err != nil {
+		slog.Error(err.Error())
+		return 2
+	}
+	workspaces := make(map[string]bool)
+	var live []exportRow
+	for _, s := range sessions {
+		channels, err := s.listChannels(cmdCtx)
+		if err != nil {
+			slog.Error(err.Error())
+			return 2
+		}
+		workspaces[s.workspace] = true
+		live = append(live, liveRows(s.workspace, channels)...)
+	}
+	if !withWorkspace {
+		for i := range old {
+			old[i].Workspace = ""
+		}
+	}
+	old = slices.DeleteFunc(old, func(r exportRow) bool {
+		return !workspaces[r.Workspace] || r.Private && !includePrivate
+	})
+	if diffExport(os.Stdout, old, live) {
+		return 1
+	}
+	return 0
+}
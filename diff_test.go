@@ -0,0 +1,51 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDiffExport(t *testing.T) {
+	old := []exportRow{
+		{Name: "general", ID: "C1"},
+		{Name: "proj-x", ID: "C2"},
+		{Name: "old-news", ID: "C3"},
+		{Name: "gone", ID: "C4"},
+	}
+	var buf bytes.Buffer
+	if err := writeExport(&buf, "csv", old, false, false); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "export.csv")
+	if err := os.WriteFile(path, buf.Bytes(), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	read, err := readExport(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	live := liveRows("", map[string]channelInfo{
+		"general":   {ID: "C1"},
+		"project-x": {ID: "C2"},
+		"old-news":  {ID: "C5", ArchivedTwin: "C3"},
+	})
+	buf.Reset()
+	if !diffExport(&buf, read, live) {
+		t.Fatal("diffExport found no changes")
+	}
+	want := `renamed:
+  ~ proj-x -> project-x (C2)
+created:
+  + old-news (C5)
+archived:
+  - old-news (C3)
+deleted:
+  - gone (C4)
+`
+	if buf.String() != want {
+		t.Errorf("diff =\n%s\nwant\n%s", buf.String(), want)
+	}
+}
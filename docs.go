@@ -0,0 +1,97 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+)
+
+func cmdDocs(args []string) int {
+	fs := newFlagSet("docs")
+	parseFlags(fs, args)
+	if fs.NArg() != 1 || fs.Arg(0) != "man" {
+		fs.Usage()
+		return 2
+	}
+	writeManPage(os.Stdout, commands(), time.Now())
+	return 0
+}
+
+// roff escapes s for a man page line: backslashes and hyphens, and a leading
+// dot or quote that would start a request.
+func roff(s string) string {
+	s = strings.NewReplacer(`\`, `\e`, "-", `\-`).Replace(s)
+	if strings.HasPrefix(s, ".") || strings.HasPrefix(s, "'") {
+		s = `\&` + s
+	}
+	return s
+}
+
+// writeManPage writes a slack-channel-renamer(1) page covering every command
+// and its flags.
+func writeManPage(w io.Writer, cmds []command, now time.Time) {
+	fmt.Fprintf(w, ".TH SLACK-CHANNEL-RENAMER 1 %q %q\n", now.Format("2006-01-02"), "slack-channel-renamer "+readBuildInfo().Version)
+	fmt.Fprint(w, `.SH NAME
+slack\-channel\-renamer \- rename, archive and merge Slack channels in bulk from a plan
+.SH SYNOPSIS
+.B slack\-channel\-renamer
+.I command
+.RI [ flags ]
+.SH DESCRIPTION
+Reads a plan of channel changes from CSV, Excel, Google Sheets or a resolved plan file,
+validates it against the workspace and applies it through the Slack Web API.
+Run a command with \fB\-h\fR for its flags.
+.SH COMMANDS
+`)
+	for _, c := range cmds {
+		fmt.Fprintf(w, ".SS %s\n%s.\n", roff(c.usage), roff(c.summary))
+		for _, f := range commandFlags(c) {
+			name, usage := flagUsage(f)
+			fmt.Fprintf(w, ".TP\n\\fB\\-%s\\fR", roff(f.Name))
+			if name != "" {
+				fmt.Fprintf(w, " \\fI%s\\fR", roff(name))
+			}
+			fmt.Fprintf(w, "\n%s\n", roff(usage))
+		}
+	}
+	fmt.Fprint(w, `.SH ENVIRONMENT
+.TP
+.B SLACK_USER_TOKEN
+The Slack token used without \fB\-config\fR, \fB\-token\-file\fR or \fB\-token\-ref\fR.
+.TP
+.B SLACK_TOKEN_REF
+A secret reference (file://, keyring://, aws\-sm://, gcp\-sm:// or vault://) holding the token.
+.TP
+.B SLACK_API_URL
+Base URL of the Slack Web API, as \fB\-api\-url\fR.
+.TP
+.B RENAMER_SETTINGS
+The settings file, as \fB\-settings\fR.
+.TP
+.B RENAMER_\fIFLAG\fR
+Sets the flag of that name, in upper case with underscores, when it is not on the command line.
+.SH FILES
+.TP
+.I renamer.yaml
+Flag values, read from the working directory or the user config directory.
+.TP
+.I rename\-history.db
+The rename history written by \fBapply\fR and \fBrollback\fR.
+.SH EXIT STATUS
+0 on success, 1 when a command or an entry failed, 2 for bad flags (and, with
+\fB\-detailed\-exitcode\fR, for plans with changes to make), 130 when interrupted.
+`)
+}
+
+// flagUsage returns a flag's value name and usage, with its default, as
+// flag.PrintDefaults shows them.
+func flagUsage(f *flag.Flag) (name, usage string) {
+	name, usage = flag.UnquoteUsage(f)
+	if f.DefValue != "" && f.DefValue != "false" && f.DefValue != "0" && f.DefValue != "[]" {
+		usage += fmt.Sprintf(" (default %q)", f.DefValue)
+	}
+	return name, usage
+}
@@ -0,0 +1,310 @@
+package main
+
+import (
+	"cmp"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"log/slog"
+	"maps"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// duplicateCluster is a group of channels 'audit duplicates' found to look
+// alike. Keep is the channel the others would be merged into: the one with
+// the most members, and the oldest of those.
+type duplicateCluster struct {
+	Workspace string             `json:"workspace,omitempty"`
+	Keep      string             `json:"keep"`
+	Channels  []duplicateChannel `json:"channels"`
+}
+
+type duplicateChannel struct {
+	Name    string    `json:"name"`
+	ID      string    `json:"id"`
+	Members int       `json:"members"`
+	Created time.Time `json:"created,omitzero"`
+	Topic   string    `json:"topic,omitempty"`
+	// Reason says why the channel joined the cluster, naming the channel it
+	// resembles; it is empty for the first channel.
+	Reason string `json:"reason,omitempty"`
+}
+
+// numericSuffixRe matches the -2, _3 ... Slack users add to a taken name.
+var numericSuffixRe = regexp.MustCompile(`[-_][0-9]+$`)
+
+// duplicateKey reduces a channel name to what duplicates share: its plain
+// Unicode form without separators or a numeric suffix, so that proj-alpha,
+// proj_alpha, projalpha and proj-alpha-2 all have the key projalpha.
+func duplicateKey(name string) string {
+	key := numericSuffixRe.ReplaceAllString(norm.NFKC.String(strings.ToLower(name)), "")
+	return strings.NewReplacer("-", "", "_", "").Replace(key)
+}
+
+// editDistance is the Levenshtein distance between a and b, in runes.
+func editDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	cur := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		cur[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(rb)]
+}
+
+// topicWords returns the set of words of a topic or purpose, lowercased.
+func topicWords(s string) map[string]bool {
+	words := make(map[string]bool)
+	for _, w := range strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !('a' <= r && r <= 'z' || '0' <= r && r <= '9' || r > 0x7f)
+	}) {
+		words[w] = true
+	}
+	return words
+}
+
+// similarTopics reports whether two topics share most of their words: at
+// least three, and four in five of all the words either has.
+func similarTopics(a, b map[string]bool) bool {
+	shared := 0
+	for w := range a {
+		if b[w] {
+			shared++
+		}
+	}
+	all := len(a) + len(b) - shared
+	return shared >= 3 && shared*5 >= all*4
+}
+
+// duplicateOptions tunes 'audit duplicates'.
+type duplicateOptions struct {
+	// maxDistance is the largest edit distance between the keys of two
+	// channels for them to count as duplicates; names whose keys are shorter
+	// than four times it must match exactly.
+	maxDistance     int
+	includeArchived bool
+}
+
+// findDuplicates clusters the channels whose names are alike, misspelled or
+// suffixed versions of each other, or whose topics are nearly the same. The
+// workspace's general channel is never part of a cluster.
+func findDuplicates(channels map[string]channelInfo, opts duplicateOptions) []duplicateCluster {
+	var names []string
+	for _, name := range slices.Sorted(maps.Keys(channels)) {
+		if ch := channels[name]; !ch.IsGeneral && (!ch.IsArchived || opts.includeArchived) {
+			names = append(names, name)
+		}
+	}
+	keys := make([]string, len(names))
+	topics := make([]map[string]bool, len(names))
+	for i, name := range names {
+		keys[i] = duplicateKey(name)
+		topics[i] = topicWords(channels[name].Topic + " " + channels[name].Purpose)
+	}
+
+	// Each channel joins the cluster of the first earlier channel it
+	// resembles.
+	parent := make([]int, len(names))
+	reasons := make([]string, len(names))
+	var find func(i int) int
+	find = func(i int) int {
+		if parent[i] != i {
+			parent[i] = find(parent[i])
+		}
+		return parent[i]
+	}
+	for i := range names {
+		parent[i] = i
+		for j := range i {
+			reason := ""
+			switch {
+			case keys[i] == keys[j]:
+				reason = fmt.Sprintf("same name as %s apart from separators or a number", names[j])
+			case len(keys[i]) >= 4*opts.maxDistance && len(keys[j]) >= 4*opts.maxDistance &&
+				abs(len(keys[i])-len(keys[j])) <= opts.maxDistance && editDistance(keys[i], keys[j]) <= opts.maxDistance:
+				reason = fmt.Sprintf("name %d edits from %s", editDistance(keys[i], keys[j]), names[j])
+			case similarTopics(topics[i], topics[j]):
+				reason = fmt.Sprintf("topic like that of %s", names[j])
+			}
+			if reason == "" || find(i) == find(j) {
+				continue
+			}
+			if reasons[i] == "" {
+				reasons[i] = reason
+			}
+			a, b := find(i), find(j)
+			parent[max(a, b)] = min(a, b)
+		}
+	}
+
+	byRoot := make(map[int][]int)
+	for i := range names {
+		byRoot[find(i)] = append(byRoot[find(i)], i)
+	}
+	var clusters []duplicateCluster
+	for _, root := range slices.Sorted(maps.Keys(byRoot)) {
+		members := byRoot[root]
+		if len(members) < 2 {
+			continue
+		}
+		var c duplicateCluster
+		for _, i := range members {
+			ch := channels[names[i]]
+			c.Channels = append(c.Channels, duplicateChannel{Name: names[i], ID: ch.ID, Members: ch.NumMembers, Created: ch.Created, Topic: ch.Topic, Reason: reasons[i]})
+		}
+		keep := slices.MinFunc(c.Channels, func(a, b duplicateChannel) int {
+			if n := cmp.Compare(b.Members, a.Members); n != 0 {
+				return n
+			}
+			return a.Created.Compare(b.Created)
+		})
+		c.Keep = keep.Name
+		clusters = append(clusters, c)
+	}
+	return clusters
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+func printDuplicates(w io.Writer, clusters []duplicateCluster) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "CLUSTER\tWORKSPACE\tCHANNEL\tMEMBERS\tCREATED\tWHY")
+	for i, c := range clusters {
+		for _, ch := range c.Channels {
+			name := ch.Name
+			if name == c.Keep {
+				name += " (keep)"
+			}
+			fmt.Fprintf(tw, "%d\t%s\t%s\t%d\t%s\t%s\n", i+1, c.Workspace, name, ch.Members, dateOf(ch.Created), ch.Reason)
+		}
+	}
+	return tw.Flush()
+}
+
+// writeDuplicatePlan writes a draft plan merging every channel of each
+// cluster into the one to keep. Each cluster starts with a comment line
+// saying why its channels were grouped, for the reviewer turning rows into
+// renames or deleting them.
+func writeDuplicatePlan(w io.Writer, clusters []duplicateCluster, withWorkspace bool) (int, error) {
+	header := []string{"asis", "tobe", "action"}
+	if withWorkspace {
+		header = append(header, "workspace")
+	}
+	cw := csv.NewWriter(w)
+	cw.Write(header)
+	n := 0
+	for i, c := range clusters {
+		cw.Flush()
+		var why []string
+		for _, ch := range c.Channels {
+			if ch.Reason != "" {
+				why = append(why, ch.Name+": "+ch.Reason)
+			}
+		}
+		fmt.Fprintf(w, "# cluster %d, keeping %s; %s\n", i+1, c.Keep, strings.ReplaceAll(strings.Join(why, "; "), "\n", " "))
+		for _, ch := range c.Channels {
+			if ch.Name == c.Keep {
+				continue
+			}
+			row := []string{ch.Name, c.Keep, actionMerge}
+			if withWorkspace {
+				row = append(row, c.Workspace)
+			}
+			cw.Write(row)
+			n++
+		}
+	}
+	cw.Flush()
+	return n, cw.Error()
+}
+
+// cmdAuditDuplicates runs 'audit duplicates', which reports clusters of
+// channels that look like duplicates of each other and can write a draft
+// plan merging them.
+func cmdAuditDuplicates(args []string) int {
+	fs := newFlagSet("audit")
+	var channelOpts channelOptions
+	channelOpts.register(fs)
+	var ws workspaceOptions
+	ws.register(fs)
+	var opts duplicateOptions
+	fs.IntVar(&opts.maxDistance, "max-distance", 2, "most single-character edits between two names, ignoring separators and numeric suffixes, for them to count as duplicates (0 for exact matches only)")
+	fs.BoolVar(&opts.includeArchived, "include-archived", false, "also cluster archived channels")
+	generate := fs.Bool("generate", false, "write a draft plan merging each cluster into its largest channel instead of the report")
+	out := fs.String("out", "", "write to this file instead of stdout")
+	registerOutput(fs)
+	parseFlags(fs, args)
+	if opts.maxDistance < 0 {
+		slog.Error("-max-distance cannot be negative")
+		return 2
+	}
+
+	sessions, err := ws.newSessions(channelOpts)
+	if err != nil {
+		slog.Error(err.Error())
+		return 1
+	}
+	var clusters []duplicateCluster
+	for _, s := range sessions {
+		channels, err := s.listChannels(cmdCtx)
+		if err != nil {
+			slog.Error(err.Error())
+			return 1
+		}
+		for _, c := range findDuplicates(channels, opts) {
+			c.Workspace = s.workspace
+			clusters = append(clusters, c)
+		}
+	}
+
+	w := io.Writer(os.Stdout)
+	if *out != "" {
+		f, err := os.Create(*out)
+		if err != nil {
+			slog.Error("failed to create output file", "file", *out, "err", err)
+			return 1
+		}
+		defer f.Close()
+		w = f
+	}
+	switch {
+	case *generate:
+		n, err := writeDuplicatePlan(w, clusters, ws.config != "")
+		if err != nil {
+			slog.Error("failed to write plan", "err", err)
+			return 1
+		}
+		slog.Info("generated merge rows for duplicate channels", "clusters", len(clusters), "count", n)
+	case output == outputJSON:
+		report.Duplicates = clusters
+	default:
+		if err := printDuplicates(w, clusters); err != nil {
+			slog.Error("failed to write report", "err", err)
+			return 1
+		}
+	}
+	return 0
+}
@@ -0,0 +1,141 @@
+package main
+
+import (
+	"bytes"
+	"encoding/base64"
+	"flag"
+	"fmt"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/smtp"
+	"net/textproto"
+	"os"
+	"strings"
+	"time"
+)
+
+// emailOptions emails the run report, with the results CSV attached, once an
+// apply ends, for stakeholders who do not follow the Slack channels.
+type emailOptions struct {
+	to          string
+	from        string
+	server      string
+	user        string
+	passwordRef string
+}
+
+func (o *emailOptions) register(fs *flag.FlagSet) {
+	fs.StringVar(&o.to, "email-to", "", "email the run report to these comma-separated addresses once the run ends")
+	fs.StringVar(&o.from, "email-from", "", "sender address of the report email")
+	fs.StringVar(&o.server, "smtp-server", "", "SMTP server to send the report through, as host:port (STARTTLS is used when offered)")
+	fs.StringVar(&o.user, "smtp-user", "", "user to authenticate to the SMTP server as; the password is read from $SMTP_PASSWORD or -smtp-password-ref")
+	fs.StringVar(&o.passwordRef, "smtp-password-ref", "", "secret reference holding the SMTP password, as for -token-ref")
+}
+
+func (o emailOptions) enabled() bool {
+	return o.to != ""
+}
+
+// check reports incomplete settings before anything runs.
+func (o emailOptions) check() error {
+	if !o.enabled() {
+		return nil
+	}
+	if o.from == "" || o.server == "" {
+		return fmt.Errorf("-email-to needs -email-from and -smtp-server")
+	}
+	if _, _, err := net.SplitHostPort(o.server); err != nil {
+		return fmt.Errorf("invalid -smtp-server %q: %w", o.server, err)
+	}
+	return nil
+}
+
+func (o emailOptions) recipients() []string {
+	var to []string
+	for _, a := range strings.Split(o.to, ",") {
+		if a = strings.TrimSpace(a); a != "" {
+			to = append(to, a)
+		}
+	}
+	return to
+}
+
+// send emails r to the recipients.
+func (o emailOptions) send(r runReport) error {
+	msg, err := o.message(r, time.Now())
+	if err != nil {
+		return err
+	}
+	var auth smtp.Auth
+	if o.user != "" {
+		password := os.Getenv("SMTP_PASSWORD")
+		if o.passwordRef != "" {
+			if password, err = resolveTokenRef(o.passwordRef); err != nil {
+				return fmt.Errorf("smtp password: %w", err)
+			}
+		}
+		host, _, _ := net.SplitHostPort(o.server)
+		auth = smtp.PlainAuth("", o.user, password, host)
+	}
+	if err := smtp.SendMail(o.server, auth, o.from, o.recipients(), msg); err != nil {
+		return fmt.Errorf("send the report email: %w", err)
+	}
+	return nil
+}
+
+// message builds the email: the HTML report as its body and the results CSV
+// as an attachment.
+func (o emailOptions) message(r runReport, now time.Time) ([]byte, error) {
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+
+	part, err := mw.CreatePart(textproto.MIMEHeader{
+		"Content-Type":              {"text/html; charset=utf-8"},
+		"Content-Transfer-Encoding": {"quoted-printable"},
+	})
+	if err != nil {
+		return nil, err
+	}
+	qp := quotedprintable.NewWriter(part)
+	if err := r.writeHTML(qp); err != nil {
+		return nil, err
+	}
+	if err := qp.Close(); err != nil {
+		return nil, err
+	}
+
+	var results bytes.Buffer
+	if err := writeResultsCSV(&results, r.Results); err != nil {
+		return nil, err
+	}
+	part, err = mw.CreatePart(textproto.MIMEHeader{
+		"Content-Type":              {`text/csv; charset=utf-8; name="results.csv"`},
+		"Content-Transfer-Encoding": {"base64"},
+		"Content-Disposition":       {`attachment; filename="results.csv"`},
+	})
+	if err != nil {
+		return nil, err
+	}
+	encoded := base64.StdEncoding.EncodeToString(results.Bytes())
+	for len(encoded) > 76 {
+		fmt.Fprintf(part, "%s\r\n", encoded[:76])
+		encoded = encoded[76:]
+	}
+	fmt.Fprintf(part, "%s\r\n", encoded)
+	if err := mw.Close(); err != nil {
+		return nil, err
+	}
+
+	subject := fmt.Sprintf("Channel rename run %s: %s (%d succeeded, %d failed)", r.Outcome(), r.Source, r.Count(resultOK), r.Count(resultFailed))
+	var msg bytes.Buffer
+	fmt.Fprintf(&msg, "From: %s\r\n", o.from)
+	fmt.Fprintf(&msg, "To: %s\r\n", strings.Join(o.recipients(), ", "))
+	fmt.Fprintf(&msg, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", subject))
+	fmt.Fprintf(&msg, "Date: %s\r\n", now.Format(time.RFC1123Z))
+	fmt.Fprintf(&msg, "MIME-Version: 1.0\r\n")
+	fmt.Fprintf(&msg, "Content-Type: multipart/mixed; boundary=%q\r\n\r\n", mw.Boundary())
+	msg.Write(body.Bytes())
+	return msg.Bytes(), nil
+}
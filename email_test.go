@@ -0,0 +1,71 @@
+package main
+
+import (
+	"bytes"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/mail"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kiddikn/slack-channel-renamer/runner"
+)
+
+func TestEmailMessage(t *testing.T) {
+	start := time.Date(2026, 10, 14, 12, 30, 0, 0, time.UTC)
+	o := emailOptions{to: "ops@example.com, it@example.com", from: "renamer@example.com", server: "smtp.example.com:587"}
+	if err := o.check(); err != nil {
+		t.Fatal(err)
+	}
+	if err := (emailOptions{to: "ops@example.com"}).check(); err == nil {
+		t.Error("check passed without -email-from and -smtp-server")
+	}
+	r := runReport{Source: "plan.csv", Started: start, Finished: start.Add(time.Minute), Results: []entryResult{
+		runner.NewResult(planEntry{Action: actionRename, Asis: "old", Tobe: "new"}, channelInfo{ID: "C1"}, resultOK, nil, start, start),
+	}}
+	raw, err := o.message(r, start)
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg, err := mail.ReadMessage(bytes.NewReader(raw))
+	if err != nil {
+		t.Fatal(err)
+	}
+	subject, _ := new(mime.WordDecoder).DecodeHeader(msg.Header.Get("Subject"))
+	if subject != "Channel rename run finished: plan.csv (1 succeeded, 0 failed)" {
+		t.Errorf("subject = %q", subject)
+	}
+	if to, _ := msg.Header.AddressList("To"); len(to) != 2 {
+		t.Errorf("To = %v, want two addresses", to)
+	}
+	mediaType, params, err := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+	if err != nil || mediaType != "multipart/mixed" {
+		t.Fatalf("Content-Type = %q: %v", msg.Header.Get("Content-Type"), err)
+	}
+	mr := multipart.NewReader(msg.Body, params["boundary"])
+	var parts []string
+	for {
+		p, err := mr.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		b, _ := io.ReadAll(p)
+		if p.FileName() == "results.csv" {
+			// multipart.Reader does not decode base64.
+			if !strings.HasPrefix(string(b), "c291cmNl") {
+				t.Errorf("attachment = %q, want the base64 results CSV", b)
+			}
+		} else if !strings.Contains(string(b), "<h1>Channel rename run: plan.csv</h1>") {
+			t.Errorf("body = %q, want the HTML report", b)
+		}
+		parts = append(parts, p.Header.Get("Content-Type"))
+	}
+	if len(parts) != 2 {
+		t.Errorf("parts = %q, want the report and the results", parts)
+	}
+}
@@ -0,0 +1,186 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/kiddikn/slack-channel-renamer/plan"
+	"github.com/kiddikn/slack-channel-renamer/runner"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// executor applies a plan against one workspace: a runner.Runner, with the
+// CLI's pacing, records and reporting around every entry.
+type executor struct {
+	*runner.Runner
+
+	// watch, when set, follows renames made outside the run, so that rows
+	// whose channel one moves are skipped before they start.
+	watch *renameWatch
+
+	// history, when set, records every change as it is made.
+	history *historyRecorder
+
+	// checkpoint, when set, records the outcome of every entry for -resume.
+	checkpoint *checkpoint
+
+	// pacer, with -adaptive-rate, also paces entries to a per-minute budget.
+	pacer *adaptivePacer
+
+	// window, when set, holds back the start of entries while it is closed.
+	window *execWindow
+
+	// chunks, when set, pauses between chunks of entries.
+	chunks *chunkPacer
+
+	// webhook, when set, sends an event for every succeeded or failed entry.
+	webhook *webhookSender
+
+	// failureLimit, when set, stops the run after too many failed entries.
+	failureLimit *failureLimit
+
+	// observe, when set, is called with the outcome of every entry.
+	observe func(entryResult)
+
+	// progress shows a progress bar, or logs progress periodically, while
+	// entries run; label names the workspace in it. shown is the display
+	// of the entries running now.
+	progress bool
+	label    string
+	shown    *progress
+}
+
+// applyEntries applies entries as the runner does, paced and shown as the
+// run's flags ask. It returns the number of failures.
+func (x *executor) applyEntries(ctx context.Context, channels map[string]channelInfo, entries []planEntry) int {
+	x.shown = x.startProgress(len(entries))
+	defer x.shown.stop()
+	return x.Runner.ApplyEntries(withPacer(ctx, x.pacer), channels, entries)
+}
+
+// waitTurn holds back the start of an entry for -chunk-size, -window and
+// -adaptive-rate.
+func (x *executor) waitTurn(ctx context.Context) error {
+	if err := x.chunks.wait(ctx); err != nil {
+		return err
+	}
+	if err := x.window.wait(ctx); err != nil {
+		return err
+	}
+	return x.pacer.wait(ctx)
+}
+
+// renamedElsewhere reports the name the rename watch saw a channel given
+// outside the run.
+func (x *executor) renamedElsewhere(channelID string) (string, bool) {
+	return x.watch.renamedTo(channelID)
+}
+
+// recordOutcome records the outcome of entry in the history and the state file.
+func (x *executor) recordOutcome(ch channelInfo, entry planEntry, err error) {
+	if runner.ChangedChannel(err) {
+		if herr := x.history.record(ch, entry); herr != nil {
+			slog.Error("failed to record history", append(plan.EntryAttrs(ch, entry), "err", herr)...)
+		}
+	}
+	if cerr := x.checkpoint.record(entry, err); cerr != nil {
+		slog.Error("failed to update state file", append(plan.EntryAttrs(ch, entry), "err", cerr)...)
+	}
+}
+
+// report prints the outcome of an entry and passes it on to the webhook, the
+// live status, the audit log, the progress display and -max-failures.
+func (x *executor) report(r entryResult) {
+	if output == outputPlain {
+		switch r.Status {
+		case resultOK:
+			fmt.Fprintf(stdout, "OK: %s\n", r.Entry)
+		case resultFailed:
+			fmt.Fprintf(stdout, "FAIL: %s (%s)\n", r.Entry, r.Error)
+		case resultSkipped:
+			fmt.Fprintf(stdout, "SKIP: %s (%s)\n", r.Entry, r.Error)
+		}
+	}
+	x.webhook.row(r)
+	live.entry(r)
+	auditLog.entry(r)
+	if x.observe != nil {
+		x.observe(r)
+	}
+	if r.Status != resultPending {
+		x.shown.record(r.Status)
+	}
+	if r.Status == resultFailed {
+		x.failureLimit.failed()
+	}
+}
+
+// verifyPass re-fetches the channels after a run and checks that every
+// successful rename in x.Renamed left its channel named tobe, since Slack has
+// been seen to report success for a name it truncated or normalised. Each
+// mismatch is printed and turned from a success into a failure. channels is
+// the channel list the run started from. It returns the number of mismatches.
+func (x *executor) verifyPass(ctx context.Context, fetch func(context.Context) (map[string]channelInfo, error), channels map[string]channelInfo) int {
+	if len(x.Renamed) == 0 {
+		return 0
+	}
+	ctx, span := tracer.Start(context.WithoutCancel(ctx), "verification pass", trace.WithAttributes(attribute.Int("renames", len(x.Renamed))))
+	defer span.End()
+	live, err := fetch(ctx)
+	if err != nil {
+		slog.Error("verification pass failed", "err", err)
+		return 1
+	}
+	nameByID := make(map[string]string, len(live))
+	for name, ch := range live {
+		nameByID[ch.ID] = name
+	}
+
+	// Only a channel's last rename is checked: the earlier ones, such as the
+	// first half of a rename through a temporary name, were meant to be undone.
+	last := make(map[string]int, len(x.Renamed))
+	for i, e := range x.Renamed {
+		last[channels[e.Asis].ID] = i
+	}
+
+	mismatches := 0
+	for i, e := range x.Renamed {
+		if last[channels[e.Asis].ID] != i {
+			continue
+		}
+		name, ok := nameByID[channels[e.Asis].ID]
+		if ok && name == e.Tobe {
+			continue
+		}
+		var msg string
+		if !ok {
+			msg = fmt.Sprintf("channel %s no longer found", channels[e.Asis].ID)
+		} else {
+			msg = fmt.Sprintf("channel is named %q, expected %q", name, e.Tobe)
+		}
+		if output == outputPlain {
+			fmt.Fprintf(stdout, "MISMATCH: %s (%s)\n", e, msg)
+		}
+		x.markMismatch(e, name, msg)
+		x.Stats.Succeeded--
+		x.Stats.Failed++
+		mismatches++
+	}
+	slog.Info("verification pass done", "checked", len(last), "mismatches", mismatches)
+	return mismatches
+}
+
+// markMismatch turns the result of e, a rename the verification pass found
+// did not stick, into a failure.
+func (x *executor) markMismatch(e planEntry, name, msg string) {
+	for i := len(x.Results) - 1; i >= 0; i-- {
+		if r := &x.Results[i]; r.Entry == e && r.Status == resultOK {
+			r.Status, r.Error, r.ChannelName = resultFailed, "verification pass: "+msg, name
+			x.webhook.row(*r)
+			live.mismatch(e.Workspace)
+			return
+		}
+	}
+}
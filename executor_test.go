@@ -0,0 +1,124 @@
+package main
+
+import (
+	"context"
+	"maps"
+	"strings"
+	"testing"
+
+	"github.com/kiddikn/slack-channel-renamer/plan"
+	"github.com/kiddikn/slack-channel-renamer/runner"
+	"github.com/kiddikn/slack-channel-renamer/slackops"
+	"github.com/slack-go/slack"
+	"golang.org/x/time/rate"
+)
+
+func TestApplyEntries(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		channels []string
+		plan     []planEntry
+		fail     map[string]error
+		// between, when set, changes the workspace after validation.
+		between  func(w *fakeWorkspace)
+		want     map[string]string
+		failures int
+		skipped  int
+	}{
+		{
+			name:     "renames",
+			channels: []string{"a", "b"},
+			plan:     []planEntry{{Action: actionRename, Asis: "a", Tobe: "x"}, {Action: actionRename, Asis: "b", Tobe: "y"}},
+			want:     map[string]string{"C1": "x", "C2": "y"},
+		},
+		{
+			name:     "chain runs in dependency order",
+			channels: []string{"a", "b"},
+			plan:     []planEntry{{Action: actionRename, Asis: "a", Tobe: "b"}, {Action: actionRename, Asis: "b", Tobe: "c"}},
+			want:     map[string]string{"C1": "b", "C2": "c"},
+		},
+		{
+			name:     "swap goes through a temporary name",
+			channels: []string{"a", "b"},
+			plan:     []planEntry{{Action: actionRename, Asis: "a", Tobe: "b"}, {Action: actionRename, Asis: "b", Tobe: "a"}},
+			want:     map[string]string{"C1": "b", "C2": "a"},
+		},
+		{
+			name:     "archive frees the name for a rename",
+			channels: []string{"a", "b"},
+			plan:     []planEntry{{Action: actionRename, Asis: "a", Tobe: "b"}, {Action: actionArchive, Asis: "b"}},
+			want:     map[string]string{"C1": "b", "C2": "b"},
+		},
+		{
+			name:     "a failure does not stop the others",
+			channels: []string{"a", "b"},
+			plan:     []planEntry{{Action: actionRename, Asis: "a", Tobe: "x"}, {Action: actionRename, Asis: "b", Tobe: "y"}},
+			fail:     map[string]error{"rename C1": slack.SlackErrorResponse{Err: "not_authorized"}},
+			want:     map[string]string{"C1": "a", "C2": "y"},
+			failures: 1,
+		},
+		{
+			name:     "rate limits are retried",
+			channels: []string{"a"},
+			plan:     []planEntry{{Action: actionRename, Asis: "a", Tobe: "x"}},
+			fail:     map[string]error{"rename C1": &slack.RateLimitedError{RetryAfter: 1}},
+			want:     map[string]string{"C1": "x"},
+		},
+		{
+			name:     "the stale check skips a channel renamed since validation",
+			channels: []string{"a"},
+			plan:     []planEntry{{Action: actionRename, Asis: "a", Tobe: "x"}},
+			between:  func(w *fakeWorkspace) { w.channels[0].Name = "moved" },
+			want:     map[string]string{"C1": "moved"},
+			skipped:  1,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			w := newFakeWorkspace(tc.channels...)
+			maps.Copy(w.fail, tc.fail)
+			s := &session{client: w, stats: &slackops.Stats{}}
+			channels, err := s.fetchChannels(context.Background())
+			if err != nil {
+				t.Fatal(err)
+			}
+			active, errs, _ := plan.Validate(tc.plan, channels, "", nil)
+			if len(errs) > 0 {
+				t.Fatalf("validation errors: %q", errs)
+			}
+			if tc.between != nil {
+				tc.between(w)
+			}
+			x := s.executor(false)
+			x.Limiter = rate.NewLimiter(rate.Inf, 1)
+			x.StaleCheck = true
+			if failures := x.applyEntries(context.Background(), channels, active); failures != tc.failures {
+				t.Errorf("failures = %d, want %d", failures, tc.failures)
+			}
+			if s.stats.Skipped != tc.skipped {
+				t.Errorf("skipped = %d, want %d", s.stats.Skipped, tc.skipped)
+			}
+			if got := w.names(); !maps.Equal(got, tc.want) {
+				t.Errorf("channels = %v, want %v (calls %q)", got, tc.want, w.calls)
+			}
+		})
+	}
+}
+
+func TestVerifyPassPrintsMismatch(t *testing.T) {
+	var b strings.Builder
+	old := stdout
+	stdout = &b
+	defer func() { stdout = old }()
+
+	x := &executor{Runner: runner.New(nil, runner.Options{Stats: &slackops.Stats{}})}
+	x.Renamed = []planEntry{{Action: actionRename, Asis: "a", Tobe: "x"}}
+	fetch := func(context.Context) (map[string]channelInfo, error) {
+		return map[string]channelInfo{"y": {ID: "C1"}}, nil
+	}
+	if n := x.verifyPass(context.Background(), fetch, map[string]channelInfo{"a": {ID: "C1"}}); n != 1 {
+		t.Fatalf("verifyPass = %d mismatches, want 1", n)
+	}
+	if want := `MISMATCH: a -> x (channel is named "y", expected "x")`; !strings.Contains(b.String(), want) {
+		t.Errorf("stdout = %q, want %q", b.String(), want)
+	}
+}
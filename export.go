@@ -0,0 +1,150 @@
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"maps"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/kiddikn/slack-channel-renamer/plan"
+)
+
+// exportRow is one channel of the export, in the field order of the CSV.
+type exportRow struct {
+	Name     string    `json:"name"`
+	ID       string    `json:"id"`
+	Archived bool      `json:"archived"`
+	Private  bool      `json:"private"`
+	Creator  string    `json:"creator,omitempty"`
+	Created  time.Time `json:"created"`
+	Members  int       `json:"members"`
+	Topic    string    `json:"topic,omitempty"`
+	// Purpose is left out of the CSV, which is edited into plans; JSON only.
+	Purpose string `json:"purpose,omitempty"`
+	// ExtShared and OrgShared mark channels shared through Slack Connect or
+	// with other workspaces of the org; JSON only.
+	ExtShared bool `json:"ext_shared,omitempty"`
+	OrgShared bool `json:"org_shared,omitempty"`
+	// LastActivity is the time of the newest message, with -last-activity.
+	LastActivity time.Time `json:"last_activity,omitzero"`
+	Workspace    string    `json:"workspace,omitempty"`
+}
+
+// exportRows returns the rows for one workspace's channels in name order.
+func exportRows(workspace string, channels map[string]channelInfo) []exportRow {
+	rows := make([]exportRow, 0, len(channels))
+	for _, name := range slices.Sorted(maps.Keys(channels)) {
+		ch := channels[name]
+		rows = append(rows, exportRow{
+			Name:      name,
+			ID:        ch.ID,
+			Archived:  ch.IsArchived,
+			Private:   ch.IsPrivate,
+			ExtShared: ch.IsExtShared,
+			OrgShared: ch.IsOrgShared,
+			Creator:   ch.Creator,
+			Created:   ch.Created,
+			Members:   ch.NumMembers,
+			Topic:     ch.Topic,
+			Purpose:   ch.Purpose,
+			Workspace: workspace,
+		})
+	}
+	return rows
+}
+
+// addLastActivity fills in the LastActivity of the session's rows, logging
+// the channels whose history cannot be read.
+func (s *session) addLastActivity(ctx context.Context, rows []exportRow) {
+	for i, r := range rows {
+		if r.Archived {
+			continue
+		}
+		last, err := s.lastActivity(ctx, r.Name, channelInfo{ID: r.ID, Created: r.Created})
+		if err != nil {
+			slog.Warn(s.label()+"cannot read the last activity of a channel", "channel", r.Name, "err", err)
+			continue
+		}
+		rows[i].LastActivity = last.UTC()
+	}
+}
+
+// writeExport writes rows to w as "csv" or "json". The CSV gains a
+// last_activity column when withActivity is set, and a workspace column when
+// withWorkspace is set, so that it can be edited into a plan covering the
+// same workspaces.
+func writeExport(w io.Writer, format string, rows []exportRow, withActivity, withWorkspace bool) error {
+	switch format {
+	case "json":
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(rows)
+	case "csv":
+	default:
+		return fmt.Errorf("unknown export format %q (want csv or json)", format)
+	}
+
+	header := []string{"name", "id", "archived", "private", "creator", "created", "members", "topic"}
+	if withActivity {
+		header = append(header, "last_activity")
+	}
+	if withWorkspace {
+		header = append(header, "workspace")
+	}
+	cw := csv.NewWriter(w)
+	cw.Write(header)
+	for _, r := range rows {
+		row := []string{r.Name, r.ID, strconv.FormatBool(r.Archived), strconv.FormatBool(r.Private),
+			r.Creator, formatTime(r.Created), strconv.Itoa(r.Members), r.Topic}
+		if withActivity {
+			row = append(row, formatTime(r.LastActivity))
+		}
+		if withWorkspace {
+			row = append(row, r.Workspace)
+		}
+		cw.Write(row)
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// formatTime formats t for a CSV cell, leaving it empty when t is unknown.
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format(time.RFC3339)
+}
+
+// addChannelStats fills in the stats of every entry of runs whose channel
+// exists. The newest message of each channel is read once; simulated
+// sessions, whose snapshots have no messages, leave it out.
+func addChannelStats(ctx context.Context, runs []workspaceRun) {
+	for _, r := range runs {
+		last := make(map[string]time.Time)
+		for i, e := range r.plan {
+			if e.Action == actionCreate {
+				continue
+			}
+			ch := plan.EntryChannel(r.channels, e)
+			if ch.ID == "" {
+				continue
+			}
+			t, ok := last[ch.ID]
+			if !ok && !r.simulated {
+				var err error
+				if t, err = r.lastActivity(ctx, e.Asis, ch); err != nil {
+					slog.Warn(r.label()+"cannot read the last activity of a channel", "channel", e.Asis, "err", err)
+				}
+				last[ch.ID] = t
+			}
+			r.plan[i].Stats = &plan.ChannelStats{Members: ch.NumMembers, Creator: ch.Creator, Created: ch.Created, LastActivity: t.UTC()}
+		}
+	}
+}
@@ -0,0 +1,208 @@
+package main
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"maps"
+	"net"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// apiLatencyBuckets are the upper bounds, in seconds, of the Slack API
+// latency histogram.
+var apiLatencyBuckets = []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30}
+
+// liveMetrics holds the metrics served on -metrics-addr. Unlike the metrics
+// file, which describes one finished run, they accumulate for as long as the
+// process lives, so a scraper can alert on a run that stops making progress
+// or keeps failing.
+type liveMetrics struct {
+	mu sync.Mutex
+
+	entries    map[[3]string]float64 // workspace, action, status
+	mismatches map[string]float64    // workspace
+	planSize   map[string]float64    // workspace
+	rateLimits map[string]float64    // Slack method
+	latency    map[string]*histogram // Slack method
+
+	running      int
+	lastProgress time.Time
+	lastFinished time.Time
+}
+
+type histogram struct {
+	counts []float64 // cumulative, one per apiLatencyBuckets bound
+	count  float64
+	sum    float64
+}
+
+// live is updated by every run whether or not -metrics-addr is set; the
+// updates are cheap next to a Slack API call.
+var live = &liveMetrics{
+	entries:    make(map[[3]string]float64),
+	mismatches: make(map[string]float64),
+	planSize:   make(map[string]float64),
+	rateLimits: make(map[string]float64),
+	latency:    make(map[string]*histogram),
+}
+
+// runStarted records the start of a run of the given plan sizes per workspace.
+func (m *liveMetrics) runStarted(runs []workspaceRun) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.running++
+	m.lastProgress = time.Now()
+	for _, r := range runs {
+		m.planSize[r.workspace] = float64(len(r.plan))
+	}
+}
+
+func (m *liveMetrics) runFinished() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.running--
+	m.lastFinished = time.Now()
+}
+
+// entry counts the outcome of one plan entry. Entries an interrupt kept from
+// starting are not counted.
+func (m *liveMetrics) entry(r entryResult) {
+	if r.Status == resultPending {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.entries[[3]string{r.Workspace, r.Action, r.Status}]++
+	m.lastProgress = time.Now()
+}
+
+// mismatch counts a rename the verification pass found did not stick.
+func (m *liveMetrics) mismatch(workspace string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.mismatches[workspace]++
+}
+
+// apiCall records the latency of one Slack API request, and whether Slack
+// answered it with a rate limit.
+func (m *liveMetrics) apiCall(method string, took time.Duration, rateLimited bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	h := m.latency[method]
+	if h == nil {
+		h = &histogram{counts: make([]float64, len(apiLatencyBuckets))}
+		m.latency[method] = h
+	}
+	s := took.Seconds()
+	for i, bound := range apiLatencyBuckets {
+		if s <= bound {
+			h.counts[i]++
+		}
+	}
+	h.count++
+	h.sum += s
+	if rateLimited {
+		m.rateLimits[method]++
+	}
+}
+
+// write renders the metrics in the Prometheus text exposition format.
+func (m *liveMetrics) write(w io.Writer) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	header := func(name, typ, help string) {
+		fmt.Fprintf(w, "# HELP %s%s %s\n", metricsPrefix, name, help)
+		fmt.Fprintf(w, "# TYPE %s%s %s\n", metricsPrefix, name, typ)
+	}
+	byLabel := func(name, typ, help, label string, values map[string]float64) {
+		header(name, typ, help)
+		for _, k := range slices.Sorted(maps.Keys(values)) {
+			fmt.Fprintf(w, "%s%s{%s=%q} %g\n", metricsPrefix, name, label, k, values[k])
+		}
+	}
+
+	header("entries_total", "counter", "Plan entries finished, by workspace, action and status (ok, failed or skipped).")
+	keys := slices.SortedFunc(maps.Keys(m.entries), func(a, b [3]string) int {
+		return cmp.Or(strings.Compare(a[0], b[0]), strings.Compare(a[1], b[1]), strings.Compare(a[2], b[2]))
+	})
+	for _, k := range keys {
+		fmt.Fprintf(w, "%sentries_total{workspace=%q,action=%q,status=%q} %g\n", metricsPrefix, k[0], k[1], k[2], m.entries[k])
+	}
+	byLabel("verification_mismatches_total", "counter", "Renames the verification pass found did not stick.", "workspace", m.mismatches)
+	byLabel("plan_entries", "gauge", "Entries in the plan of the latest run, by workspace.", "workspace", m.planSize)
+	byLabel("rate_limit_hits_total", "counter", "Slack API responses that were rate limited, by method.", "method", m.rateLimits)
+
+	header("api_request_duration_seconds", "histogram", "Latency of Slack API requests, by method.")
+	for _, method := range slices.Sorted(maps.Keys(m.latency)) {
+		h := m.latency[method]
+		for i, bound := range apiLatencyBuckets {
+			fmt.Fprintf(w, "%sapi_request_duration_seconds_bucket{method=%q,le=\"%g\"} %g\n", metricsPrefix, method, bound, h.counts[i])
+		}
+		fmt.Fprintf(w, "%sapi_request_duration_seconds_bucket{method=%q,le=\"+Inf\"} %g\n", metricsPrefix, method, h.count)
+		fmt.Fprintf(w, "%sapi_request_duration_seconds_sum{method=%q} %g\n", metricsPrefix, method, h.sum)
+		fmt.Fprintf(w, "%sapi_request_duration_seconds_count{method=%q} %g\n", metricsPrefix, method, h.count)
+	}
+
+	header("runs_in_progress", "gauge", "Runs currently applying changes.")
+	fmt.Fprintf(w, "%sruns_in_progress %d\n", metricsPrefix, m.running)
+	header("last_progress_timestamp_seconds", "gauge", "Unix time at which a run last started or finished an entry.")
+	fmt.Fprintf(w, "%slast_progress_timestamp_seconds %g\n", metricsPrefix, unixOrZero(m.lastProgress))
+	header("last_run_finished_timestamp_seconds", "gauge", "Unix time at which the latest run finished.")
+	fmt.Fprintf(w, "%slast_run_finished_timestamp_seconds %g\n", metricsPrefix, unixOrZero(m.lastFinished))
+}
+
+func (m *liveMetrics) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	m.write(w)
+}
+
+func unixOrZero(t time.Time) float64 {
+	if t.IsZero() {
+		return 0
+	}
+	return float64(t.UnixMilli()) / 1000
+}
+
+// serveMetrics serves /metrics on addr in the background until the process
+// exits. An empty addr serves nothing.
+func serveMetrics(addr string) error {
+	if addr == "" {
+		return nil
+	}
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("metrics listener: %w", err)
+	}
+	mux := http.NewServeMux()
+	mux.Handle("GET /metrics", live)
+	go func() {
+		srv := &http.Server{Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+		if err := srv.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			slog.Error("metrics server stopped", "err", err)
+		}
+	}()
+	slog.Info("serving metrics", "addr", ln.Addr().String(), "path", "/metrics")
+	return nil
+}
+
+// metricsTransport records the latency and rate limits of every Slack API
+// request in live and in the runs' API statistics.
+type metricsTransport struct {
+	base http.RoundTripper
+}
+
+func (t metricsTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	start := time.Now()
+	resp, err := t.base.RoundTrip(req)
+	method, took, rateLimited := strings.TrimPrefix(req.URL.Path, "/api/"), time.Since(start), err == nil && resp.StatusCode == http.StatusTooManyRequests
+	live.apiCall(method, took, rateLimited)
+	apiRecorders.call(req.Context(), method, took, rateLimited)
+	return resp, err
+}
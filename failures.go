@@ -0,0 +1,39 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+)
+
+// errTooManyFailures is the cause of a run stopped by -max-failures or
+// -on-error fail-fast.
+var errTooManyFailures = errors.New("too many failures")
+
+// Values of -on-error.
+const (
+	onErrorContinue = "continue"
+	onErrorFailFast = "fail-fast"
+)
+
+// failureLimit stops a run once max entries have failed, so that a problem
+// shared by every row, such as a missing scope, fails the run in a few calls
+// instead of grinding through the whole plan.
+type failureLimit struct {
+	max, failures int
+	abort         context.CancelCauseFunc
+}
+
+// failed counts a failed entry and stops the run when it is the last one
+// allowed. A nil limit never stops it.
+func (l *failureLimit) failed() {
+	if l == nil {
+		return
+	}
+	l.failures++
+	if l.failures == l.max {
+		slog.Error("stopping the run: too many failures", "failures", l.failures, "max_failures", l.max)
+		l.abort(fmt.Errorf("%w: %d entries failed", errTooManyFailures, l.failures))
+	}
+}
@@ -0,0 +1,147 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+
+	"github.com/kiddikn/slack-channel-renamer/slackops"
+	"github.com/slack-go/slack"
+)
+
+// fakeWorkspace is an in-memory Slack workspace implementing the channel
+// calls of slackops.API. Calling a method it does not implement panics through
+// the nil embedded interface.
+type fakeWorkspace struct {
+	slackops.API
+
+	mu       sync.Mutex
+	channels []*slack.Channel
+	// fail holds an error to return, once, for the next call of a method on
+	// a channel ID, keyed by "Method channelID".
+	fail map[string]error
+	// calls records every call made, as "Method channelID [arg]".
+	calls []string
+	// latest holds the timestamp of the newest message of a channel, by ID.
+	latest map[string]string
+}
+
+// newFakeWorkspace returns a workspace with public channels named names,
+// with IDs C1, C2 and so on.
+func newFakeWorkspace(names ...string) *fakeWorkspace {
+	w := &fakeWorkspace{fail: make(map[string]error)}
+	for i, name := range names {
+		ch := &slack.Channel{}
+		ch.ID, ch.Name, ch.NumMembers = fmt.Sprintf("C%d", i+1), name, 1
+		w.channels = append(w.channels, ch)
+	}
+	return w
+}
+
+// names returns the channel names by ID.
+func (w *fakeWorkspace) names() map[string]string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	names := make(map[string]string)
+	for _, ch := range w.channels {
+		names[ch.ID] = ch.Name
+	}
+	return names
+}
+
+func (w *fakeWorkspace) call(method, id string, args ...string) (*slack.Channel, error) {
+	w.calls = append(w.calls, fmt.Sprint(slices.Concat([]string{method, id}, args)))
+	if err, ok := w.fail[method+" "+id]; ok {
+		delete(w.fail, method+" "+id)
+		return nil, err
+	}
+	for _, ch := range w.channels {
+		if ch.ID == id {
+			return ch, nil
+		}
+	}
+	return nil, slack.SlackErrorResponse{Err: "channel_not_found"}
+}
+
+func (w *fakeWorkspace) GetConversationsContext(ctx context.Context, params *slack.GetConversationsParameters) ([]slack.Channel, string, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.calls = append(w.calls, "[list]")
+	var out []slack.Channel
+	for _, ch := range w.channels {
+		out = append(out, *ch)
+	}
+	return out, "", nil
+}
+
+func (w *fakeWorkspace) GetConversationHistoryContext(ctx context.Context, params *slack.GetConversationHistoryParameters) (*slack.GetConversationHistoryResponse, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if _, err := w.call("history", params.ChannelID); err != nil {
+		return nil, err
+	}
+	resp := &slack.GetConversationHistoryResponse{}
+	if ts, ok := w.latest[params.ChannelID]; ok {
+		resp.Messages = []slack.Message{{Msg: slack.Msg{Timestamp: ts}}}
+	}
+	return resp, nil
+}
+
+func (w *fakeWorkspace) GetConversationInfoContext(ctx context.Context, input *slack.GetConversationInfoInput) (*slack.Channel, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	ch, err := w.call("info", input.ChannelID)
+	if err != nil {
+		return nil, err
+	}
+	c := *ch
+	return &c, nil
+}
+
+func (w *fakeWorkspace) RenameConversationContext(ctx context.Context, id, name string) (*slack.Channel, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	ch, err := w.call("rename", id, name)
+	if err != nil {
+		return nil, err
+	}
+	for _, other := range w.channels {
+		// As validation assumes, an archived channel does not hold its name.
+		if other.Name == name && other.ID != id && !other.IsArchived {
+			return nil, slack.SlackErrorResponse{Err: "name_taken"}
+		}
+	}
+	ch.Name = name
+	return ch, nil
+}
+
+func (w *fakeWorkspace) ArchiveConversationContext(ctx context.Context, id string) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	ch, err := w.call("archive", id)
+	if err == nil {
+		ch.IsArchived = true
+	}
+	return err
+}
+
+func (w *fakeWorkspace) UnArchiveConversationContext(ctx context.Context, id string) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	ch, err := w.call("unarchive", id)
+	if err == nil {
+		ch.IsArchived = false
+	}
+	return err
+}
+
+func (w *fakeWorkspace) SetTopicOfConversationContext(ctx context.Context, id, topic string) (*slack.Channel, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	ch, err := w.call("topic", id, topic)
+	if err == nil {
+		ch.Topic.Value = topic
+	}
+	return ch, err
+}
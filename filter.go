@@ -0,0 +1,122 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"strings"
+)
+
+// rowFilter restricts a plan to part of its rows, so that one master mapping
+// file can be applied a team at a time. A row matches a prefix or pattern when
+// its asis or its tobe does.
+type rowFilter struct {
+	prefixes stringList
+	only     string
+	exclude  string
+
+	// names and skip select rows by their exact asis, as comma-separated
+	// lists; limit keeps the first rows left after every other filter.
+	names stringList
+	skip  stringList
+	limit int
+}
+
+func (f *rowFilter) register(fs *flag.FlagSet) {
+	fs.Var(&f.prefixes, "only-prefix", "only consider rows whose asis or tobe starts with this prefix (repeatable)")
+	fs.StringVar(&f.only, "only-regex", "", "only consider rows whose asis or tobe matches this regular expression")
+	fs.StringVar(&f.exclude, "exclude-regex", "", "ignore rows whose asis or tobe matches this regular expression")
+	fs.Var(&f.names, "only", "only consider the rows with these comma-separated asis names (repeatable)")
+	fs.Var(&f.skip, "skip", "ignore the rows with these comma-separated asis names (repeatable)")
+	fs.IntVar(&f.limit, "limit", 0, "only consider the first N rows left by the other filters (0 = all)")
+}
+
+// nameSet splits comma-separated lists of names into a set. A leading # is
+// dropped, so that names can be pasted as Slack shows them.
+func nameSet(lists []string) map[string]bool {
+	set := make(map[string]bool)
+	for _, list := range lists {
+		for name := range strings.SplitSeq(list, ",") {
+			if name = strings.TrimPrefix(strings.TrimSpace(name), "#"); name != "" {
+				set[name] = true
+			}
+		}
+	}
+	return set
+}
+
+// active reports whether any filter is set.
+func (f rowFilter) active() bool {
+	return len(f.prefixes) > 0 || f.only != "" || f.exclude != "" || len(f.names) > 0 || len(f.skip) > 0 || f.limit > 0
+}
+
+// apply returns the rows of plan the filters let through.
+func (f rowFilter) apply(plan []planEntry) ([]planEntry, error) {
+	if !f.active() {
+		return plan, nil
+	}
+	if f.limit < 0 {
+		return nil, fmt.Errorf("-limit cannot be negative, got %d", f.limit)
+	}
+	compile := func(flagName, expr string) (*regexp.Regexp, error) {
+		if expr == "" {
+			return nil, nil
+		}
+		re, err := regexp.Compile(expr)
+		if err != nil {
+			return nil, fmt.Errorf("-%s: %w", flagName, err)
+		}
+		return re, nil
+	}
+	only, err := compile("only-regex", f.only)
+	if err != nil {
+		return nil, err
+	}
+	exclude, err := compile("exclude-regex", f.exclude)
+	if err != nil {
+		return nil, err
+	}
+
+	matches := func(e planEntry, match func(string) bool) bool {
+		return match(e.Asis) || (e.Tobe != "" && match(e.Tobe))
+	}
+	hasPrefix := func(name string) bool {
+		for _, p := range f.prefixes {
+			if strings.HasPrefix(name, p) {
+				return true
+			}
+		}
+		return false
+	}
+	names, skip := nameSet(f.names), nameSet(f.skip)
+	if len(f.names) > 0 && len(names) == 0 {
+		// An empty list, as from a retry with nothing to retry, must not
+		// select the whole plan.
+		return nil, fmt.Errorf("-only lists no names")
+	}
+	seen := make(map[string]bool)
+	var kept []planEntry
+	for _, e := range plan {
+		seen[e.Asis] = true
+		switch {
+		case len(f.prefixes) > 0 && !matches(e, hasPrefix):
+		case only != nil && !matches(e, only.MatchString):
+		case exclude != nil && matches(e, exclude.MatchString):
+		case len(names) > 0 && !names[e.Asis]:
+		case skip[e.Asis]:
+		default:
+			kept = append(kept, e)
+		}
+	}
+	for name := range names {
+		if !seen[name] {
+			slog.Warn("-only names a channel that is not in the plan", "asis", name)
+		}
+	}
+	if f.limit > 0 && len(kept) > f.limit {
+		kept = kept[:f.limit]
+	}
+	slog.Info("row filters applied", "kept", len(kept), "total", len(plan))
+	return kept, nil
+}
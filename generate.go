@@ -0,0 +1,130 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"maps"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/kiddikn/slack-channel-renamer/plan"
+)
+
+// generateRows applies re and replace to the name of every channel that re
+// matches and returns the asis/tobe rows for those whose name would change,
+// in name order. Archived channels are left out unless includeArchived is set.
+// Rows whose new name is not a valid channel name are logged for review.
+func generateRows(channels map[string]channelInfo, re *regexp.Regexp, replace string, includeArchived bool) [][2]string {
+	var rows [][2]string
+	for _, name := range slices.Sorted(maps.Keys(channels)) {
+		if channels[name].IsArchived && !includeArchived {
+			continue
+		}
+		if !re.MatchString(name) {
+			continue
+		}
+		tobe := re.ReplaceAllString(name, replace)
+		if tobe == name {
+			continue
+		}
+		if plan.NormalizeName(tobe) != tobe || !plan.ChannelNameRe.MatchString(tobe) {
+			slog.Warn("generated name is not a valid channel name; review it before applying", "asis", name, "tobe", tobe)
+		}
+		rows = append(rows, [2]string{name, tobe})
+	}
+	return rows
+}
+
+// transformOptions are the prefix and suffix changes of 'transform'. Each
+// channel name has its prefix and suffix stripped first, then gets the new
+// ones.
+type transformOptions struct {
+	match       string
+	re          *regexp.Regexp
+	addPrefix   string
+	stripPrefix string
+	addSuffix   string
+	stripSuffix string
+}
+
+// transformFlags are the flags of transform that apply does not take.
+var transformFlags = []string{"match", "add-prefix", "strip-prefix", "add-suffix", "strip-suffix"}
+
+func (o *transformOptions) register(fs *flag.FlagSet) {
+	fs.StringVar(&o.match, "match", "", "regular expression selecting the channels to change, e.g. '^eng-' (required unless stripping)")
+	fs.StringVar(&o.addPrefix, "add-prefix", "", "prefix to add, e.g. 'team-eng-'; channels that already have it are left alone")
+	fs.StringVar(&o.stripPrefix, "strip-prefix", "", "prefix to remove; only channels that have it are changed")
+	fs.StringVar(&o.addSuffix, "add-suffix", "", "suffix to add; channels that already have it are left alone")
+	fs.StringVar(&o.stripSuffix, "strip-suffix", "", "suffix to remove; only channels that have it are changed")
+}
+
+// check compiles -match and reports a transform that does nothing or would
+// change every channel.
+func (o *transformOptions) check() error {
+	if o.addPrefix == "" && o.stripPrefix == "" && o.addSuffix == "" && o.stripSuffix == "" {
+		return errors.New("transform needs -add-prefix, -strip-prefix, -add-suffix or -strip-suffix")
+	}
+	if o.match == "" && o.stripPrefix == "" && o.stripSuffix == "" {
+		return errors.New("-add-prefix and -add-suffix need -match to select the channels")
+	}
+	if o.match != "" {
+		re, err := regexp.Compile(o.match)
+		if err != nil {
+			return fmt.Errorf("invalid -match: %w", err)
+		}
+		o.re = re
+	}
+	return nil
+}
+
+// apply returns the new name of the channel called name, and whether the
+// transform selects it.
+func (o *transformOptions) apply(name string) (string, bool) {
+	if o.re != nil && !o.re.MatchString(name) {
+		return "", false
+	}
+	tobe := name
+	if o.stripPrefix != "" {
+		var ok bool
+		if tobe, ok = strings.CutPrefix(tobe, o.stripPrefix); !ok {
+			return "", false
+		}
+	}
+	if o.stripSuffix != "" {
+		var ok bool
+		if tobe, ok = strings.CutSuffix(tobe, o.stripSuffix); !ok {
+			return "", false
+		}
+	}
+	if !strings.HasPrefix(tobe, o.addPrefix) {
+		tobe = o.addPrefix + tobe
+	}
+	if !strings.HasSuffix(tobe, o.addSuffix) {
+		tobe += o.addSuffix
+	}
+	return tobe, true
+}
+
+// rows returns the asis/tobe rows of the channels whose name the transform
+// changes, in name order, leaving out archived channels unless
+// includeArchived is set. Invalid new names are logged, as by generateRows.
+func (o *transformOptions) rows(channels map[string]channelInfo, includeArchived bool) [][2]string {
+	var rows [][2]string
+	for _, name := range slices.Sorted(maps.Keys(channels)) {
+		if channels[name].IsArchived && !includeArchived {
+			continue
+		}
+		tobe, ok := o.apply(name)
+		if !ok || tobe == name {
+			continue
+		}
+		if plan.NormalizeName(tobe) != tobe || !plan.ChannelNameRe.MatchString(tobe) {
+			slog.Warn("transformed name is not a valid channel name; review it before applying", "asis", name, "tobe", tobe)
+		}
+		rows = append(rows, [2]string{name, tobe})
+	}
+	return rows
+}
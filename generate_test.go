@@ -0,0 +1,81 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestTransformRows(t *testing.T) {
+	channels := map[string]channelInfo{
+		"eng-api":      {ID: "C1"},
+		"eng-web":      {ID: "C2"},
+		"team-eng-ops": {ID: "C3"},
+		"eng-old":      {ID: "C4", IsArchived: true},
+		"random":       {ID: "C5"},
+	}
+	for _, tc := range []struct {
+		name string
+		opts transformOptions
+		want [][2]string
+	}{
+		{"add prefix", transformOptions{match: "^eng-", addPrefix: "team-"}, [][2]string{{"eng-api", "team-eng-api"}, {"eng-web", "team-eng-web"}}},
+		{"replace prefix", transformOptions{stripPrefix: "eng-", addPrefix: "team-eng-"}, [][2]string{{"eng-api", "team-eng-api"}, {"eng-web", "team-eng-web"}}},
+		{"already prefixed", transformOptions{match: "eng-", addPrefix: "team-"}, [][2]string{{"eng-api", "team-eng-api"}, {"eng-web", "team-eng-web"}}},
+		{"strip prefix", transformOptions{stripPrefix: "team-"}, [][2]string{{"team-eng-ops", "eng-ops"}}},
+		{"add suffix", transformOptions{match: "^random$", addSuffix: "-2026"}, [][2]string{{"random", "random-2026"}}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if err := tc.opts.check(); err != nil {
+				t.Fatal(err)
+			}
+			if got := tc.opts.rows(channels, false); !slices.Equal(got, tc.want) {
+				t.Errorf("rows = %q, want %q", got, tc.want)
+			}
+		})
+	}
+
+	if err := (&transformOptions{addPrefix: "team-"}).check(); err == nil {
+		t.Error("check accepted -add-prefix without -match")
+	}
+}
+
+func TestTransformApply(t *testing.T) {
+	dir := t.TempDir()
+	snapshot := filepath.Join(dir, "snapshot.json")
+	if err := os.WriteFile(snapshot, []byte(`[{"name":"eng-api","id":"C1"},{"name":"random","id":"C2"}]`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	results := filepath.Join(dir, "results.csv")
+	code := cmdTransform([]string{"-simulate", snapshot, "-strip-prefix", "eng-", "-add-prefix", "team-eng-", "-apply", "--", "-results-file", results, "-rollback-dir", ""})
+	if code != 0 {
+		t.Fatalf("exit code %d", code)
+	}
+	data, err := os.ReadFile(results)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), "eng-api,team-eng-api,ok") {
+		t.Errorf("results:\n%s", data)
+	}
+}
+
+func TestNormalizeRows(t *testing.T) {
+	channels := map[string]channelInfo{
+		"ｐｒｏｊ-alpha": {ID: "C1"},
+		"proj--beta": {ID: "C2"},
+		"proj-beta":  {ID: "C3"},
+		"ops-":       {ID: "C4"},
+		"ok-name":    {ID: "C5"},
+		"old__":      {ID: "C6", IsArchived: true},
+	}
+	want := [][2]string{{"ops-", "ops"}, {"proj--beta", "proj-beta-2"}, {"ｐｒｏｊ-alpha", "proj-alpha"}}
+	if got := normalizeRows(channels, false); !slices.Equal(got, want) {
+		t.Errorf("rows = %q, want %q", got, want)
+	}
+	if _, problems := idiomaticName("Ｅng--Ops-"); len(problems) != 4 {
+		t.Errorf("problems = %q, want 4", problems)
+	}
+}
@@ -0,0 +1,137 @@
+package main
+
+import (
+	"cmp"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// ghActions is the GitHub Actions job the command runs in, or nil outside
+// one. It is set up in main, so tests never write to a real job's files.
+var ghActions *githubActions
+
+// githubActions reports to the GitHub Actions job running the command:
+// validation errors become annotations on the plan file's lines, the plan and
+// the results go into the job summary, and the counts of pending and failed
+// entries become step outputs.
+type githubActions struct {
+	// annotations receives the workflow commands. The runner reads them from
+	// stderr as well as stdout, and stdout may hold the plan or a JSON report.
+	annotations io.Writer
+	// summary and output are the $GITHUB_STEP_SUMMARY and $GITHUB_OUTPUT
+	// files, or empty.
+	summary string
+	output  string
+}
+
+// githubActionsFromEnv returns the job the environment describes, or nil
+// when GITHUB_ACTIONS is not set.
+func githubActionsFromEnv() *githubActions {
+	if os.Getenv("GITHUB_ACTIONS") != "true" {
+		return nil
+	}
+	return &githubActions{annotations: os.Stderr, summary: os.Getenv("GITHUB_STEP_SUMMARY"), output: os.Getenv("GITHUB_OUTPUT")}
+}
+
+// sourcedErrRe splits a validation error into the plan file and line it
+// names, after any workspace label, and the message.
+var sourcedErrRe = regexp.MustCompile(`(?s)^(?:workspace [^:]*: )?(.+?):(\d+): (.*)$`)
+
+// validation annotates each validation error, on its plan file line when it
+// names one, and sets the outputs to the error count and the plan's size.
+func (g *githubActions) validation(errs []string, runs []workspaceRun) {
+	if g == nil {
+		return
+	}
+	for _, e := range errs {
+		if m := sourcedErrRe.FindStringSubmatch(e); m != nil {
+			fmt.Fprintf(g.annotations, "::error file=%s,line=%s::%s\n", escapeProperty(m[1]), m[2], escapeData(m[3]))
+			continue
+		}
+		fmt.Fprintf(g.annotations, "::error::%s\n", escapeData(e))
+	}
+	pending := 0
+	for _, r := range runs {
+		pending += len(r.plan)
+	}
+	g.setOutputs(pending, len(errs))
+	if len(errs) == 0 {
+		g.planSummary(runs)
+	}
+}
+
+// planSummary adds the plan to the job summary as a table.
+func (g *githubActions) planSummary(runs []workspaceRun) {
+	var b strings.Builder
+	b.WriteString("### Plan\n\n")
+	n := 0
+	for _, r := range runs {
+		n += len(r.plan)
+	}
+	if n == 0 {
+		b.WriteString("Nothing to change.\n\n")
+		g.appendSummary(b.String())
+		return
+	}
+	b.WriteString("| Workspace | Action | Channel | New name | Source |\n|---|---|---|---|---|\n")
+	for _, r := range runs {
+		for _, e := range r.plan {
+			fmt.Fprintf(&b, "| %s | %s | %s | %s | %s |\n", markdownCell(r.workspace), e.Action, markdownCell(e.Asis), markdownCell(e.Tobe), markdownCell(e.Source))
+		}
+	}
+	b.WriteString("\n")
+	g.appendSummary(b.String())
+}
+
+// results adds the run report to the job summary and sets the outputs to
+// the entries left pending and those that failed.
+func (g *githubActions) results(r runReport) {
+	if g == nil {
+		return
+	}
+	var b strings.Builder
+	if err := r.writeMarkdown(&b); err != nil {
+		slog.Warn("failed to write the job summary", "err", err)
+	}
+	b.WriteString("\n")
+	g.appendSummary(b.String())
+	g.setOutputs(r.Count(resultPending), r.Count(resultFailed))
+}
+
+func (g *githubActions) setOutputs(pending, failed int) {
+	g.appendFile(g.output, fmt.Sprintf("pending_count=%d\nfailed_count=%d\n", pending, failed))
+}
+
+func (g *githubActions) appendSummary(markdown string) {
+	g.appendFile(g.summary, markdown)
+}
+
+// appendFile appends s to the job file path, if the job has one. Failures are
+// logged and do not fail the command.
+func (g *githubActions) appendFile(path, s string) {
+	if path == "" {
+		return
+	}
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o644)
+	if err == nil {
+		_, err = f.WriteString(s)
+		err = cmp.Or(err, f.Close())
+	}
+	if err != nil {
+		slog.Warn("failed to write to the GitHub Actions job file", "file", path, "err", err)
+	}
+}
+
+// escapeData escapes the message of a workflow command.
+func escapeData(s string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A").Replace(s)
+}
+
+// escapeProperty escapes a property value of a workflow command.
+func escapeProperty(s string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A", ":", "%3A", ",", "%2C").Replace(s)
+}
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestGithubActions(t *testing.T) {
+	dir := t.TempDir()
+	var annotations strings.Builder
+	g := &githubActions{annotations: &annotations, summary: filepath.Join(dir, "summary.md"), output: filepath.Join(dir, "output")}
+	runs := []workspaceRun{{session: &session{}, plan: []planEntry{{Action: actionRename, Asis: "a|b", Tobe: "ab", Source: "channel_mapping.csv:2"}}}}
+
+	g.validation([]string{"workspace acme: channel_mapping.csv:3: tobe \"Bad,Name\" is not valid\n100%", "no token"}, runs)
+	want := "::error file=channel_mapping.csv,line=3::tobe \"Bad,Name\" is not valid%0A100%25\n::error::no token\n"
+	if annotations.String() != want {
+		t.Errorf("annotations = %q, want %q", annotations.String(), want)
+	}
+	g.validation(nil, runs)
+	g.results(runReport{Results: []entryResult{{Action: actionRename, Asis: "a|b", Tobe: "ab", Status: resultFailed, Error: "name_taken"}}})
+
+	b, err := os.ReadFile(g.output)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := string(b); !strings.HasSuffix(got, "pending_count=0\nfailed_count=1\n") || !strings.HasPrefix(got, "pending_count=1\nfailed_count=2\n") {
+		t.Errorf("outputs = %q", got)
+	}
+	b, err = os.ReadFile(g.summary)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range []string{"| a\\|b | ab | channel_mapping.csv:2 |", "name_taken"} {
+		if !strings.Contains(string(b), s) {
+			t.Errorf("summary does not contain %q:\n%s", s, b)
+		}
+	}
+}
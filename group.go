@@ -0,0 +1,99 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/kiddikn/slack-channel-renamer/plan"
+	"github.com/kiddikn/slack-channel-renamer/runner"
+)
+
+// noOwner labels the group of entries whose owner column is empty.
+const noOwner = "(no owner)"
+
+type ownerGroup struct {
+	owner   string
+	entries []planEntry
+}
+
+// groupByOwner partitions entries by owner, keeping groups in the order their
+// owner first appears in the plan and entries in plan order within a group.
+// The entries of a swap or chain of renames (see plan.Units) stay together, in
+// the group of the unit's first entry, so that declining one owner's group
+// cannot leave another owner's channel under a temporary name.
+func groupByOwner(entries []planEntry) []ownerGroup {
+	var groups []ownerGroup
+	index := make(map[string]int)
+	units := plan.Units(entries)
+	for i, e := range entries {
+		owner := entries[units[i]].Owner
+		if owner == "" {
+			owner = noOwner
+		}
+		i, ok := index[owner]
+		if !ok {
+			i = len(groups)
+			index[owner] = i
+			groups = append(groups, ownerGroup{owner: owner})
+		}
+		groups[i].entries = append(groups[i].entries, e)
+	}
+	return groups
+}
+
+func printGroupPlan(g ownerGroup) {
+	fmt.Printf("plan for %s (%d):\n", g.owner, len(g.entries))
+	for _, entry := range g.entries {
+		printEntry(entry)
+	}
+}
+
+// errGroupDeclined is the results-file error of entries in a declined group.
+var errGroupDeclined = errors.New("group declined")
+
+// applyByGroup applies the plan one owner group at a time. Each group's plan is
+// printed and must be confirmed on in before it is applied; declined groups are
+// counted as skipped. It returns the total number of failures.
+func applyByGroup(ctx context.Context, x *executor, channels map[string]channelInfo, entries []planEntry, in *bufio.Reader) int {
+	failures := 0
+	for _, g := range groupByOwner(entries) {
+		if ctx.Err() != nil {
+			x.Pending = append(x.Pending, g.entries...)
+			x.Results = append(x.Results, runner.PendingResults(g.entries, channels)...)
+			continue
+		}
+		printGroupPlan(g)
+		if !confirm(in, fmt.Sprintf("apply %d changes for %s? [y/N]: ", len(g.entries), g.owner)) {
+			fmt.Printf("group %s: skipped\n", g.owner)
+			x.Stats.Skipped += len(g.entries)
+			for _, e := range g.entries {
+				r := runner.NewResult(e, channels[e.Asis], resultSkipped, errGroupDeclined, time.Time{}, time.Time{})
+				x.Results = append(x.Results, r)
+				live.entry(r)
+				auditLog.entry(r)
+			}
+			continue
+		}
+		pending := len(x.Pending)
+		n := x.applyEntries(ctx, channels, g.entries)
+		pending = len(x.Pending) - pending
+		fmt.Printf("group %s: %d succeeded, %d failed\n", g.owner, len(g.entries)-n-pending, n)
+		failures += n
+	}
+	return failures
+}
+
+// confirm prints prompt and reports whether the next line read from in is "y" or "yes".
+func confirm(in *bufio.Reader, prompt string) bool {
+	fmt.Print(prompt)
+	line, _ := in.ReadString('\n')
+	switch strings.ToLower(strings.TrimSpace(line)) {
+	case "y", "yes":
+		return true
+	}
+	return false
+}
@@ -0,0 +1,305 @@
+package main
+
+import (
+	"cmp"
+	"context"
+	"strings"
+	"time"
+
+	"github.com/kiddikn/slack-channel-renamer/plan"
+	"github.com/kiddikn/slack-channel-renamer/renamerpb"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// watchRunInterval is how often WatchRun looks at a run for changes.
+var watchRunInterval = time.Second
+
+// grpcServer serves renamerpb.RenamerService from the plans and runs of a
+// server: the same operations as its HTTP API, with the same validation and
+// runs behind them.
+type grpcServer struct {
+	renamerpb.UnimplementedRenamerServiceServer
+	s *server
+}
+
+// grpcServer returns the gRPC server of 'serve -grpc-addr', with the
+// standard health service, which needs no token, next to RenamerService.
+func (s *server) grpcServer() *grpc.Server {
+	g := grpc.NewServer(
+		// Leave room for the rest of the message around the plan.
+		grpc.MaxRecvMsgSize(maxPlanUpload+1<<20),
+		grpc.UnaryInterceptor(func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+			if err := s.grpcAuthorized(ctx, info.FullMethod); err != nil {
+				return nil, err
+			}
+			return handler(ctx, req)
+		}),
+		grpc.StreamInterceptor(func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+			if err := s.grpcAuthorized(ss.Context(), info.FullMethod); err != nil {
+				return err
+			}
+			return handler(srv, ss)
+		}),
+	)
+	renamerpb.RegisterRenamerServiceServer(g, grpcServer{s: s})
+	healthpb.RegisterHealthServer(g, health.NewServer())
+	return g
+}
+
+// grpcAuthorized rejects calls that do not carry the API token as bearer
+// token metadata, as the HTTP API does, except health checks.
+func (s *server) grpcAuthorized(ctx context.Context, method string) error {
+	if strings.HasPrefix(method, "/"+healthpb.Health_ServiceDesc.ServiceName+"/") {
+		return nil
+	}
+	md, _ := metadata.FromIncomingContext(ctx)
+	for _, auth := range md.Get("authorization") {
+		if s.bearer(auth) {
+			return nil
+		}
+	}
+	return status.Error(codes.Unauthenticated, errUnauthorized.Error())
+}
+
+func (g grpcServer) UploadPlan(_ context.Context, req *renamerpb.UploadPlanRequest) (*renamerpb.UploadPlanResponse, error) {
+	name := cmp.Or(req.GetName(), defaultUploadName)
+	load, err := planInput{format: req.GetFormat()}.loaderFor(name)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if len(req.GetContent()) > maxPlanUpload {
+		return nil, status.Errorf(codes.ResourceExhausted, "the plan is larger than %d bytes", maxPlanUpload)
+	}
+	entries, err := load(name, req.GetContent(), plan.Options{})
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	p := g.s.addPlan(name, entries)
+	g.s.mu.Lock()
+	defer g.s.mu.Unlock()
+	return &renamerpb.UploadPlanResponse{Plan: planMessage(p)}, nil
+}
+
+func (g grpcServer) ListPlans(context.Context, *renamerpb.ListPlansRequest) (*renamerpb.ListPlansResponse, error) {
+	g.s.mu.Lock()
+	defer g.s.mu.Unlock()
+	resp := &renamerpb.ListPlansResponse{}
+	for _, p := range g.s.sortedPlans() {
+		resp.Plans = append(resp.Plans, planMessage(p))
+	}
+	return resp, nil
+}
+
+func (g grpcServer) GetPlan(_ context.Context, req *renamerpb.GetPlanRequest) (*renamerpb.GetPlanResponse, error) {
+	p, err := g.s.planByID(req.GetPlanId())
+	if err != nil {
+		return nil, status.Error(codes.NotFound, err.Error())
+	}
+	g.s.mu.Lock()
+	defer g.s.mu.Unlock()
+	return &renamerpb.GetPlanResponse{Plan: planMessage(p)}, nil
+}
+
+func (g grpcServer) ValidatePlan(ctx context.Context, req *renamerpb.ValidatePlanRequest) (*renamerpb.ValidatePlanResponse, error) {
+	p, err := g.s.planByID(req.GetPlanId())
+	if err != nil {
+		return nil, status.Error(codes.NotFound, err.Error())
+	}
+	if err := g.s.canValidate(); err != nil {
+		return nil, status.Error(codes.FailedPrecondition, err.Error())
+	}
+	runs, v, err := g.s.validate(p)
+	if err != nil {
+		return nil, status.Error(codes.Unavailable, err.Error())
+	}
+	if req.GetStats() {
+		addChannelStats(ctx, runs)
+	}
+	resp := &renamerpb.ValidatePlanResponse{Validation: validationMessage(v)}
+	for _, e := range planReport(runs) {
+		resp.Plan = append(resp.Plan, planEntryMessage(e))
+	}
+	return resp, nil
+}
+
+func (g grpcServer) ApplyPlan(_ context.Context, req *renamerpb.ApplyPlanRequest) (*renamerpb.ApplyPlanResponse, error) {
+	p, err := g.s.planByID(req.GetPlanId())
+	if err != nil {
+		return nil, status.Error(codes.NotFound, err.Error())
+	}
+	run, err := g.s.start(p)
+	if err != nil {
+		return nil, status.Error(codes.FailedPrecondition, err.Error())
+	}
+	g.s.mu.Lock()
+	defer g.s.mu.Unlock()
+	return &renamerpb.ApplyPlanResponse{Run: runMessage(g.s.runStatus(run))}, nil
+}
+
+func (g grpcServer) ListRuns(context.Context, *renamerpb.ListRunsRequest) (*renamerpb.ListRunsResponse, error) {
+	g.s.mu.Lock()
+	defer g.s.mu.Unlock()
+	resp := &renamerpb.ListRunsResponse{}
+	for _, run := range g.s.runStatuses() {
+		resp.Runs = append(resp.Runs, runMessage(run))
+	}
+	return resp, nil
+}
+
+func (g grpcServer) GetRun(_ context.Context, req *renamerpb.GetRunRequest) (*renamerpb.GetRunResponse, error) {
+	run, _, err := g.run(req.GetRunId())
+	if err != nil {
+		return nil, err
+	}
+	return &renamerpb.GetRunResponse{Run: run}, nil
+}
+
+func (g grpcServer) WatchRun(req *renamerpb.WatchRunRequest, stream grpc.ServerStreamingServer[renamerpb.WatchRunResponse]) error {
+	tick := time.NewTicker(watchRunInterval)
+	defer tick.Stop()
+	var last *renamerpb.Run
+	for {
+		run, done, err := g.run(req.GetRunId())
+		if err != nil {
+			return err
+		}
+		if !proto.Equal(run, last) {
+			if err := stream.Send(&renamerpb.WatchRunResponse{Run: run}); err != nil {
+				return err
+			}
+			last = run
+		}
+		if run.GetFinished() != nil {
+			return nil
+		}
+		select {
+		case <-stream.Context().Done():
+			return stream.Context().Err()
+		case <-done:
+		case <-tick.C:
+		}
+	}
+}
+
+// run returns the status of the run with the ID, as GetRun does, and a
+// channel closed once it finishes.
+func (g grpcServer) run(id string) (*renamerpb.Run, <-chan struct{}, error) {
+	g.s.mu.Lock()
+	defer g.s.mu.Unlock()
+	run, err := g.s.runByID(id)
+	if err != nil {
+		return nil, nil, status.Error(codes.NotFound, err.Error())
+	}
+	return runMessage(g.s.runStatus(run)), run.done, nil
+}
+
+func (g grpcServer) GetRunResults(_ context.Context, req *renamerpb.GetRunResultsRequest) (*renamerpb.GetRunResultsResponse, error) {
+	g.s.mu.Lock()
+	defer g.s.mu.Unlock()
+	run, err := g.s.runByID(req.GetRunId())
+	if err != nil {
+		return nil, status.Error(codes.NotFound, err.Error())
+	}
+	resp := &renamerpb.GetRunResultsResponse{}
+	for _, r := range run.Results {
+		resp.Results = append(resp.Results, entryResultMessage(r))
+	}
+	return resp, nil
+}
+
+// timestamp converts t, leaving out the zero time as JSON's omitzero does.
+func timestamp(t time.Time) *timestamppb.Timestamp {
+	if t.IsZero() {
+		return nil
+	}
+	return timestamppb.New(t)
+}
+
+// planMessage converts p. The caller holds the server's mu.
+func planMessage(p *servedPlan) *renamerpb.Plan {
+	return &renamerpb.Plan{Id: p.ID, Name: p.Name, Uploaded: timestamp(p.Uploaded), Entries: int32(p.Entries), Validation: validationMessage(p.Validation)}
+}
+
+func validationMessage(v *validationReport) *renamerpb.Validation {
+	if v == nil {
+		return nil
+	}
+	return &renamerpb.Validation{Passed: v.Passed, Errors: v.Errors, Skipped: v.Skipped}
+}
+
+func planEntryMessage(e planReportEntry) *renamerpb.PlanEntry {
+	m := &renamerpb.PlanEntry{
+		Action:       e.Action,
+		ChannelId:    e.ChannelID,
+		Asis:         e.Asis,
+		Tobe:         e.Tobe,
+		Owner:        e.Owner,
+		Topic:        e.Topic,
+		Purpose:      e.Purpose,
+		Private:      e.Private,
+		Members:      e.Members,
+		Workspace:    e.Workspace,
+		State:        e.State,
+		Rearchive:    e.Rearchive,
+		NotBefore:    timestamp(e.NotBefore),
+		ChannelTeam:  e.ChannelTeam,
+		Shared:       e.Shared,
+		PinnedNote:   e.PinnedNote,
+		Plan:         e.Plan,
+		Source:       e.Source,
+		NeedsConfirm: e.NeedsConfirm,
+	}
+	if st := e.Stats; st != nil {
+		m.Stats = &renamerpb.ChannelStats{Members: int32(st.Members), Creator: st.Creator, Created: timestamp(st.Created), LastActivity: timestamp(st.LastActivity)}
+	}
+	return m
+}
+
+func runMessage(r servedRun) *renamerpb.Run {
+	m := &renamerpb.Run{
+		Id:           r.ID,
+		Plan:         r.Plan,
+		Status:       r.Status,
+		Started:      timestamp(r.Started),
+		Finished:     timestamp(r.Finished),
+		Entries:      int32(r.Entries),
+		Error:        r.Error,
+		RollbackFile: r.RollbackFile,
+		Summary: &renamerpb.RunSummary{
+			Succeeded:   int32(r.Summary.Succeeded),
+			Failed:      int32(r.Summary.Failed),
+			Skipped:     int32(r.Summary.Skipped),
+			NotStarted:  int32(r.Summary.NotStarted),
+			Interrupted: r.Summary.Interrupted,
+		},
+	}
+	if p := r.Progress; p != nil {
+		m.Progress = &renamerpb.RunProgress{Done: int32(p.Done), Failed: int32(p.Failed), Total: int32(p.Total), Eta: p.ETA, LastError: p.LastError}
+	}
+	return m
+}
+
+func entryResultMessage(r entryResult) *renamerpb.EntryResult {
+	return &renamerpb.EntryResult{
+		Source:      r.Source,
+		Workspace:   r.Workspace,
+		Action:      r.Action,
+		Asis:        r.Asis,
+		Tobe:        r.Tobe,
+		Status:      r.Status,
+		Error:       r.Error,
+		Started:     timestamp(r.Started),
+		Finished:    timestamp(r.Finished),
+		ChannelId:   r.ChannelID,
+		ChannelName: r.ChannelName,
+		Rearchived:  r.Rearchived,
+		Edits:       r.Edits,
+	}
+}
@@ -0,0 +1,168 @@
+package main
+
+import (
+	"context"
+	"net"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"testing"
+
+	"github.com/kiddikn/slack-channel-renamer/plan"
+	"github.com/kiddikn/slack-channel-renamer/renamerpb"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/proto"
+)
+
+// dialGRPC serves s's gRPC API in memory and returns a client of it.
+func dialGRPC(t *testing.T, s *server) (renamerpb.RenamerServiceClient, *grpc.ClientConn) {
+	t.Helper()
+	lis := bufconn.Listen(1 << 20)
+	g := s.grpcServer()
+	go g.Serve(lis)
+	t.Cleanup(g.Stop)
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return renamerpb.NewRenamerServiceClient(conn), conn
+}
+
+func TestGRPCAuthorization(t *testing.T) {
+	c, conn := dialGRPC(t, newTestServer())
+	for _, tc := range []struct {
+		name, token string
+		want        codes.Code
+	}{
+		{"no token", "", codes.Unauthenticated},
+		{"wrong token", "guess", codes.Unauthenticated},
+		{"right token", "secret", codes.OK},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := t.Context()
+			if tc.token != "" {
+				ctx = metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer "+tc.token)
+			}
+			if _, err := c.ListPlans(ctx, &renamerpb.ListPlansRequest{}); status.Code(err) != tc.want {
+				t.Errorf("ListPlans: %v, want %v", err, tc.want)
+			}
+		})
+	}
+	resp, err := healthpb.NewHealthClient(conn).Check(t.Context(), &healthpb.HealthCheckRequest{})
+	if err != nil || resp.GetStatus() != healthpb.HealthCheckResponse_SERVING {
+		t.Errorf("health check without a token: %v %v", resp, err)
+	}
+}
+
+func TestGRPCErrors(t *testing.T) {
+	c, _ := dialGRPC(t, newTestServer())
+	ctx := metadata.AppendToOutgoingContext(t.Context(), "authorization", "Bearer secret")
+	if _, err := c.UploadPlan(ctx, &renamerpb.UploadPlanRequest{Format: "toml", Content: []byte("asis,tobe\n")}); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("upload of an unknown format: %v", err)
+	}
+	if _, err := c.GetPlan(ctx, &renamerpb.GetPlanRequest{PlanId: "nope"}); status.Code(err) != codes.NotFound {
+		t.Errorf("GetPlan of an unknown plan: %v", err)
+	}
+	if _, err := c.GetRunResults(ctx, &renamerpb.GetRunResultsRequest{RunId: "nope"}); status.Code(err) != codes.NotFound {
+		t.Errorf("GetRunResults of an unknown run: %v", err)
+	}
+}
+
+func TestGRPCApply(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "snapshot.json")
+	if err := os.WriteFile(path, []byte(`[{"name":"old-a","id":"C1","members":42}]`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	s := newTestServer()
+	s.ws = workspaceOptions{simulate: path}
+	s.run = runOptions{verb: "rename", pool: poolOptions{concurrency: 1, perMinute: 60}}
+	c, _ := dialGRPC(t, s)
+	ctx := metadata.AppendToOutgoingContext(t.Context(), "authorization", "Bearer secret")
+
+	up, err := c.UploadPlan(ctx, &renamerpb.UploadPlanRequest{Name: "teams.csv", Content: []byte("asis,tobe\nold-a,new-a\n")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p := up.GetPlan(); p.GetId() == "" || p.GetName() != "teams.csv" || p.GetEntries() != 1 {
+		t.Fatalf("UploadPlan returned %v", p)
+	}
+	id := up.GetPlan().GetId()
+
+	v, err := c.ValidatePlan(ctx, &renamerpb.ValidatePlanRequest{PlanId: id, Stats: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !v.GetValidation().GetPassed() || len(v.GetPlan()) != 1 || v.GetPlan()[0].GetStats().GetMembers() != 42 {
+		t.Fatalf("ValidatePlan returned %v", v)
+	}
+
+	applied, err := c.ApplyPlan(ctx, &renamerpb.ApplyPlanRequest{PlanId: id})
+	if err != nil {
+		t.Fatal(err)
+	}
+	stream, err := c.WatchRun(ctx, &renamerpb.WatchRunRequest{RunId: applied.GetRun().GetId()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var last *renamerpb.Run
+	for {
+		resp, err := stream.Recv()
+		if err != nil {
+			break
+		}
+		last = resp.GetRun()
+	}
+	if last.GetFinished() == nil || last.GetStatus() != runSucceeded || last.GetSummary().GetSucceeded() != 1 {
+		t.Fatalf("WatchRun ended with %v", last)
+	}
+
+	res, err := c.GetRunResults(ctx, &renamerpb.GetRunResultsRequest{RunId: last.GetId()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r := res.GetResults(); len(r) != 1 || r[0].GetStatus() != resultOK || r[0].GetTobe() != "new-a" {
+		t.Errorf("GetRunResults returned %v", r)
+	}
+}
+
+// TestGRPCMessages checks that the messages have the fields of the JSON
+// the HTTP API returns, under the same names. A run's results are left to
+// GetRunResults, as GET /v1/runs/{id} leaves them to its results endpoint.
+func TestGRPCMessages(t *testing.T) {
+	for _, tc := range []struct {
+		msg  proto.Message
+		v    any
+		omit []string
+	}{
+		{&renamerpb.Validation{}, validationReport{}, nil},
+		{&renamerpb.Plan{}, servedPlan{}, nil},
+		{&renamerpb.PlanEntry{}, planReportEntry{}, nil},
+		{&renamerpb.ChannelStats{}, plan.ChannelStats{}, nil},
+		{&renamerpb.Run{}, servedRun{}, []string{"results"}},
+		{&renamerpb.RunSummary{}, runSummary{}, nil},
+		{&renamerpb.RunProgress{}, runProgress{}, nil},
+		{&renamerpb.EntryResult{}, entryResult{}, nil},
+	} {
+		var got []string
+		fields := tc.msg.ProtoReflect().Descriptor().Fields()
+		for i := range fields.Len() {
+			got = append(got, string(fields.Get(i).Name()))
+		}
+		want := slices.DeleteFunc(jsonFields(reflect.TypeOf(tc.v)), func(name string) bool { return slices.Contains(tc.omit, name) })
+		slices.Sort(got)
+		slices.Sort(want)
+		if !slices.Equal(got, want) {
+			t.Errorf("%s fields = %v, want %v", tc.msg.ProtoReflect().Descriptor().FullName(), got, want)
+		}
+	}
+}
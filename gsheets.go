@@ -0,0 +1,114 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"regexp"
+	"strconv"
+
+	"github.com/kiddikn/slack-channel-renamer/plan"
+	"github.com/kiddikn/slack-channel-renamer/slackops"
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/google"
+)
+
+const (
+	sheetsScope        = "https://www.googleapis.com/auth/spreadsheets.readonly"
+	sheetsValuesURL    = "https://sheets.googleapis.com/v4/spreadsheets/%s/values/%s"
+	defaultSheetsRange = "A:Z"
+)
+
+// sheetSource identifies a Google Sheet to read the plan from.
+type sheetSource struct {
+	id          string
+	rng         string
+	credentials string
+}
+
+// sheetRangeStartRe extracts the first row number from an A1 range such as "Plan!A1:C20".
+var sheetRangeStartRe = regexp.MustCompile(`![A-Z]*(\d+)`)
+
+// loadGoogleSheet reads a rename plan from a Google Sheet through the Sheets API.
+// Credentials come from the service-account key file named by src.credentials,
+// or from Application Default Credentials (GOOGLE_APPLICATION_CREDENTIALS) when
+// it is empty. The range uses the same layout as the CSV.
+func loadGoogleSheet(src sheetSource) ([]planEntry, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), slackops.Retry.Timeout)
+	defer cancel()
+	ctx = context.WithValue(ctx, oauth2.HTTPClient, netClient())
+
+	var client *http.Client
+	if src.credentials != "" {
+		key, err := os.ReadFile(src.credentials)
+		if err != nil {
+			return nil, fmt.Errorf("read credentials: %w", err)
+		}
+		cfg, err := google.JWTConfigFromJSON(key, sheetsScope)
+		if err != nil {
+			return nil, fmt.Errorf("parse credentials %q: %w", src.credentials, err)
+		}
+		client = cfg.Client(ctx)
+	} else {
+		var err error
+		client, err = google.DefaultClient(ctx, sheetsScope)
+		if err != nil {
+			return nil, fmt.Errorf("find Google credentials: %w", err)
+		}
+	}
+
+	rng := src.rng
+	if rng == "" {
+		rng = defaultSheetsRange
+	}
+	u := fmt.Sprintf(sheetsValuesURL, url.PathEscape(src.id), url.PathEscape(rng))
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u+"?majorDimension=ROWS", nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("fetch sheet %s: %w", src.id, err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("read sheet %s: %w", src.id, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("fetch sheet %s: %s: %s", src.id, resp.Status, body)
+	}
+
+	var vr struct {
+		Range  string     `json:"range"`
+		Values [][]string `json:"values"`
+	}
+	if err := json.Unmarshal(body, &vr); err != nil {
+		return nil, fmt.Errorf("parse sheet %s: %w", src.id, err)
+	}
+
+	first := 1
+	if m := sheetRangeStartRe.FindStringSubmatch(vr.Range); m != nil {
+		first, _ = strconv.Atoi(m[1])
+	}
+	var records [][]string
+	var lines []int
+	for i, row := range vr.Values {
+		if len(row) == 0 {
+			continue
+		}
+		// Trailing blank cells are omitted by the API, so pad to the header width.
+		if len(records) > 0 {
+			for len(row) < len(records[0]) {
+				row = append(row, "")
+			}
+		}
+		records = append(records, row)
+		lines = append(lines, first+i)
+	}
+	return plan.ParseRows("sheet "+vr.Range, records, lines)
+}
@@ -0,0 +1,324 @@
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// defaultHistoryDB is where apply, rollback and history keep the rename history.
+const defaultHistoryDB = "rename-history.db"
+
+var (
+	historyRunsBucket    = []byte("runs")
+	historyChangesBucket = []byte("changes")
+)
+
+// historyStore is the embedded bbolt database recording every change made to
+// a channel. Runs are keyed by a sequence number; changes are keyed by their
+// run number followed by their own sequence number, so a run's changes are
+// stored together and in order.
+type historyStore struct {
+	db *bolt.DB
+}
+
+// historyRun is one apply, rollback or revert invocation.
+type historyRun struct {
+	ID     uint64    `json:"id"`
+	Start  time.Time `json:"start"`
+	Source string    `json:"source"`
+}
+
+// historyChange is one channel change made during a run.
+type historyChange struct {
+	Run       uint64    `json:"run"`
+	Time      time.Time `json:"time"`
+	Actor     string    `json:"actor,omitempty"`
+	Workspace string    `json:"workspace,omitempty"`
+	ChannelID string    `json:"channel_id"`
+	Action    string    `json:"action"`
+	OldName   string    `json:"old_name"`
+	NewName   string    `json:"new_name,omitempty"`
+}
+
+func openHistory(path string) (*historyStore, error) {
+	db, err := bolt.Open(path, 0o644, &bolt.Options{Timeout: time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("open history %q: %w", path, err)
+	}
+	err = db.Update(func(tx *bolt.Tx) error {
+		for _, name := range [][]byte{historyRunsBucket, historyChangesBucket} {
+			if _, err := tx.CreateBucketIfNotExists(name); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("initialise history %q: %w", path, err)
+	}
+	return &historyStore{db: db}, nil
+}
+
+func (h *historyStore) Close() error {
+	return h.db.Close()
+}
+
+func historyKey(ids ...uint64) []byte {
+	b := make([]byte, 0, 8*len(ids))
+	for _, id := range ids {
+		b = binary.BigEndian.AppendUint64(b, id)
+	}
+	return b
+}
+
+// startRun records the start of a run and returns it.
+func (h *historyStore) startRun(source string) (historyRun, error) {
+	run := historyRun{Start: time.Now().UTC().Truncate(time.Second), Source: source}
+	err := h.db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket(historyRunsBucket)
+		id, err := b.NextSequence()
+		if err != nil {
+			return err
+		}
+		run.ID = id
+		v, err := json.Marshal(run)
+		if err != nil {
+			return err
+		}
+		return b.Put(historyKey(id), v)
+	})
+	if err != nil {
+		return historyRun{}, fmt.Errorf("record run: %w", err)
+	}
+	return run, nil
+}
+
+// record stores one change. Each change is committed on its own so the
+// history survives a run that is interrupted halfway.
+func (h *historyStore) record(c historyChange) error {
+	err := h.db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket(historyChangesBucket)
+		seq, err := b.NextSequence()
+		if err != nil {
+			return err
+		}
+		v, err := json.Marshal(c)
+		if err != nil {
+			return err
+		}
+		return b.Put(historyKey(c.Run, seq), v)
+	})
+	if err != nil {
+		return fmt.Errorf("record change to %s: %w", c.ChannelID, err)
+	}
+	return nil
+}
+
+// runs returns every recorded run, oldest first.
+func (h *historyStore) runs() ([]historyRun, error) {
+	var runs []historyRun
+	err := h.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(historyRunsBucket).ForEach(func(_, v []byte) error {
+			var r historyRun
+			if err := json.Unmarshal(v, &r); err != nil {
+				return err
+			}
+			runs = append(runs, r)
+			return nil
+		})
+	})
+	return runs, err
+}
+
+// run returns the run with the given ID.
+func (h *historyStore) run(id uint64) (historyRun, error) {
+	var r historyRun
+	err := h.db.View(func(tx *bolt.Tx) error {
+		v := tx.Bucket(historyRunsBucket).Get(historyKey(id))
+		if v == nil {
+			return fmt.Errorf("no run %d in history", id)
+		}
+		return json.Unmarshal(v, &r)
+	})
+	return r, err
+}
+
+// changes returns the changes for which keep returns true, in the order they were made.
+func (h *historyStore) changes(keep func(historyChange) bool) ([]historyChange, error) {
+	var changes []historyChange
+	err := h.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(historyChangesBucket).ForEach(func(_, v []byte) error {
+			var c historyChange
+			if err := json.Unmarshal(v, &c); err != nil {
+				return err
+			}
+			if keep(c) {
+				changes = append(changes, c)
+			}
+			return nil
+		})
+	})
+	return changes, err
+}
+
+// runChanges returns the changes made by one run, in order.
+func (h *historyStore) runChanges(id uint64) ([]historyChange, error) {
+	var changes []historyChange
+	prefix := historyKey(id)
+	err := h.db.View(func(tx *bolt.Tx) error {
+		c := tx.Bucket(historyChangesBucket).Cursor()
+		for k, v := c.Seek(prefix); k != nil && bytes.HasPrefix(k, prefix); k, v = c.Next() {
+			var ch historyChange
+			if err := json.Unmarshal(v, &ch); err != nil {
+				return err
+			}
+			changes = append(changes, ch)
+		}
+		return nil
+	})
+	return changes, err
+}
+
+// entry turns a recorded change back into the resolved plan entry that made it.
+func (c historyChange) entry() planEntry {
+	return planEntry{
+		Action:    c.Action,
+		Asis:      c.OldName,
+		Tobe:      c.NewName,
+		ChannelID: c.ChannelID,
+		Workspace: c.Workspace,
+		Source:    fmt.Sprintf("run %d", c.Run),
+	}
+}
+
+// historyRecorder records the changes one session makes during a run.
+type historyRecorder struct {
+	store     *historyStore
+	run       uint64
+	actor     string
+	workspace string
+}
+
+func (r *historyRecorder) record(ch channelInfo, entry planEntry) error {
+	if r == nil {
+		return nil
+	}
+	return r.store.record(historyChange{
+		Run:       r.run,
+		Time:      time.Now().UTC(),
+		Actor:     r.actor,
+		Workspace: r.workspace,
+		ChannelID: ch.ID,
+		Action:    entry.Action,
+		OldName:   entry.Asis,
+		NewName:   entry.Tobe,
+	})
+}
+
+// printHistoryRuns writes a table of every recorded run and its number of changes.
+func printHistoryRuns(w io.Writer, h *historyStore) error {
+	runs, err := h.runs()
+	if err != nil {
+		return err
+	}
+	if output == outputJSON {
+		report.Runs = runs
+		return nil
+	}
+	counts := make(map[uint64]int)
+	if _, err := h.changes(func(c historyChange) bool { counts[c.Run]++; return false }); err != nil {
+		return err
+	}
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "RUN\tSTARTED\tCHANGES\tSOURCE")
+	for _, r := range runs {
+		fmt.Fprintf(tw, "%d\t%s\t%d\t%s\n", r.ID, r.Start.Format(time.RFC3339), counts[r.ID], r.Source)
+	}
+	return tw.Flush()
+}
+
+// printChannelHistory writes every recorded change to the channel with the
+// given ID, or that had the given name before or after the change.
+func printChannelHistory(w io.Writer, h *historyStore, channel string) error {
+	changes, err := h.changes(func(c historyChange) bool {
+		return c.ChannelID == channel || c.OldName == channel || c.NewName == channel
+	})
+	if err != nil {
+		return err
+	}
+	if output == outputJSON {
+		report.Changes = changes
+		return nil
+	}
+	if len(changes) == 0 {
+		fmt.Fprintf(w, "no recorded changes to %s\n", channel)
+		return nil
+	}
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "TIME\tRUN\tACTOR\tWORKSPACE\tCHANNEL\tCHANGE")
+	for _, c := range changes {
+		fmt.Fprintf(tw, "%s\t%d\t%s\t%s\t%s\t%s\n", c.Time.Format(time.RFC3339), c.Run, c.Actor, c.Workspace, c.ChannelID, c.entry())
+	}
+	return tw.Flush()
+}
+
+// renameHistory holds the recorded renames of each channel, keyed by
+// workspace and channel ID, so that validation can recognise plan rows an
+// earlier run already carried out.
+type renameHistory map[string][]historyChange
+
+// loadRenameHistory reads the renames recorded in the history database at
+// path. A missing database, or an empty path, is an empty history.
+func loadRenameHistory(path string) (renameHistory, error) {
+	if path == "" {
+		return nil, nil
+	}
+	if _, err := os.Stat(path); errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	h, err := openHistory(path)
+	if err != nil {
+		return nil, err
+	}
+	defer h.Close()
+	past := make(renameHistory)
+	_, err = h.changes(func(c historyChange) bool {
+		if c.Action == actionRename {
+			key := c.Workspace + "\x00" + c.ChannelID
+			past[key] = append(past[key], c)
+		}
+		return false
+	})
+	if err != nil {
+		return nil, fmt.Errorf("read history %q: %w", path, err)
+	}
+	return past, nil
+}
+
+// Renamed reports whether the history shows e's rename as done: ch, the
+// channel now named e.Tobe, was renamed away from e.Asis and its latest
+// recorded rename was to e.Tobe. Renames routed through a temporary name
+// count as well.
+func (past renameHistory) Renamed(e planEntry, ch channelInfo) bool {
+	changes := past[e.Workspace+"\x00"+ch.ID]
+	if len(changes) == 0 || changes[len(changes)-1].NewName != e.Tobe {
+		return false
+	}
+	for _, c := range changes {
+		if c.OldName == e.Asis {
+			return true
+		}
+	}
+	return false
+}
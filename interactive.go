@@ -0,0 +1,144 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/kiddikn/slack-channel-renamer/plan"
+	"github.com/kiddikn/slack-channel-renamer/runner"
+)
+
+// errDeclined is the results-file error of entries declined with -interactive.
+var errDeclined = errors.New("declined")
+
+// rowPrompter asks before each row in -interactive mode. Its answers to
+// "all" and "quit" carry over to the remaining rows of every workspace.
+type rowPrompter struct {
+	in   *bufio.Reader
+	all  bool
+	quit bool
+}
+
+// ask prompts for one row and returns "y", "n", "a" or "q". End of input
+// counts as "q".
+func (p *rowPrompter) ask(row string) string {
+	for {
+		fmt.Printf("%s? [y/n/a/q]: ", row)
+		line, err := p.in.ReadString('\n')
+		switch answer := strings.ToLower(strings.TrimSpace(line)); answer {
+		case "y", "yes":
+			return "y"
+		case "n", "no":
+			return "n"
+		case "a", "all":
+			return "a"
+		case "q", "quit":
+			return "q"
+		}
+		if err != nil {
+			fmt.Println()
+			return "q"
+		}
+		fmt.Println("answer y (apply), n (skip), a (apply this and all remaining) or q (skip all remaining)")
+	}
+}
+
+// applyInteractively applies entries one plan row at a time, asking before
+// each row. Entries read from the same row, such as the two halves of a
+// rename through a temporary name, follow the answer given for the first, and
+// the rows of a swap or chain of renames are asked about together (see
+// plan.Units). Declined rows are counted as skipped. It returns the number of
+// failures.
+func applyInteractively(ctx context.Context, x *executor, channels map[string]channelInfo, entries []planEntry, p *rowPrompter) int {
+	units := plan.Units(entries)
+	approved := make(map[int]bool)
+	failures := 0
+	for i, e := range entries {
+		if ctx.Err() != nil {
+			x.Pending = append(x.Pending, entries[i:]...)
+			x.Results = append(x.Results, runner.PendingResults(entries[i:], channels)...)
+			break
+		}
+		ok, answered := approved[units[i]]
+		if !answered && !p.all && !p.quit {
+			switch p.ask(unitSummary(entries, units, units[i])) {
+			case "y":
+				ok = true
+			case "a":
+				p.all = true
+			case "q":
+				p.quit = true
+			}
+		}
+		if !answered && p.all {
+			// Apply this and every remaining entry whose row was not declined.
+			var rest []planEntry
+			for j, e := range entries[i:] {
+				if ok, answered := approved[units[i+j]]; !answered || ok {
+					rest = append(rest, e)
+				}
+			}
+			failures += x.applyEntries(ctx, channels, rest)
+			for _, e := range entries[i:] {
+				if !slices.Contains(rest, e) {
+					x.decline(channels, e)
+				}
+			}
+			break
+		}
+		approved[units[i]] = ok
+		if !ok {
+			x.decline(channels, e)
+			continue
+		}
+		failures += x.applyEntries(ctx, channels, []planEntry{e})
+	}
+	return failures
+}
+
+// decline records e as skipped by the operator.
+func (x *executor) decline(channels map[string]channelInfo, e planEntry) {
+	fmt.Printf("SKIP: %s (%v)\n", e, errDeclined)
+	x.Stats.Skipped++
+	r := runner.NewResult(e, channels[e.Asis], resultSkipped, errDeclined, time.Time{}, time.Time{})
+	x.Results = append(x.Results, r)
+	live.entry(r)
+	auditLog.entry(r)
+}
+
+// unitSummary describes the change made by the entries of unit, one row at a
+// time: for a rename through a temporary name, the rename from the first name
+// to the last.
+func unitSummary(entries []planEntry, units []int, unit int) string {
+	var rows []string
+	seen := make(map[string]bool)
+	for i, e := range entries {
+		if units[i] != unit || seen[e.Source] {
+			continue
+		}
+		seen[e.Source] = true
+		rows = append(rows, rowSummary(entries, e.Source))
+	}
+	return strings.Join(rows, "; ")
+}
+
+// rowSummary describes the change made by the entries read from source: for
+// a rename through a temporary name, the rename from the first name to the last.
+func rowSummary(entries []planEntry, source string) string {
+	var row []planEntry
+	for _, e := range entries {
+		if e.Source == source {
+			row = append(row, e)
+		}
+	}
+	first, last := row[0], row[len(row)-1]
+	if len(row) > 1 && first.Action == actionRename && last.Action == actionRename {
+		first.Tobe = last.Tobe
+	}
+	return fmt.Sprintf("%s%s", first.At(), first)
+}
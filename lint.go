@@ -0,0 +1,111 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"gopkg.in/yaml.v3"
+)
+
+// lintPolicy is the -policy file of naming rules checked by lint:
+//
+//	teams:
+//	  eng: [eng-, dev-]
+//	  sales: [sales-]
+//	banned_words: [temp, test]
+//	max_length: 40
+//	separators: "-"
+type lintPolicy struct {
+	// Teams maps a team, matched against the owner column of plan rows, to
+	// the prefixes its channel names must start with. Channels without a
+	// known team must start with the prefix of any team.
+	Teams map[string][]string `yaml:"teams"`
+	// BannedWords may not appear as a word of a name, in any case.
+	BannedWords []string `yaml:"banned_words"`
+	// MaxLength caps the number of characters; 0 means Slack's 80.
+	MaxLength int `yaml:"max_length"`
+	// Separators lists the separator characters allowed between words, out
+	// of "-" and "_"; empty allows both.
+	Separators string `yaml:"separators"`
+}
+
+func loadLintPolicy(path string) (*lintPolicy, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read %q: %w", path, err)
+	}
+	dec := yaml.NewDecoder(bytes.NewReader(b))
+	dec.KnownFields(true)
+	var p lintPolicy
+	if err := dec.Decode(&p); err != nil {
+		return nil, fmt.Errorf("parse %q: %w", path, err)
+	}
+	if strings.Trim(p.Separators, "-_") != "" {
+		return nil, fmt.Errorf("%s: separators may only contain - and _", path)
+	}
+	if p.MaxLength == 0 {
+		p.MaxLength = 80
+	}
+	return &p, nil
+}
+
+// prefixesFor returns the prefixes a channel of team may start with.
+func (p *lintPolicy) prefixesFor(team string) []string {
+	if prefixes, ok := p.Teams[team]; ok {
+		return prefixes
+	}
+	var all []string
+	for _, prefixes := range p.Teams {
+		all = append(all, prefixes...)
+	}
+	slices.Sort(all)
+	return all
+}
+
+// check returns the rules name breaks, and a name that keeps to them, for a
+// channel owned by team ("" if unknown).
+func (p *lintPolicy) check(name, team string) (problems []string, suggestion string) {
+	allowed := p.Separators
+	if allowed == "" {
+		allowed = "-_"
+	}
+	sep := allowed[:1]
+	fixed := name
+	for _, s := range "-_" {
+		if !strings.ContainsRune(allowed, s) && strings.ContainsRune(name, s) {
+			problems = append(problems, fmt.Sprintf("separator %q is not allowed", string(s)))
+			fixed = strings.ReplaceAll(fixed, string(s), sep)
+		}
+	}
+
+	words := strings.FieldsFunc(fixed, func(r rune) bool { return r == '-' || r == '_' })
+	kept := words[:0]
+	for _, w := range words {
+		if slices.ContainsFunc(p.BannedWords, func(b string) bool { return strings.EqualFold(b, w) }) {
+			problems = append(problems, fmt.Sprintf("banned word %q", w))
+			continue
+		}
+		kept = append(kept, w)
+	}
+	if len(kept) < len(words) {
+		fixed = strings.Join(kept, sep)
+	}
+
+	if prefixes := p.prefixesFor(team); len(prefixes) > 0 &&
+		!slices.ContainsFunc(prefixes, func(pre string) bool { return strings.HasPrefix(fixed, pre) }) {
+		problems = append(problems, fmt.Sprintf("does not start with %s", strings.Join(prefixes, " or ")))
+		fixed = prefixes[0] + fixed
+	}
+
+	if utf8.RuneCountInString(name) > p.MaxLength {
+		problems = append(problems, fmt.Sprintf("longer than %d characters", p.MaxLength))
+	}
+	if utf8.RuneCountInString(fixed) > p.MaxLength {
+		fixed = strings.TrimRight(string([]rune(fixed)[:p.MaxLength]), "-_")
+	}
+	return problems, fixed
+}
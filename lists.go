@@ -0,0 +1,145 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	"github.com/kiddikn/slack-channel-renamer/plan"
+)
+
+// listColumns are the header names a channel list's channel column may have.
+var listColumns = []string{"channel", "asis", "name", "channel_id"}
+
+// memberColumns are the header names the members column of an 'invite' list
+// may have.
+var memberColumns = []string{"members", "member", "users", "user", "email"}
+
+func isListColumn(col string) bool {
+	return slices.Contains(listColumns, strings.ToLower(strings.TrimSpace(col)))
+}
+
+// listDefaultColumns are the columns of a list without a header row, by the
+// action of the command reading it: tobe is the name to unarchive a channel
+// under.
+var listDefaultColumns = map[string][]string{
+	actionArchive:   {"channel"},
+	actionUnarchive: {"channel", "tobe"},
+	actionSetTopic:  {"channel", "topic", "purpose"},
+	actionInvite:    {"channel", "members"},
+}
+
+// channelListLoader returns a loader for the channel lists of 'archive',
+// 'unarchive', 'set-topic' and 'invite': one channel, by name (without the #)
+// or ID, per line, followed by the columns listDefaultColumns gives the
+// action, with blank lines and # comments skipped. Every channel becomes an
+// entry with the given action; the invite rows of a channel are combined into
+// one, so that its users are invited in batches. An optional header row names
+// the channel column and may add tobe, topic, purpose, members, workspace and
+// owner columns.
+func channelListLoader(action string) planLoader {
+	return func(path string, data []byte, opts plan.Options) ([]planEntry, error) {
+		data, err := plan.DecodeText(data, opts.Encoding)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		r := csv.NewReader(bytes.NewReader(data))
+		r.Comma = opts.Delimiter
+		if r.Comma == 0 {
+			r.Comma = plan.DetectDelimiter(data)
+		}
+		r.Comment = '#'
+		r.FieldsPerRecord = -1
+		r.TrimLeadingSpace = true
+
+		cols := make(map[string]int)
+		for i, col := range listDefaultColumns[action] {
+			cols[col] = i
+		}
+		column := func(row []string, name string) string {
+			if i, ok := cols[name]; ok && i < len(row) {
+				return strings.TrimSpace(row[i])
+			}
+			return ""
+		}
+		var entries []planEntry
+		for first := true; ; first = false {
+			row, err := r.Read()
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			if err != nil {
+				return nil, fmt.Errorf("parse %s: %w", path, err)
+			}
+			if first && slices.ContainsFunc(row, isListColumn) {
+				cols = make(map[string]int)
+				for i, col := range row {
+					switch {
+					case isListColumn(col):
+						col = "channel"
+					case slices.Contains(memberColumns, strings.ToLower(strings.TrimSpace(col))):
+						col = "members"
+					}
+					cols[strings.ToLower(strings.TrimSpace(col))] = i
+				}
+				continue
+			}
+			line, _ := r.FieldPos(0)
+			entry := planEntry{
+				Action:    action,
+				Asis:      column(row, "channel"),
+				Tobe:      column(row, "tobe"),
+				Topic:     column(row, "topic"),
+				Purpose:   column(row, "purpose"),
+				Members:   column(row, "members"),
+				Owner:     column(row, "owner"),
+				Workspace: column(row, "workspace"),
+				Source:    fmt.Sprintf("%s:%d", path, line),
+			}
+			if entry.Asis == "" {
+				return nil, fmt.Errorf("%s:%d: no channel named", path, line)
+			}
+			if err := entry.Check(); err != nil {
+				return nil, err
+			}
+			if i := slices.IndexFunc(entries, func(e planEntry) bool { return e.Asis == entry.Asis && e.Workspace == entry.Workspace }); i >= 0 && action == actionInvite {
+				entries[i].Members += " " + entry.Members
+				continue
+			}
+			entries = append(entries, entry)
+		}
+		if len(entries) == 0 {
+			return nil, fmt.Errorf("%s lists no channels", path)
+		}
+		return entries, nil
+	}
+}
+
+// listLoader returns the loader of the lists read by the command that
+// applies action to every row.
+func listLoader(action string) planLoader {
+	if action == actionCreate {
+		return loadCreateList
+	}
+	return channelListLoader(action)
+}
+
+func cmdArchive(args []string) int {
+	return applyPlan("archive", actionArchive, args)
+}
+
+func cmdInvite(args []string) int {
+	return applyPlan("invite", actionInvite, args)
+}
+
+func cmdSetTopic(args []string) int {
+	return applyPlan("set-topic", actionSetTopic, args)
+}
+
+func cmdUnarchive(args []string) int {
+	return applyPlan("unarchive", actionUnarchive, args)
+}
@@ -0,0 +1,129 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/kiddikn/slack-channel-renamer/plan"
+)
+
+func TestChannelList(t *testing.T) {
+	load := channelListLoader(actionArchive)
+	entries, err := load("list.txt", []byte("# stale project channels\nproj-old\n\nC0123456789\n"), plan.Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 || entries[0].Asis != "proj-old" || entries[0].Source != "list.txt:2" || entries[1].ChannelID != "C0123456789" {
+		t.Fatalf("entries = %+v", entries)
+	}
+	for _, e := range entries {
+		if e.Action != actionArchive {
+			t.Errorf("%s: action = %q, want archive", e.Source, e.Action)
+		}
+	}
+
+	entries, err = load("list.csv", []byte("workspace,channel\nacme,dead\n"), plan.Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 || entries[0].Asis != "dead" || entries[0].Workspace != "acme" {
+		t.Errorf("entries = %+v", entries)
+	}
+
+	if _, err := load("empty.txt", []byte("# nothing yet\n"), plan.Options{}); err == nil {
+		t.Error("an empty list loaded without error")
+	}
+}
+
+func TestUnarchiveTakenName(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "snapshot.json")
+	snapshot := `[{"name":"proj","id":"C1"},{"name":"proj","id":"C2","archived":true},{"name":"old","id":"C3","archived":true}]`
+	if err := os.WriteFile(path, []byte(snapshot), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	sessions, err := (workspaceOptions{simulate: path}).newSessions(channelOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, errs, _, err := validateRuns(sessions, workspaceOptions{}, []planEntry{{Action: actionUnarchive, Asis: "proj"}}, prepareOptions{})
+	if err != nil || len(errs) != 1 || !strings.Contains(errs[0], `such as "proj-2"`) {
+		t.Fatalf("validateRuns without a tobe: %v %q", err, errs)
+	}
+
+	plan := []planEntry{
+		{Action: actionUnarchive, Asis: "proj", Tobe: "proj-2019"},
+		{Action: actionUnarchive, Asis: "old", Tobe: "old-2"},
+	}
+	runs, errs, _, err := validateRuns(sessions, workspaceOptions{}, plan, prepareOptions{})
+	if err != nil || len(errs) > 0 {
+		t.Fatalf("validateRuns: %v %q", err, errs)
+	}
+	res, err := executeRuns(runs, runOptions{verb: "unarchive", pool: poolOptions{concurrency: 1, perMinute: 60}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.failures != 0 {
+		t.Errorf("failures = %d, want 0", res.failures)
+	}
+	w := sessions[0].client.(*simWorkspace)
+	want := map[string]string{"C1": "proj", "C2": "proj-2019", "C3": "old-2"}
+	for _, ch := range w.channels {
+		if ch.Name != want[ch.ID] || ch.IsArchived {
+			t.Errorf("%s is named %s (archived %v), want %s unarchived", ch.ID, ch.Name, ch.IsArchived, want[ch.ID])
+		}
+	}
+}
+
+func TestSetTopicChangesOnly(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "snapshot.json")
+	if err := os.WriteFile(path, []byte(`[{"name":"alpha","id":"C1","topic":"R&amp;D"},{"name":"beta","id":"C2","topic":"Beta"}]`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	sessions, err := (workspaceOptions{simulate: path}).newSessions(channelOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	plan, err := channelListLoader(actionSetTopic)("topics.csv", []byte("alpha,R&D,Research\nbeta,Beta\n"), plan.Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	runs, errs, skipped, err := validateRuns(sessions, workspaceOptions{}, plan, prepareOptions{})
+	if err != nil || len(errs) > 0 || len(skipped) != 1 {
+		t.Fatalf("validateRuns: %v %q, skipped %q", err, errs, skipped)
+	}
+	if e := runs[0].plan[0]; e.Topic != "" || e.Purpose != "Research" {
+		t.Errorf("entry sets topic %q and purpose %q, want only the purpose", e.Topic, e.Purpose)
+	}
+}
+
+func TestInvite(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "snapshot.json")
+	if err := os.WriteFile(path, []byte(`[{"name":"alpha","id":"C1","members":1}]`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	sessions, err := (workspaceOptions{simulate: path}).newSessions(channelOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	list := "channel,email\nalpha,ana@example.com\nalpha,UC10001AAA\nalpha,UC10001AAA\n"
+	plan, err := channelListLoader(actionInvite)("members.csv", []byte(list), plan.Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(plan) != 1 {
+		t.Fatalf("plan = %+v, want the rows of alpha combined", plan)
+	}
+	runs, errs, _, err := validateRuns(sessions, workspaceOptions{}, plan, prepareOptions{})
+	if err != nil || len(errs) > 0 {
+		t.Fatalf("validateRuns: %v %q", err, errs)
+	}
+	res, err := executeRuns(runs, runOptions{verb: "invite", pool: poolOptions{concurrency: 1, perMinute: 60}})
+	if err != nil || res.failures != 0 {
+		t.Fatalf("executeRuns: %v, %d failures", err, res.failures)
+	}
+	if members := sessions[0].client.(*simWorkspace).members["C1"]; len(members) != 3 {
+		t.Errorf("members = %q, want the existing member and the two invited", members)
+	}
+}
@@ -0,0 +1,213 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/kiddikn/slack-channel-renamer/slackops"
+	"github.com/slack-go/slack"
+)
+
+// lockOptions controls the lock a run takes on each workspace it changes, so
+// that two operators cannot apply overlapping batches at once.
+type lockOptions struct {
+	enabled bool
+	// channel, when set, is the channel ID the lock is also pinned in, for
+	// operators on other machines to see.
+	channel string
+	// ttl is the age past which a lock is taken to be left by a crashed run.
+	ttl time.Duration
+}
+
+func (o *lockOptions) register(fs *flag.FlagSet) {
+	fs.BoolVar(&o.enabled, "lock", true, "refuse to run while another run on this machine holds the workspace's lock file")
+	fs.StringVar(&o.channel, "lock-channel", "", "also hold the lock as a pinned message in this channel ID, shared by every operator of the workspace")
+	fs.DurationVar(&o.ttl, "lock-ttl", 6*time.Hour, "treat locks older than this as left by a crashed run and take them over")
+}
+
+// lockPrefix starts the message of a lock pinned in -lock-channel.
+const lockPrefix = ":lock: slack-channel-renamer lock"
+
+// lockHolder describes the run holding a lock.
+type lockHolder struct {
+	User    string    `json:"user"`
+	Host    string    `json:"host"`
+	PID     int       `json:"pid"`
+	Source  string    `json:"source"`
+	Started time.Time `json:"started"`
+}
+
+func (h lockHolder) String() string {
+	return fmt.Sprintf("%s on %s (pid %d), applying %s since %s", h.User, h.Host, h.PID, h.Source, h.Started.Format(time.RFC3339))
+}
+
+// alive reports whether the process holding the lock may still be running:
+// it is on another machine, or a process with its PID exists on this one.
+func (h lockHolder) alive(host string) bool {
+	if h.Host != host || runtime.GOOS == "windows" {
+		return true
+	}
+	p, err := os.FindProcess(h.PID)
+	return err == nil && p.Signal(syscall.Signal(0)) == nil
+}
+
+// acquireLocks takes the lock of every run's workspace, and returns a
+// function releasing them. It fails, releasing what it took, when another run
+// holds one. Simulated runs, and sessions whose team is unknown, take none.
+func acquireLocks(ctx context.Context, runs []workspaceRun, opts lockOptions, source string) (func(), error) {
+	var releases []func()
+	release := func() {
+		for _, f := range slices.Backward(releases) {
+			f()
+		}
+	}
+	if !opts.enabled {
+		return release, nil
+	}
+	host, _ := os.Hostname()
+	for _, r := range runs {
+		if r.simulated || r.auth == nil || r.auth.TeamID == "" {
+			continue
+		}
+		holder := lockHolder{User: r.auth.User, Host: host, PID: os.Getpid(), Source: source, Started: time.Now().UTC().Truncate(time.Second)}
+		unlock, err := lockFile(filepath.Join(os.TempDir(), "slack-channel-renamer-"+r.auth.TeamID+".lock"), holder, opts.ttl)
+		if err == nil && opts.channel != "" {
+			releases = append(releases, unlock)
+			unlock, err = r.lockChannel(ctx, opts.channel, holder, opts.ttl)
+		}
+		if err != nil {
+			release()
+			return nil, fmt.Errorf("%s%w", r.label(), err)
+		}
+		releases = append(releases, unlock)
+	}
+	return release, nil
+}
+
+// lockFile creates the lock file at path, or takes over one left by a run
+// that has died or is older than ttl.
+func lockFile(path string, holder lockHolder, ttl time.Duration) (func(), error) {
+	b, err := json.Marshal(holder)
+	if err != nil {
+		return nil, err
+	}
+	for range 2 {
+		f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+		if err == nil {
+			_, err = f.Write(b)
+			if cerr := f.Close(); err == nil {
+				err = cerr
+			}
+			if err != nil {
+				os.Remove(path)
+				return nil, fmt.Errorf("write lock file: %w", err)
+			}
+			return func() {
+				if err := os.Remove(path); err != nil {
+					slog.Warn("failed to remove the lock file", "file", path, "err", err)
+				}
+			}, nil
+		}
+		if !errors.Is(err, os.ErrExist) {
+			return nil, fmt.Errorf("create lock file: %w", err)
+		}
+		var other lockHolder
+		if data, err := os.ReadFile(path); err == nil {
+			json.Unmarshal(data, &other)
+		}
+		if other.alive(holder.Host) && time.Since(other.Started) < ttl {
+			return nil, fmt.Errorf("another run holds the lock: %s (lock file %s)", other, path)
+		}
+		slog.Warn("taking over a stale lock", "holder", other.String(), "file", path)
+		if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf("remove stale lock file: %w", err)
+		}
+	}
+	return nil, fmt.Errorf("another run took the lock file %s first", path)
+}
+
+// lockChannel pins a lock message in channel, then checks the channel's pins
+// for a lock pinned before it and not older than ttl. If there is one, that
+// run holds the lock and this one is withdrawn; otherwise the returned
+// function marks the message released and unpins it.
+func (s *session) lockChannel(ctx context.Context, channel string, holder lockHolder, ttl time.Duration) (func(), error) {
+	text := fmt.Sprintf("%s held by %s", lockPrefix, holder)
+	var ts string
+	err := slackops.Call(ctx, s.stats, "posting the lock", func(ctx context.Context) error {
+		var err error
+		_, ts, err = s.client.PostMessageContext(ctx, channel, slack.MsgOptionText(text, false))
+		return err
+	}, "channel_id", channel)
+	if err != nil {
+		return nil, fmt.Errorf("post the lock in %s: %w", channel, err)
+	}
+	item := slack.NewRefToMessage(channel, ts)
+	unlock := func() {
+		ctx := context.WithoutCancel(ctx)
+		err := slackops.Call(ctx, s.stats, "releasing the lock", func(ctx context.Context) error {
+			err := s.client.RemovePinContext(ctx, channel, item)
+			var se slack.SlackErrorResponse
+			if errors.As(err, &se) && se.Err == "no_pin" {
+				err = nil
+			}
+			return err
+		}, "channel_id", channel)
+		if err == nil {
+			released := fmt.Sprintf(":unlock: slack-channel-renamer lock released by %s at %s", holder.User, time.Now().UTC().Format(time.RFC3339))
+			err = slackops.Call(ctx, s.stats, "releasing the lock", func(ctx context.Context) error {
+				_, _, _, err := s.client.UpdateMessageContext(ctx, channel, ts, slack.MsgOptionText(released, false))
+				return err
+			}, "channel_id", channel)
+		}
+		if err != nil {
+			slog.Warn(s.label()+"failed to release the lock pinned in the lock channel; unpin it by hand", "channel_id", channel, "ts", ts, "err", err)
+		}
+	}
+	err = slackops.Call(ctx, s.stats, "pinning the lock", func(ctx context.Context) error {
+		return s.client.AddPinContext(ctx, channel, item)
+	}, "channel_id", channel)
+	if err != nil {
+		unlock()
+		return nil, fmt.Errorf("pin the lock in %s: %w", channel, err)
+	}
+
+	var pins []slack.Item
+	err = slackops.Call(ctx, s.stats, "reading the pinned locks", func(ctx context.Context) error {
+		var err error
+		pins, _, err = s.client.ListPinsContext(ctx, channel)
+		return err
+	}, "channel_id", channel)
+	if err != nil {
+		unlock()
+		return nil, fmt.Errorf("read the pins of %s: %w", channel, err)
+	}
+	mine, _ := parseSlackTS(ts)
+	for _, p := range pins {
+		if p.Message == nil || p.Message.Timestamp == ts || !strings.HasPrefix(p.Message.Text, lockPrefix) {
+			continue
+		}
+		at, err := parseSlackTS(p.Message.Timestamp)
+		if err != nil || !at.Before(mine) {
+			continue
+		}
+		if mine.Sub(at) >= ttl {
+			slog.Warn(s.label()+"ignoring a stale lock pinned in the lock channel", "channel_id", channel, "ts", p.Message.Timestamp)
+			continue
+		}
+		unlock()
+		_, held, _ := strings.Cut(p.Message.Text, " held by ")
+		return nil, fmt.Errorf("another run holds the lock pinned in %s: %s", channel, held)
+	}
+	return unlock, nil
+}
@@ -0,0 +1,36 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLockFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "team.lock")
+	host, _ := os.Hostname()
+	holder := lockHolder{User: "ana", Host: host, PID: os.Getpid(), Source: "a.csv", Started: time.Now()}
+	unlock, err := lockFile(path, holder, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := lockFile(path, holder, time.Hour); err == nil || !strings.Contains(err.Error(), "ana on") {
+		t.Errorf("second lock: %v, want it refused naming the holder", err)
+	}
+	unlock()
+	if _, err := os.Stat(path); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("lock file left after unlock: %v", err)
+	}
+
+	old := holder
+	old.Started = time.Now().Add(-2 * time.Hour)
+	if _, err := lockFile(path, old, time.Hour); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := lockFile(path, holder, time.Hour); err != nil {
+		t.Errorf("a lock older than the ttl was not taken over: %v", err)
+	}
+}
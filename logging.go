@@ -0,0 +1,65 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"strconv"
+
+	"github.com/slack-go/slack"
+)
+
+// logLevel is the minimum level logged, set with -log-level.
+var logLevel = new(slog.LevelVar)
+
+// setupLogging sends logs to stderr as text, with the time of day only.
+func setupLogging() {
+	slog.SetDefault(slog.New(stderrHandler()))
+}
+
+func stderrHandler() slog.Handler {
+	return slog.NewTextHandler(statusWriter{os.Stderr}, &slog.HandlerOptions{
+		Level: logLevel,
+		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+			if a.Key == slog.TimeKey && len(groups) == 0 {
+				a.Value = slog.StringValue(a.Value.Time().Format("15:04:05"))
+			}
+			return a
+		},
+	})
+}
+
+// registerLogging adds -log-level, -log-file and -debug to every command.
+// They take effect as soon as they are parsed.
+func registerLogging(fs *flag.FlagSet) {
+	fs.Func("record", "write every Slack API call and its response to this fixture file, for -replay", setRecord)
+	fs.Func("replay", "answer Slack API calls from a fixture file written by -record instead of calling Slack; no token is needed", setReplay)
+	fs.Func("api-url", "base URL of the Slack Web API, e.g. a mock server for tests (default $SLACK_API_URL, or "+slack.APIURL+")", setAPIURL)
+	fs.BoolFunc("debug", "log every Slack API request and response, with tokens redacted, its latency and rate-limit headers; implies -log-level debug", func(s string) error {
+		on, err := strconv.ParseBool(s)
+		if err != nil {
+			return err
+		}
+		debugAPI = on
+		if on {
+			logLevel.Set(slog.LevelDebug)
+		}
+		return nil
+	})
+	fs.Func("log-level", "minimum level to log: debug, info, warn or error (default info)", func(s string) error {
+		return logLevel.UnmarshalText([]byte(s))
+	})
+	fs.Func("log-file", "also append logs to this file as JSON lines, for ingestion into a log pipeline", func(path string) error {
+		f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o644)
+		if err != nil {
+			return fmt.Errorf("open log file: %w", err)
+		}
+		slog.SetDefault(slog.New(slog.NewMultiHandler(
+			stderrHandler(),
+			slog.NewJSONHandler(f, &slog.HandlerOptions{Level: logLevel}),
+		)))
+		return nil
+	})
+	fs.Func("audit-log", "append every decision (validation, Slack API call, entry result) to this file as JSON lines, with a run ID and the operator", openAuditLog)
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/kiddikn/slack-channel-renamer/slackops"
+)
+
+const metricsPrefix = "slack_channel_renamer_"
+
+// writeMetricsFile writes each session's stats in the Prometheus
+// textfile-collector format, labelled by workspace when -config is used.
+// The file is written to a temporary name and renamed into place so that
+// node_exporter never reads a partially written file.
+func writeMetricsFile(path string, sessions []*session) error {
+	var b strings.Builder
+	gauge := func(name, help string, value func(*slackops.Stats) float64) {
+		fmt.Fprintf(&b, "# HELP %s%s %s\n", metricsPrefix, name, help)
+		fmt.Fprintf(&b, "# TYPE %s%s gauge\n", metricsPrefix, name)
+		for _, s := range sessions {
+			labels := ""
+			if s.workspace != "" {
+				labels = fmt.Sprintf("{workspace=%q}", s.workspace)
+			}
+			fmt.Fprintf(&b, "%s%s%s %g\n", metricsPrefix, name, labels, value(s.stats))
+		}
+	}
+	gauge("renames_attempted", "Renames attempted in the last run.", func(s *slackops.Stats) float64 { return float64(s.Attempted) })
+	gauge("renames_succeeded", "Renames that succeeded in the last run.", func(s *slackops.Stats) float64 { return float64(s.Succeeded) })
+	gauge("renames_failed", "Renames that failed in the last run.", func(s *slackops.Stats) float64 { return float64(s.Failed) })
+	gauge("renames_skipped", "Plan entries skipped in the last run.", func(s *slackops.Stats) float64 { return float64(s.Skipped) })
+	gauge("rate_limit_retries", "Retries caused by Slack rate limiting in the last run.", func(s *slackops.Stats) float64 { return float64(s.RateLimitRetries.Load()) })
+	gauge("transient_retries", "Retries caused by timeouts, dropped connections and 5xx responses in the last run.", func(s *slackops.Stats) float64 { return float64(s.TransientRetries.Load()) })
+	gauge("run_duration_seconds", "Wall-clock duration of the last run.", func(s *slackops.Stats) float64 { return time.Since(s.Start).Seconds() })
+	gauge("last_run_timestamp_seconds", "Unix time at which the last run finished.", func(*slackops.Stats) float64 { return float64(time.Now().Unix()) })
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".metrics-*.tmp")
+	if err != nil {
+		return fmt.Errorf("create temp file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.WriteString(b.String()); err != nil {
+		tmp.Close()
+		return fmt.Errorf("write %q: %w", tmp.Name(), err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("close %q: %w", tmp.Name(), err)
+	}
+	if err := os.Chmod(tmp.Name(), 0o644); err != nil {
+		return fmt.Errorf("chmod %q: %w", tmp.Name(), err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("rename to %q: %w", path, err)
+	}
+	return nil
+}
@@ -0,0 +1,132 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/kiddikn/slack-channel-renamer/plan"
+)
+
+// planFileSummary is what -per-plan reports of one plan file: the changes it
+// plans or, once applied, the outcomes of its entries and the files written
+// for it.
+type planFileSummary struct {
+	Plan        string         `json:"plan"`
+	Changes     map[string]int `json:"changes,omitempty"`
+	Succeeded   int            `json:"succeeded"`
+	Failed      int            `json:"failed"`
+	Skipped     int            `json:"skipped"`
+	Pending     int            `json:"pending"`
+	ResultsFile string         `json:"results_file,omitempty"`
+	Report      string         `json:"report,omitempty"`
+}
+
+// planFiles returns the plan files of entries in the order they were first
+// named, each with its entries.
+func planFiles[T any](entries []T, fileOf func(T) string) ([]string, map[string][]T) {
+	var files []string
+	byFile := make(map[string][]T)
+	for _, e := range entries {
+		f := fileOf(e)
+		if _, ok := byFile[f]; !ok {
+			files = append(files, f)
+		}
+		byFile[f] = append(byFile[f], e)
+	}
+	return files, byFile
+}
+
+// printPlanFileSummaries prints, for -per-plan, the changes each plan file
+// makes, counted across workspaces.
+func printPlanFileSummaries(runs []workspaceRun) {
+	var all []planEntry
+	for _, r := range runs {
+		all = append(all, r.plan...)
+	}
+	files, byFile := planFiles(all, func(e planEntry) string { return e.File })
+	var summaries []planFileSummary
+	for _, f := range files {
+		s := planFileSummary{Plan: f, Changes: make(map[string]int)}
+		for _, e := range byFile[f] {
+			s.Changes[e.Action]++
+		}
+		summaries = append(summaries, s)
+	}
+	if output == outputJSON {
+		report.PlanFiles = summaries
+		return
+	}
+	for _, s := range summaries {
+		var parts []string
+		for _, action := range plan.ActionOrder {
+			if n := s.Changes[action]; n > 0 {
+				parts = append(parts, fmt.Sprintf("%d %s", n, action))
+			}
+		}
+		fmt.Printf("plan %s: %s\n", s.Plan, strings.Join(parts, ", "))
+	}
+}
+
+// perPlanPath returns the path of plan's own copy of the artifact path, such
+// as results-eng.csv for results.csv and the plan teams/eng.csv. used holds
+// the paths returned so far, so that plans of the same name in different
+// directories get paths of their own.
+func perPlanPath(path, plan string, used map[string]bool) string {
+	ext := filepath.Ext(path)
+	stem := strings.TrimSuffix(path, ext)
+	name := strings.TrimSuffix(filepath.Base(plan), filepath.Ext(plan))
+	name = strings.Map(func(r rune) rune {
+		if r == '/' || r == '\\' || r == ':' || r == ' ' {
+			return '_'
+		}
+		return r
+	}, name)
+	p := stem + "-" + name + ext
+	for n := 2; used[p]; n++ {
+		p = fmt.Sprintf("%s-%s-%d%s", stem, name, n, ext)
+	}
+	used[p] = true
+	return p
+}
+
+// writePlanFileArtifacts splits the results of a run by plan file for
+// -per-plan and, for each plan, prints its summary and writes its own results
+// file and run report when resultsFile and reportFile are set.
+func writePlanFileArtifacts(res runResult, started time.Time, resultsFile, reportFile string) error {
+	files, byFile := planFiles(res.results, func(r entryResult) string { return r.Entry.File })
+	used := make(map[string]bool)
+	var summaries []planFileSummary
+	for _, f := range files {
+		results := byFile[f]
+		r := runReport{Source: f, Started: started.UTC(), Finished: time.Now().UTC(), Interrupted: res.interrupted, Aborted: res.aborted, Results: results, API: res.planAPI[f]}
+		s := planFileSummary{Plan: f, Succeeded: r.Count(resultOK), Failed: r.Count(resultFailed), Skipped: r.Count(resultSkipped), Pending: r.Count(resultPending)}
+		if resultsFile != "" {
+			s.ResultsFile = perPlanPath(resultsFile, f, used)
+			if err := writeResultsFile(s.ResultsFile, results, res.planAPI[f]); err != nil {
+				return fmt.Errorf("write results file of %s: %w", f, err)
+			}
+		}
+		if reportFile != "" {
+			s.Report = perPlanPath(reportFile, f, used)
+			if err := writeRunReport(s.Report, r); err != nil {
+				return fmt.Errorf("write the run report of %s: %w", f, err)
+			}
+		}
+		summaries = append(summaries, s)
+	}
+	if output == outputJSON {
+		report.PlanFiles = summaries
+		return nil
+	}
+	for _, s := range summaries {
+		fmt.Printf("plan %s: %d succeeded, %d failed, %d skipped, %d not started\n", s.Plan, s.Succeeded, s.Failed, s.Skipped, s.Pending)
+		for _, f := range []string{s.ResultsFile, s.Report} {
+			if f != "" {
+				fmt.Printf("  wrote %s\n", f)
+			}
+		}
+	}
+	return nil
+}
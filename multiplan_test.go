@@ -0,0 +1,73 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPerPlanPath(t *testing.T) {
+	used := make(map[string]bool)
+	for _, c := range []struct{ plan, want string }{
+		{"teams/eng.csv", "out/results-eng.csv"},
+		{"sheet 1AbC", "out/results-sheet_1AbC.csv"},
+		{"other/eng.csv", "out/results-eng-2.csv"},
+	} {
+		if got := perPlanPath("out/results.csv", c.plan, used); got != c.want {
+			t.Errorf("perPlanPath(%q) = %q, want %q", c.plan, got, c.want)
+		}
+	}
+}
+
+func TestWritePlanFileArtifacts(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "snapshot.json")
+	snapshot := `[{"name":"alpha","id":"C1"},{"name":"beta","id":"C2"},{"name":"gamma","id":"C3"}]`
+	if err := os.WriteFile(path, []byte(snapshot), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	sessions, err := (workspaceOptions{simulate: path}).newSessions(channelOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	plan := []planEntry{
+		{Action: actionRename, Asis: "alpha", Tobe: "alpha-eng", File: "eng.csv"},
+		{Action: actionRename, Asis: "beta", Tobe: "beta-ops", File: "ops.csv"},
+		{Action: actionArchive, Asis: "gamma", File: "eng.csv"},
+	}
+	runs, errs, _, err := validateRuns(sessions, workspaceOptions{}, plan, prepareOptions{})
+	if err != nil || len(errs) > 0 {
+		t.Fatalf("validateRuns: %v %q", err, errs)
+	}
+	started := time.Now()
+	res, err := executeRuns(runs, runOptions{verb: "rename", pool: poolOptions{concurrency: 1, perMinute: 60}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	results := filepath.Join(dir, "results.csv")
+	if err := writePlanFileArtifacts(res, started, results, ""); err != nil {
+		t.Fatal(err)
+	}
+	for file, want := range map[string][]string{
+		"results-eng.csv": {"alpha-eng", "gamma"},
+		"results-ops.csv": {"beta-ops"},
+	} {
+		b, err := os.ReadFile(filepath.Join(dir, file))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if lines := strings.Count(string(b), "\n"); lines != len(want)+1 {
+			t.Errorf("%s has %d lines, want %d:\n%s", file, lines, len(want)+1, b)
+		}
+		for _, name := range want {
+			if !strings.Contains(string(b), name) {
+				t.Errorf("%s does not mention %s:\n%s", file, name, b)
+			}
+		}
+	}
+	if _, err := os.Stat(results); err == nil {
+		t.Errorf("the combined %s was written too", results)
+	}
+}
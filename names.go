@@ -0,0 +1,132 @@
+package main
+
+import (
+	"log/slog"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/kiddikn/slack-channel-renamer/plan"
+	"golang.org/x/text/unicode/norm"
+)
+
+// autoFixNames rewrites the tobe of every rename and merge to its normalized
+// form, logging each change, for -auto-fix.
+func autoFixNames(entries []planEntry) []planEntry {
+	for i, e := range entries {
+		if e.Action != actionRename && e.Action != actionMerge {
+			continue
+		}
+		if fixed := plan.NormalizeName(e.Tobe); fixed != e.Tobe {
+			slog.Info(e.At()+"-auto-fix: rewriting target name", "tobe", e.Tobe, "fixed", fixed)
+			entries[i].Tobe = fixed
+		}
+	}
+	return entries
+}
+
+// normalizeUnicode rewrites the tobe of every rename and merge to Unicode NFKC
+// form, for -normalize-unicode. NFKC turns full-width letters and digits into
+// their ASCII forms and half-width katakana into full-width, so that plans typed
+// with a Japanese input method produce the names their authors meant.
+func normalizeUnicode(plan []planEntry) []planEntry {
+	for i, e := range plan {
+		if e.Action != actionRename && e.Action != actionMerge {
+			continue
+		}
+		if nfkc := norm.NFKC.String(e.Tobe); nfkc != e.Tobe {
+			slog.Info(e.At()+"-normalize-unicode: rewriting target name", "tobe", e.Tobe, "normalized", nfkc)
+			plan[i].Tobe = nfkc
+		}
+	}
+	return plan
+}
+
+// matchUnicodeForms replaces each asis that names no channel, but equals a
+// live channel name once both are in NFKC form, with the live name, so that a
+// plan mixing full-width and half-width characters still finds its channels.
+func matchUnicodeForms(plan []planEntry, channels map[string]channelInfo) []planEntry {
+	byForm := make(map[string]string, len(channels))
+	for name := range channels {
+		byForm[norm.NFKC.String(name)] = name
+	}
+	for i, e := range plan {
+		if _, ok := channels[e.Asis]; ok {
+			continue
+		}
+		if live, ok := byForm[norm.NFKC.String(e.Asis)]; ok {
+			slog.Info(e.At()+"channel name differs only in Unicode form, using the live name", "asis", e.Asis, "live", live)
+			plan[i].Asis = live
+		}
+	}
+	return plan
+}
+
+// idiomaticName returns name in the form Slack's own channel names take, and
+// what was wrong with it: compatibility characters such as full-width letters
+// and digits in their plain form, lowercase, only characters a channel name
+// can hold, runs of separators collapsed to their first and no separator at
+// either end.
+func idiomaticName(name string) (string, []string) {
+	var problems []string
+	fixed := name
+	if nfkc := norm.NFKC.String(fixed); nfkc != fixed {
+		problems = append(problems, "full-width or compatibility characters")
+		fixed = nfkc
+	}
+	if lower := strings.ToLower(fixed); lower != fixed {
+		problems = append(problems, "upper-case letters")
+		fixed = lower
+	}
+	if valid := plan.NormalizeName(fixed); valid != fixed {
+		problems = append(problems, "characters a channel name cannot hold")
+		fixed = valid
+	}
+	isSep := func(r rune) bool { return r == '-' || r == '_' }
+	var b strings.Builder
+	var prev rune
+	for _, r := range fixed {
+		if isSep(r) && isSep(prev) {
+			continue
+		}
+		b.WriteRune(r)
+		prev = r
+	}
+	if b.Len() < len(fixed) {
+		problems = append(problems, "doubled separators")
+		fixed = b.String()
+	}
+	if trimmed := strings.TrimFunc(fixed, isSep); trimmed != fixed {
+		problems = append(problems, "leading or trailing separators")
+		fixed = trimmed
+	}
+	return fixed, problems
+}
+
+// normalizeRows returns the asis/tobe rows renaming the channels whose names
+// idiomaticName changes, in name order, leaving out archived channels unless
+// includeArchived is set. A new name that is taken, by another channel or by
+// an earlier row, gets the first free -2, -3, ... suffix.
+func normalizeRows(channels map[string]channelInfo, includeArchived bool) [][2]string {
+	taken := make(map[string]bool)
+	var rows [][2]string
+	for _, name := range slices.Sorted(maps.Keys(channels)) {
+		if channels[name].IsArchived && !includeArchived {
+			continue
+		}
+		tobe, problems := idiomaticName(name)
+		if tobe == name || tobe == "" {
+			continue
+		}
+		if _, exists := channels[tobe]; exists || taken[tobe] {
+			tobe = plan.SuffixedName(tobe, func(n string) bool {
+				_, exists := channels[n]
+				return !exists && !taken[n]
+			})
+		}
+		taken[tobe] = true
+		slog.Info("channel name is not in the usual form", "asis", name, "tobe", tobe, "problems", strings.Join(problems, "; "))
+		rows = append(rows, [2]string{name, tobe})
+	}
+	return rows
+}
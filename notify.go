@@ -0,0 +1,104 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/kiddikn/slack-channel-renamer/slackops"
+	"github.com/slack-go/slack"
+)
+
+// notifyFailureLimit is how many failed entries a run summary lists.
+const notifyFailureLimit = 10
+
+// notifyOptions sends a summary of an apply to a Slack channel or an
+// incoming webhook once it ends.
+type notifyOptions struct {
+	channel string
+	webhook string
+	link    string
+}
+
+func (o *notifyOptions) register(fs *flag.FlagSet) {
+	fs.StringVar(&o.channel, "notify-channel", "", "post a summary of the run to this channel (name or ID)")
+	fs.StringVar(&o.webhook, "notify-webhook", "", "post a summary of the run to this Slack incoming webhook URL")
+	fs.StringVar(&o.link, "notify-link", "", "link to the results in the summary, e.g. the CI artifact holding -results-file")
+}
+
+func (o notifyOptions) enabled() bool {
+	return o.channel != "" || o.webhook != ""
+}
+
+// summaryText describes the outcome of a run for the summary message.
+func (o notifyOptions) summaryText(res runResult, source, resultsFile string) string {
+	counts := make(map[string]int)
+	var failed []entryResult
+	for _, r := range res.results {
+		counts[r.Status]++
+		if r.Status == resultFailed {
+			failed = append(failed, r)
+		}
+	}
+
+	var b strings.Builder
+	switch {
+	case res.interrupted:
+		b.WriteString(":warning: Channel rename run was interrupted")
+	case len(failed) > 0:
+		b.WriteString(":x: Channel rename run finished with failures")
+	default:
+		b.WriteString(":white_check_mark: Channel rename run finished")
+	}
+	fmt.Fprintf(&b, " (%s)\n", source)
+	fmt.Fprintf(&b, "%d succeeded, %d failed, %d skipped", counts[resultOK], counts[resultFailed], counts[resultSkipped])
+	if n := counts[resultPending]; n > 0 {
+		fmt.Fprintf(&b, ", %d not started", n)
+	}
+	b.WriteString("\n")
+	for i, r := range failed {
+		if i == notifyFailureLimit {
+			fmt.Fprintf(&b, "• ... and %d more\n", len(failed)-notifyFailureLimit)
+			break
+		}
+		fmt.Fprintf(&b, "• `%s%s`: %s\n", r.Entry.At(), r.Entry, r.Error)
+	}
+	switch {
+	case o.link != "":
+		fmt.Fprintf(&b, "Results: <%s>\n", o.link)
+	case resultsFile != "":
+		fmt.Fprintf(&b, "Results file: `%s`\n", resultsFile)
+	}
+	return b.String()
+}
+
+// send posts the summary to the channel, in the first run's workspace, and
+// to the webhook.
+func (o notifyOptions) send(runs []workspaceRun, text string) error {
+	var errs []error
+	if o.channel != "" && len(runs) > 0 {
+		r := runs[0]
+		channelID := o.channel
+		if ch, ok := r.channels[strings.TrimPrefix(o.channel, "#")]; ok {
+			channelID = ch.ID
+		}
+		err := slackops.Call(cmdCtx, r.stats, "posting the run summary", func(ctx context.Context) error {
+			_, _, err := r.client.PostMessageContext(ctx, channelID, slack.MsgOptionText(text, false))
+			return err
+		}, "channel_id", channelID)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("post summary to %s: %w", o.channel, err))
+		}
+	}
+	if o.webhook != "" {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		if err := slack.PostWebhookCustomHTTPContext(ctx, o.webhook, netClient(), &slack.WebhookMessage{Text: text}); err != nil {
+			errs = append(errs, fmt.Errorf("post summary to webhook: %w", err))
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,271 @@
+package main
+
+import (
+	"io"
+	"net/http"
+)
+
+// openAPIJSON describes the HTTP API of 'serve', for clients to generate code
+// from. It is served at /v1/openapi.json and printed by 'schema api'; the
+// tests check it against the routes and the response types.
+const openAPIJSON = `{
+  "openapi": "3.1.0",
+  "info": {
+    "title": "slack-channel-renamer",
+    "description": "Upload, validate and apply channel rename plans, and follow their runs.",
+    "version": "1"
+  },
+  "security": [{"bearer": []}],
+  "paths": {
+    "/healthz": {
+      "get": {
+        "operationId": "health",
+        "summary": "Reply ok while the process is up",
+        "security": [],
+        "responses": {"200": {"description": "The process is up", "content": {"text/plain": {"schema": {"type": "string"}}}}}
+      }
+    },
+    "/readyz": {
+      "get": {
+        "operationId": "ready",
+        "summary": "Report whether every workspace's token resolves and passes auth.test",
+        "security": [],
+        "responses": {
+          "200": {"description": "Ready", "content": {"application/json": {"schema": {"type": "object", "properties": {"ready": {"type": "boolean"}}}}}},
+          "503": {"$ref": "#/components/responses/Error"}
+        }
+      }
+    },
+    "/v1/plans": {
+      "post": {
+        "operationId": "uploadPlan",
+        "summary": "Upload a plan; the body is the file",
+        "parameters": [
+          {"name": "name", "in": "query", "description": "The file name, whose extension gives the format; upload.csv by default.", "schema": {"type": "string"}},
+          {"name": "format", "in": "query", "description": "The format, as for -format.", "schema": {"type": "string", "enum": ["csv", "json", "yaml", "xlsx"]}}
+        ],
+        "requestBody": {"required": true, "content": {"application/octet-stream": {"schema": {"type": "string", "format": "binary"}}}},
+        "responses": {
+          "201": {"description": "The stored plan", "content": {"application/json": {"schema": {"$ref": "#/components/schemas/Plan"}}}},
+          "400": {"$ref": "#/components/responses/Error"},
+          "413": {"$ref": "#/components/responses/Error"},
+          "422": {"$ref": "#/components/responses/Error"}
+        }
+      },
+      "get": {
+        "operationId": "listPlans",
+        "summary": "List the uploaded plans, oldest first",
+        "responses": {"200": {"description": "The plans", "content": {"application/json": {"schema": {"type": "array", "items": {"$ref": "#/components/schemas/Plan"}}}}}}
+      }
+    },
+    "/v1/plans/{id}": {
+      "get": {
+        "operationId": "getPlan",
+        "summary": "Show a plan with its latest validation",
+        "parameters": [{"$ref": "#/components/parameters/ID"}],
+        "responses": {
+          "200": {"description": "The plan", "content": {"application/json": {"schema": {"$ref": "#/components/schemas/Plan"}}}},
+          "404": {"$ref": "#/components/responses/Error"}
+        }
+      }
+    },
+    "/v1/plans/{id}/validate": {
+      "post": {
+        "operationId": "validatePlan",
+        "summary": "Validate a plan against the live channels",
+        "parameters": [
+          {"$ref": "#/components/parameters/ID"},
+          {"name": "stats", "in": "query", "description": "Add each channel's member count, creation and last activity.", "allowEmptyValue": true, "schema": {"type": "string"}}
+        ],
+        "responses": {
+          "200": {"description": "The validation report and the entries that would run", "content": {"application/json": {"schema": {"$ref": "#/components/schemas/ValidationResult"}}}},
+          "404": {"$ref": "#/components/responses/Error"},
+          "409": {"$ref": "#/components/responses/Error"},
+          "502": {"$ref": "#/components/responses/Error"}
+        }
+      }
+    },
+    "/v1/plans/{id}/apply": {
+      "post": {
+        "operationId": "applyPlan",
+        "summary": "Start applying a plan in the background",
+        "parameters": [{"$ref": "#/components/parameters/ID"}],
+        "responses": {
+          "202": {"description": "The new run", "content": {"application/json": {"schema": {"$ref": "#/components/schemas/Run"}}}},
+          "404": {"$ref": "#/components/responses/Error"},
+          "409": {"$ref": "#/components/responses/Error"}
+        }
+      }
+    },
+    "/v1/runs": {
+      "get": {
+        "operationId": "listRuns",
+        "summary": "List the runs, oldest first",
+        "responses": {"200": {"description": "The runs, without their results", "content": {"application/json": {"schema": {"type": "array", "items": {"$ref": "#/components/schemas/Run"}}}}}}
+      }
+    },
+    "/v1/runs/{id}": {
+      "get": {
+        "operationId": "getRun",
+        "summary": "Show a run with its status, counts and progress so far",
+        "parameters": [{"$ref": "#/components/parameters/ID"}],
+        "responses": {
+          "200": {"description": "The run, without its results", "content": {"application/json": {"schema": {"$ref": "#/components/schemas/Run"}}}},
+          "404": {"$ref": "#/components/responses/Error"}
+        }
+      }
+    },
+    "/v1/runs/{id}/results": {
+      "get": {
+        "operationId": "getResults",
+        "summary": "The outcome of every entry of a run finished so far",
+        "parameters": [{"$ref": "#/components/parameters/ID"}],
+        "responses": {
+          "200": {"description": "The results", "content": {"application/json": {"schema": {"type": "array", "items": {"$ref": "#/components/schemas/EntryResult"}}}}},
+          "404": {"$ref": "#/components/responses/Error"}
+        }
+      }
+    }
+  },
+  "components": {
+    "securitySchemes": {
+      "bearer": {"type": "http", "scheme": "bearer", "description": "The token in the variable named by -api-token-env."}
+    },
+    "parameters": {
+      "ID": {"name": "id", "in": "path", "required": true, "schema": {"type": "string"}}
+    },
+    "responses": {
+      "Error": {"description": "What went wrong", "content": {"application/json": {"schema": {"$ref": "#/components/schemas/Error"}}}}
+    },
+    "schemas": {
+      "Error": {"type": "object", "required": ["error"], "properties": {"error": {"type": "string"}}},
+      "Validation": {
+        "type": "object",
+        "required": ["passed", "errors", "skipped"],
+        "properties": {
+          "passed": {"type": "boolean"},
+          "errors": {"type": "array", "items": {"type": "string"}},
+          "skipped": {"type": "array", "items": {"type": "string"}}
+        }
+      },
+      "Plan": {
+        "type": "object",
+        "required": ["id", "name", "uploaded", "entries"],
+        "properties": {
+          "id": {"type": "string"},
+          "name": {"type": "string"},
+          "uploaded": {"type": "string", "format": "date-time"},
+          "entries": {"type": "integer"},
+          "validation": {"$ref": "#/components/schemas/Validation"}
+        }
+      },
+      "ValidationResult": {
+        "allOf": [
+          {"$ref": "#/components/schemas/Validation"},
+          {"type": "object", "required": ["plan"], "properties": {"plan": {"type": "array", "items": {"$ref": "#/components/schemas/PlanEntry"}}}}
+        ]
+      },
+      "PlanEntry": {
+        "type": "object",
+        "required": ["action", "channel_id", "asis"],
+        "properties": {
+          "action": {"$ref": "#/components/schemas/Action"},
+          "channel_id": {"type": "string"},
+          "asis": {"type": "string"},
+          "tobe": {"type": "string"},
+          "owner": {"type": "string"},
+          "topic": {"type": "string"},
+          "purpose": {"type": "string"},
+          "private": {"type": "boolean"},
+          "members": {"type": "string"},
+          "workspace": {"type": "string"},
+          "state": {"type": "string"},
+          "rearchive": {"type": "boolean"},
+          "not_before": {"type": "string", "format": "date-time"},
+          "channel_team": {"type": "string"},
+          "shared": {"type": "string"},
+          "pinned_note": {"type": "string"},
+          "plan": {"type": "string"},
+          "source": {"type": "string"},
+          "stats": {"$ref": "#/components/schemas/ChannelStats"},
+          "needs_confirm": {"type": "string"}
+        }
+      },
+      "ChannelStats": {
+        "type": "object",
+        "required": ["members"],
+        "properties": {
+          "members": {"type": "integer"},
+          "creator": {"type": "string"},
+          "created": {"type": "string", "format": "date-time"},
+          "last_activity": {"type": "string", "format": "date-time"}
+        }
+      },
+      "Run": {
+        "type": "object",
+        "required": ["id", "plan", "status", "started", "entries", "summary"],
+        "properties": {
+          "id": {"type": "string"},
+          "plan": {"type": "string", "description": "The ID of the plan the run applies."},
+          "status": {"type": "string", "enum": ["validating", "running", "succeeded", "failed", "interrupted", "error"]},
+          "started": {"type": "string", "format": "date-time"},
+          "finished": {"type": "string", "format": "date-time"},
+          "entries": {"type": "integer"},
+          "error": {"type": "string"},
+          "summary": {"$ref": "#/components/schemas/RunSummary"},
+          "progress": {"$ref": "#/components/schemas/RunProgress"},
+          "rollback_file": {"type": "string"},
+          "results": {"type": "array", "items": {"$ref": "#/components/schemas/EntryResult"}}
+        }
+      },
+      "RunSummary": {
+        "type": "object",
+        "required": ["succeeded", "failed", "skipped", "not_started", "interrupted"],
+        "properties": {
+          "succeeded": {"type": "integer"},
+          "failed": {"type": "integer"},
+          "skipped": {"type": "integer"},
+          "not_started": {"type": "integer"},
+          "interrupted": {"type": "boolean"}
+        }
+      },
+      "RunProgress": {
+        "type": "object",
+        "required": ["done", "failed", "total"],
+        "properties": {
+          "done": {"type": "integer"},
+          "failed": {"type": "integer"},
+          "total": {"type": "integer"},
+          "eta": {"type": "string", "description": "The estimated time left, such as 4m40s, while the run is applying."},
+          "last_error": {"type": "string"}
+        }
+      },
+      "EntryResult": {
+        "type": "object",
+        "required": ["action", "asis", "status"],
+        "properties": {
+          "source": {"type": "string"},
+          "workspace": {"type": "string"},
+          "action": {"$ref": "#/components/schemas/Action"},
+          "asis": {"type": "string"},
+          "tobe": {"type": "string"},
+          "status": {"type": "string", "enum": ["ok", "failed", "skipped", "pending"]},
+          "error": {"type": "string"},
+          "started": {"type": "string", "format": "date-time"},
+          "finished": {"type": "string", "format": "date-time"},
+          "channel_id": {"type": "string"},
+          "channel_name": {"type": "string"},
+          "rearchived": {"type": "boolean"},
+          "edits": {"type": "array", "items": {"type": "string"}}
+        }
+      },
+      "Action": {"type": "string", "enum": ["rename", "archive", "unarchive", "set-topic", "merge", "create", "invite"]}
+    }
+  }
+}
+`
+
+func serveOpenAPI(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	io.WriteString(w, openAPIJSON)
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"encoding/json"
+	"maps"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/kiddikn/slack-channel-renamer/plan"
+)
+
+type openAPIDoc struct {
+	Paths      map[string]map[string]json.RawMessage `json:"paths"`
+	Components struct {
+		Schemas map[string]struct {
+			Properties map[string]json.RawMessage `json:"properties"`
+		} `json:"schemas"`
+	} `json:"components"`
+}
+
+func parseOpenAPI(t *testing.T) openAPIDoc {
+	t.Helper()
+	var doc openAPIDoc
+	if err := json.Unmarshal([]byte(openAPIJSON), &doc); err != nil {
+		t.Fatalf("openAPIJSON does not parse: %v", err)
+	}
+	return doc
+}
+
+// TestOpenAPIRoutes checks that every documented operation is one the server
+// routes.
+func TestOpenAPIRoutes(t *testing.T) {
+	mux := newTestServer().routes().(*http.ServeMux)
+	for path, ops := range parseOpenAPI(t).Paths {
+		for method := range ops {
+			method = strings.ToUpper(method)
+			_, pattern := mux.Handler(httptest.NewRequest(method, strings.ReplaceAll(path, "{id}", "x"), nil))
+			if want := method + " " + path; pattern != want {
+				t.Errorf("%s is routed to %q, want %q", want, pattern, want)
+			}
+		}
+	}
+}
+
+// jsonFields returns the JSON names of t's fields, including those of
+// embedded structs.
+func jsonFields(t reflect.Type) []string {
+	var names []string
+	for f := range t.Fields() {
+		if f.Anonymous {
+			names = append(names, jsonFields(f.Type)...)
+			continue
+		}
+		if name, _, _ := strings.Cut(f.Tag.Get("json"), ","); f.IsExported() && name != "-" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// TestOpenAPISchemas checks that the component schemas have the fields the
+// server encodes, no more and no fewer.
+func TestOpenAPISchemas(t *testing.T) {
+	schemas := parseOpenAPI(t).Components.Schemas
+	for name, v := range map[string]any{
+		"Error":        nil,
+		"Validation":   validationReport{},
+		"Plan":         servedPlan{},
+		"PlanEntry":    planReportEntry{},
+		"ChannelStats": plan.ChannelStats{},
+		"Run":          servedRun{},
+		"RunSummary":   runSummary{},
+		"RunProgress":  runProgress{},
+		"EntryResult":  entryResult{},
+	} {
+		s, ok := schemas[name]
+		if !ok {
+			t.Errorf("no %s schema", name)
+			continue
+		}
+		if v == nil {
+			continue
+		}
+		got := slices.Sorted(maps.Keys(s.Properties))
+		want := slices.Sorted(slices.Values(jsonFields(reflect.TypeOf(v))))
+		if !slices.Equal(got, want) {
+			t.Errorf("%s properties = %v, want %v", name, got, want)
+		}
+	}
+}
+
+func TestServeOpenAPI(t *testing.T) {
+	rec := serveRequest(t, newTestServer().routes(), "GET", "/v1/openapi.json", "", "")
+	if rec.Code != http.StatusOK || rec.Header().Get("Content-Type") != "application/json" {
+		t.Fatalf("status %d, content type %q", rec.Code, rec.Header().Get("Content-Type"))
+	}
+	if rec.Body.String() != openAPIJSON {
+		t.Error("the served document differs from openAPIJSON")
+	}
+}
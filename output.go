@@ -0,0 +1,209 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/kiddikn/slack-channel-renamer/plan"
+	"github.com/kiddikn/slack-channel-renamer/slackops"
+)
+
+// outputFormat is how a command prints its plan, validation report and results.
+type outputFormat string
+
+const (
+	// outputPlain streams human-readable lines as the command runs.
+	outputPlain outputFormat = "plain"
+	// outputTable prints the plan and results as aligned tables.
+	outputTable outputFormat = "table"
+	// outputJSON prints one JSON document on stdout when the command ends;
+	// logs still go to stderr.
+	outputJSON outputFormat = "json"
+)
+
+// output is the format chosen with -output. Commands set it while parsing
+// their flags, before printing anything.
+var output = outputPlain
+
+// report collects what a command prints with -output json.
+var report jsonReport
+
+func registerOutput(fs *flag.FlagSet) {
+	fs.Func("output", "output format: plain, table or json (default plain)", func(s string) error {
+		switch f := outputFormat(s); f {
+		case outputPlain, outputTable, outputJSON:
+			output = f
+			return nil
+		}
+		return fmt.Errorf("unknown output format %q (want plain, table or json)", s)
+	})
+}
+
+// jsonReport is the document printed with -output json.
+type jsonReport struct {
+	Validation  *validationReport  `json:"validation,omitempty"`
+	Plan        []planReportEntry  `json:"plan,omitempty"`
+	Results     []entryResult      `json:"results,omitempty"`
+	Summaries   []summaryReport    `json:"summaries,omitempty"`
+	Interrupted bool               `json:"interrupted,omitempty"`
+	Aborted     bool               `json:"aborted,omitempty"`
+	API         *apiStats          `json:"api,omitempty"`
+	Runs        []historyRun       `json:"runs,omitempty"`
+	Changes     []historyChange    `json:"changes,omitempty"`
+	Version     *buildInfo         `json:"version,omitempty"`
+	Inactive    []inactiveChannel  `json:"inactive,omitempty"`
+	Duplicates  []duplicateCluster `json:"duplicates,omitempty"`
+	PlanFiles   []planFileSummary  `json:"plan_files,omitempty"`
+	Status      []rowStatus        `json:"status,omitempty"`
+}
+
+type validationReport struct {
+	Passed  bool     `json:"passed"`
+	Errors  []string `json:"errors"`
+	Skipped []string `json:"skipped"`
+}
+
+// planReportEntry is one plan entry in the JSON report.
+type planReportEntry struct {
+	resolvedEntry
+	Source string             `json:"source,omitempty"`
+	Stats  *plan.ChannelStats `json:"stats,omitempty"`
+	// NeedsConfirm lists the channels over -max-members-without-confirm
+	// the entry touches.
+	NeedsConfirm string `json:"needs_confirm,omitempty"`
+}
+
+type summaryReport struct {
+	Workspace        string  `json:"workspace,omitempty"`
+	Attempted        int     `json:"attempted"`
+	Succeeded        int     `json:"succeeded"`
+	Failed           int     `json:"failed"`
+	Skipped          int     `json:"skipped"`
+	RateLimitRetries int64   `json:"rate_limit_retries"`
+	TransientRetries int64   `json:"transient_retries"`
+	Seconds          float64 `json:"seconds"`
+}
+
+// flushReport prints the JSON report if -output json was chosen.
+func flushReport(w io.Writer) error {
+	if output != outputJSON {
+		return nil
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(report)
+}
+
+// reportPlan records or prints the plan of every run for -output json and table.
+func reportPlan(w io.Writer, runs []workspaceRun) {
+	if output == outputJSON {
+		report.Plan = planReport(runs)
+		return
+	}
+	var entries []planEntry
+	for _, r := range runs {
+		entries = append(entries, resolveIDs(r.plan, r.channels)...)
+	}
+	withStats := slices.ContainsFunc(entries, func(e planEntry) bool { return e.Stats != nil })
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	header := "WORKSPACE\tACTION\tASIS\tTOBE\tTOPIC\tPURPOSE\tSOURCE"
+	if withStats {
+		header += "\tMEMBERS\tCREATED\tLAST MESSAGE"
+	}
+	fmt.Fprintln(tw, header)
+	for _, e := range entries {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\t%s", e.Workspace, e.ActionLabel(), e.Asis, e.Tobe, e.Topic, e.Purpose, e.Source)
+		if withStats {
+			var st plan.ChannelStats
+			if e.Stats != nil {
+				st = *e.Stats
+			}
+			fmt.Fprintf(tw, "\t%d\t%s\t%s", st.Members, dateOf(st.Created), dateOf(st.LastActivity))
+		}
+		fmt.Fprintln(tw)
+	}
+	tw.Flush()
+}
+
+// dateOf formats the date of t for a table, leaving it empty when unknown.
+func dateOf(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format(time.DateOnly)
+}
+
+// planReport returns the plan of every run, with channel IDs, as reported in JSON.
+func planReport(runs []workspaceRun) []planReportEntry {
+	entries := []planReportEntry{}
+	for _, r := range runs {
+		for _, e := range resolveIDs(r.plan, r.channels) {
+			entries = append(entries, planReportEntry{
+				resolvedEntry: resolvedEntry{
+					Action:      e.Action,
+					ChannelID:   e.ChannelID,
+					Asis:        e.Asis,
+					Tobe:        e.Tobe,
+					Owner:       e.Owner,
+					Topic:       e.Topic,
+					Purpose:     e.Purpose,
+					Workspace:   e.Workspace,
+					Rearchive:   e.Rearchive,
+					NotBefore:   e.NotBefore,
+					ChannelTeam: e.ChannelTeam,
+					Shared:      e.Shared,
+				},
+				Source:       e.Source,
+				Stats:        e.Stats,
+				NeedsConfirm: e.HighImpact,
+			})
+		}
+	}
+	return entries
+}
+
+// printResultsTable prints the outcome of each entry for -output table.
+func printResultsTable(w io.Writer, results []entryResult) {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "STATUS\tACTION\tASIS\tTOBE\tCHANNEL\tERROR")
+	for _, r := range results {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\n", strings.ToUpper(r.Status), r.Action, r.Asis, r.Tobe, r.ChannelID, r.Error)
+	}
+	tw.Flush()
+}
+
+// reportSummary records the run's counters for -output json.
+func reportSummary(workspace string, stats *slackops.Stats) {
+	report.Summaries = append(report.Summaries, summaryReport{
+		Workspace:        workspace,
+		Attempted:        stats.Attempted,
+		Succeeded:        stats.Succeeded,
+		Failed:           stats.Failed,
+		Skipped:          stats.Skipped,
+		RateLimitRetries: stats.RateLimitRetries.Load(),
+		TransientRetries: stats.TransientRetries.Load(),
+		Seconds:          time.Since(stats.Start).Seconds(),
+	})
+}
+
+// printSummary prints the run's counters, naming the workspace when -config is
+// used. With -output json they go into the report instead.
+func printSummary(workspace string, stats *slackops.Stats) {
+	if output == outputJSON {
+		reportSummary(workspace, stats)
+		return
+	}
+	label := "summary"
+	if workspace != "" {
+		label = "summary for " + workspace
+	}
+	fmt.Printf("%s: %d attempted, %d succeeded, %d failed, %d skipped, %d rate-limit retries, %d transient retries in %v\n",
+		label, stats.Attempted, stats.Succeeded, stats.Failed, stats.Skipped, stats.RateLimitRetries.Load(), stats.TransientRetries.Load(),
+		time.Since(stats.Start).Round(time.Millisecond))
+}
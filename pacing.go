@@ -0,0 +1,136 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// defaultRateBudget is the entries a minute -adaptive-rate starts from: the
+// 20 calls a minute of Slack's Tier 2, which conversations.rename,
+// conversations.archive and conversations.setTopic belong to.
+const defaultRateBudget = 20
+
+// adaptivePacer, for -adaptive-rate, paces entries to a budget of starts
+// per minute, counted over the last minute rather than spread evenly, so that
+// a run smaller than the budget starts at once while a large one never goes
+// past it. Rate-limit errors pause every worker for as long as Slack asks and
+// shrink the budget; a streak of calls that are not rate limited grows it
+// again, back up to its ceiling.
+type adaptivePacer struct {
+	ceiling int
+
+	mu     sync.Mutex
+	budget int
+	starts []time.Time
+	until  time.Time
+	streak int
+	now    func() time.Time
+}
+
+// newAdaptivePacer returns a pacer for budget entries a minute, or nil,
+// which never waits, if budget is 0.
+func newAdaptivePacer(budget int) *adaptivePacer {
+	if budget <= 0 {
+		return nil
+	}
+	return &adaptivePacer{ceiling: budget, budget: budget, now: time.Now}
+}
+
+// next returns when the next entry may start: once any rate-limit pause is
+// over and fewer than budget entries started in the minute before.
+func (p *adaptivePacer) next() time.Time {
+	now := p.now()
+	cut := 0
+	for cut < len(p.starts) && now.Sub(p.starts[cut]) >= time.Minute {
+		cut++
+	}
+	p.starts = p.starts[cut:]
+	at := now
+	if n := len(p.starts); n >= p.budget {
+		at = p.starts[n-p.budget].Add(time.Minute)
+	}
+	if p.until.After(at) {
+		at = p.until
+	}
+	return at
+}
+
+// wait is called before an entry starts, and returns ctx's error if ctx is
+// done first.
+func (p *adaptivePacer) wait(ctx context.Context) error {
+	if p == nil {
+		return nil
+	}
+	for {
+		p.mu.Lock()
+		now := p.now()
+		at := p.next()
+		if !at.After(now) {
+			p.starts = append(p.starts, now)
+			p.mu.Unlock()
+			return nil
+		}
+		p.mu.Unlock()
+		timer := time.NewTimer(at.Sub(now))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// rateLimited pauses the run for wait and cuts the budget by a quarter.
+func (p *adaptivePacer) rateLimited(wait time.Duration) {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if until := p.now().Add(wait); until.After(p.until) {
+		p.until = until
+	}
+	p.streak = 0
+	if budget := max(1, p.budget*3/4); budget < p.budget {
+		p.budget = budget
+		slog.Warn("rate limited, slowing down", "entries_per_minute", p.budget, "pause", wait)
+	}
+}
+
+// succeeded counts a call that was not rate limited. Each budget of them in
+// a row raises the budget by one, up to its ceiling.
+func (p *adaptivePacer) succeeded() {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.budget >= p.ceiling {
+		return
+	}
+	if p.streak++; p.streak >= p.budget {
+		p.streak = 0
+		p.budget++
+		slog.Debug("no rate limits for a while, speeding up", "entries_per_minute", p.budget)
+	}
+}
+
+type pacerKey struct{}
+
+// withPacer returns ctx carrying p, for callObserver to report the rate limits
+// of slackops calls to.
+func withPacer(ctx context.Context, p *adaptivePacer) context.Context {
+	if p == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, pacerKey{}, p)
+}
+
+// pacerOf returns the pacer ctx carries, or nil.
+func pacerOf(ctx context.Context) *adaptivePacer {
+	p, _ := ctx.Value(pacerKey{}).(*adaptivePacer)
+	return p
+}
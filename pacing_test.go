@@ -0,0 +1,53 @@
+package main
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestAdaptivePacer(t *testing.T) {
+	now := time.Date(2026, 10, 14, 12, 0, 0, 0, time.UTC)
+	p := newAdaptivePacer(4)
+	p.now = func() time.Time { return now }
+	for i := range 4 {
+		if err := p.wait(context.Background()); err != nil {
+			t.Fatalf("entry %d waited: %v", i, err)
+		}
+	}
+	if at := p.next(); !at.Equal(now.Add(time.Minute)) {
+		t.Errorf("the fifth entry may start at %v, want a minute after the first", at)
+	}
+	now = now.Add(time.Minute)
+	if at := p.next(); !at.Equal(now) {
+		t.Errorf("after a minute the next entry may start at %v, want at once", at)
+	}
+
+	p.rateLimited(30 * time.Second)
+	if p.budget != 3 {
+		t.Errorf("budget after a rate limit = %d, want 3", p.budget)
+	}
+	if at := p.next(); !at.Equal(now.Add(30 * time.Second)) {
+		t.Errorf("after a rate limit the next entry may start at %v, want after the pause", at)
+	}
+	for range 3 {
+		p.succeeded()
+	}
+	if p.budget != 4 {
+		t.Errorf("budget after a streak of successes = %d, want 4", p.budget)
+	}
+	p.succeeded()
+	if p.budget != 4 {
+		t.Errorf("budget grew past its ceiling to %d", p.budget)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := p.wait(ctx); err == nil {
+		t.Error("wait did not return the context's error during a pause")
+	}
+	var none *adaptivePacer
+	if err := none.wait(ctx); err != nil {
+		t.Errorf("a nil pacer waited: %v", err)
+	}
+}
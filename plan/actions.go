@@ -0,0 +1,108 @@
+package plan
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/kiddikn/slack-channel-renamer/slackops"
+)
+
+// Plan actions. Rows without an action column are renames.
+const (
+	ActionRename    = "rename"
+	ActionArchive   = "archive"
+	ActionUnarchive = "unarchive"
+	ActionSetTopic  = "set-topic"
+	ActionMerge     = "merge"
+	ActionCreate    = "create"
+	ActionInvite    = "invite"
+)
+
+// ActionOrder is the order in which actions are reported in validation and plan output.
+var ActionOrder = []string{ActionRename, ActionArchive, ActionUnarchive, ActionSetTopic, ActionMerge, ActionCreate, ActionInvite}
+
+// Check validates the parts of an entry that do not depend on the workspace
+// and defaults an empty action to rename. Loaders call it for every row.
+func (e *Entry) Check() error {
+	if e.Action == "" {
+		e.Action = ActionRename
+	}
+	if e.ChannelID == "" && channelIDRe.MatchString(e.Asis) {
+		e.ChannelID = e.Asis
+	}
+	if !slices.Contains(ActionOrder, e.Action) {
+		return fmt.Errorf("%sunknown action %q (want rename, archive, unarchive, set-topic, merge, create or invite)", e.At(), e.Action)
+	}
+	if e.Asis == "" {
+		return fmt.Errorf("%s'asis' is empty", e.At())
+	}
+	switch e.Action {
+	case ActionRename:
+		if e.Tobe == "" {
+			return fmt.Errorf("%s'tobe' is empty", e.At())
+		}
+		return nil
+	case ActionMerge:
+		if e.Tobe == "" {
+			return fmt.Errorf("%smerge needs the channel to merge into in 'tobe'", e.At())
+		}
+		return nil
+	case ActionSetTopic:
+		if e.Topic == "" && e.Purpose == "" {
+			return fmt.Errorf("%sset-topic needs a topic or purpose", e.At())
+		}
+	case ActionCreate:
+		if err := checkMembers(*e); err != nil {
+			return err
+		}
+	case ActionInvite:
+		if e.Members == "" {
+			return fmt.Errorf("%sinvite needs the members to invite", e.At())
+		}
+		if err := checkMembers(*e); err != nil {
+			return err
+		}
+	}
+	if e.Tobe != "" && e.Action != ActionUnarchive {
+		return fmt.Errorf("%s'tobe' is only used by rename, merge and unarchive rows, not %s", e.At(), e.Action)
+	}
+	return nil
+}
+
+// String describes the entry for plan and result output, e.g. "a -> b",
+// "archive a", "unarchive a as b" or "merge a into b".
+func (e Entry) String() string {
+	switch {
+	case e.Action == ActionRename && e.Rearchive:
+		return e.Asis + " -> " + e.Tobe + " (archived: unarchive, rename, archive again)"
+	case e.Action == ActionRename:
+		return e.Asis + " -> " + e.Tobe
+	case e.Action == ActionMerge:
+		return "merge " + e.Asis + " into " + e.Tobe
+	case e.Action == ActionUnarchive && e.Tobe != "":
+		return "unarchive " + e.Asis + " as " + e.Tobe
+	case e.Action == ActionCreate && e.Private:
+		return "create " + e.Asis + " (private)"
+	}
+	return e.Action + " " + e.Asis
+}
+
+// ActionLabel is the entry's action for tables, marking a rename of an
+// archived channel.
+func (e Entry) ActionLabel() string {
+	if e.Rearchive {
+		return e.Action + " (archived)"
+	}
+	return e.Action
+}
+
+// EntryChannel returns the channel in channels that entry acts on: the one
+// named entry.Asis or, for an unarchive whose name an active channel has
+// taken, the archived channel validation picked by ID.
+func EntryChannel(channels map[string]slackops.Channel, entry Entry) slackops.Channel {
+	ch := channels[entry.Asis]
+	if entry.Action == ActionUnarchive && ch.ArchivedTwin != "" && entry.ChannelID == ch.ArchivedTwin {
+		return slackops.Channel{ID: ch.ArchivedTwin, IsArchived: true}
+	}
+	return ch
+}
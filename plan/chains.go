@@ -0,0 +1,248 @@
+package plan
+
+import (
+	"cmp"
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"slices"
+	"strings"
+
+	"github.com/kiddikn/slack-channel-renamer/slackops"
+)
+
+// RenameDeps returns, for each entry, the index of the entry that frees its
+// target name, or -1: the rename moving that channel away or, failing that,
+// its archive. Each rename depends on at most one other entry. Renames away
+// from a temporary name free nothing anyone waits for.
+func RenameDeps(plan []Entry) []int {
+	frees := make(map[string]int)
+	for i, e := range plan {
+		switch {
+		case e.Action == ActionRename && e.Asis != e.Via:
+			frees[e.Asis] = i
+		case e.Action == ActionArchive:
+			if _, ok := frees[e.Asis]; !ok {
+				frees[e.Asis] = i
+			}
+		}
+	}
+	dep := make([]int, len(plan))
+	for i, e := range plan {
+		dep[i] = -1
+		if j, ok := frees[e.Tobe]; ok && e.Action == ActionRename && j != i {
+			dep[i] = j
+		}
+	}
+	return dep
+}
+
+// renameCycles returns the entries of every cycle of renames, such as a swap,
+// in dependency order starting from the earliest entry the walk reached.
+func renameCycles(plan []Entry) [][]int {
+	dep := RenameDeps(plan)
+	const (
+		unvisited = iota
+		walking
+		done
+	)
+	state := make([]int, len(plan))
+	var cycles [][]int
+	for start := range plan {
+		var walk []int
+		i := start
+		for i >= 0 && state[i] == unvisited {
+			state[i] = walking
+			walk = append(walk, i)
+			i = dep[i]
+		}
+		if i >= 0 && state[i] == walking {
+			cycles = append(cycles, slices.Clone(walk[slices.Index(walk, i):]))
+		}
+		for _, j := range walk {
+			state[j] = done
+		}
+	}
+	return cycles
+}
+
+// breakCycles routes one rename of every cycle through a temporary name, so
+// that a -> b and b -> a become a -> tmp-xxxx, b -> a and tmp-xxxx -> b once
+// ordered. The rename's via name is reused when it is free, so a plan read
+// back from a plan file keeps its temporary names. Each temporary name is
+// added to channels as an alias of its channel, so that the entry renaming
+// it away can be executed.
+func breakCycles(plan []Entry, channels map[string]slackops.Channel) []Entry {
+	cycles := renameCycles(plan)
+	if len(cycles) == 0 {
+		return plan
+	}
+	taken := make(map[string]bool)
+	for _, e := range plan {
+		taken[e.Asis], taken[e.Tobe] = true, true
+	}
+	free := func(name string) bool {
+		_, exists := channels[name]
+		return name != "" && !exists && !taken[name]
+	}
+
+	split := make(map[int]string)
+	for _, cycle := range cycles {
+		// Prefer an entry that already has a temporary name.
+		pick := slices.Min(cycle)
+		for _, i := range cycle {
+			if free(plan[i].Via) {
+				pick = i
+				break
+			}
+		}
+		tmp := plan[pick].Via
+		for !free(tmp) {
+			tmp = fmt.Sprintf("tmp-%08x", rand.Uint32())
+		}
+		taken[tmp] = true
+		split[pick] = tmp
+	}
+
+	out := make([]Entry, 0, len(plan)+len(split))
+	for i, e := range plan {
+		tmp, ok := split[i]
+		if !ok {
+			out = append(out, e)
+			continue
+		}
+		channels[tmp] = channels[e.Asis]
+		first, second := e, e
+		first.Tobe, first.Topic, first.Purpose, first.Via = tmp, "", "", ""
+		second.Asis, second.Via = tmp, tmp
+		out = append(out, first, second)
+	}
+	return out
+}
+
+// CollapseRenames merges successive renames of the same channel in a resolved
+// plan, such as the two halves of a rename routed through a temporary name,
+// into one rename from the first name to the last that remembers the name in
+// between as via. Renames that bring a channel back to its name are dropped.
+func CollapseRenames(plan []Entry) []Entry {
+	out := make([]Entry, 0, len(plan))
+	latest := make(map[string]int)
+	for _, e := range plan {
+		if e.Action != ActionRename || e.ChannelID == "" {
+			out = append(out, e)
+			continue
+		}
+		if i, ok := latest[e.ChannelID]; ok && out[i].Tobe == e.Asis {
+			out[i].Via, out[i].Tobe = e.Asis, e.Tobe
+			if e.Topic != "" || e.Purpose != "" {
+				out[i].Topic, out[i].Purpose = e.Topic, e.Purpose
+			}
+			continue
+		}
+		latest[e.ChannelID] = len(out)
+		out = append(out, e)
+	}
+	return slices.DeleteFunc(out, func(e Entry) bool {
+		return e.Action == ActionRename && e.Asis == e.Tobe
+	})
+}
+
+// Units returns, for each entry of an ordered plan, the index of the first
+// entry of its unit: the entries that must be applied or skipped together.
+// A unit joins the entries read from the same row with the renames that wait
+// for them and that they wait for, so that approving part of a swap or chain
+// cannot leave a channel stranded under a temporary name.
+func Units(plan []Entry) []int {
+	unit := make([]int, len(plan))
+	for i := range unit {
+		unit[i] = i
+	}
+	var find func(i int) int
+	find = func(i int) int {
+		if unit[i] != i {
+			unit[i] = find(unit[i])
+		}
+		return unit[i]
+	}
+	join := func(i, j int) {
+		a, b := find(i), find(j)
+		unit[max(a, b)] = min(a, b)
+	}
+	for i, j := range RenameDeps(plan) {
+		if j >= 0 {
+			join(i, j)
+		}
+	}
+	first := make(map[string]int)
+	for i, e := range plan {
+		if e.Source == "" {
+			continue
+		}
+		if j, ok := first[e.Source]; ok {
+			join(i, j)
+		} else {
+			first[e.Source] = i
+		}
+	}
+	for i := range unit {
+		find(i)
+	}
+	return unit
+}
+
+// byPriority returns plan stably sorted by priority, entries without one
+// last. order then still moves a rename after the one freeing its name,
+// whatever their priorities.
+func byPriority(plan []Entry) []Entry {
+	rank := func(e Entry) int {
+		if e.Priority == 0 {
+			return math.MaxInt
+		}
+		return e.Priority
+	}
+	slices.SortStableFunc(plan, func(a, b Entry) int { return cmp.Compare(rank(a), rank(b)) })
+	return plan
+}
+
+// order returns plan reordered so that every rename runs after the rename
+// that frees its target name: for a -> b and b -> c, b -> c runs first. Other
+// entries keep their plan order. breakCycles must run first; any cycle left
+// cannot be ordered, so its renames are left out and reported.
+func order(plan []Entry) ([]Entry, []string) {
+	dep := RenameDeps(plan)
+	inCycle := make([]bool, len(plan))
+	var errs []string
+	for _, cycle := range renameCycles(plan) {
+		var names, sources []string
+		for _, i := range cycle {
+			inCycle[i] = true
+			names = append(names, plan[i].Asis)
+			if plan[i].Source != "" {
+				sources = append(sources, plan[i].Source)
+			}
+		}
+		msg := fmt.Sprintf("renames form a cycle: %s -> %s", strings.Join(names, " -> "), names[0])
+		if len(sources) > 0 {
+			msg += fmt.Sprintf(" (%s)", strings.Join(sources, ", "))
+		}
+		errs = append(errs, msg)
+	}
+
+	visited := make([]bool, len(plan))
+	ordered := make([]Entry, 0, len(plan))
+	var visit func(i int)
+	visit = func(i int) {
+		if visited[i] || inCycle[i] {
+			return
+		}
+		visited[i] = true
+		if dep[i] >= 0 {
+			visit(dep[i])
+		}
+		ordered = append(ordered, plan[i])
+	}
+	for i := range plan {
+		visit(i)
+	}
+	return ordered, errs
+}
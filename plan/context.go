@@ -0,0 +1,17 @@
+package plan
+
+import "context"
+
+type fileKey struct{}
+
+// WithFile returns ctx carrying the plan file of the entry it runs, so that
+// the API calls made for each plan file can be told apart.
+func WithFile(ctx context.Context, file string) context.Context {
+	return context.WithValue(ctx, fileKey{}, file)
+}
+
+// FileOf returns the plan file ctx carries, or "" outside any entry.
+func FileOf(ctx context.Context) string {
+	f, _ := ctx.Value(fileKey{}).(string)
+	return f
+}
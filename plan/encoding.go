@@ -0,0 +1,99 @@
+package plan
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/japanese"
+	"golang.org/x/text/encoding/unicode"
+)
+
+// textEncodings maps -encoding values to decoders. "auto" is handled by DecodeText.
+var textEncodings = map[string]encoding.Encoding{
+	"utf-8":     unicode.UTF8BOM,
+	"shift_jis": japanese.ShiftJIS,
+	"utf-16le":  unicode.UTF16(unicode.LittleEndian, unicode.UseBOM),
+	"utf-16be":  unicode.UTF16(unicode.BigEndian, unicode.UseBOM),
+}
+
+// DecodeText converts data to UTF-8. With enc "auto" (or empty), a UTF-8 or
+// UTF-16 byte order mark wins; otherwise UTF-16 is recognised by its NUL
+// bytes, valid UTF-8 is kept as is and anything else is read as Shift-JIS.
+func DecodeText(data []byte, enc string) ([]byte, error) {
+	enc = strings.ToLower(enc)
+	switch enc {
+	case "", "auto":
+		enc = detectEncoding(data)
+	case "sjis", "cp932":
+		enc = "shift_jis"
+	case "utf8":
+		enc = "utf-8"
+	case "utf-16":
+		enc = detectEncoding(data)
+		if !strings.HasPrefix(enc, "utf-16") {
+			enc = "utf-16le"
+		}
+	}
+	e, ok := textEncodings[enc]
+	if !ok {
+		return nil, fmt.Errorf("unknown encoding %q (want auto, utf-8, shift_jis, utf-16, utf-16le or utf-16be)", enc)
+	}
+	out, err := e.NewDecoder().Bytes(data)
+	if err != nil {
+		return nil, fmt.Errorf("decode as %s: %w", enc, err)
+	}
+	return out, nil
+}
+
+func detectEncoding(data []byte) string {
+	switch {
+	case bytes.HasPrefix(data, []byte{0xEF, 0xBB, 0xBF}):
+		return "utf-8"
+	case bytes.HasPrefix(data, []byte{0xFF, 0xFE}):
+		return "utf-16le"
+	case bytes.HasPrefix(data, []byte{0xFE, 0xFF}):
+		return "utf-16be"
+	}
+
+	// Without a BOM, ASCII-heavy UTF-16 text has a NUL in every other byte.
+	sample := data[:min(len(data), 512)]
+	var evenNUL, oddNUL int
+	for i, b := range sample {
+		if b == 0 {
+			if i%2 == 0 {
+				evenNUL++
+			} else {
+				oddNUL++
+			}
+		}
+	}
+	if half := len(sample) / 2; half > 0 {
+		if oddNUL > half/2 {
+			return "utf-16le"
+		}
+		if evenNUL > half/2 {
+			return "utf-16be"
+		}
+	}
+
+	if utf8.Valid(data) {
+		return "utf-8"
+	}
+	return "shift_jis"
+}
+
+// DetectDelimiter picks the most frequent of tab, comma and semicolon on the
+// header line, defaulting to comma.
+func DetectDelimiter(data []byte) rune {
+	header, _, _ := bytes.Cut(data, []byte("\n"))
+	best, bestCount := ',', 0
+	for _, r := range []rune{',', '\t', ';'} {
+		if n := bytes.Count(header, []byte(string(r))); n > bestCount {
+			best, bestCount = r, n
+		}
+	}
+	return best
+}
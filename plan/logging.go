@@ -0,0 +1,18 @@
+package plan
+
+import "github.com/kiddikn/slack-channel-renamer/slackops"
+
+// EntryAttrs returns the fields logged with every operation on entry's channel ch.
+func EntryAttrs(ch slackops.Channel, entry Entry) []any {
+	attrs := []any{"action", entry.Action, "channel_id", ch.ID, "asis", entry.Asis}
+	if entry.Tobe != "" {
+		attrs = append(attrs, "tobe", entry.Tobe)
+	}
+	if entry.Workspace != "" {
+		attrs = append(attrs, "workspace", entry.Workspace)
+	}
+	if entry.Source != "" {
+		attrs = append(attrs, "source", entry.Source)
+	}
+	return attrs
+}
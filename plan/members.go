@@ -0,0 +1,26 @@
+package plan
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// UserIDRe matches Slack user IDs.
+var UserIDRe = regexp.MustCompile(`^[UW][A-Z0-9]{8,}$`)
+
+// MemberList splits a members column into user IDs and email addresses.
+func MemberList(v string) []string {
+	return strings.FieldsFunc(v, func(r rune) bool { return r == ' ' || r == ';' || r == ',' })
+}
+
+// checkMembers reports the members of e that are neither user IDs nor email
+// addresses.
+func checkMembers(e Entry) error {
+	for _, m := range MemberList(e.Members) {
+		if !UserIDRe.MatchString(m) && !strings.Contains(m, "@") {
+			return fmt.Errorf("%smember %q is not a user ID or email address", e.At(), m)
+		}
+	}
+	return nil
+}
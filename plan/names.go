@@ -0,0 +1,39 @@
+package plan
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// NormalizeName returns name as Slack would store it: lowercased,
+// with whitespace turned into hyphens and any other character a channel name
+// cannot hold removed.
+func NormalizeName(name string) string {
+	var b strings.Builder
+	for _, r := range strings.TrimSpace(name) {
+		r = unicode.ToLower(r)
+		switch {
+		case unicode.IsSpace(r):
+			b.WriteRune('-')
+		case r == '-' || r == '_' || unicode.IsLetter(r) || unicode.IsNumber(r):
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// SuffixedName returns name-2, name-3, ... for the first suffix free accepts,
+// shortening name so that the result stays within Slack's 80 characters.
+func SuffixedName(name string, free func(string) bool) string {
+	for n := 2; ; n++ {
+		suffix := fmt.Sprintf("-%d", n)
+		base := name
+		if r := []rune(base); len(r)+len(suffix) > 80 {
+			base = string(r[:80-len(suffix)])
+		}
+		if candidate := base + suffix; free(candidate) {
+			return candidate
+		}
+	}
+}
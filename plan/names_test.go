@@ -0,0 +1,18 @@
+package plan
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSuffixedName(t *testing.T) {
+	taken := map[string]bool{"x-2": true}
+	if got := SuffixedName("x", func(n string) bool { return !taken[n] }); got != "x-3" {
+		t.Errorf("suffixedName = %q, want x-3", got)
+	}
+	long := strings.Repeat("あ", 80)
+	got := SuffixedName(long, func(string) bool { return true })
+	if r := []rune(got); len(r) != 80 || !strings.HasSuffix(got, "-2") {
+		t.Errorf("suffixedName of 80 runes = %q (%d runes)", got, len(r))
+	}
+}
@@ -0,0 +1,296 @@
+// Package plan loads rename plans from CSV, JSON, YAML and .xlsx files and
+// validates them against the channels of a workspace, ordering the entries
+// so that chained and swapped renames can run.
+package plan
+
+import (
+	"bytes"
+	"cmp"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ChannelNameRe matches the channel names Slack accepts.
+var ChannelNameRe = regexp.MustCompile(`^[a-z0-9_\-\p{L}\p{N}]{1,80}$`)
+
+// channelIDRe matches Slack conversation IDs. Channel names are lowercase, so
+// an asis value in this form is treated as an ID rather than a name.
+var channelIDRe = regexp.MustCompile(`^[CG][A-Z0-9]{8,}$`)
+
+// An Entry is one row of a plan: an action on a channel.
+type Entry struct {
+	// Action is one of the Action* constants; loaders default it to rename.
+	Action string
+
+	Asis  string
+	Tobe  string
+	Owner string
+
+	// Topic and Purpose, when set, are applied after a successful rename.
+	Topic   string
+	Purpose string
+
+	// OldTopic and OldPurpose are the values a set-topic entry replaces,
+	// filled in by validation for the plan output.
+	OldTopic   string
+	OldPurpose string
+
+	// Private and Members are the privacy and the initial members (user IDs
+	// or email addresses) of the channel a create entry makes; Members are
+	// also the users an invite entry invites.
+	Private bool
+	Members string
+
+	// Source records where the entry was read from (file:line) for error messages.
+	Source string
+	// File is the plan file, URL or sheet the entry was read from, by which
+	// -per-plan reports.
+	File string
+
+	// ChannelID is set when the plan identifies the channel by ID, either in a
+	// channel_id column, as an ID-shaped asis value or in a resolved plan file.
+	ChannelID string
+
+	// Workspace names the -config workspace the entry runs against; empty
+	// means the only configured workspace or the one chosen with -workspace.
+	Workspace string
+
+	// Team and State are what a resolved plan file recorded of the entry's
+	// workspace and channel, for the CLI to check against the live ones.
+	Team  string
+	State string
+
+	// Stats, when -stats is set, describes the channel for the plan output.
+	Stats *ChannelStats
+
+	// Priority, from a priority or order column, runs the entry before those
+	// with a higher one or none; 0 means none.
+	Priority int
+
+	// NotBefore, from a not_before column, is the earliest time apply may
+	// run the entry; zero means any time.
+	NotBefore time.Time
+
+	// Disabled is set by a truthy skip column: the row stays in the plan
+	// file but validation skips it.
+	Disabled bool
+
+	// Rearchive is set by -include-archived on a rename of an archived
+	// channel, which is unarchived for the rename and archived again.
+	Rearchive bool
+
+	// PinnedNote is the timestamp of the note with the old name that
+	// -pin-old-name pinned after the rename, carried into the rollback file
+	// so that rollback can unpin it.
+	PinnedNote string
+
+	// HighImpact lists the channels over -max-members-without-confirm the
+	// entry touches, which apply asks to confirm, e.g. "#general (10234
+	// members)".
+	HighImpact string
+
+	// ChannelTeam is the workspace the entry's channel belongs to, set on
+	// Enterprise Grid listings that span several; see MarkChannelTeams.
+	ChannelTeam string
+
+	// Shared says how the channel the entry changes is shared beyond the
+	// workspace, for entries -include-shared lets through; see
+	// slackops.Channel.Sharing.
+	Shared string
+
+	// Via is the temporary name a rename in a cycle is routed through. It is
+	// set on the second half of a rename split by breakCycles, and on a rename
+	// collapsed from a plan file, whose temporary name breakCycles reuses.
+	Via string
+}
+
+// At returns the entry's source as a "file:line: " message prefix, or "" if unknown.
+func (e Entry) At() string {
+	if e.Source == "" {
+		return ""
+	}
+	return e.Source + ": "
+}
+
+// Options carries format-specific settings to the loaders.
+type Options struct {
+	// Sheet selects the worksheet of an .xlsx plan; empty means the first sheet.
+	Sheet string
+
+	// Delimiter is the CSV field separator; 0 means detect it from the header line.
+	Delimiter rune
+
+	// Encoding is the CSV text encoding; empty or "auto" means detect it.
+	Encoding string
+}
+
+// LoadCSV parses a mapping CSV and returns a slice of rename entries.
+// The text encoding and field delimiter are detected unless opts sets them.
+func LoadCSV(path string, data []byte, opts Options) ([]Entry, error) {
+	data, err := DecodeText(data, opts.Encoding)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	delim := opts.Delimiter
+	if delim == 0 {
+		delim = DetectDelimiter(data)
+	}
+
+	r := csv.NewReader(bytes.NewReader(data))
+	r.Comma = delim
+	r.Comment = '#'
+	r.TrimLeadingSpace = true
+
+	// Comment and blank lines are dropped by the reader, so record the line
+	// each row starts on.
+	var records [][]string
+	var lines []int
+	for {
+		row, err := r.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("parse %s: %w", path, err)
+		}
+		line, _ := r.FieldPos(0)
+		records = append(records, row)
+		lines = append(lines, line)
+	}
+	return ParseRows(path, records, lines)
+}
+
+// ParseRows converts tabular rows (a header row followed by data rows) into
+// rename entries. lines gives the source line of each row; if nil, rows are
+// assumed to be on consecutive lines starting at 1. Rows whose first cell
+// starts with '#' are comments.
+func ParseRows(path string, records [][]string, lines []int) ([]Entry, error) {
+	var kept [][]string
+	var keptLines []int
+	for i, row := range records {
+		if len(row) > 0 && strings.HasPrefix(strings.TrimSpace(row[0]), "#") {
+			continue
+		}
+		line := i + 1
+		if lines != nil {
+			line = lines[i]
+		}
+		kept = append(kept, row)
+		keptLines = append(keptLines, line)
+	}
+	records = kept
+	lineOf := func(i int) int { return keptLines[i] }
+	if len(records) == 0 {
+		return nil, fmt.Errorf("%s is empty", path)
+	}
+
+	hdr := records[0]
+	if len(hdr) < 2 ||
+		strings.ToLower(strings.TrimSpace(hdr[0])) != "asis" ||
+		strings.ToLower(strings.TrimSpace(hdr[1])) != "tobe" {
+		return nil, fmt.Errorf("%s: header must be 'asis,tobe', got: %v", path, hdr)
+	}
+	if len(records) < 2 {
+		return nil, fmt.Errorf("%s has no data rows", path)
+	}
+
+	// Optional columns after asis,tobe are located by header name.
+	optCols := make(map[string]int)
+	for i, col := range hdr[2:] {
+		optCols[strings.ToLower(strings.TrimSpace(col))] = i + 2
+	}
+	optional := func(row []string, name string) string {
+		if i, ok := optCols[name]; ok && i < len(row) {
+			return strings.TrimSpace(row[i])
+		}
+		return ""
+	}
+
+	entries := make([]Entry, 0, len(records)-1)
+	for i, row := range records[1:] {
+		lineNum := lineOf(i + 1)
+		if len(row) < 2 {
+			return nil, fmt.Errorf("%s:%d: expected 2 columns, got %d", path, lineNum, len(row))
+		}
+		entry := Entry{
+			Action:    strings.ToLower(optional(row, "action")),
+			Asis:      strings.TrimSpace(row[0]),
+			Tobe:      strings.TrimSpace(row[1]),
+			Owner:     optional(row, "owner"),
+			Topic:     optional(row, "topic"),
+			Purpose:   optional(row, "purpose"),
+			Members:   optional(row, "members"),
+			ChannelID: optional(row, "channel_id"),
+			Workspace: optional(row, "workspace"),
+			Source:    fmt.Sprintf("%s:%d", path, lineNum),
+		}
+		disabled, err := parseSkip(optional(row, "skip"))
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", entry.Source, err)
+		}
+		entry.Disabled = disabled
+		if entry.Priority, err = parsePriority(cmp.Or(optional(row, "priority"), optional(row, "order"))); err != nil {
+			return nil, fmt.Errorf("%s: %w", entry.Source, err)
+		}
+		if entry.NotBefore, err = ParseNotBefore(optional(row, "not_before")); err != nil {
+			return nil, fmt.Errorf("%s: %w", entry.Source, err)
+		}
+		if err := entry.Check(); err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+// parsePriority reads the priority or order column: empty, or a positive
+// number, lowest first.
+func parsePriority(v string) (int, error) {
+	if v == "" {
+		return 0, nil
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil || n < 1 {
+		return 0, fmt.Errorf("priority must be a positive number, got %q", v)
+	}
+	return n, nil
+}
+
+// parseSkip reads the skip column: empty, "no" or a false boolean keeps the
+// row; "yes", "skip", "x" or a true boolean disables it.
+func parseSkip(v string) (bool, error) {
+	switch strings.ToLower(v) {
+	case "", "no", "n":
+		return false, nil
+	case "yes", "y", "skip", "x":
+		return true, nil
+	}
+	skip, err := strconv.ParseBool(v)
+	if err != nil {
+		return false, fmt.Errorf("skip must be empty, yes, no, true or false, got %q", v)
+	}
+	return skip, nil
+}
+
+// notBeforeLayouts are the forms a not_before time is read in; those
+// without a zone are in local time.
+var notBeforeLayouts = []string{time.RFC3339, "2006-01-02T15:04", "2006-01-02 15:04", time.DateOnly}
+
+// ParseNotBefore reads a not_before column; empty means any time.
+func ParseNotBefore(v string) (time.Time, error) {
+	if v == "" {
+		return time.Time{}, nil
+	}
+	for _, layout := range notBeforeLayouts {
+		if t, err := time.ParseInLocation(layout, v, time.Local); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("not_before must be a time such as 2026-04-01T09:00:00+09:00 or 2026-04-01 09:00, got %q", v)
+}
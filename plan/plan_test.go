@@ -0,0 +1,39 @@
+package plan
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestParseNotBefore(t *testing.T) {
+	for _, v := range []string{"2026-04-01T09:00:00+09:00", "2026-04-01 09:00", "2026-04-01T09:00", "2026-04-01"} {
+		if got, err := ParseNotBefore(v); err != nil || got.IsZero() {
+			t.Errorf("parseNotBefore(%q) = %v, %v", v, got, err)
+		}
+	}
+	if _, err := ParseNotBefore("next tuesday"); err == nil {
+		t.Error("parseNotBefore accepted a malformed time")
+	}
+}
+
+func TestLoadCSVCommentsAndSkip(t *testing.T) {
+	data := "# reviewed by ops\nasis,tobe,skip\n\nold-a,new-a,\n# waiting on the owner\nold-b,new-b,yes\nold-c,new-c,false\n"
+	entries, err := LoadCSV("plan.csv", []byte(data), Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, e := range entries {
+		got = append(got, fmt.Sprintf("%s %s %v", e.Source, e.Asis, e.Disabled))
+	}
+	want := []string{"plan.csv:4 old-a false", "plan.csv:6 old-b true", "plan.csv:7 old-c false"}
+	if !slices.Equal(got, want) {
+		t.Errorf("entries = %q, want %q", got, want)
+	}
+
+	if _, err := LoadCSV("plan.csv", []byte("asis,tobe,skip\na,b,maybe\n"), Options{}); err == nil || !strings.Contains(err.Error(), "plan.csv:2: skip must be") {
+		t.Errorf("err = %v, want an invalid skip value", err)
+	}
+}
@@ -0,0 +1,110 @@
+package plan
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Record is one entry of a JSON or YAML plan.
+type Record struct {
+	Action    string `json:"action,omitempty" yaml:"action,omitempty"`
+	Asis      string `json:"asis" yaml:"asis"`
+	Tobe      string `json:"tobe,omitempty" yaml:"tobe,omitempty"`
+	Owner     string `json:"owner,omitempty" yaml:"owner,omitempty"`
+	Topic     string `json:"topic,omitempty" yaml:"topic,omitempty"`
+	Purpose   string `json:"purpose,omitempty" yaml:"purpose,omitempty"`
+	Members   string `json:"members,omitempty" yaml:"members,omitempty"`
+	ChannelID string `json:"channel_id,omitempty" yaml:"channel_id,omitempty"`
+	Workspace string `json:"workspace,omitempty" yaml:"workspace,omitempty"`
+	Skip      bool   `json:"skip,omitempty" yaml:"skip,omitempty"`
+	Priority  int    `json:"priority,omitempty" yaml:"priority,omitempty"`
+	Order     int    `json:"order,omitempty" yaml:"order,omitempty"`
+	NotBefore string `json:"not_before,omitempty" yaml:"not_before,omitempty"`
+}
+
+// toEntry applies the same checks as LoadCSV to a structured plan record.
+func (r Record) toEntry(source string) (Entry, error) {
+	e := Entry{
+		Action:    strings.ToLower(strings.TrimSpace(r.Action)),
+		Asis:      strings.TrimSpace(r.Asis),
+		Tobe:      strings.TrimSpace(r.Tobe),
+		Owner:     strings.TrimSpace(r.Owner),
+		Topic:     strings.TrimSpace(r.Topic),
+		Purpose:   strings.TrimSpace(r.Purpose),
+		Members:   strings.TrimSpace(r.Members),
+		ChannelID: strings.TrimSpace(r.ChannelID),
+		Workspace: strings.TrimSpace(r.Workspace),
+		Disabled:  r.Skip,
+		Priority:  cmp.Or(r.Priority, r.Order),
+		Source:    source,
+	}
+	if e.Priority < 0 {
+		return Entry{}, fmt.Errorf("%s: priority must be a positive number, got %d", source, e.Priority)
+	}
+	var err error
+	if e.NotBefore, err = ParseNotBefore(strings.TrimSpace(r.NotBefore)); err != nil {
+		return Entry{}, fmt.Errorf("%s: %w", source, err)
+	}
+	if err := e.Check(); err != nil {
+		return Entry{}, err
+	}
+	return e, nil
+}
+
+// LoadJSON reads a JSON array of {"asis", "tobe", ...} objects.
+func LoadJSON(path string, data []byte, _ Options) ([]Entry, error) {
+	var records []Record
+	if err := json.Unmarshal(data, &records); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", path, err)
+	}
+	if len(records) == 0 {
+		return nil, fmt.Errorf("%s has no entries", path)
+	}
+
+	entries := make([]Entry, 0, len(records))
+	for i, r := range records {
+		e, err := r.toEntry(fmt.Sprintf("%s entry %d", path, i+1))
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, e)
+	}
+	return entries, nil
+}
+
+// LoadYAML reads a YAML sequence of asis/tobe mappings. Entries are
+// attributed to the line they start on.
+func LoadYAML(path string, data []byte, _ Options) ([]Entry, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", path, err)
+	}
+	if len(doc.Content) == 0 {
+		return nil, fmt.Errorf("%s has no entries", path)
+	}
+	root := doc.Content[0]
+	if root.Kind != yaml.SequenceNode {
+		return nil, fmt.Errorf("%s:%d: plan must be a list of asis/tobe entries", path, root.Line)
+	}
+	if len(root.Content) == 0 {
+		return nil, fmt.Errorf("%s has no entries", path)
+	}
+	entries := make([]Entry, 0, len(root.Content))
+	for _, n := range root.Content {
+		source := fmt.Sprintf("%s:%d", path, n.Line)
+		var r Record
+		if err := n.Decode(&r); err != nil {
+			return nil, fmt.Errorf("%s: %w", source, err)
+		}
+		e, err := r.toEntry(source)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, e)
+	}
+	return entries, nil
+}
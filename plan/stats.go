@@ -0,0 +1,29 @@
+package plan
+
+import (
+	"fmt"
+	"time"
+)
+
+// ChannelStats are the details of an entry's channel that -stats prints
+// next to it, so that reviewers can judge how many people a change reaches.
+type ChannelStats struct {
+	Members      int       `json:"members"`
+	Creator      string    `json:"creator,omitempty"`
+	Created      time.Time `json:"created,omitzero"`
+	LastActivity time.Time `json:"last_activity,omitzero"`
+}
+
+func (st ChannelStats) String() string {
+	s := fmt.Sprintf("%d members", st.Members)
+	if !st.Created.IsZero() {
+		s += ", created " + st.Created.Format(time.DateOnly)
+	}
+	if st.Creator != "" {
+		s += " by " + st.Creator
+	}
+	if !st.LastActivity.IsZero() {
+		s += ", last message " + st.LastActivity.Format(time.DateOnly)
+	}
+	return s
+}
@@ -0,0 +1,355 @@
+package plan
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/kiddikn/slack-channel-renamer/slackops"
+	"github.com/slack-go/slack"
+)
+
+// MarkArchivedRenames marks, for -include-archived, the renames of archived
+// channels to be unarchived for the rename and archived again.
+func MarkArchivedRenames(plan []Entry, channels map[string]slackops.Channel) []Entry {
+	for i, e := range plan {
+		if e.Action == ActionRename && channels[e.Asis].IsArchived {
+			plan[i].Rearchive = true
+		}
+	}
+	return plan
+}
+
+// MarkChannelTeams notes on each entry the workspace its channel belongs to,
+// for the plan to show when the channels span several workspaces of an org,
+// or always with always, as when -team-id picked one or -admin searched the
+// whole org.
+func MarkChannelTeams(plan []Entry, channels map[string]slackops.Channel, always bool) []Entry {
+	teams := make(map[string]bool)
+	for _, ch := range channels {
+		if ch.TeamID != "" {
+			teams[ch.TeamID] = true
+		}
+	}
+	if len(teams) < 2 && !always {
+		return plan
+	}
+	for i, e := range plan {
+		if e.Action != ActionCreate {
+			plan[i].ChannelTeam = channels[e.Asis].TeamID
+		}
+	}
+	return plan
+}
+
+// SkipShared leaves out the entries that change a shared channel,
+// or the channel a merge goes into, since everyone it is shared with sees
+// the change. With includeShared they are kept and marked instead, for the
+// plan to point them out.
+func SkipShared(plan []Entry, channels map[string]slackops.Channel, includeShared bool) (kept []Entry, skipped []string) {
+	for _, e := range plan {
+		ch, ok := channels[e.Asis]
+		if e.Action == ActionCreate || !ok {
+			ch = slackops.Channel{}
+		}
+		e.Shared = ch.Sharing()
+		if e.Shared == "" && e.Action == ActionMerge {
+			if target := channels[e.Tobe].Sharing(); target != "" {
+				e.Shared = "merge target " + target
+			}
+		}
+		if e.Shared != "" && !includeShared && !e.Disabled {
+			skipped = append(skipped, e.At()+fmt.Sprintf("channel %q is %s, skipping (pass -include-shared to change it)", e.Asis, e.Shared))
+			continue
+		}
+		kept = append(kept, e)
+	}
+	if len(skipped) > 0 {
+		slog.Warn("skipping plan rows that change shared channels", "count", len(skipped))
+	}
+	return kept, skipped
+}
+
+// History is a record of renames made before, by which Validate
+// skips the renames of a plan that was already applied.
+type History interface {
+	Renamed(e Entry, ch slackops.Channel) bool
+}
+
+// Validate checks that all plan operations are safe to execute without executing any of them.
+// It returns the entries to execute, in plan order except where order moves a chained
+// rename after the rename it depends on and breakCycles routes a cycle through a
+// temporary name, along with all validation errors and
+// skipped entries (e.g. archived channels, or renames past shows were already made).
+// Errors and skips are grouped by action.
+func Validate(plan []Entry, channels map[string]slackops.Channel, notFoundHint string, past History) (active []Entry, errs []string, skipped []string) {
+	// Collect the sources of each rename target to detect duplicates. Slack
+	// compares names case-insensitively, so targets are keyed in the form
+	// Slack would store them.
+	tobeSources := make(map[string][]string)
+	for _, e := range plan {
+		switch {
+		case e.Disabled:
+		case e.Action == ActionRename || e.Action == ActionUnarchive && e.Tobe != "":
+			key := NormalizeName(e.Tobe)
+			tobeSources[key] = append(tobeSources[key], e.Source)
+		case e.Action == ActionCreate:
+			key := NormalizeName(e.Asis)
+			tobeSources[key] = append(tobeSources[key], e.Source)
+		}
+	}
+	duplicatesReported := make(map[string]bool)
+
+	// Names that a rename in the plan moves a channel away from, or whose
+	// channel the plan archives, so that another rename may take them over
+	// (a -> b, b -> c).
+	renamedAway := make(map[string]bool)
+	for _, e := range plan {
+		if !e.Disabled && (e.Action == ActionRename && e.Asis != e.Tobe || e.Action == ActionArchive) {
+			renamedAway[e.Asis] = true
+		}
+	}
+
+	errsByAction := make(map[string][]string)
+	skippedByAction := make(map[string][]string)
+	fail := func(e Entry, msg string) { errsByAction[e.Action] = append(errsByAction[e.Action], msg) }
+	skip := func(e Entry, msg string) { skippedByAction[e.Action] = append(skippedByAction[e.Action], msg) }
+
+	// validTarget checks the name a rename, or an unarchive that renames its
+	// channel, gives the channel.
+	validTarget := func(e Entry) bool {
+		valid := true
+		key := NormalizeName(e.Tobe)
+		switch {
+		case !ChannelNameRe.MatchString(key):
+			fail(e, e.At()+fmt.Sprintf("channel name %q is invalid (must match ^[a-z0-9_-]{1,80}$)", e.Tobe))
+			valid = false
+		case key != e.Tobe:
+			fail(e, e.At()+fmt.Sprintf("channel name %q would be stored by Slack as %q (pass -auto-fix to use that)", e.Tobe, key))
+			valid = false
+		}
+
+		if e.Asis != key {
+			if existing, exists := channels[key]; exists && !existing.IsArchived && !renamedAway[key] {
+				fail(e, e.At()+fmt.Sprintf("target channel %q already exists", key))
+				valid = false
+			}
+		}
+
+		if sources := tobeSources[key]; len(sources) > 1 {
+			valid = false
+			if !duplicatesReported[key] {
+				msg := fmt.Sprintf("duplicate tobe target: %q", key)
+				if e.Source != "" {
+					msg += fmt.Sprintf(" (%s)", strings.Join(sources, ", "))
+				}
+				fail(e, msg)
+				duplicatesReported[key] = true
+			}
+		}
+		return valid
+	}
+
+	for _, e := range plan {
+		if e.Disabled {
+			skip(e, e.At()+"row is disabled by its skip column, skipping")
+			continue
+		}
+		if e.Action == ActionCreate {
+			if ch, exists := channels[e.Asis]; exists {
+				state := ""
+				if ch.IsArchived {
+					state = " (archived)"
+				}
+				skip(e, e.At()+fmt.Sprintf("channel %q already exists%s, skipping", e.Asis, state))
+			} else if validTarget(Entry{Action: e.Action, Tobe: e.Asis, Source: e.Source}) {
+				active = append(active, e)
+			}
+			continue
+		}
+		ch, ok := channels[e.Asis]
+		if !ok {
+			if target, exists := channels[e.Tobe]; exists && e.Action == ActionRename && past != nil && past.Renamed(e, target) {
+				skip(e, e.At()+fmt.Sprintf("already renamed: channel %q is now named %q, skipping", e.Asis, e.Tobe))
+				continue
+			}
+			fail(e, e.At()+fmt.Sprintf("channel %q not found%s", e.Asis, notFoundHint))
+			continue
+		}
+
+		switch e.Action {
+		case ActionArchive:
+			if ch.IsArchived {
+				skip(e, e.At()+fmt.Sprintf("channel %q is already archived, skipping", e.Asis))
+				continue
+			}
+			active = append(active, e)
+			continue
+		case ActionUnarchive:
+			if !ch.IsArchived && ch.ArchivedTwin != "" && (e.ChannelID == "" || e.ChannelID == ch.ArchivedTwin) {
+				// An active channel has taken the name of the archived one.
+				if e.Tobe == "" || NormalizeName(e.Tobe) == e.Asis {
+					free := SuffixedName(e.Asis, func(name string) bool { _, exists := channels[name]; return !exists })
+					fail(e, e.At()+fmt.Sprintf("archived channel %s cannot be unarchived as %q, which active channel %s holds; give it a tobe such as %q to rename it on unarchive", ch.ArchivedTwin, e.Asis, ch.ID, free))
+					continue
+				}
+				e.ChannelID, ch = ch.ArchivedTwin, slackops.Channel{ID: ch.ArchivedTwin, IsArchived: true}
+			}
+			if !ch.IsArchived {
+				skip(e, e.At()+fmt.Sprintf("channel %q is not archived, skipping", e.Asis))
+				continue
+			}
+			if e.Tobe == "" || validTarget(e) {
+				active = append(active, e)
+			}
+			continue
+		case ActionMerge:
+			if ch.IsArchived {
+				skip(e, e.At()+fmt.Sprintf("channel %q is archived, skipping", e.Asis))
+				continue
+			}
+			switch target, ok := channels[e.Tobe]; {
+			case !ok:
+				fail(e, e.At()+fmt.Sprintf("merge target %q not found%s", e.Tobe, notFoundHint))
+			case target.IsArchived:
+				fail(e, e.At()+fmt.Sprintf("merge target %q is archived", e.Tobe))
+			case target.ID == ch.ID:
+				fail(e, e.At()+fmt.Sprintf("channel %q cannot be merged into itself", e.Asis))
+			default:
+				active = append(active, e)
+			}
+			continue
+		}
+
+		if ch.IsArchived && !(e.Rearchive && e.Action == ActionRename) {
+			skip(e, e.At()+fmt.Sprintf("channel %q is archived, skipping (pass -include-archived to rename it)", e.Asis))
+			continue
+		}
+		if e.Action == ActionInvite {
+			active = append(active, e)
+			continue
+		}
+		if e.Action == ActionSetTopic {
+			// Only what differs from the channel is set.
+			if e.Topic == slackops.Unescape(ch.Topic) {
+				e.Topic = ""
+			}
+			if e.Purpose == slackops.Unescape(ch.Purpose) {
+				e.Purpose = ""
+			}
+			if e.Topic == "" && e.Purpose == "" {
+				skip(e, e.At()+fmt.Sprintf("channel %q already has this topic and purpose, skipping", e.Asis))
+				continue
+			}
+			e.OldTopic, e.OldPurpose = slackops.Unescape(ch.Topic), slackops.Unescape(ch.Purpose)
+			active = append(active, e)
+			continue
+		}
+
+		if validTarget(e) {
+			active = append(active, e)
+		}
+	}
+
+	// A rename into a name still held by another channel is only valid while
+	// the rename or archive freeing it is; dropping one can break a chain.
+	for dropped := true; dropped; {
+		dropped = false
+		activeAsis := make(map[string]bool)
+		for _, e := range active {
+			if e.Action == ActionRename || e.Action == ActionArchive {
+				activeAsis[e.Asis] = true
+			}
+		}
+		kept := active[:0]
+		for _, e := range active {
+			existing, exists := channels[e.Tobe]
+			if e.Action == ActionRename && e.Asis != e.Tobe && exists && !existing.IsArchived && !activeAsis[e.Tobe] {
+				fail(e, e.At()+fmt.Sprintf("target channel %q already exists and the rename moving it away cannot run", e.Tobe))
+				dropped = true
+				continue
+			}
+			kept = append(kept, e)
+		}
+		active = kept
+	}
+
+	active, cycleErrs := order(breakCycles(byPriority(active), channels))
+	errsByAction[ActionRename] = append(errsByAction[ActionRename], cycleErrs...)
+
+	for _, action := range ActionOrder {
+		errs = append(errs, errsByAction[action]...)
+		skipped = append(skipped, skippedByAction[action]...)
+	}
+	return active, errs, skipped
+}
+
+// ResolveIDs looks up every plan entry that carries a channel ID with
+// conversations.info, replaces its asis with the channel's live name and adds
+// the channel to channels. This keeps ID-based rows working even when the
+// channel was renamed since the plan was written or is missing from the listing.
+// Entries whose ID cannot be resolved are reported and dropped from the result.
+func ResolveIDs(ctx context.Context, client slackops.API, stats *slackops.Stats, plan []Entry, channels map[string]slackops.Channel, opts slackops.ListOptions) ([]Entry, []string) {
+	resolved := make([]Entry, 0, len(plan))
+	var errs []string
+	for _, e := range plan {
+		if e.ChannelID == "" {
+			resolved = append(resolved, e)
+			continue
+		}
+		var info *slack.Channel
+		err := slackops.Call(ctx, stats, fmt.Sprintf("looking up %s", e.ChannelID), func(ctx context.Context) error {
+			var err error
+			info, err = client.GetConversationInfoContext(ctx, &slack.GetConversationInfoInput{ChannelID: e.ChannelID, IncludeNumMembers: true})
+			return err
+		}, "channel_id", e.ChannelID)
+		if err != nil {
+			errs = append(errs, e.At()+fmt.Sprintf("channel %s: %v", e.ChannelID, err))
+			continue
+		}
+		if !slackops.InTeam(*info, opts.TeamID) {
+			errs = append(errs, e.At()+fmt.Sprintf("channel %s (%q) belongs to workspace %s, not -team-id %s", e.ChannelID, info.Name, info.ContextTeamID, opts.TeamID))
+			continue
+		}
+		if info.IsPrivate && !opts.IncludePrivate {
+			errs = append(errs, e.At()+fmt.Sprintf("channel %s (%q) is private (pass -include-private to act on it)", e.ChannelID, info.Name))
+			continue
+		}
+		if e.Asis != e.ChannelID && e.Asis != info.Name {
+			slog.Info(e.At()+"channel was renamed since the plan was written, using the live name", "channel_id", e.ChannelID, "asis", e.Asis, "live", info.Name)
+		}
+		e.Asis = info.Name
+		// Keep what the listing already knows about the channel and fill in
+		// only what it is missing.
+		ch, ok := channels[info.Name]
+		if ok && ch.ID != info.ID && info.IsArchived && !ch.IsArchived {
+			// The listing has an active channel by that name.
+			ch.ArchivedTwin = info.ID
+			channels[info.Name] = ch
+			resolved = append(resolved, e)
+			continue
+		}
+		if !ok || ch.ID != info.ID {
+			ch = slackops.Channel{ID: info.ID, IsArchived: info.IsArchived, IsPrivate: info.IsPrivate, IsGeneral: info.IsGeneral, IsMember: info.IsMember, IsExtShared: info.IsExtShared, IsOrgShared: info.IsOrgShared, TeamID: info.ContextTeamID}
+		}
+		if ch.Creator == "" {
+			ch.Creator = info.Creator
+		}
+		if ch.Created.IsZero() && info.Created != 0 {
+			ch.Created = info.Created.Time().UTC()
+		}
+		if ch.NumMembers == 0 {
+			ch.NumMembers = info.NumMembers
+		}
+		if ch.Topic == "" {
+			ch.Topic = info.Topic.Value
+		}
+		if ch.Purpose == "" {
+			ch.Purpose = info.Purpose.Value
+		}
+		channels[info.Name] = ch
+		resolved = append(resolved, e)
+	}
+	return resolved, errs
+}
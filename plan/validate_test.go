@@ -0,0 +1,123 @@
+package plan
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/kiddikn/slack-channel-renamer/slackops"
+	"github.com/slack-go/slack"
+)
+
+func TestValidatePlan(t *testing.T) {
+	channels := map[string]slackops.Channel{
+		"a":        {ID: "C1"},
+		"b":        {ID: "C2"},
+		"c":        {ID: "C3"},
+		"archived": {ID: "C4", IsArchived: true},
+	}
+	rename := func(asis, tobe string) Entry { return Entry{Action: ActionRename, Asis: asis, Tobe: tobe} }
+	for _, tc := range []struct {
+		name    string
+		plan    []Entry
+		active  int
+		errs    []string // substrings of the errors, in order
+		skipped int
+	}{
+		{"rename", []Entry{rename("a", "x")}, 1, nil, 0},
+		{"not found", []Entry{rename("nope", "x")}, 0, []string{`channel "nope" not found`}, 0},
+		{"target exists", []Entry{rename("a", "b")}, 0, []string{`target channel "b" already exists`}, 0},
+		{"invalid name", []Entry{rename("a", "!!!")}, 0, []string{"is invalid"}, 0},
+		{"name Slack would change", []Entry{rename("a", "Upper")}, 0, []string{`would be stored by Slack as "upper"`}, 0},
+		{"duplicate targets", []Entry{rename("a", "x"), rename("b", "x")}, 0, []string{`duplicate tobe target: "x"`}, 0},
+		{"chain", []Entry{rename("a", "b"), rename("b", "x")}, 2, nil, 0},
+		{"swap", []Entry{rename("a", "b"), rename("b", "a")}, 3, nil, 0},
+		{"three-way cycle", []Entry{rename("a", "b"), rename("b", "c"), rename("c", "a")}, 4, nil, 0},
+		{
+			"a chain whose first link fails",
+			[]Entry{rename("a", "b"), rename("b", "c")},
+			0,
+			[]string{`target channel "c" already exists`, `target channel "b" already exists and the rename moving it away cannot run`},
+			0,
+		},
+		{"archived channels are skipped", []Entry{rename("archived", "x"), {Action: ActionArchive, Asis: "archived"}}, 0, nil, 2},
+		{"archive then take the name", []Entry{{Action: ActionArchive, Asis: "b"}, rename("a", "b")}, 2, nil, 0},
+		{"unarchive a live channel", []Entry{{Action: ActionUnarchive, Asis: "a"}}, 0, nil, 1},
+		{"disabled rows are skipped", []Entry{rename("a", "x"), {Action: ActionRename, Asis: "b", Tobe: "x", Disabled: true}}, 1, nil, 1},
+		{"merge into itself", []Entry{{Action: ActionMerge, Asis: "a", Tobe: "a"}}, 0, []string{"cannot be merged into itself"}, 0},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			active, errs, skipped := Validate(tc.plan, channels, "", nil)
+			if len(active) != tc.active {
+				t.Errorf("%d active entries, want %d: %v", len(active), tc.active, active)
+			}
+			if len(errs) != len(tc.errs) {
+				t.Fatalf("errors = %q, want %d", errs, len(tc.errs))
+			}
+			for i, want := range tc.errs {
+				if !strings.Contains(errs[i], want) {
+					t.Errorf("error %d = %q, want it to contain %q", i, errs[i], want)
+				}
+			}
+			if len(skipped) != tc.skipped {
+				t.Errorf("skipped = %q, want %d", skipped, tc.skipped)
+			}
+		})
+	}
+}
+
+func TestValidatePlanPriority(t *testing.T) {
+	channels := map[string]slackops.Channel{"a": {ID: "C1"}, "b": {ID: "C2"}, "c": {ID: "C3"}, "d": {ID: "C4"}}
+	plan := []Entry{
+		{Action: ActionRename, Asis: "a", Tobe: "a2"},
+		{Action: ActionRename, Asis: "b", Tobe: "b2", Priority: 2},
+		{Action: ActionRename, Asis: "c", Tobe: "d", Priority: 1},
+		{Action: ActionRename, Asis: "d", Tobe: "d2", Priority: 3},
+	}
+	active, errs, _ := Validate(plan, channels, "", nil)
+	if len(errs) > 0 {
+		t.Fatal(errs)
+	}
+	var got []string
+	for _, e := range active {
+		got = append(got, e.Asis)
+	}
+	// c -> d waits for d -> d2 despite its priority.
+	if want := []string{"d", "c", "b", "a"}; !slices.Equal(got, want) {
+		t.Errorf("order = %q, want %q", got, want)
+	}
+}
+
+func TestInTeam(t *testing.T) {
+	ch := func(team string, shared ...string) slack.Channel {
+		var c slack.Channel
+		c.ContextTeamID, c.SharedTeamIDs = team, shared
+		return c
+	}
+	for _, c := range []struct {
+		ch     slack.Channel
+		teamID string
+		want   bool
+	}{
+		{ch("T1"), "", true},
+		{ch("T1"), "T1", true},
+		{ch("T2"), "T1", false},
+		{ch("T2", "T1"), "T1", true},
+		{ch(""), "T1", true},
+	} {
+		if got := slackops.InTeam(c.ch, c.teamID); got != c.want {
+			t.Errorf("InTeam(%q %v, %q) = %v, want %v", c.ch.ContextTeamID, c.ch.SharedTeamIDs, c.teamID, got, c.want)
+		}
+	}
+
+	channels := map[string]slackops.Channel{"a": {ID: "C1", TeamID: "T1"}, "b": {ID: "C2", TeamID: "T2"}}
+	plan := MarkChannelTeams([]Entry{{Action: ActionRename, Asis: "a", Tobe: "x"}, {Action: ActionArchive, Asis: "b"}}, channels, false)
+	if plan[0].ChannelTeam != "T1" || plan[1].ChannelTeam != "T2" {
+		t.Errorf("markChannelTeams = %q, %q; want T1, T2", plan[0].ChannelTeam, plan[1].ChannelTeam)
+	}
+	delete(channels, "b")
+	plan = MarkChannelTeams([]Entry{{Action: ActionRename, Asis: "a", Tobe: "x"}}, channels, false)
+	if plan[0].ChannelTeam != "" {
+		t.Errorf("a listing of one workspace marked %q", plan[0].ChannelTeam)
+	}
+}
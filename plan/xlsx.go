@@ -0,0 +1,206 @@
+package plan
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// The xlsx reader below understands just enough of the Office Open XML
+// spreadsheet format to read cell text from one worksheet: the workbook's
+// sheet list, its relationships, the shared string table and the sheet data.
+
+type xlsxWorkbook struct {
+	Sheets []struct {
+		Name string `xml:"name,attr"`
+		RID  string `xml:"http://schemas.openxmlformats.org/officeDocument/2006/relationships id,attr"`
+	} `xml:"sheets>sheet"`
+}
+
+type xlsxRelationships struct {
+	Relationships []struct {
+		ID     string `xml:"Id,attr"`
+		Target string `xml:"Target,attr"`
+	} `xml:"Relationship"`
+}
+
+type xlsxSharedStrings struct {
+	Items []struct {
+		T    string `xml:"t"`
+		Runs []struct {
+			T string `xml:"t"`
+		} `xml:"r"`
+	} `xml:"si"`
+}
+
+type xlsxSheet struct {
+	Rows []struct {
+		R     int `xml:"r,attr"`
+		Cells []struct {
+			Ref    string `xml:"r,attr"`
+			Type   string `xml:"t,attr"`
+			Value  string `xml:"v"`
+			Inline struct {
+				T    string `xml:"t"`
+				Runs []struct {
+					T string `xml:"t"`
+				} `xml:"r"`
+			} `xml:"is"`
+		} `xml:"c"`
+	} `xml:"sheetData>row"`
+}
+
+// LoadXLSX reads a rename plan from one worksheet of an .xlsx file. The sheet
+// must have the same layout as the CSV: an asis,tobe header row (plus an
+// optional owner column) followed by data rows. Errors refer to sheet rows.
+func LoadXLSX(file string, data []byte, opts Options) ([]Entry, error) {
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, fmt.Errorf("open %q: %w", file, err)
+	}
+
+	files := make(map[string]*zip.File, len(zr.File))
+	for _, f := range zr.File {
+		files[f.Name] = f
+	}
+	decode := func(name string, v any) error {
+		f, ok := files[name]
+		if !ok {
+			return fmt.Errorf("%s: missing %s", file, name)
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return fmt.Errorf("%s: open %s: %w", file, name, err)
+		}
+		defer rc.Close()
+		if err := xml.NewDecoder(rc).Decode(v); err != nil {
+			return fmt.Errorf("%s: parse %s: %w", file, name, err)
+		}
+		return nil
+	}
+
+	var wb xlsxWorkbook
+	if err := decode("xl/workbook.xml", &wb); err != nil {
+		return nil, err
+	}
+	if len(wb.Sheets) == 0 {
+		return nil, fmt.Errorf("%s has no worksheets", file)
+	}
+	sheet := wb.Sheets[0]
+	if opts.Sheet != "" {
+		found := false
+		for _, s := range wb.Sheets {
+			if s.Name == opts.Sheet {
+				sheet, found = s, true
+				break
+			}
+		}
+		if !found {
+			names := make([]string, 0, len(wb.Sheets))
+			for _, s := range wb.Sheets {
+				names = append(names, s.Name)
+			}
+			return nil, fmt.Errorf("%s has no sheet %q (sheets: %s)", file, opts.Sheet, strings.Join(names, ", "))
+		}
+	}
+
+	var rels xlsxRelationships
+	if err := decode("xl/_rels/workbook.xml.rels", &rels); err != nil {
+		return nil, err
+	}
+	var target string
+	for _, r := range rels.Relationships {
+		if r.ID == sheet.RID {
+			target = r.Target
+		}
+	}
+	if target == "" {
+		return nil, fmt.Errorf("%s: sheet %q has no worksheet part", file, sheet.Name)
+	}
+	if strings.HasPrefix(target, "/") {
+		target = strings.TrimPrefix(target, "/")
+	} else {
+		target = path.Join("xl", target)
+	}
+
+	var shared []string
+	if _, ok := files["xl/sharedStrings.xml"]; ok {
+		var sst xlsxSharedStrings
+		if err := decode("xl/sharedStrings.xml", &sst); err != nil {
+			return nil, err
+		}
+		for _, si := range sst.Items {
+			text := si.T
+			for _, r := range si.Runs {
+				text += r.T
+			}
+			shared = append(shared, text)
+		}
+	}
+
+	var ws xlsxSheet
+	if err := decode(target, &ws); err != nil {
+		return nil, err
+	}
+
+	name := fmt.Sprintf("%s[%s]", file, sheet.Name)
+	var records [][]string
+	var lines []int
+	for _, row := range ws.Rows {
+		var fields []string
+		for i, c := range row.Cells {
+			col := i
+			if c.Ref != "" {
+				col = xlsxColumn(c.Ref)
+			}
+			var text string
+			switch c.Type {
+			case "s":
+				idx, err := strconv.Atoi(c.Value)
+				if err != nil || idx < 0 || idx >= len(shared) {
+					return nil, fmt.Errorf("%s: cell %s has invalid shared string index %q", name, c.Ref, c.Value)
+				}
+				text = shared[idx]
+			case "inlineStr":
+				text = c.Inline.T
+				for _, r := range c.Inline.Runs {
+					text += r.T
+				}
+			default:
+				text = c.Value
+			}
+			for len(fields) <= col {
+				fields = append(fields, "")
+			}
+			fields[col] = text
+		}
+		if strings.TrimSpace(strings.Join(fields, "")) == "" {
+			continue
+		}
+		// Blank cells are not stored, so pad rows out to the header width.
+		if len(records) > 0 {
+			for len(fields) < len(records[0]) {
+				fields = append(fields, "")
+			}
+		}
+		records = append(records, fields)
+		lines = append(lines, row.R)
+	}
+	return ParseRows(name, records, lines)
+}
+
+// xlsxColumn returns the zero-based column index of a cell reference such as "B3".
+func xlsxColumn(ref string) int {
+	col := 0
+	for _, r := range ref {
+		if r < 'A' || r > 'Z' {
+			break
+		}
+		col = col*26 + int(r-'A'+1)
+	}
+	return col - 1
+}
@@ -0,0 +1,245 @@
+package main
+
+import (
+	"cmp"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"github.com/kiddikn/slack-channel-renamer/plan"
+)
+
+// resolvedPlan is the on-disk form of a validated plan written by -plan-out and
+// executed by -plan-in. Checksum covers every other field.
+type resolvedPlan struct {
+	GeneratedAt time.Time `json:"generated_at"`
+	Source      string    `json:"source"`
+	// Teams holds the Slack team ID each workspace of the plan was resolved
+	// against, keyed by -config workspace name ("" without -config).
+	Teams    map[string]string `json:"teams,omitempty"`
+	Entries  []resolvedEntry   `json:"entries"`
+	Checksum string            `json:"checksum,omitempty"`
+}
+
+type resolvedEntry struct {
+	Action    string `json:"action"`
+	ChannelID string `json:"channel_id"`
+	Asis      string `json:"asis"`
+	Tobe      string `json:"tobe,omitempty"`
+	Owner     string `json:"owner,omitempty"`
+	Topic     string `json:"topic,omitempty"`
+	Purpose   string `json:"purpose,omitempty"`
+	Private   bool   `json:"private,omitempty"`
+	Members   string `json:"members,omitempty"`
+	Workspace string `json:"workspace,omitempty"`
+	// State is the channelState of the channel when the plan was written.
+	State string `json:"state,omitempty"`
+	// Rearchive marks a rename of an archived channel; see planEntry.
+	Rearchive bool      `json:"rearchive,omitempty"`
+	NotBefore time.Time `json:"not_before,omitzero"`
+	// ChannelTeam is the workspace the channel belongs to on Enterprise Grid.
+	ChannelTeam string `json:"channel_team,omitempty"`
+	// Shared says how the channel is shared beyond its workspace; see
+	// planEntry.
+	Shared string `json:"shared,omitempty"`
+	// PinnedNote is the timestamp of the note -pin-old-name pinned, in a
+	// rollback file.
+	PinnedNote string `json:"pinned_note,omitempty"`
+	// Plan is the plan file the entry was read from, when there were several.
+	Plan string `json:"plan,omitempty"`
+}
+
+// checksum returns the SHA-256 of the plan's JSON encoding with Checksum cleared.
+func (p resolvedPlan) checksum() (string, error) {
+	p.Checksum = ""
+	b, err := json.Marshal(p)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(b)
+	return "sha256:" + hex.EncodeToString(sum[:]), nil
+}
+
+// channelState hashes what a plan reviewer saw of a channel: whether it is
+// archived or private, and its topic and purpose. Member counts are left
+// out, as they change too often to hold a reviewed plan to.
+func channelState(ch channelInfo) string {
+	b, _ := json.Marshal([]any{ch.IsArchived, ch.IsPrivate, ch.Topic, ch.Purpose})
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:8])
+}
+
+// writePlanFile writes entries, which must already carry their channel IDs
+// and states (see resolveIDs and stateOf), with the team IDs of their
+// workspaces and a checksum to path.
+func writePlanFile(path, source string, entries []planEntry, teams map[string]string) error {
+	p := resolvedPlan{GeneratedAt: time.Now().UTC().Truncate(time.Second), Source: source, Teams: teams}
+	for _, e := range entries {
+		p.Entries = append(p.Entries, resolvedEntry{
+			Action:      e.Action,
+			ChannelID:   e.ChannelID,
+			Asis:        e.Asis,
+			Tobe:        e.Tobe,
+			Owner:       e.Owner,
+			Topic:       e.Topic,
+			Purpose:     e.Purpose,
+			Private:     e.Private,
+			Members:     e.Members,
+			Workspace:   e.Workspace,
+			State:       e.State,
+			Rearchive:   e.Rearchive,
+			NotBefore:   e.NotBefore,
+			ChannelTeam: e.ChannelTeam,
+			Shared:      e.Shared,
+			PinnedNote:  e.PinnedNote,
+			Plan:        e.File,
+		})
+	}
+	sum, err := p.checksum()
+	if err != nil {
+		return fmt.Errorf("compute checksum: %w", err)
+	}
+	p.Checksum = sum
+
+	b, err := json.MarshalIndent(p, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode plan: %w", err)
+	}
+	if err := os.WriteFile(path, append(b, '\n'), 0o644); err != nil {
+		return fmt.Errorf("write %q: %w", path, err)
+	}
+	return nil
+}
+
+// readPlanFile reads a plan written by writePlanFile and refuses it if the
+// checksum does not match its contents.
+func readPlanFile(path string) ([]planEntry, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read %q: %w", path, err)
+	}
+	var p resolvedPlan
+	if err := json.Unmarshal(b, &p); err != nil {
+		return nil, fmt.Errorf("parse %q: %w", path, err)
+	}
+	if p.Checksum == "" {
+		return nil, fmt.Errorf("%s has no checksum", path)
+	}
+	sum, err := p.checksum()
+	if err != nil {
+		return nil, fmt.Errorf("compute checksum: %w", err)
+	}
+	if sum != p.Checksum {
+		return nil, fmt.Errorf("%s checksum mismatch: file says %s, contents hash to %s", path, p.Checksum, sum)
+	}
+
+	entries := make([]planEntry, 0, len(p.Entries))
+	for i, e := range p.Entries {
+		// Only the channels a create entry makes have no ID yet.
+		if e.ChannelID == "" && e.Action != actionCreate {
+			return nil, fmt.Errorf("%s entry %d: channel_id is required", path, i+1)
+		}
+		entry := planEntry{
+			Action:      e.Action,
+			ChannelID:   e.ChannelID,
+			Asis:        e.Asis,
+			Tobe:        e.Tobe,
+			Owner:       e.Owner,
+			Topic:       e.Topic,
+			Purpose:     e.Purpose,
+			Private:     e.Private,
+			Members:     e.Members,
+			Workspace:   e.Workspace,
+			Team:        p.Teams[e.Workspace],
+			State:       e.State,
+			Rearchive:   e.Rearchive,
+			NotBefore:   e.NotBefore,
+			ChannelTeam: e.ChannelTeam,
+			Shared:      e.Shared,
+			PinnedNote:  e.PinnedNote,
+			File:        cmp.Or(e.Plan, path),
+			Source:      fmt.Sprintf("%s entry %d", path, i+1),
+		}
+		if err := entry.Check(); err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+// writeRollbackFile writes the reverse of changed, which must carry channel
+// IDs, as a resolved plan in dir named after the current time. The entries are
+// written newest first, so that renames through a temporary name are undone
+// in the order they were made. It returns the path written.
+func writeRollbackFile(dir, source string, changed []planEntry) (string, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", fmt.Errorf("create %q: %w", dir, err)
+	}
+	path := filepath.Join(dir, "rollback-"+time.Now().UTC().Format("20060102T150405Z")+".json")
+	newestFirst := slices.Clone(changed)
+	slices.Reverse(newestFirst)
+	if err := writePlanFile(path, "rollback of "+source, reverseEntries(newestFirst), nil); err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+// resolveIDs returns a copy of entries with each channel ID filled in from channels.
+func resolveIDs(entries []planEntry, channels map[string]channelInfo) []planEntry {
+	resolved := slices.Clone(entries)
+	for i, e := range resolved {
+		// A channel a create entry made is not in channels, and its entry
+		// already carries the ID.
+		if ch := plan.EntryChannel(channels, e); ch.ID != "" {
+			resolved[i].ChannelID = ch.ID
+		}
+	}
+	return resolved
+}
+
+// stateOf returns a copy of entries, which must carry their channel IDs,
+// with the state of each one's channel in channels filled in.
+func stateOf(entries []planEntry, channels map[string]channelInfo) []planEntry {
+	out := slices.Clone(entries)
+	for i, e := range out {
+		if ch := plan.EntryChannel(channels, e); ch.ID != "" && ch.ID == e.ChannelID {
+			out[i].State = channelState(ch)
+		}
+	}
+	return out
+}
+
+// checkPlanIDs confirms that every resolved channel ID still exists under its
+// planned name, in the state the plan was reviewed in.
+func checkPlanIDs(entries []planEntry, channels map[string]channelInfo) []string {
+	nameByID := make(map[string]string, len(channels))
+	for name, ch := range channels {
+		nameByID[ch.ID] = name
+		if ch.ArchivedTwin != "" {
+			nameByID[ch.ArchivedTwin] = name
+		}
+	}
+
+	var errs []string
+	for _, e := range entries {
+		if e.Action == actionCreate {
+			continue
+		}
+		name, ok := nameByID[e.ChannelID]
+		switch {
+		case !ok:
+			errs = append(errs, fmt.Sprintf("channel %s (%q) no longer exists", e.ChannelID, e.Asis))
+		case name != e.Asis:
+			errs = append(errs, fmt.Sprintf("channel %s is now named %q, plan expected %q", e.ChannelID, name, e.Asis))
+		case e.State != "" && channelState(plan.EntryChannel(channels, e)) != e.State:
+			errs = append(errs, fmt.Sprintf("channel %s (%q) was archived, unarchived, made private or given a new topic or purpose since the plan was written", e.ChannelID, e.Asis))
+		}
+	}
+	return errs
+}
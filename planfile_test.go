@@ -0,0 +1,31 @@
+package main
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestPlanFileState(t *testing.T) {
+	channels := map[string]channelInfo{"alpha": {ID: "C1", Topic: "Alpha"}, "beta": {ID: "C2"}}
+	plan := []planEntry{{Action: actionRename, Asis: "alpha", Tobe: "a"}, {Action: actionArchive, Asis: "beta"}}
+	path := filepath.Join(t.TempDir(), "plan.json")
+	if err := writePlanFile(path, "test", stateOf(resolveIDs(plan, channels), channels), map[string]string{"": "T0123ABCD"}); err != nil {
+		t.Fatal(err)
+	}
+	read, err := readPlanFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if read[0].Team != "T0123ABCD" || read[0].State == "" {
+		t.Fatalf("entry read back as %+v", read[0])
+	}
+	if errs := checkPlanIDs(read, channels); len(errs) > 0 {
+		t.Errorf("unchanged channels: %q", errs)
+	}
+
+	channels["alpha"] = channelInfo{ID: "C1", Topic: "Changed"}
+	channels["beta"] = channelInfo{ID: "C2", IsArchived: true}
+	if errs := checkPlanIDs(read, channels); len(errs) != 2 {
+		t.Errorf("changed channels: %q, want an error for each", errs)
+	}
+}
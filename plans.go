@@ -0,0 +1,328 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/kiddikn/slack-channel-renamer/plan"
+	"github.com/kiddikn/slack-channel-renamer/slackops"
+	"gopkg.in/yaml.v3"
+)
+
+// stdinPlan is the -plan value that reads the plan from standard input.
+const stdinPlan = "-"
+
+// planLoader parses the contents of one plan source into rename entries.
+// name identifies the source in error messages.
+type planLoader func(name string, data []byte, opts plan.Options) ([]planEntry, error)
+
+// planFormats maps a -format value to its loader and the file extensions it
+// is detected from.
+var planFormats = map[string]struct {
+	load planLoader
+	exts []string
+}{
+	"csv":  {plan.LoadCSV, []string{".csv", ".tsv", ".txt"}},
+	"json": {loadJSONPlan, []string{".json"}},
+	"yaml": {loadYAMLPlan, []string{".yaml", ".yml"}},
+	"xlsx": {plan.LoadXLSX, []string{".xlsx"}},
+}
+
+// stringList is a repeatable string flag; each use appends one value.
+type stringList []string
+
+func (l *stringList) String() string { return strings.Join(*l, ",") }
+
+func (l *stringList) Set(v string) error {
+	*l = append(*l, v)
+	return nil
+}
+
+// planInput holds the plan flags shared by the commands that read a plan.
+type planInput struct {
+	// paths are the -plan values: plan files, directories (all plan files in
+	// them), glob patterns or URLs.
+	paths  stringList
+	format string
+	opts   plan.Options
+	sheet  sheetSource
+
+	// headers are extra HTTP headers ("Name: value") sent when fetching plan URLs.
+	headers stringList
+
+	// filter restricts the plan to some of its rows.
+	filter rowFilter
+
+	// listAction is set by the commands that read channel lists instead of
+	// plans, such as 'archive', to the action of every listed channel.
+	listAction string
+}
+
+func (in *planInput) register(fs *flag.FlagSet) {
+	fs.Var(&in.paths, "plan", "plan file, directory, glob, http(s) URL or - for stdin (repeatable; default "+csvFileName+")")
+	in.registerFormat(fs)
+	fs.StringVar(&in.sheet.id, "sheet-id", "", "read the plan from this Google Sheet")
+	fs.StringVar(&in.sheet.rng, "sheet-range", defaultSheetsRange, "A1 range to read with -sheet-id, e.g. 'Plan!A1:C'")
+	fs.StringVar(&in.sheet.credentials, "sheet-credentials", "", "service-account key file for -sheet-id (default: GOOGLE_APPLICATION_CREDENTIALS)")
+	in.filter.register(fs)
+}
+
+// registerFormat registers the flags that control how plan sources are read.
+func (in *planInput) registerFormat(fs *flag.FlagSet) {
+	fs.Var(&in.headers, "plan-header", "HTTP header 'Name: value' sent when fetching plan URLs; $VARS are expanded (repeatable)")
+	fs.StringVar(&in.format, "format", "", "plan format: csv, json, yaml or xlsx (default: detect from file extension)")
+	fs.StringVar(&in.opts.Sheet, "sheet", "", "worksheet to read from .xlsx plans (default: first sheet)")
+	fs.Func("delimiter", "CSV field separator, e.g. ',', ';' or 'tab' (default: detect from the header line)", func(v string) error {
+		r, err := parseDelimiter(v)
+		in.opts.Delimiter = r
+		return err
+	})
+	fs.StringVar(&in.opts.Encoding, "encoding", "auto", "CSV text encoding: auto, utf-8, shift_jis, utf-16, utf-16le or utf-16be")
+}
+
+// describe names the plan sources given on the command line, for the resolved plan file.
+func (in planInput) describe() string {
+	var parts []string
+	if in.sheet.id != "" {
+		parts = append(parts, "sheet "+in.sheet.id)
+	}
+	parts = append(parts, in.paths...)
+	if len(parts) == 0 {
+		return csvFileName
+	}
+	return strings.Join(parts, ",")
+}
+
+// loaderFor returns the loader for path, using in.format when set and the
+// file extension otherwise.
+func (in planInput) loaderFor(path string) (planLoader, error) {
+	if in.format != "" {
+		f, ok := planFormats[in.format]
+		if !ok {
+			return nil, fmt.Errorf("unknown format %q (want csv, json, yaml or xlsx)", in.format)
+		}
+		return f.load, nil
+	}
+	remote := path == stdinPlan || isURL(path)
+	if u, err := url.Parse(path); err == nil && isURL(path) {
+		path = u.Path
+	}
+	ext := strings.ToLower(filepath.Ext(path))
+	for _, f := range planFormats {
+		if slices.Contains(f.exts, ext) {
+			return f.load, nil
+		}
+	}
+	// Stdin and URLs often carry no extension; treat them as CSV.
+	if remote {
+		return plan.LoadCSV, nil
+	}
+	return nil, fmt.Errorf("%s: cannot detect plan format from extension %q (use -format)", path, ext)
+}
+
+// isPlanFile reports whether a directory entry should be read as a plan.
+func (in planInput) isPlanFile(path string) bool {
+	ext := strings.ToLower(filepath.Ext(path))
+	if in.format != "" {
+		return slices.Contains(planFormats[in.format].exts, ext)
+	}
+	for _, f := range planFormats {
+		if slices.Contains(f.exts, ext) {
+			return true
+		}
+	}
+	return false
+}
+
+// files expands the -plan values into a list of files, defaulting to
+// csvFileName when neither -plan nor -sheet-id was given. Files are de-duplicated while
+// keeping the order in which they were first named.
+func (in planInput) files() ([]string, error) {
+	if len(in.paths) == 0 {
+		if in.sheet.id != "" {
+			return nil, nil
+		}
+		return []string{csvFileName}, nil
+	}
+
+	var files []string
+	seen := make(map[string]bool)
+	add := func(f string) {
+		if !seen[f] {
+			seen[f] = true
+			files = append(files, f)
+		}
+	}
+	for _, v := range in.paths {
+		if v == stdinPlan || isURL(v) {
+			add(v)
+			continue
+		}
+		if info, err := os.Stat(v); err == nil && info.IsDir() {
+			dirEntries, err := os.ReadDir(v)
+			if err != nil {
+				return nil, fmt.Errorf("list %q: %w", v, err)
+			}
+			n := 0
+			for _, de := range dirEntries {
+				if !de.IsDir() && in.isPlanFile(de.Name()) {
+					add(filepath.Join(v, de.Name()))
+					n++
+				}
+			}
+			if n == 0 {
+				return nil, fmt.Errorf("directory %q contains no plan files", v)
+			}
+			continue
+		}
+		if strings.ContainsAny(v, "*?[") {
+			matches, err := filepath.Glob(v)
+			if err != nil {
+				return nil, fmt.Errorf("bad pattern %q: %w", v, err)
+			}
+			if len(matches) == 0 {
+				return nil, fmt.Errorf("pattern %q matches no files", v)
+			}
+			slices.Sort(matches)
+			for _, m := range matches {
+				add(m)
+			}
+			continue
+		}
+		add(v)
+	}
+	return files, nil
+}
+
+// load loads and concatenates every plan file named by the -plan flags and the
+// Google Sheet named by -sheet-id. Each entry keeps its source so validation
+// errors point at the right file. The returned list names every source read.
+func (in planInput) load() ([]planEntry, []string, error) {
+	files, err := in.files()
+	if err != nil {
+		return nil, nil, err
+	}
+	var plan []planEntry
+	var sources []string
+	if in.sheet.id != "" {
+		entries, err := loadGoogleSheet(in.sheet)
+		if err != nil {
+			return nil, nil, err
+		}
+		for i := range entries {
+			entries[i].File = "sheet " + in.sheet.id
+		}
+		plan = append(plan, entries...)
+		sources = append(sources, "sheet "+in.sheet.id)
+	}
+	for _, f := range files {
+		load, err := in.loaderFor(f)
+		if in.listAction != "" {
+			load, err = listLoader(in.listAction), nil
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		data, err := in.read(f)
+		if err != nil {
+			return nil, nil, err
+		}
+		entries, err := load(f, data, in.opts)
+		if err != nil {
+			return nil, nil, err
+		}
+		for i := range entries {
+			entries[i].File = f
+		}
+		plan = append(plan, entries...)
+	}
+	return plan, append(sources, files...), nil
+}
+
+// read returns the contents of one plan source: a local file, stdin or an http(s) URL.
+func (in planInput) read(name string) ([]byte, error) {
+	switch {
+	case name == stdinPlan:
+		data, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			return nil, fmt.Errorf("read stdin: %w", err)
+		}
+		return data, nil
+	case isURL(name):
+		return in.fetch(name)
+	}
+	data, err := os.ReadFile(name)
+	if err != nil {
+		return nil, fmt.Errorf("open %q: %w", name, err)
+	}
+	return data, nil
+}
+
+// fetch downloads a plan from an http(s) URL, sending the -plan-header headers.
+func (in planInput) fetch(rawURL string) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), slackops.Retry.Timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("fetch %s: %w", rawURL, err)
+	}
+	for _, h := range in.headers {
+		name, value, ok := strings.Cut(h, ":")
+		if !ok {
+			return nil, fmt.Errorf("bad -plan-header %q (want 'Name: value')", h)
+		}
+		req.Header.Set(strings.TrimSpace(name), strings.TrimSpace(os.ExpandEnv(value)))
+	}
+
+	resp, err := netClient().Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("fetch %s: %w", rawURL, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("fetch %s: %s", rawURL, resp.Status)
+	}
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("fetch %s: %w", rawURL, err)
+	}
+	return data, nil
+}
+
+func isURL(s string) bool {
+	return strings.HasPrefix(s, "https://") || strings.HasPrefix(s, "http://")
+}
+
+// loadJSONPlan checks a JSON plan against the plan schema, so that mistakes
+// are reported by line, and loads it.
+func loadJSONPlan(path string, data []byte, opts plan.Options) ([]planEntry, error) {
+	if err := checkSchema(planSchema, path, data, true); err != nil {
+		return nil, err
+	}
+	return plan.LoadJSON(path, data, opts)
+}
+
+// loadYAMLPlan checks a YAML plan against the plan schema, as loadJSONPlan
+// does, and loads it. Plans that are not a list of entries are left for
+// plan.LoadYAML to report.
+func loadYAMLPlan(path string, data []byte, opts plan.Options) ([]planEntry, error) {
+	var doc yaml.Node
+	if yaml.Unmarshal(data, &doc) == nil && len(doc.Content) > 0 {
+		if root := doc.Content[0]; root.Kind == yaml.SequenceNode && len(root.Content) > 0 {
+			if problems := validateSchema(planSchema, path, root, false); len(problems) > 0 {
+				return nil, errors.New(strings.Join(problems, "; "))
+			}
+		}
+	}
+	return plan.LoadYAML(path, data, opts)
+}
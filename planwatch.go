@@ -0,0 +1,160 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// planWatchSettle is how long -watch waits after the last change to a plan
+// file before checking it, since saving a file often takes several writes,
+// or a write and a rename.
+var planWatchSettle = 200 * time.Millisecond
+
+// checkWatch returns an error when -watch cannot be used with the plan input:
+// only plan files on disk can be watched, not stdin, URLs or a Google Sheet.
+// Nor can -output json, which prints its one document when the command ends.
+func (in planInput) checkWatch() error {
+	if output == outputJSON {
+		return errors.New("-watch cannot be used with -output json")
+	}
+	if in.sheet.id != "" {
+		return errors.New("-watch cannot be used with -sheet-id")
+	}
+	for _, v := range in.paths {
+		if v == stdinPlan || isURL(v) {
+			return fmt.Errorf("-watch needs plan files, not %q", v)
+		}
+	}
+	return nil
+}
+
+// planWatchDirs returns the directories -watch follows: those of the plan
+// files and -plan patterns, and the -plan directories themselves. Watching
+// the directories rather than the files sees a file an editor saves by
+// writing a new one and renaming it over the old.
+func planWatchDirs(in planInput) []string {
+	paths := in.paths
+	if len(paths) == 0 {
+		paths = stringList{csvFileName}
+	}
+	var dirs []string
+	add := func(d string) {
+		if d = filepath.Clean(d); !slices.Contains(dirs, d) {
+			dirs = append(dirs, d)
+		}
+	}
+	for _, v := range paths {
+		switch info, err := os.Stat(v); {
+		case err == nil && info.IsDir():
+			add(v)
+		case !strings.ContainsAny(filepath.Dir(v), "*?["):
+			add(filepath.Dir(v))
+		}
+	}
+	files, _ := in.files()
+	for _, f := range files {
+		add(filepath.Dir(f))
+	}
+	return dirs
+}
+
+// isWatchedPlan reports whether a change to the file name, seen in a watched
+// directory, changes the plan of in: it is one of the plan files read last
+// time, in known, or one a -plan value names, or would now match.
+func isWatchedPlan(in planInput, known []string, name string) bool {
+	name = filepath.Clean(name)
+	if slices.Contains(known, name) {
+		return true
+	}
+	paths := in.paths
+	if len(paths) == 0 {
+		paths = stringList{csvFileName}
+	}
+	for _, v := range paths {
+		if info, err := os.Stat(v); err == nil && info.IsDir() {
+			if filepath.Dir(name) == filepath.Clean(v) && in.isPlanFile(name) {
+				return true
+			}
+			continue
+		}
+		if ok, _ := filepath.Match(filepath.Clean(v), name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// cleanFiles returns the plan files of in as clean paths, or none when they
+// cannot be listed.
+func cleanFiles(in planInput) []string {
+	files, _ := in.files()
+	for i, f := range files {
+		files[i] = filepath.Clean(f)
+	}
+	return files
+}
+
+// watchPlan calls run, then calls it again each time the plan files of in
+// change, until ctx is done, and returns the exit code of the last call.
+// Changes are followed with fsnotify, and a burst of them, as an editor
+// makes when it saves, starts one call once they settle. The sessions keep
+// the token check and the channel list of the first call, so that later ones
+// ask Slack only for channel IDs the list does not have; both are read again
+// when -watch is restarted.
+func watchPlan(ctx context.Context, in planInput, sessions []*session, run func() int) int {
+	w, err := fsnotify.NewWatcher()
+	if err != nil {
+		slog.Error("cannot watch the plan files", "err", err)
+		return 1
+	}
+	defer w.Close()
+	for _, d := range planWatchDirs(in) {
+		if err := w.Add(d); err != nil {
+			slog.Error("cannot watch the plan files", "dir", d, "err", err)
+			return 1
+		}
+	}
+	for _, s := range sessions {
+		s.keepList = true
+		// Look the plan's channels up in the kept list rather than by ID.
+		s.channelOpts.fullList = true
+	}
+
+	known := cleanFiles(in)
+	code := run()
+	slog.Info("watching the plan for changes (interrupt to stop)")
+	var settle <-chan time.Time
+	for {
+		select {
+		case <-ctx.Done():
+			return code
+		case err, ok := <-w.Errors:
+			if !ok {
+				return code
+			}
+			slog.Warn("error watching the plan files", "err", err)
+		case ev, ok := <-w.Events:
+			if !ok {
+				return code
+			}
+			if ev.Op != fsnotify.Chmod && isWatchedPlan(in, known, ev.Name) {
+				settle = time.After(planWatchSettle)
+			}
+		case <-settle:
+			settle = nil
+			known = cleanFiles(in)
+			fmt.Println()
+			slog.Info("the plan changed, checking it again")
+			code = run()
+		}
+	}
+}
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestWatchPlan(t *testing.T) {
+	old := planWatchSettle
+	planWatchSettle = 10 * time.Millisecond
+	defer func() { planWatchSettle = old }()
+
+	dir := t.TempDir()
+	workspace := filepath.Join(dir, "workspace.json")
+	if err := os.WriteFile(workspace, []byte(`[{"name":"alpha","id":"C1"},{"name":"beta","id":"C2"}]`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	sessions, err := (workspaceOptions{simulate: workspace}).newSessions(channelOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, "plan.csv")
+	if err := os.WriteFile(path, []byte("asis,tobe\nalpha,beta\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	in := planInput{paths: stringList{path}}
+
+	ctx, cancel := context.WithCancel(t.Context())
+	defer cancel()
+	var codes []int
+	run := func() int {
+		plan, err := loadPlan(in, "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		code := 0
+		if _, errs, _, err := validateRuns(sessions, workspaceOptions{}, plan, prepareOptions{}); err != nil || len(errs) > 0 {
+			code = 1
+		}
+		codes = append(codes, code)
+		switch len(codes) {
+		case 1:
+			// Renamed behind the watch's back: the kept list still has alpha.
+			sessions[0].client.(*simWorkspace).channels[0].Name = "gamma"
+			// A file beside the plan is not watched.
+			if err := os.WriteFile(filepath.Join(dir, "notes.txt"), []byte("x"), 0o600); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(path, []byte("asis,tobe\nalpha,delta\n"), 0o600); err != nil {
+				t.Fatal(err)
+			}
+		case 2:
+			cancel()
+		}
+		return code
+	}
+	if code := watchPlan(ctx, in, sessions, run); code != 0 {
+		t.Errorf("watchPlan = %d, want the last run's 0", code)
+	}
+	if len(codes) != 2 || codes[0] != 1 {
+		t.Errorf("runs exited with %v, want a failing one (beta is taken), then a passing one", codes)
+	}
+}
+
+func TestCheckWatch(t *testing.T) {
+	for _, in := range []planInput{
+		{paths: stringList{stdinPlan}},
+		{paths: stringList{"https://example.com/plan.csv"}},
+		{sheet: sheetSource{id: "sheet"}},
+	} {
+		if err := in.checkWatch(); err == nil {
+			t.Errorf("checkWatch(%+v) = nil, want an error", in)
+		}
+	}
+	if err := (planInput{paths: stringList{"plan.csv", "plans/"}}).checkWatch(); err != nil {
+		t.Errorf("checkWatch of plan files = %v", err)
+	}
+}
+
+func TestIsWatchedPlan(t *testing.T) {
+	dir := t.TempDir()
+	plans := filepath.Join(dir, "plans")
+	if err := os.Mkdir(plans, 0o700); err != nil {
+		t.Fatal(err)
+	}
+	in := planInput{paths: stringList{plans, filepath.Join(dir, "team-*.csv"), filepath.Join(dir, "main.csv")}}
+	for name, want := range map[string]bool{
+		filepath.Join(plans, "new.yaml"):      true,
+		filepath.Join(plans, "notes.md"):      false,
+		filepath.Join(dir, "team-eng.csv"):    true,
+		filepath.Join(dir, "main.csv"):        true,
+		filepath.Join(dir, "main.csv.swp"):    false,
+		filepath.Join(dir, "old", "gone.csv"): true,
+	} {
+		if got := isWatchedPlan(in, []string{filepath.Join(dir, "old", "gone.csv")}, name); got != want {
+			t.Errorf("isWatchedPlan(%s) = %v, want %v", name, got, want)
+		}
+	}
+	if dirs := planWatchDirs(in); len(dirs) != 2 || dirs[0] != plans || dirs[1] != dir {
+		t.Errorf("planWatchDirs = %v, want %s and %s", dirs, plans, dir)
+	}
+}
+
+func TestPreflightUnderWatch(t *testing.T) {
+	serveAuthTest(t, "channels:read,channels:write")
+	s := newSession("", "xoxp-test", channelOptions{})
+	s.keepList = true
+	plan := []planEntry{{Action: actionRename, Asis: "a", Tobe: "b"}}
+	if problems, err := s.preflight(plan); err != nil || len(problems) > 0 {
+		t.Fatalf("first preflight = %q, %v", problems, err)
+	}
+	// auth.test would reject the token now, so a later pass must not ask.
+	s.token = "xoxp-revoked"
+	if problems, err := s.preflight(plan); err != nil || len(problems) > 0 {
+		t.Errorf("second preflight = %q, %v; want the first check reused", problems, err)
+	}
+	// What depends on the plan is still checked.
+	plan[0].Team = "T9"
+	if problems, _ := s.preflight(plan); len(problems) != 1 {
+		t.Errorf("preflight of a plan for another team = %q, want one problem", problems)
+	}
+}
@@ -0,0 +1,180 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/kiddikn/slack-channel-renamer/slackops"
+	"github.com/slack-go/slack"
+)
+
+// authInfo is what auth.test says about a token. scopes holds the scopes
+// Slack reports in the X-OAuth-Scopes header, and is nil when it reports
+// none, as some proxies and mock servers do.
+type authInfo struct {
+	URL    string `json:"url"`
+	Team   string `json:"team"`
+	TeamID string `json:"team_id"`
+	User   string `json:"user"`
+	UserID string `json:"user_id"`
+	BotID  string `json:"bot_id"`
+	scopes []string
+}
+
+// authTest calls auth.test directly rather than through slack-go, whose
+// response leaves out the granted scopes.
+func (s *session) authTest(ctx context.Context) (authInfo, error) {
+	var info authInfo
+	err := slackops.Call(ctx, s.stats, "checking the token", func(ctx context.Context) error {
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, apiURL+"auth.test", nil)
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Authorization", "Bearer "+s.token)
+		resp, err := slackHTTPClient().Do(req)
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return err
+		}
+		if resp.StatusCode != http.StatusOK {
+			return slack.StatusCodeError{Code: resp.StatusCode, Status: resp.Status}
+		}
+		var r struct {
+			authInfo
+			slack.SlackResponse
+		}
+		if err := json.Unmarshal(body, &r); err != nil {
+			return fmt.Errorf("parse auth.test response: %w", err)
+		}
+		if err := r.Err(); err != nil {
+			return err
+		}
+		info = r.authInfo
+		if h := resp.Header.Get("X-OAuth-Scopes"); h != "" {
+			for scope := range strings.SplitSeq(h, ",") {
+				info.scopes = append(info.scopes, strings.TrimSpace(scope))
+			}
+		}
+		return nil
+	})
+	return info, err
+}
+
+// requiredScopes returns the scopes the entries of plan need, each with what
+// it is needed for, in a stable order. Bot tokens need the bot scopes for the
+// same calls, and channels:join to join the public channels they change.
+func requiredScopes(plan []planEntry, opts channelOptions, bot bool) [][2]string {
+	var need [][2]string
+	add := func(scope, why string) {
+		for _, n := range need {
+			if n[0] == scope {
+				return
+			}
+		}
+		need = append(need, [2]string{scope, why})
+	}
+	if opts.admin {
+		add("admin.conversations:read", "to find channels with admin.conversations.search")
+		if len(plan) > 0 {
+			add("admin.conversations:write", "to change channels in admin mode")
+		}
+		return need
+	}
+	add("channels:read", "to list channels")
+	if opts.includePrivate {
+		add("groups:read", "to list private channels (-include-private)")
+	}
+	write, invites := "channels:write", "channels:write.invites"
+	if bot {
+		write, invites = "channels:manage", "channels:manage"
+	}
+	for _, e := range plan {
+		add(write, "to "+e.Action+" channels")
+		if bot {
+			add("channels:join", "to join the public channels the bot is not in")
+		}
+		if opts.includePrivate {
+			add("groups:write", "to "+e.Action+" private channels (-include-private)")
+		}
+		if e.Action == actionMerge {
+			add(invites, "to invite members for merge rows")
+			add("chat:write", "to post the redirect message of merge rows")
+			if opts.includePrivate && !bot {
+				add("groups:write.invites", "to invite members into private channels for merge rows")
+			}
+		}
+	}
+	return need
+}
+
+// preflight checks the session's token before any channel is fetched: that
+// auth.test accepts it, that it belongs to the workspace the -config entry
+// names, and to the team a resolved plan file was written for, that admin
+// mode has a user token and that it has the scopes plan needs. It returns what is wrong as validation errors; err is set only when
+// the check itself could not be made.
+func (s *session) preflight(plan []planEntry) (problems []string, err error) {
+	var info authInfo
+	if s.keepList && s.auth != nil {
+		// Under -watch the token was checked on the first pass; only the
+		// plan has changed since.
+		info = *s.auth
+	} else {
+		info, err = s.authTest(cmdCtx)
+		if err != nil {
+			var se slack.SlackErrorResponse
+			if errors.As(err, &se) {
+				return []string{fmt.Sprintf("the token was rejected by auth.test: %v", err)}, nil
+			}
+			return nil, fmt.Errorf("%scheck the token: %w", s.label(), err)
+		}
+		s.auth = &info
+		slog.Debug(s.label()+"token checked", "team", info.Team, "team_id", info.TeamID, "user", info.User, "scopes", strings.Join(info.scopes, ","))
+	}
+
+	if s.team != "" && !info.belongsTo(s.team) {
+		problems = append(problems, fmt.Sprintf("the token belongs to workspace %s (%s, %s) but the config expects %s", info.Team, info.domain(), info.TeamID, s.team))
+	}
+	if i := slices.IndexFunc(plan, func(e planEntry) bool { return e.Team != "" }); i >= 0 && plan[i].Team != info.TeamID {
+		problems = append(problems, fmt.Sprintf("the plan file was written for team %s but the token belongs to workspace %s (%s)", plan[i].Team, info.Team, info.TeamID))
+	}
+	if s.bot && s.channelOpts.admin {
+		problems = append(problems, "the token is a bot token (xoxb-); -admin needs a user token (xoxp-) of an org admin")
+	}
+	if info.scopes == nil {
+		slog.Debug(s.label() + "auth.test reported no scopes; not checking them")
+		return problems, nil
+	}
+	kind := "user"
+	if s.bot {
+		kind = "bot"
+	}
+	for _, n := range requiredScopes(plan, s.channelOpts, s.bot) {
+		if !slices.Contains(info.scopes, n[0]) {
+			problems = append(problems, fmt.Sprintf("the token is a %s token missing %s, needed %s", kind, n[0], n[1]))
+		}
+	}
+	return problems, nil
+}
+
+// domain returns the workspace's subdomain, such as acme for acme.slack.com.
+func (a authInfo) domain() string {
+	host := strings.TrimSuffix(strings.TrimPrefix(a.URL, "https://"), "/")
+	d, _, _ := strings.Cut(host, ".")
+	return d
+}
+
+// belongsTo reports whether team is the workspace's name, domain or ID.
+func (a authInfo) belongsTo(team string) bool {
+	return strings.EqualFold(team, a.Team) || strings.EqualFold(team, a.domain()) || team == a.TeamID
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// serveAuthTest serves auth.test for a token with the given scopes, and points
+// apiURL at itself for the duration of the test.
+func serveAuthTest(t *testing.T, scopes string) {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/auth.test" {
+			http.NotFound(w, r)
+			return
+		}
+		if r.Header.Get("Authorization") != "Bearer xoxp-test" {
+			fmt.Fprint(w, `{"ok":false,"error":"invalid_auth"}`)
+			return
+		}
+		w.Header().Set("X-OAuth-Scopes", scopes)
+		fmt.Fprint(w, `{"ok":true,"url":"https://acme.slack.com/","team":"Acme","team_id":"T0123","user":"ops","user_id":"U0123"}`)
+	}))
+	t.Cleanup(srv.Close)
+	old := apiURL
+	t.Cleanup(func() { apiURL = old })
+	if err := setAPIURL(srv.URL + "/api"); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestPreflight(t *testing.T) {
+	serveAuthTest(t, "channels:read,chat:write")
+	plan := []planEntry{{Action: actionRename, Asis: "a", Tobe: "b"}}
+
+	s := newSession("", "xoxp-test", channelOptions{})
+	problems, err := s.preflight(plan)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(problems) != 1 || !strings.Contains(problems[0], "missing channels:write") {
+		t.Errorf("problems = %q, want channels:write missing", problems)
+	}
+
+	s.team = "acme"
+	if problems, _ := s.preflight(nil); len(problems) != 0 {
+		t.Errorf("domain acme: problems = %q, want none", problems)
+	}
+	s.team = "T9999"
+	if problems, _ := s.preflight(nil); len(problems) != 1 || !strings.Contains(problems[0], "expects T9999") {
+		t.Errorf("other team: problems = %q", problems)
+	}
+
+	s = newSession("", "xoxp-wrong", channelOptions{})
+	if problems, _ := s.preflight(nil); len(problems) != 1 || !strings.Contains(problems[0], "invalid_auth") {
+		t.Errorf("bad token: problems = %q", problems)
+	}
+}
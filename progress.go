@@ -0,0 +1,180 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// How often the progress bar is redrawn, and how often progress is logged
+// when there is no terminal to draw it on.
+const (
+	progressRedraw      = 250 * time.Millisecond
+	progressLogInterval = 30 * time.Second
+	progressBarWidth    = 30
+)
+
+// statusLine is the progress bar currently shown at the bottom of the
+// terminal, if any. Everything printed during a run goes through a
+// statusWriter, which clears the bar first and draws it again afterwards so
+// that output lines never end up interleaved with it.
+var statusLine struct {
+	mu   sync.Mutex
+	text string
+}
+
+// stdout is where a run prints its per-entry results.
+var stdout io.Writer = statusWriter{os.Stdout}
+
+type statusWriter struct {
+	w io.Writer
+}
+
+func (s statusWriter) Write(p []byte) (int, error) {
+	statusLine.mu.Lock()
+	defer statusLine.mu.Unlock()
+	if statusLine.text != "" {
+		fmt.Fprint(os.Stderr, "\r\033[K")
+		defer fmt.Fprint(os.Stderr, statusLine.text)
+	}
+	return s.w.Write(p)
+}
+
+// isTerminal reports whether f is a character device, such as a terminal.
+func isTerminal(f *os.File) bool {
+	fi, err := f.Stat()
+	return err == nil && fi.Mode()&os.ModeCharDevice != 0
+}
+
+// progress tracks how far applyEntries has got. On a terminal it draws a bar
+// with the completed and failed counts, the throughput and the estimated time
+// remaining; otherwise it logs the same figures every progressLogInterval.
+type progress struct {
+	label string
+	total int
+	// maxRate is the most entries per second the limiter lets start.
+	maxRate float64
+	start   time.Time
+	bar     bool
+
+	mu                    sync.Mutex
+	done, failed, skipped int
+
+	stopped chan struct{}
+	wg      sync.WaitGroup
+}
+
+// startProgress starts reporting progress for a run of total entries, or
+// returns nil if x.progress is off.
+func (x *executor) startProgress(total int) *progress {
+	if !x.progress || total == 0 {
+		return nil
+	}
+	maxRate := float64(x.Limiter.Limit())
+	if x.pacer != nil {
+		maxRate = min(maxRate, float64(x.pacer.ceiling)/60)
+	}
+	p := &progress{
+		label:   x.label,
+		total:   total,
+		maxRate: maxRate,
+		start:   time.Now(),
+		bar:     isTerminal(os.Stdout) && isTerminal(os.Stderr),
+		stopped: make(chan struct{}),
+	}
+	interval := progressLogInterval
+	if p.bar {
+		interval = progressRedraw
+	}
+	p.wg.Add(1)
+	go func() {
+		defer p.wg.Done()
+		tick := time.NewTicker(interval)
+		defer tick.Stop()
+		for {
+			select {
+			case <-tick.C:
+				p.report()
+			case <-p.stopped:
+				return
+			}
+		}
+	}()
+	return p
+}
+
+// record counts one finished entry.
+func (p *progress) record(status string) {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.done++
+	switch status {
+	case resultFailed:
+		p.failed++
+	case resultSkipped:
+		p.skipped++
+	}
+}
+
+// stop ends the reporting, leaving the final state of the bar on screen.
+func (p *progress) stop() {
+	if p == nil {
+		return
+	}
+	close(p.stopped)
+	p.wg.Wait()
+	if p.bar {
+		p.report()
+		statusLine.mu.Lock()
+		statusLine.text = ""
+		fmt.Fprintln(os.Stderr)
+		statusLine.mu.Unlock()
+	}
+}
+
+// eta estimates the time left from the throughput so far, which includes any
+// time spent waiting out rate limits, capped by the rate the limiter allows.
+func (p *progress) eta(done int, elapsed time.Duration) (float64, time.Duration) {
+	perSecond := p.maxRate
+	if done > 0 && elapsed > 0 {
+		perSecond = min(float64(done)/elapsed.Seconds(), p.maxRate)
+	}
+	if perSecond <= 0 {
+		return 0, 0
+	}
+	left := time.Duration(float64(p.total-done) / perSecond * float64(time.Second))
+	return perSecond, left.Round(time.Second)
+}
+
+func (p *progress) report() {
+	p.mu.Lock()
+	done, failed, skipped := p.done, p.failed, p.skipped
+	p.mu.Unlock()
+	elapsed := time.Since(p.start)
+	perSecond, left := p.eta(done, elapsed)
+
+	if !p.bar {
+		slog.Info(p.label+"progress", "done", done, "total", p.total, "failed", failed, "skipped", skipped,
+			"per_second", fmt.Sprintf("%.2f", perSecond), "eta", left)
+		return
+	}
+	filled := progressBarWidth * done / p.total
+	bar := strings.Repeat("=", filled)
+	if filled < progressBarWidth {
+		bar += ">" + strings.Repeat(" ", progressBarWidth-filled-1)
+	}
+	text := fmt.Sprintf("%s[%s] %d/%d (%d failed, %d skipped)  %.1f/s  ETA %v",
+		p.label, bar, done, p.total, failed, skipped, perSecond, left)
+
+	statusLine.mu.Lock()
+	defer statusLine.mu.Unlock()
+	statusLine.text = text
+	fmt.Fprint(os.Stderr, "\r\033[K"+text)
+}
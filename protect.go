@@ -0,0 +1,113 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// protectOptions lists the channels no plan row may touch, given as names,
+// channel IDs or /regex/ patterns matched against channel names. The
+// workspace's #general channel is protected unless -allow-general is passed.
+type protectOptions struct {
+	entries      []string
+	file         string
+	allowGeneral bool
+}
+
+func (o *protectOptions) register(fs *flag.FlagSet) {
+	fs.Func("protect", "never touch this channel: a name, a channel ID or a /regex/ matched against names (repeatable)", func(s string) error {
+		o.entries = append(o.entries, s)
+		return nil
+	})
+	fs.StringVar(&o.file, "protect-file", "", "file of protected channels, one name, ID or /regex/ per line ('#' starts a comment line)")
+	fs.BoolVar(&o.allowGeneral, "allow-general", false, "allow plan rows to touch the workspace's #general channel")
+}
+
+// protectedChannels is the compiled form of protectOptions.
+type protectedChannels struct {
+	names    map[string]bool
+	patterns []*regexp.Regexp
+	general  bool
+}
+
+// compile reads -protect-file and parses every entry.
+func (o protectOptions) compile() (*protectedChannels, error) {
+	entries := o.entries
+	if o.file != "" {
+		f, err := os.Open(o.file)
+		if err != nil {
+			return nil, fmt.Errorf("read protected channels: %w", err)
+		}
+		defer f.Close()
+		sc := bufio.NewScanner(f)
+		for sc.Scan() {
+			line := strings.TrimSpace(sc.Text())
+			if line != "" && !strings.HasPrefix(line, "#") {
+				entries = append(entries, line)
+			}
+		}
+		if err := sc.Err(); err != nil {
+			return nil, fmt.Errorf("read %q: %w", o.file, err)
+		}
+	}
+
+	p := &protectedChannels{names: make(map[string]bool), general: !o.allowGeneral}
+	for _, entry := range entries {
+		if len(entry) > 2 && strings.HasPrefix(entry, "/") && strings.HasSuffix(entry, "/") {
+			re, err := regexp.Compile(entry[1 : len(entry)-1])
+			if err != nil {
+				return nil, fmt.Errorf("protected channel pattern %s: %w", entry, err)
+			}
+			p.patterns = append(p.patterns, re)
+			continue
+		}
+		p.names[strings.TrimPrefix(entry, "#")] = true
+	}
+	return p, nil
+}
+
+// reason returns why the channel named name is protected, or "" if it is not.
+func (p *protectedChannels) reason(name string, ch channelInfo) string {
+	switch {
+	case p.general && ch.IsGeneral:
+		return "it is the workspace's #general channel"
+	case p.names[name], ch.ID != "" && p.names[ch.ID]:
+		return "it is on the protected list"
+	}
+	for _, re := range p.patterns {
+		if re.MatchString(name) {
+			return fmt.Sprintf("it matches /%s/", re)
+		}
+	}
+	return ""
+}
+
+// check returns an error for every entry that touches a protected channel:
+// the channel it acts on and, for merges, the channel it merges into. Creates
+// touch no existing channel.
+func (p *protectedChannels) check(plan []planEntry, channels map[string]channelInfo) []string {
+	var errs []string
+	for _, e := range plan {
+		if e.Action == actionCreate {
+			continue
+		}
+		names := []string{e.Asis}
+		if e.Action == actionMerge {
+			names = append(names, e.Tobe)
+		}
+		for _, name := range names {
+			ch := channels[name]
+			if ch.ID == "" && name == e.Asis {
+				ch.ID = e.ChannelID
+			}
+			if why := p.reason(name, ch); why != "" {
+				errs = append(errs, e.At()+fmt.Sprintf("channel %q is protected: %s", name, why))
+			}
+		}
+	}
+	return errs
+}
@@ -0,0 +1,172 @@
+package main
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"log/slog"
+	"maps"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/kiddikn/slack-channel-renamer/slackops"
+	"github.com/slack-go/slack"
+)
+
+// The -references modes of apply.
+const (
+	referencesReport = "report"
+	referencesUpdate = "update"
+)
+
+func checkReferencesMode(mode string) error {
+	switch mode {
+	case "", referencesReport, referencesUpdate:
+		return nil
+	}
+	return fmt.Errorf("unknown -references %q (want report or update)", mode)
+}
+
+// mentionRe matches a plain-text #name mention; the name is the second group.
+// Real channel mentions, <#C123|name>, have no # before the name and follow
+// the channel on their own.
+var mentionRe = regexp.MustCompile(`(^|[^\p{L}\p{N}_-])#([\p{L}\p{N}_-]+)`)
+
+// replaceMentions replaces every #old mention in text of a name in renames
+// with #new, all at once, so that a chain such as #a -> #b, #b -> #c does not
+// rename #a twice. It returns the old names it replaced.
+func replaceMentions(text string, renames map[string]string) (string, []string) {
+	var b strings.Builder
+	var replaced []string
+	last := 0
+	for _, m := range mentionRe.FindAllStringSubmatchIndex(text, -1) {
+		name := text[m[4]:m[5]]
+		to, ok := renames[name]
+		if !ok {
+			continue
+		}
+		b.WriteString(text[last:m[4]])
+		b.WriteString(to)
+		last = m[5]
+		if !slices.Contains(replaced, name) {
+			replaced = append(replaced, name)
+		}
+	}
+	if replaced == nil {
+		return text, nil
+	}
+	b.WriteString(text[last:])
+	return b.String(), replaced
+}
+
+// updateReferences looks for #old-name mentions of the channels the run
+// renamed in the topics and purposes of every channel of the workspace, which
+// Slack leaves alone since they are only text. In report mode it logs them;
+// in update mode it rewrites them to the new names. Each mention is added to
+// the edits of the rename it refers to. Failures are logged and do not fail
+// the run.
+func (x *executor) updateReferences(mode string, r workspaceRun) {
+	renamed := x.renamedChannels(r.channels)
+	if len(renamed) == 0 {
+		return
+	}
+	if x.Admin {
+		slog.Warn(x.label + "topics and purposes cannot be changed in -admin mode; not looking for mentions of the old names")
+		return
+	}
+	channels := r.channels
+	if r.targeted {
+		// Only the plan's channels were looked up.
+		var err error
+		if channels, err = r.listChannels(cmdCtx); err != nil {
+			slog.Warn("failed to list the channels to look for mentions of the old names in", "err", err)
+			return
+		}
+	}
+	renames := make(map[string]string)
+	byOldName := make(map[string]renamedChannel)
+	newNames := make(map[string]string)
+	for _, c := range renamed {
+		renames[c.oldName] = c.newName
+		byOldName[c.oldName] = c
+		newNames[c.id] = c.newName
+	}
+
+	found := 0
+	for _, name := range slices.Sorted(maps.Keys(channels)) {
+		ch := channels[name]
+		if ch.IsArchived {
+			continue
+		}
+		if _, topic := replaceMentions(slackops.Unescape(ch.Topic), renames); topic == nil {
+			if _, purpose := replaceMentions(slackops.Unescape(ch.Purpose), renames); purpose == nil {
+				continue
+			}
+		}
+		name = cmp.Or(newNames[ch.ID], name)
+		attrs := []any{"channel", name, "channel_id", ch.ID}
+		// The listing may predate the run's own topic changes.
+		var info *slack.Channel
+		err := slackops.Call(cmdCtx, x.Stats, fmt.Sprintf("looking up the topic of %s", name), func(ctx context.Context) error {
+			var err error
+			info, err = x.Client.GetConversationInfoContext(ctx, &slack.GetConversationInfoInput{ChannelID: ch.ID})
+			return err
+		}, attrs...)
+		if err != nil {
+			slog.Warn("failed to look up the channel's topic", append(attrs, "err", err)...)
+			continue
+		}
+		for _, field := range []struct {
+			what string
+			text string
+			set  func(ctx context.Context, id, text string) (*slack.Channel, error)
+		}{
+			{"topic", slackops.Unescape(info.Topic.Value), x.Client.SetTopicOfConversationContext},
+			{"purpose", slackops.Unescape(info.Purpose.Value), x.Client.SetPurposeOfConversationContext},
+		} {
+			text, olds := replaceMentions(field.text, renames)
+			if len(olds) == 0 {
+				continue
+			}
+			found++
+			note := fmt.Sprintf("%s of #%s names #%s", field.what, name, strings.Join(olds, ", #"))
+			if mode == referencesUpdate {
+				note = x.rewriteReference(&ch, name, field.what, field.text, text, field.set, attrs)
+			} else {
+				slog.Info("found mentions of old channel names", append(attrs, field.what, field.text, "old_names", strings.Join(olds, ","))...)
+			}
+			for _, old := range olds {
+				x.addEdits(byOldName[old], []string{note})
+			}
+		}
+	}
+	if mode == referencesUpdate {
+		slog.Info(x.label+"rewrote topics and purposes naming old channel names", "mentions", found)
+	} else {
+		slog.Info(x.label+"found topics and purposes naming old channel names (pass -references update to rewrite them)", "mentions", found)
+	}
+}
+
+// rewriteReference sets the topic or purpose (what) of ch, called name, from
+// old to text, joining the channel first with a bot token, and returns the
+// edit to note. ch is marked a member once joined.
+func (x *executor) rewriteReference(ch *channelInfo, name, what, old, text string, set func(ctx context.Context, id, text string) (*slack.Channel, error), attrs []any) string {
+	if x.Bot {
+		if err := x.Join(cmdCtx, *ch, name, planEntry{Action: actionSetTopic, Asis: name}); err != nil {
+			slog.Warn("failed to join the channel to rewrite its "+what, append(attrs, "err", err)...)
+			return fmt.Sprintf("%s of #%s not updated: %v", what, name, err)
+		}
+		ch.IsMember = true
+	}
+	err := slackops.Call(cmdCtx, x.Stats, fmt.Sprintf("setting %s of %s", what, name), func(ctx context.Context) error {
+		_, err := set(ctx, ch.ID, text)
+		return err
+	}, attrs...)
+	if err != nil {
+		slog.Warn("failed to rewrite the channel's "+what, append(attrs, "err", err)...)
+		return fmt.Sprintf("%s of #%s not updated: %v", what, name, err)
+	}
+	slog.Info("rewrote old channel names in the channel's "+what, append(attrs, what, text)...)
+	return fmt.Sprintf("%s of #%s: %q -> %q", what, name, old, text)
+}
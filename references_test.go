@@ -0,0 +1,65 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestReplaceMentions(t *testing.T) {
+	renames := map[string]string{"a": "b", "b": "c"}
+	for _, c := range []struct {
+		text, want string
+		olds       []string
+	}{
+		{"see #a and #b", "see #b and #c", []string{"a", "b"}},
+		{"#a,#a", "#b,#b", []string{"a"}},
+		{"#a-old and a and <#C1|a>", "#a-old and a and <#C1|a>", nil},
+	} {
+		got, olds := replaceMentions(c.text, renames)
+		if got != c.want || !slices.Equal(olds, c.olds) {
+			t.Errorf("replaceMentions(%q) = %q, %q, want %q, %q", c.text, got, olds, c.want, c.olds)
+		}
+	}
+}
+
+func TestUpdateReferences(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "snapshot.json")
+	snapshot := `[{"name":"eng-old","id":"C1"},{"name":"ops-old","id":"C2"},{"name":"help","id":"C3","topic":"Ask in #eng-old or #ops-old, not #eng-old-archive"}]`
+	if err := os.WriteFile(path, []byte(snapshot), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	plan := []planEntry{
+		{Action: actionRename, Asis: "eng-old", Tobe: "eng-core"},
+		{Action: actionRename, Asis: "ops-old", Tobe: "ops"},
+	}
+	for _, mode := range []string{referencesReport, referencesUpdate} {
+		sessions, err := (workspaceOptions{simulate: path}).newSessions(channelOptions{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		runs, errs, _, err := validateRuns(sessions, workspaceOptions{}, plan, prepareOptions{})
+		if err != nil || len(errs) > 0 {
+			t.Fatalf("validateRuns: %v %q", err, errs)
+		}
+		res, err := executeRuns(runs, runOptions{verb: "rename", references: mode, pool: poolOptions{concurrency: 1, perMinute: 60}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		want, note := "Ask in #eng-old or #ops-old, not #eng-old-archive", "topic of #help names #eng-old, #ops-old"
+		if mode == referencesUpdate {
+			want = "Ask in #eng-core or #ops, not #eng-old-archive"
+			note = `topic of #help: "Ask in #eng-old or #ops-old, not #eng-old-archive" -> "` + want + `"`
+		}
+		w := sessions[0].client.(*simWorkspace)
+		if got := w.channels[2].Topic.Value; got != want {
+			t.Errorf("%s: topic = %q, want %q", mode, got, want)
+		}
+		for _, r := range res.results {
+			if !slices.Equal(r.Edits, []string{note}) {
+				t.Errorf("%s: edits of %s = %q, want %q", mode, r.Asis, r.Edits, note)
+			}
+		}
+	}
+}
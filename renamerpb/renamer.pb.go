@@ -0,0 +1,1915 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.12
+// 	protoc        (unknown)
+// source: renamer/v1/renamer.proto
+
+// The gRPC API of 'serve -grpc-addr': the operations of its HTTP API, for
+// services that drive renames from code. Every call but those of the
+// grpc.health.v1 service needs the API token as "authorization: Bearer
+// <token>" metadata. The messages have the fields of the HTTP API's JSON,
+// under the same names.
+
+package renamerpb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type UploadPlanRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// name is the file name, whose extension gives the format; upload.csv by
+	// default.
+	Name string `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	// format is csv, json, yaml or xlsx, as for -format.
+	Format        string `protobuf:"bytes,2,opt,name=format,proto3" json:"format,omitempty"`
+	Content       []byte `protobuf:"bytes,3,opt,name=content,proto3" json:"content,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UploadPlanRequest) Reset() {
+	*x = UploadPlanRequest{}
+	mi := &file_renamer_v1_renamer_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UploadPlanRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UploadPlanRequest) ProtoMessage() {}
+
+func (x *UploadPlanRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_renamer_v1_renamer_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UploadPlanRequest.ProtoReflect.Descriptor instead.
+func (*UploadPlanRequest) Descriptor() ([]byte, []int) {
+	return file_renamer_v1_renamer_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *UploadPlanRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *UploadPlanRequest) GetFormat() string {
+	if x != nil {
+		return x.Format
+	}
+	return ""
+}
+
+func (x *UploadPlanRequest) GetContent() []byte {
+	if x != nil {
+		return x.Content
+	}
+	return nil
+}
+
+type UploadPlanResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Plan          *Plan                  `protobuf:"bytes,1,opt,name=plan,proto3" json:"plan,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UploadPlanResponse) Reset() {
+	*x = UploadPlanResponse{}
+	mi := &file_renamer_v1_renamer_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UploadPlanResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UploadPlanResponse) ProtoMessage() {}
+
+func (x *UploadPlanResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_renamer_v1_renamer_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UploadPlanResponse.ProtoReflect.Descriptor instead.
+func (*UploadPlanResponse) Descriptor() ([]byte, []int) {
+	return file_renamer_v1_renamer_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *UploadPlanResponse) GetPlan() *Plan {
+	if x != nil {
+		return x.Plan
+	}
+	return nil
+}
+
+type Plan struct {
+	state    protoimpl.MessageState `protogen:"open.v1"`
+	Id       string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Name     string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Uploaded *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=uploaded,proto3" json:"uploaded,omitempty"`
+	Entries  int32                  `protobuf:"varint,4,opt,name=entries,proto3" json:"entries,omitempty"`
+	// validation is the outcome of the plan's latest validation, if any.
+	Validation    *Validation `protobuf:"bytes,5,opt,name=validation,proto3" json:"validation,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Plan) Reset() {
+	*x = Plan{}
+	mi := &file_renamer_v1_renamer_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Plan) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Plan) ProtoMessage() {}
+
+func (x *Plan) ProtoReflect() protoreflect.Message {
+	mi := &file_renamer_v1_renamer_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Plan.ProtoReflect.Descriptor instead.
+func (*Plan) Descriptor() ([]byte, []int) {
+	return file_renamer_v1_renamer_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *Plan) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Plan) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Plan) GetUploaded() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Uploaded
+	}
+	return nil
+}
+
+func (x *Plan) GetEntries() int32 {
+	if x != nil {
+		return x.Entries
+	}
+	return 0
+}
+
+func (x *Plan) GetValidation() *Validation {
+	if x != nil {
+		return x.Validation
+	}
+	return nil
+}
+
+type Validation struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Passed        bool                   `protobuf:"varint,1,opt,name=passed,proto3" json:"passed,omitempty"`
+	Errors        []string               `protobuf:"bytes,2,rep,name=errors,proto3" json:"errors,omitempty"`
+	Skipped       []string               `protobuf:"bytes,3,rep,name=skipped,proto3" json:"skipped,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Validation) Reset() {
+	*x = Validation{}
+	mi := &file_renamer_v1_renamer_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Validation) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Validation) ProtoMessage() {}
+
+func (x *Validation) ProtoReflect() protoreflect.Message {
+	mi := &file_renamer_v1_renamer_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Validation.ProtoReflect.Descriptor instead.
+func (*Validation) Descriptor() ([]byte, []int) {
+	return file_renamer_v1_renamer_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *Validation) GetPassed() bool {
+	if x != nil {
+		return x.Passed
+	}
+	return false
+}
+
+func (x *Validation) GetErrors() []string {
+	if x != nil {
+		return x.Errors
+	}
+	return nil
+}
+
+func (x *Validation) GetSkipped() []string {
+	if x != nil {
+		return x.Skipped
+	}
+	return nil
+}
+
+type ListPlansRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListPlansRequest) Reset() {
+	*x = ListPlansRequest{}
+	mi := &file_renamer_v1_renamer_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListPlansRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListPlansRequest) ProtoMessage() {}
+
+func (x *ListPlansRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_renamer_v1_renamer_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListPlansRequest.ProtoReflect.Descriptor instead.
+func (*ListPlansRequest) Descriptor() ([]byte, []int) {
+	return file_renamer_v1_renamer_proto_rawDescGZIP(), []int{4}
+}
+
+type ListPlansResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Plans         []*Plan                `protobuf:"bytes,1,rep,name=plans,proto3" json:"plans,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListPlansResponse) Reset() {
+	*x = ListPlansResponse{}
+	mi := &file_renamer_v1_renamer_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListPlansResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListPlansResponse) ProtoMessage() {}
+
+func (x *ListPlansResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_renamer_v1_renamer_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListPlansResponse.ProtoReflect.Descriptor instead.
+func (*ListPlansResponse) Descriptor() ([]byte, []int) {
+	return file_renamer_v1_renamer_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *ListPlansResponse) GetPlans() []*Plan {
+	if x != nil {
+		return x.Plans
+	}
+	return nil
+}
+
+type GetPlanRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	PlanId        string                 `protobuf:"bytes,1,opt,name=plan_id,json=planId,proto3" json:"plan_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetPlanRequest) Reset() {
+	*x = GetPlanRequest{}
+	mi := &file_renamer_v1_renamer_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetPlanRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetPlanRequest) ProtoMessage() {}
+
+func (x *GetPlanRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_renamer_v1_renamer_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetPlanRequest.ProtoReflect.Descriptor instead.
+func (*GetPlanRequest) Descriptor() ([]byte, []int) {
+	return file_renamer_v1_renamer_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *GetPlanRequest) GetPlanId() string {
+	if x != nil {
+		return x.PlanId
+	}
+	return ""
+}
+
+type GetPlanResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Plan          *Plan                  `protobuf:"bytes,1,opt,name=plan,proto3" json:"plan,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetPlanResponse) Reset() {
+	*x = GetPlanResponse{}
+	mi := &file_renamer_v1_renamer_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetPlanResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetPlanResponse) ProtoMessage() {}
+
+func (x *GetPlanResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_renamer_v1_renamer_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetPlanResponse.ProtoReflect.Descriptor instead.
+func (*GetPlanResponse) Descriptor() ([]byte, []int) {
+	return file_renamer_v1_renamer_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *GetPlanResponse) GetPlan() *Plan {
+	if x != nil {
+		return x.Plan
+	}
+	return nil
+}
+
+type ValidatePlanRequest struct {
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	PlanId string                 `protobuf:"bytes,1,opt,name=plan_id,json=planId,proto3" json:"plan_id,omitempty"`
+	// stats adds each entry's channel stats, reading its latest message.
+	Stats         bool `protobuf:"varint,2,opt,name=stats,proto3" json:"stats,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ValidatePlanRequest) Reset() {
+	*x = ValidatePlanRequest{}
+	mi := &file_renamer_v1_renamer_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ValidatePlanRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ValidatePlanRequest) ProtoMessage() {}
+
+func (x *ValidatePlanRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_renamer_v1_renamer_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ValidatePlanRequest.ProtoReflect.Descriptor instead.
+func (*ValidatePlanRequest) Descriptor() ([]byte, []int) {
+	return file_renamer_v1_renamer_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *ValidatePlanRequest) GetPlanId() string {
+	if x != nil {
+		return x.PlanId
+	}
+	return ""
+}
+
+func (x *ValidatePlanRequest) GetStats() bool {
+	if x != nil {
+		return x.Stats
+	}
+	return false
+}
+
+type ValidatePlanResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Validation    *Validation            `protobuf:"bytes,1,opt,name=validation,proto3" json:"validation,omitempty"`
+	Plan          []*PlanEntry           `protobuf:"bytes,2,rep,name=plan,proto3" json:"plan,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ValidatePlanResponse) Reset() {
+	*x = ValidatePlanResponse{}
+	mi := &file_renamer_v1_renamer_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ValidatePlanResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ValidatePlanResponse) ProtoMessage() {}
+
+func (x *ValidatePlanResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_renamer_v1_renamer_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ValidatePlanResponse.ProtoReflect.Descriptor instead.
+func (*ValidatePlanResponse) Descriptor() ([]byte, []int) {
+	return file_renamer_v1_renamer_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *ValidatePlanResponse) GetValidation() *Validation {
+	if x != nil {
+		return x.Validation
+	}
+	return nil
+}
+
+func (x *ValidatePlanResponse) GetPlan() []*PlanEntry {
+	if x != nil {
+		return x.Plan
+	}
+	return nil
+}
+
+// PlanEntry is an entry that would run.
+type PlanEntry struct {
+	state       protoimpl.MessageState `protogen:"open.v1"`
+	Action      string                 `protobuf:"bytes,1,opt,name=action,proto3" json:"action,omitempty"`
+	ChannelId   string                 `protobuf:"bytes,2,opt,name=channel_id,json=channelId,proto3" json:"channel_id,omitempty"`
+	Asis        string                 `protobuf:"bytes,3,opt,name=asis,proto3" json:"asis,omitempty"`
+	Tobe        string                 `protobuf:"bytes,4,opt,name=tobe,proto3" json:"tobe,omitempty"`
+	Owner       string                 `protobuf:"bytes,5,opt,name=owner,proto3" json:"owner,omitempty"`
+	Topic       string                 `protobuf:"bytes,6,opt,name=topic,proto3" json:"topic,omitempty"`
+	Purpose     string                 `protobuf:"bytes,7,opt,name=purpose,proto3" json:"purpose,omitempty"`
+	Private     bool                   `protobuf:"varint,8,opt,name=private,proto3" json:"private,omitempty"`
+	Members     string                 `protobuf:"bytes,9,opt,name=members,proto3" json:"members,omitempty"`
+	Workspace   string                 `protobuf:"bytes,10,opt,name=workspace,proto3" json:"workspace,omitempty"`
+	State       string                 `protobuf:"bytes,11,opt,name=state,proto3" json:"state,omitempty"`
+	Rearchive   bool                   `protobuf:"varint,12,opt,name=rearchive,proto3" json:"rearchive,omitempty"`
+	NotBefore   *timestamppb.Timestamp `protobuf:"bytes,13,opt,name=not_before,json=notBefore,proto3" json:"not_before,omitempty"`
+	ChannelTeam string                 `protobuf:"bytes,14,opt,name=channel_team,json=channelTeam,proto3" json:"channel_team,omitempty"`
+	Shared      string                 `protobuf:"bytes,15,opt,name=shared,proto3" json:"shared,omitempty"`
+	PinnedNote  string                 `protobuf:"bytes,16,opt,name=pinned_note,json=pinnedNote,proto3" json:"pinned_note,omitempty"`
+	// plan is the plan file the entry was read from, when there were several.
+	Plan          string        `protobuf:"bytes,17,opt,name=plan,proto3" json:"plan,omitempty"`
+	Source        string        `protobuf:"bytes,18,opt,name=source,proto3" json:"source,omitempty"`
+	Stats         *ChannelStats `protobuf:"bytes,19,opt,name=stats,proto3" json:"stats,omitempty"`
+	NeedsConfirm  string        `protobuf:"bytes,20,opt,name=needs_confirm,json=needsConfirm,proto3" json:"needs_confirm,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PlanEntry) Reset() {
+	*x = PlanEntry{}
+	mi := &file_renamer_v1_renamer_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PlanEntry) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PlanEntry) ProtoMessage() {}
+
+func (x *PlanEntry) ProtoReflect() protoreflect.Message {
+	mi := &file_renamer_v1_renamer_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PlanEntry.ProtoReflect.Descriptor instead.
+func (*PlanEntry) Descriptor() ([]byte, []int) {
+	return file_renamer_v1_renamer_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *PlanEntry) GetAction() string {
+	if x != nil {
+		return x.Action
+	}
+	return ""
+}
+
+func (x *PlanEntry) GetChannelId() string {
+	if x != nil {
+		return x.ChannelId
+	}
+	return ""
+}
+
+func (x *PlanEntry) GetAsis() string {
+	if x != nil {
+		return x.Asis
+	}
+	return ""
+}
+
+func (x *PlanEntry) GetTobe() string {
+	if x != nil {
+		return x.Tobe
+	}
+	return ""
+}
+
+func (x *PlanEntry) GetOwner() string {
+	if x != nil {
+		return x.Owner
+	}
+	return ""
+}
+
+func (x *PlanEntry) GetTopic() string {
+	if x != nil {
+		return x.Topic
+	}
+	return ""
+}
+
+func (x *PlanEntry) GetPurpose() string {
+	if x != nil {
+		return x.Purpose
+	}
+	return ""
+}
+
+func (x *PlanEntry) GetPrivate() bool {
+	if x != nil {
+		return x.Private
+	}
+	return false
+}
+
+func (x *PlanEntry) GetMembers() string {
+	if x != nil {
+		return x.Members
+	}
+	return ""
+}
+
+func (x *PlanEntry) GetWorkspace() string {
+	if x != nil {
+		return x.Workspace
+	}
+	return ""
+}
+
+func (x *PlanEntry) GetState() string {
+	if x != nil {
+		return x.State
+	}
+	return ""
+}
+
+func (x *PlanEntry) GetRearchive() bool {
+	if x != nil {
+		return x.Rearchive
+	}
+	return false
+}
+
+func (x *PlanEntry) GetNotBefore() *timestamppb.Timestamp {
+	if x != nil {
+		return x.NotBefore
+	}
+	return nil
+}
+
+func (x *PlanEntry) GetChannelTeam() string {
+	if x != nil {
+		return x.ChannelTeam
+	}
+	return ""
+}
+
+func (x *PlanEntry) GetShared() string {
+	if x != nil {
+		return x.Shared
+	}
+	return ""
+}
+
+func (x *PlanEntry) GetPinnedNote() string {
+	if x != nil {
+		return x.PinnedNote
+	}
+	return ""
+}
+
+func (x *PlanEntry) GetPlan() string {
+	if x != nil {
+		return x.Plan
+	}
+	return ""
+}
+
+func (x *PlanEntry) GetSource() string {
+	if x != nil {
+		return x.Source
+	}
+	return ""
+}
+
+func (x *PlanEntry) GetStats() *ChannelStats {
+	if x != nil {
+		return x.Stats
+	}
+	return nil
+}
+
+func (x *PlanEntry) GetNeedsConfirm() string {
+	if x != nil {
+		return x.NeedsConfirm
+	}
+	return ""
+}
+
+type ChannelStats struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Members       int32                  `protobuf:"varint,1,opt,name=members,proto3" json:"members,omitempty"`
+	Creator       string                 `protobuf:"bytes,2,opt,name=creator,proto3" json:"creator,omitempty"`
+	Created       *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=created,proto3" json:"created,omitempty"`
+	LastActivity  *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=last_activity,json=lastActivity,proto3" json:"last_activity,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ChannelStats) Reset() {
+	*x = ChannelStats{}
+	mi := &file_renamer_v1_renamer_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ChannelStats) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ChannelStats) ProtoMessage() {}
+
+func (x *ChannelStats) ProtoReflect() protoreflect.Message {
+	mi := &file_renamer_v1_renamer_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ChannelStats.ProtoReflect.Descriptor instead.
+func (*ChannelStats) Descriptor() ([]byte, []int) {
+	return file_renamer_v1_renamer_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *ChannelStats) GetMembers() int32 {
+	if x != nil {
+		return x.Members
+	}
+	return 0
+}
+
+func (x *ChannelStats) GetCreator() string {
+	if x != nil {
+		return x.Creator
+	}
+	return ""
+}
+
+func (x *ChannelStats) GetCreated() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Created
+	}
+	return nil
+}
+
+func (x *ChannelStats) GetLastActivity() *timestamppb.Timestamp {
+	if x != nil {
+		return x.LastActivity
+	}
+	return nil
+}
+
+type ApplyPlanRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	PlanId        string                 `protobuf:"bytes,1,opt,name=plan_id,json=planId,proto3" json:"plan_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ApplyPlanRequest) Reset() {
+	*x = ApplyPlanRequest{}
+	mi := &file_renamer_v1_renamer_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ApplyPlanRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ApplyPlanRequest) ProtoMessage() {}
+
+func (x *ApplyPlanRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_renamer_v1_renamer_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ApplyPlanRequest.ProtoReflect.Descriptor instead.
+func (*ApplyPlanRequest) Descriptor() ([]byte, []int) {
+	return file_renamer_v1_renamer_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *ApplyPlanRequest) GetPlanId() string {
+	if x != nil {
+		return x.PlanId
+	}
+	return ""
+}
+
+type ApplyPlanResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Run           *Run                   `protobuf:"bytes,1,opt,name=run,proto3" json:"run,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ApplyPlanResponse) Reset() {
+	*x = ApplyPlanResponse{}
+	mi := &file_renamer_v1_renamer_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ApplyPlanResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ApplyPlanResponse) ProtoMessage() {}
+
+func (x *ApplyPlanResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_renamer_v1_renamer_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ApplyPlanResponse.ProtoReflect.Descriptor instead.
+func (*ApplyPlanResponse) Descriptor() ([]byte, []int) {
+	return file_renamer_v1_renamer_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *ApplyPlanResponse) GetRun() *Run {
+	if x != nil {
+		return x.Run
+	}
+	return nil
+}
+
+type Run struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Id    string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	// plan is the ID of the plan the run applies.
+	Plan string `protobuf:"bytes,2,opt,name=plan,proto3" json:"plan,omitempty"`
+	// status is validating, running, succeeded, failed, interrupted or error.
+	Status        string                 `protobuf:"bytes,3,opt,name=status,proto3" json:"status,omitempty"`
+	Started       *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=started,proto3" json:"started,omitempty"`
+	Finished      *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=finished,proto3" json:"finished,omitempty"`
+	Entries       int32                  `protobuf:"varint,6,opt,name=entries,proto3" json:"entries,omitempty"`
+	Error         string                 `protobuf:"bytes,7,opt,name=error,proto3" json:"error,omitempty"`
+	Summary       *RunSummary            `protobuf:"bytes,8,opt,name=summary,proto3" json:"summary,omitempty"`
+	Progress      *RunProgress           `protobuf:"bytes,9,opt,name=progress,proto3" json:"progress,omitempty"`
+	RollbackFile  string                 `protobuf:"bytes,10,opt,name=rollback_file,json=rollbackFile,proto3" json:"rollback_file,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Run) Reset() {
+	*x = Run{}
+	mi := &file_renamer_v1_renamer_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Run) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Run) ProtoMessage() {}
+
+func (x *Run) ProtoReflect() protoreflect.Message {
+	mi := &file_renamer_v1_renamer_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Run.ProtoReflect.Descriptor instead.
+func (*Run) Descriptor() ([]byte, []int) {
+	return file_renamer_v1_renamer_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *Run) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Run) GetPlan() string {
+	if x != nil {
+		return x.Plan
+	}
+	return ""
+}
+
+func (x *Run) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *Run) GetStarted() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Started
+	}
+	return nil
+}
+
+func (x *Run) GetFinished() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Finished
+	}
+	return nil
+}
+
+func (x *Run) GetEntries() int32 {
+	if x != nil {
+		return x.Entries
+	}
+	return 0
+}
+
+func (x *Run) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+func (x *Run) GetSummary() *RunSummary {
+	if x != nil {
+		return x.Summary
+	}
+	return nil
+}
+
+func (x *Run) GetProgress() *RunProgress {
+	if x != nil {
+		return x.Progress
+	}
+	return nil
+}
+
+func (x *Run) GetRollbackFile() string {
+	if x != nil {
+		return x.RollbackFile
+	}
+	return ""
+}
+
+type RunSummary struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Succeeded     int32                  `protobuf:"varint,1,opt,name=succeeded,proto3" json:"succeeded,omitempty"`
+	Failed        int32                  `protobuf:"varint,2,opt,name=failed,proto3" json:"failed,omitempty"`
+	Skipped       int32                  `protobuf:"varint,3,opt,name=skipped,proto3" json:"skipped,omitempty"`
+	NotStarted    int32                  `protobuf:"varint,4,opt,name=not_started,json=notStarted,proto3" json:"not_started,omitempty"`
+	Interrupted   bool                   `protobuf:"varint,5,opt,name=interrupted,proto3" json:"interrupted,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RunSummary) Reset() {
+	*x = RunSummary{}
+	mi := &file_renamer_v1_renamer_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RunSummary) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RunSummary) ProtoMessage() {}
+
+func (x *RunSummary) ProtoReflect() protoreflect.Message {
+	mi := &file_renamer_v1_renamer_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RunSummary.ProtoReflect.Descriptor instead.
+func (*RunSummary) Descriptor() ([]byte, []int) {
+	return file_renamer_v1_renamer_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *RunSummary) GetSucceeded() int32 {
+	if x != nil {
+		return x.Succeeded
+	}
+	return 0
+}
+
+func (x *RunSummary) GetFailed() int32 {
+	if x != nil {
+		return x.Failed
+	}
+	return 0
+}
+
+func (x *RunSummary) GetSkipped() int32 {
+	if x != nil {
+		return x.Skipped
+	}
+	return 0
+}
+
+func (x *RunSummary) GetNotStarted() int32 {
+	if x != nil {
+		return x.NotStarted
+	}
+	return 0
+}
+
+func (x *RunSummary) GetInterrupted() bool {
+	if x != nil {
+		return x.Interrupted
+	}
+	return false
+}
+
+type RunProgress struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Done          int32                  `protobuf:"varint,1,opt,name=done,proto3" json:"done,omitempty"`
+	Failed        int32                  `protobuf:"varint,2,opt,name=failed,proto3" json:"failed,omitempty"`
+	Total         int32                  `protobuf:"varint,3,opt,name=total,proto3" json:"total,omitempty"`
+	Eta           string                 `protobuf:"bytes,4,opt,name=eta,proto3" json:"eta,omitempty"`
+	LastError     string                 `protobuf:"bytes,5,opt,name=last_error,json=lastError,proto3" json:"last_error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RunProgress) Reset() {
+	*x = RunProgress{}
+	mi := &file_renamer_v1_renamer_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RunProgress) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RunProgress) ProtoMessage() {}
+
+func (x *RunProgress) ProtoReflect() protoreflect.Message {
+	mi := &file_renamer_v1_renamer_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RunProgress.ProtoReflect.Descriptor instead.
+func (*RunProgress) Descriptor() ([]byte, []int) {
+	return file_renamer_v1_renamer_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *RunProgress) GetDone() int32 {
+	if x != nil {
+		return x.Done
+	}
+	return 0
+}
+
+func (x *RunProgress) GetFailed() int32 {
+	if x != nil {
+		return x.Failed
+	}
+	return 0
+}
+
+func (x *RunProgress) GetTotal() int32 {
+	if x != nil {
+		return x.Total
+	}
+	return 0
+}
+
+func (x *RunProgress) GetEta() string {
+	if x != nil {
+		return x.Eta
+	}
+	return ""
+}
+
+func (x *RunProgress) GetLastError() string {
+	if x != nil {
+		return x.LastError
+	}
+	return ""
+}
+
+type ListRunsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListRunsRequest) Reset() {
+	*x = ListRunsRequest{}
+	mi := &file_renamer_v1_renamer_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListRunsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListRunsRequest) ProtoMessage() {}
+
+func (x *ListRunsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_renamer_v1_renamer_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListRunsRequest.ProtoReflect.Descriptor instead.
+func (*ListRunsRequest) Descriptor() ([]byte, []int) {
+	return file_renamer_v1_renamer_proto_rawDescGZIP(), []int{17}
+}
+
+type ListRunsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Runs          []*Run                 `protobuf:"bytes,1,rep,name=runs,proto3" json:"runs,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListRunsResponse) Reset() {
+	*x = ListRunsResponse{}
+	mi := &file_renamer_v1_renamer_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListRunsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListRunsResponse) ProtoMessage() {}
+
+func (x *ListRunsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_renamer_v1_renamer_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListRunsResponse.ProtoReflect.Descriptor instead.
+func (*ListRunsResponse) Descriptor() ([]byte, []int) {
+	return file_renamer_v1_renamer_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *ListRunsResponse) GetRuns() []*Run {
+	if x != nil {
+		return x.Runs
+	}
+	return nil
+}
+
+type GetRunRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	RunId         string                 `protobuf:"bytes,1,opt,name=run_id,json=runId,proto3" json:"run_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetRunRequest) Reset() {
+	*x = GetRunRequest{}
+	mi := &file_renamer_v1_renamer_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetRunRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetRunRequest) ProtoMessage() {}
+
+func (x *GetRunRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_renamer_v1_renamer_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetRunRequest.ProtoReflect.Descriptor instead.
+func (*GetRunRequest) Descriptor() ([]byte, []int) {
+	return file_renamer_v1_renamer_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *GetRunRequest) GetRunId() string {
+	if x != nil {
+		return x.RunId
+	}
+	return ""
+}
+
+type GetRunResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Run           *Run                   `protobuf:"bytes,1,opt,name=run,proto3" json:"run,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetRunResponse) Reset() {
+	*x = GetRunResponse{}
+	mi := &file_renamer_v1_renamer_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetRunResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetRunResponse) ProtoMessage() {}
+
+func (x *GetRunResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_renamer_v1_renamer_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetRunResponse.ProtoReflect.Descriptor instead.
+func (*GetRunResponse) Descriptor() ([]byte, []int) {
+	return file_renamer_v1_renamer_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *GetRunResponse) GetRun() *Run {
+	if x != nil {
+		return x.Run
+	}
+	return nil
+}
+
+type WatchRunRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	RunId         string                 `protobuf:"bytes,1,opt,name=run_id,json=runId,proto3" json:"run_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WatchRunRequest) Reset() {
+	*x = WatchRunRequest{}
+	mi := &file_renamer_v1_renamer_proto_msgTypes[21]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WatchRunRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WatchRunRequest) ProtoMessage() {}
+
+func (x *WatchRunRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_renamer_v1_renamer_proto_msgTypes[21]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WatchRunRequest.ProtoReflect.Descriptor instead.
+func (*WatchRunRequest) Descriptor() ([]byte, []int) {
+	return file_renamer_v1_renamer_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *WatchRunRequest) GetRunId() string {
+	if x != nil {
+		return x.RunId
+	}
+	return ""
+}
+
+type WatchRunResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Run           *Run                   `protobuf:"bytes,1,opt,name=run,proto3" json:"run,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WatchRunResponse) Reset() {
+	*x = WatchRunResponse{}
+	mi := &file_renamer_v1_renamer_proto_msgTypes[22]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WatchRunResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WatchRunResponse) ProtoMessage() {}
+
+func (x *WatchRunResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_renamer_v1_renamer_proto_msgTypes[22]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WatchRunResponse.ProtoReflect.Descriptor instead.
+func (*WatchRunResponse) Descriptor() ([]byte, []int) {
+	return file_renamer_v1_renamer_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *WatchRunResponse) GetRun() *Run {
+	if x != nil {
+		return x.Run
+	}
+	return nil
+}
+
+type GetRunResultsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	RunId         string                 `protobuf:"bytes,1,opt,name=run_id,json=runId,proto3" json:"run_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetRunResultsRequest) Reset() {
+	*x = GetRunResultsRequest{}
+	mi := &file_renamer_v1_renamer_proto_msgTypes[23]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetRunResultsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetRunResultsRequest) ProtoMessage() {}
+
+func (x *GetRunResultsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_renamer_v1_renamer_proto_msgTypes[23]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetRunResultsRequest.ProtoReflect.Descriptor instead.
+func (*GetRunResultsRequest) Descriptor() ([]byte, []int) {
+	return file_renamer_v1_renamer_proto_rawDescGZIP(), []int{23}
+}
+
+func (x *GetRunResultsRequest) GetRunId() string {
+	if x != nil {
+		return x.RunId
+	}
+	return ""
+}
+
+type GetRunResultsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Results       []*EntryResult         `protobuf:"bytes,1,rep,name=results,proto3" json:"results,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetRunResultsResponse) Reset() {
+	*x = GetRunResultsResponse{}
+	mi := &file_renamer_v1_renamer_proto_msgTypes[24]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetRunResultsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetRunResultsResponse) ProtoMessage() {}
+
+func (x *GetRunResultsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_renamer_v1_renamer_proto_msgTypes[24]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetRunResultsResponse.ProtoReflect.Descriptor instead.
+func (*GetRunResultsResponse) Descriptor() ([]byte, []int) {
+	return file_renamer_v1_renamer_proto_rawDescGZIP(), []int{24}
+}
+
+func (x *GetRunResultsResponse) GetResults() []*EntryResult {
+	if x != nil {
+		return x.Results
+	}
+	return nil
+}
+
+type EntryResult struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	Source    string                 `protobuf:"bytes,1,opt,name=source,proto3" json:"source,omitempty"`
+	Workspace string                 `protobuf:"bytes,2,opt,name=workspace,proto3" json:"workspace,omitempty"`
+	Action    string                 `protobuf:"bytes,3,opt,name=action,proto3" json:"action,omitempty"`
+	Asis      string                 `protobuf:"bytes,4,opt,name=asis,proto3" json:"asis,omitempty"`
+	Tobe      string                 `protobuf:"bytes,5,opt,name=tobe,proto3" json:"tobe,omitempty"`
+	// status is ok, failed, skipped or pending.
+	Status        string                 `protobuf:"bytes,6,opt,name=status,proto3" json:"status,omitempty"`
+	Error         string                 `protobuf:"bytes,7,opt,name=error,proto3" json:"error,omitempty"`
+	Started       *timestamppb.Timestamp `protobuf:"bytes,8,opt,name=started,proto3" json:"started,omitempty"`
+	Finished      *timestamppb.Timestamp `protobuf:"bytes,9,opt,name=finished,proto3" json:"finished,omitempty"`
+	ChannelId     string                 `protobuf:"bytes,10,opt,name=channel_id,json=channelId,proto3" json:"channel_id,omitempty"`
+	ChannelName   string                 `protobuf:"bytes,11,opt,name=channel_name,json=channelName,proto3" json:"channel_name,omitempty"`
+	Rearchived    bool                   `protobuf:"varint,12,opt,name=rearchived,proto3" json:"rearchived,omitempty"`
+	Edits         []string               `protobuf:"bytes,13,rep,name=edits,proto3" json:"edits,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *EntryResult) Reset() {
+	*x = EntryResult{}
+	mi := &file_renamer_v1_renamer_proto_msgTypes[25]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *EntryResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*EntryResult) ProtoMessage() {}
+
+func (x *EntryResult) ProtoReflect() protoreflect.Message {
+	mi := &file_renamer_v1_renamer_proto_msgTypes[25]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use EntryResult.ProtoReflect.Descriptor instead.
+func (*EntryResult) Descriptor() ([]byte, []int) {
+	return file_renamer_v1_renamer_proto_rawDescGZIP(), []int{25}
+}
+
+func (x *EntryResult) GetSource() string {
+	if x != nil {
+		return x.Source
+	}
+	return ""
+}
+
+func (x *EntryResult) GetWorkspace() string {
+	if x != nil {
+		return x.Workspace
+	}
+	return ""
+}
+
+func (x *EntryResult) GetAction() string {
+	if x != nil {
+		return x.Action
+	}
+	return ""
+}
+
+func (x *EntryResult) GetAsis() string {
+	if x != nil {
+		return x.Asis
+	}
+	return ""
+}
+
+func (x *EntryResult) GetTobe() string {
+	if x != nil {
+		return x.Tobe
+	}
+	return ""
+}
+
+func (x *EntryResult) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *EntryResult) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+func (x *EntryResult) GetStarted() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Started
+	}
+	return nil
+}
+
+func (x *EntryResult) GetFinished() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Finished
+	}
+	return nil
+}
+
+func (x *EntryResult) GetChannelId() string {
+	if x != nil {
+		return x.ChannelId
+	}
+	return ""
+}
+
+func (x *EntryResult) GetChannelName() string {
+	if x != nil {
+		return x.ChannelName
+	}
+	return ""
+}
+
+func (x *EntryResult) GetRearchived() bool {
+	if x != nil {
+		return x.Rearchived
+	}
+	return false
+}
+
+func (x *EntryResult) GetEdits() []string {
+	if x != nil {
+		return x.Edits
+	}
+	return nil
+}
+
+var File_renamer_v1_renamer_proto protoreflect.FileDescriptor
+
+const file_renamer_v1_renamer_proto_rawDesc = "" +
+	"\n" +
+	"\x18renamer/v1/renamer.proto\x12\n" +
+	"renamer.v1\x1a\x1fgoogle/protobuf/timestamp.proto\"Y\n" +
+	"\x11UploadPlanRequest\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x16\n" +
+	"\x06format\x18\x02 \x01(\tR\x06format\x12\x18\n" +
+	"\acontent\x18\x03 \x01(\fR\acontent\":\n" +
+	"\x12UploadPlanResponse\x12$\n" +
+	"\x04plan\x18\x01 \x01(\v2\x10.renamer.v1.PlanR\x04plan\"\xb4\x01\n" +
+	"\x04Plan\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x126\n" +
+	"\buploaded\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\buploaded\x12\x18\n" +
+	"\aentries\x18\x04 \x01(\x05R\aentries\x126\n" +
+	"\n" +
+	"validation\x18\x05 \x01(\v2\x16.renamer.v1.ValidationR\n" +
+	"validation\"V\n" +
+	"\n" +
+	"Validation\x12\x16\n" +
+	"\x06passed\x18\x01 \x01(\bR\x06passed\x12\x16\n" +
+	"\x06errors\x18\x02 \x03(\tR\x06errors\x12\x18\n" +
+	"\askipped\x18\x03 \x03(\tR\askipped\"\x12\n" +
+	"\x10ListPlansRequest\";\n" +
+	"\x11ListPlansResponse\x12&\n" +
+	"\x05plans\x18\x01 \x03(\v2\x10.renamer.v1.PlanR\x05plans\")\n" +
+	"\x0eGetPlanRequest\x12\x17\n" +
+	"\aplan_id\x18\x01 \x01(\tR\x06planId\"7\n" +
+	"\x0fGetPlanResponse\x12$\n" +
+	"\x04plan\x18\x01 \x01(\v2\x10.renamer.v1.PlanR\x04plan\"D\n" +
+	"\x13ValidatePlanRequest\x12\x17\n" +
+	"\aplan_id\x18\x01 \x01(\tR\x06planId\x12\x14\n" +
+	"\x05stats\x18\x02 \x01(\bR\x05stats\"y\n" +
+	"\x14ValidatePlanResponse\x126\n" +
+	"\n" +
+	"validation\x18\x01 \x01(\v2\x16.renamer.v1.ValidationR\n" +
+	"validation\x12)\n" +
+	"\x04plan\x18\x02 \x03(\v2\x15.renamer.v1.PlanEntryR\x04plan\"\xce\x04\n" +
+	"\tPlanEntry\x12\x16\n" +
+	"\x06action\x18\x01 \x01(\tR\x06action\x12\x1d\n" +
+	"\n" +
+	"channel_id\x18\x02 \x01(\tR\tchannelId\x12\x12\n" +
+	"\x04asis\x18\x03 \x01(\tR\x04asis\x12\x12\n" +
+	"\x04tobe\x18\x04 \x01(\tR\x04tobe\x12\x14\n" +
+	"\x05owner\x18\x05 \x01(\tR\x05owner\x12\x14\n" +
+	"\x05topic\x18\x06 \x01(\tR\x05topic\x12\x18\n" +
+	"\apurpose\x18\a \x01(\tR\apurpose\x12\x18\n" +
+	"\aprivate\x18\b \x01(\bR\aprivate\x12\x18\n" +
+	"\amembers\x18\t \x01(\tR\amembers\x12\x1c\n" +
+	"\tworkspace\x18\n" +
+	" \x01(\tR\tworkspace\x12\x14\n" +
+	"\x05state\x18\v \x01(\tR\x05state\x12\x1c\n" +
+	"\trearchive\x18\f \x01(\bR\trearchive\x129\n" +
+	"\n" +
+	"not_before\x18\r \x01(\v2\x1a.google.protobuf.TimestampR\tnotBefore\x12!\n" +
+	"\fchannel_team\x18\x0e \x01(\tR\vchannelTeam\x12\x16\n" +
+	"\x06shared\x18\x0f \x01(\tR\x06shared\x12\x1f\n" +
+	"\vpinned_note\x18\x10 \x01(\tR\n" +
+	"pinnedNote\x12\x12\n" +
+	"\x04plan\x18\x11 \x01(\tR\x04plan\x12\x16\n" +
+	"\x06source\x18\x12 \x01(\tR\x06source\x12.\n" +
+	"\x05stats\x18\x13 \x01(\v2\x18.renamer.v1.ChannelStatsR\x05stats\x12#\n" +
+	"\rneeds_confirm\x18\x14 \x01(\tR\fneedsConfirm\"\xb9\x01\n" +
+	"\fChannelStats\x12\x18\n" +
+	"\amembers\x18\x01 \x01(\x05R\amembers\x12\x18\n" +
+	"\acreator\x18\x02 \x01(\tR\acreator\x124\n" +
+	"\acreated\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\acreated\x12?\n" +
+	"\rlast_activity\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampR\flastActivity\"+\n" +
+	"\x10ApplyPlanRequest\x12\x17\n" +
+	"\aplan_id\x18\x01 \x01(\tR\x06planId\"6\n" +
+	"\x11ApplyPlanResponse\x12!\n" +
+	"\x03run\x18\x01 \x01(\v2\x0f.renamer.v1.RunR\x03run\"\xeb\x02\n" +
+	"\x03Run\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x12\n" +
+	"\x04plan\x18\x02 \x01(\tR\x04plan\x12\x16\n" +
+	"\x06status\x18\x03 \x01(\tR\x06status\x124\n" +
+	"\astarted\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampR\astarted\x126\n" +
+	"\bfinished\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampR\bfinished\x12\x18\n" +
+	"\aentries\x18\x06 \x01(\x05R\aentries\x12\x14\n" +
+	"\x05error\x18\a \x01(\tR\x05error\x120\n" +
+	"\asummary\x18\b \x01(\v2\x16.renamer.v1.RunSummaryR\asummary\x123\n" +
+	"\bprogress\x18\t \x01(\v2\x17.renamer.v1.RunProgressR\bprogress\x12#\n" +
+	"\rrollback_file\x18\n" +
+	" \x01(\tR\frollbackFile\"\x9f\x01\n" +
+	"\n" +
+	"RunSummary\x12\x1c\n" +
+	"\tsucceeded\x18\x01 \x01(\x05R\tsucceeded\x12\x16\n" +
+	"\x06failed\x18\x02 \x01(\x05R\x06failed\x12\x18\n" +
+	"\askipped\x18\x03 \x01(\x05R\askipped\x12\x1f\n" +
+	"\vnot_started\x18\x04 \x01(\x05R\n" +
+	"notStarted\x12 \n" +
+	"\vinterrupted\x18\x05 \x01(\bR\vinterrupted\"\x80\x01\n" +
+	"\vRunProgress\x12\x12\n" +
+	"\x04done\x18\x01 \x01(\x05R\x04done\x12\x16\n" +
+	"\x06failed\x18\x02 \x01(\x05R\x06failed\x12\x14\n" +
+	"\x05total\x18\x03 \x01(\x05R\x05total\x12\x10\n" +
+	"\x03eta\x18\x04 \x01(\tR\x03eta\x12\x1d\n" +
+	"\n" +
+	"last_error\x18\x05 \x01(\tR\tlastError\"\x11\n" +
+	"\x0fListRunsRequest\"7\n" +
+	"\x10ListRunsResponse\x12#\n" +
+	"\x04runs\x18\x01 \x03(\v2\x0f.renamer.v1.RunR\x04runs\"&\n" +
+	"\rGetRunRequest\x12\x15\n" +
+	"\x06run_id\x18\x01 \x01(\tR\x05runId\"3\n" +
+	"\x0eGetRunResponse\x12!\n" +
+	"\x03run\x18\x01 \x01(\v2\x0f.renamer.v1.RunR\x03run\"(\n" +
+	"\x0fWatchRunRequest\x12\x15\n" +
+	"\x06run_id\x18\x01 \x01(\tR\x05runId\"5\n" +
+	"\x10WatchRunResponse\x12!\n" +
+	"\x03run\x18\x01 \x01(\v2\x0f.renamer.v1.RunR\x03run\"-\n" +
+	"\x14GetRunResultsRequest\x12\x15\n" +
+	"\x06run_id\x18\x01 \x01(\tR\x05runId\"J\n" +
+	"\x15GetRunResultsResponse\x121\n" +
+	"\aresults\x18\x01 \x03(\v2\x17.renamer.v1.EntryResultR\aresults\"\x97\x03\n" +
+	"\vEntryResult\x12\x16\n" +
+	"\x06source\x18\x01 \x01(\tR\x06source\x12\x1c\n" +
+	"\tworkspace\x18\x02 \x01(\tR\tworkspace\x12\x16\n" +
+	"\x06action\x18\x03 \x01(\tR\x06action\x12\x12\n" +
+	"\x04asis\x18\x04 \x01(\tR\x04asis\x12\x12\n" +
+	"\x04tobe\x18\x05 \x01(\tR\x04tobe\x12\x16\n" +
+	"\x06status\x18\x06 \x01(\tR\x06status\x12\x14\n" +
+	"\x05error\x18\a \x01(\tR\x05error\x124\n" +
+	"\astarted\x18\b \x01(\v2\x1a.google.protobuf.TimestampR\astarted\x126\n" +
+	"\bfinished\x18\t \x01(\v2\x1a.google.protobuf.TimestampR\bfinished\x12\x1d\n" +
+	"\n" +
+	"channel_id\x18\n" +
+	" \x01(\tR\tchannelId\x12!\n" +
+	"\fchannel_name\x18\v \x01(\tR\vchannelName\x12\x1e\n" +
+	"\n" +
+	"rearchived\x18\f \x01(\bR\n" +
+	"rearchived\x12\x14\n" +
+	"\x05edits\x18\r \x03(\tR\x05edits2\xaf\x05\n" +
+	"\x0eRenamerService\x12K\n" +
+	"\n" +
+	"UploadPlan\x12\x1d.renamer.v1.UploadPlanRequest\x1a\x1e.renamer.v1.UploadPlanResponse\x12H\n" +
+	"\tListPlans\x12\x1c.renamer.v1.ListPlansRequest\x1a\x1d.renamer.v1.ListPlansResponse\x12B\n" +
+	"\aGetPlan\x12\x1a.renamer.v1.GetPlanRequest\x1a\x1b.renamer.v1.GetPlanResponse\x12Q\n" +
+	"\fValidatePlan\x12\x1f.renamer.v1.ValidatePlanRequest\x1a .renamer.v1.ValidatePlanResponse\x12H\n" +
+	"\tApplyPlan\x12\x1c.renamer.v1.ApplyPlanRequest\x1a\x1d.renamer.v1.ApplyPlanResponse\x12E\n" +
+	"\bListRuns\x12\x1b.renamer.v1.ListRunsRequest\x1a\x1c.renamer.v1.ListRunsResponse\x12?\n" +
+	"\x06GetRun\x12\x19.renamer.v1.GetRunRequest\x1a\x1a.renamer.v1.GetRunResponse\x12G\n" +
+	"\bWatchRun\x12\x1b.renamer.v1.WatchRunRequest\x1a\x1c.renamer.v1.WatchRunResponse0\x01\x12T\n" +
+	"\rGetRunResults\x12 .renamer.v1.GetRunResultsRequest\x1a!.renamer.v1.GetRunResultsResponseB4Z2github.com/kiddikn/slack-channel-renamer/renamerpbb\x06proto3"
+
+var (
+	file_renamer_v1_renamer_proto_rawDescOnce sync.Once
+	file_renamer_v1_renamer_proto_rawDescData []byte
+)
+
+func file_renamer_v1_renamer_proto_rawDescGZIP() []byte {
+	file_renamer_v1_renamer_proto_rawDescOnce.Do(func() {
+		file_renamer_v1_renamer_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_renamer_v1_renamer_proto_rawDesc), len(file_renamer_v1_renamer_proto_rawDesc)))
+	})
+	return file_renamer_v1_renamer_proto_rawDescData
+}
+
+var file_renamer_v1_renamer_proto_msgTypes = make([]protoimpl.MessageInfo, 26)
+var file_renamer_v1_renamer_proto_goTypes = []any{
+	(*UploadPlanRequest)(nil),     // 0: renamer.v1.UploadPlanRequest
+	(*UploadPlanResponse)(nil),    // 1: renamer.v1.UploadPlanResponse
+	(*Plan)(nil),                  // 2: renamer.v1.Plan
+	(*Validation)(nil),            // 3: renamer.v1.Validation
+	(*ListPlansRequest)(nil),      // 4: renamer.v1.ListPlansRequest
+	(*ListPlansResponse)(nil),     // 5: renamer.v1.ListPlansResponse
+	(*GetPlanRequest)(nil),        // 6: renamer.v1.GetPlanRequest
+	(*GetPlanResponse)(nil),       // 7: renamer.v1.GetPlanResponse
+	(*ValidatePlanRequest)(nil),   // 8: renamer.v1.ValidatePlanRequest
+	(*ValidatePlanResponse)(nil),  // 9: renamer.v1.ValidatePlanResponse
+	(*PlanEntry)(nil),             // 10: renamer.v1.PlanEntry
+	(*ChannelStats)(nil),          // 11: renamer.v1.ChannelStats
+	(*ApplyPlanRequest)(nil),      // 12: renamer.v1.ApplyPlanRequest
+	(*ApplyPlanResponse)(nil),     // 13: renamer.v1.ApplyPlanResponse
+	(*Run)(nil),                   // 14: renamer.v1.Run
+	(*RunSummary)(nil),            // 15: renamer.v1.RunSummary
+	(*RunProgress)(nil),           // 16: renamer.v1.RunProgress
+	(*ListRunsRequest)(nil),       // 17: renamer.v1.ListRunsRequest
+	(*ListRunsResponse)(nil),      // 18: renamer.v1.ListRunsResponse
+	(*GetRunRequest)(nil),         // 19: renamer.v1.GetRunRequest
+	(*GetRunResponse)(nil),        // 20: renamer.v1.GetRunResponse
+	(*WatchRunRequest)(nil),       // 21: renamer.v1.WatchRunRequest
+	(*WatchRunResponse)(nil),      // 22: renamer.v1.WatchRunResponse
+	(*GetRunResultsRequest)(nil),  // 23: renamer.v1.GetRunResultsRequest
+	(*GetRunResultsResponse)(nil), // 24: renamer.v1.GetRunResultsResponse
+	(*EntryResult)(nil),           // 25: renamer.v1.EntryResult
+	(*timestamppb.Timestamp)(nil), // 26: google.protobuf.Timestamp
+}
+var file_renamer_v1_renamer_proto_depIdxs = []int32{
+	2,  // 0: renamer.v1.UploadPlanResponse.plan:type_name -> renamer.v1.Plan
+	26, // 1: renamer.v1.Plan.uploaded:type_name -> google.protobuf.Timestamp
+	3,  // 2: renamer.v1.Plan.validation:type_name -> renamer.v1.Validation
+	2,  // 3: renamer.v1.ListPlansResponse.plans:type_name -> renamer.v1.Plan
+	2,  // 4: renamer.v1.GetPlanResponse.plan:type_name -> renamer.v1.Plan
+	3,  // 5: renamer.v1.ValidatePlanResponse.validation:type_name -> renamer.v1.Validation
+	10, // 6: renamer.v1.ValidatePlanResponse.plan:type_name -> renamer.v1.PlanEntry
+	26, // 7: renamer.v1.PlanEntry.not_before:type_name -> google.protobuf.Timestamp
+	11, // 8: renamer.v1.PlanEntry.stats:type_name -> renamer.v1.ChannelStats
+	26, // 9: renamer.v1.ChannelStats.created:type_name -> google.protobuf.Timestamp
+	26, // 10: renamer.v1.ChannelStats.last_activity:type_name -> google.protobuf.Timestamp
+	14, // 11: renamer.v1.ApplyPlanResponse.run:type_name -> renamer.v1.Run
+	26, // 12: renamer.v1.Run.started:type_name -> google.protobuf.Timestamp
+	26, // 13: renamer.v1.Run.finished:type_name -> google.protobuf.Timestamp
+	15, // 14: renamer.v1.Run.summary:type_name -> renamer.v1.RunSummary
+	16, // 15: renamer.v1.Run.progress:type_name -> renamer.v1.RunProgress
+	14, // 16: renamer.v1.ListRunsResponse.runs:type_name -> renamer.v1.Run
+	14, // 17: renamer.v1.GetRunResponse.run:type_name -> renamer.v1.Run
+	14, // 18: renamer.v1.WatchRunResponse.run:type_name -> renamer.v1.Run
+	25, // 19: renamer.v1.GetRunResultsResponse.results:type_name -> renamer.v1.EntryResult
+	26, // 20: renamer.v1.EntryResult.started:type_name -> google.protobuf.Timestamp
+	26, // 21: renamer.v1.EntryResult.finished:type_name -> google.protobuf.Timestamp
+	0,  // 22: renamer.v1.RenamerService.UploadPlan:input_type -> renamer.v1.UploadPlanRequest
+	4,  // 23: renamer.v1.RenamerService.ListPlans:input_type -> renamer.v1.ListPlansRequest
+	6,  // 24: renamer.v1.RenamerService.GetPlan:input_type -> renamer.v1.GetPlanRequest
+	8,  // 25: renamer.v1.RenamerService.ValidatePlan:input_type -> renamer.v1.ValidatePlanRequest
+	12, // 26: renamer.v1.RenamerService.ApplyPlan:input_type -> renamer.v1.ApplyPlanRequest
+	17, // 27: renamer.v1.RenamerService.ListRuns:input_type -> renamer.v1.ListRunsRequest
+	19, // 28: renamer.v1.RenamerService.GetRun:input_type -> renamer.v1.GetRunRequest
+	21, // 29: renamer.v1.RenamerService.WatchRun:input_type -> renamer.v1.WatchRunRequest
+	23, // 30: renamer.v1.RenamerService.GetRunResults:input_type -> renamer.v1.GetRunResultsRequest
+	1,  // 31: renamer.v1.RenamerService.UploadPlan:output_type -> renamer.v1.UploadPlanResponse
+	5,  // 32: renamer.v1.RenamerService.ListPlans:output_type -> renamer.v1.ListPlansResponse
+	7,  // 33: renamer.v1.RenamerService.GetPlan:output_type -> renamer.v1.GetPlanResponse
+	9,  // 34: renamer.v1.RenamerService.ValidatePlan:output_type -> renamer.v1.ValidatePlanResponse
+	13, // 35: renamer.v1.RenamerService.ApplyPlan:output_type -> renamer.v1.ApplyPlanResponse
+	18, // 36: renamer.v1.RenamerService.ListRuns:output_type -> renamer.v1.ListRunsResponse
+	20, // 37: renamer.v1.RenamerService.GetRun:output_type -> renamer.v1.GetRunResponse
+	22, // 38: renamer.v1.RenamerService.WatchRun:output_type -> renamer.v1.WatchRunResponse
+	24, // 39: renamer.v1.RenamerService.GetRunResults:output_type -> renamer.v1.GetRunResultsResponse
+	31, // [31:40] is the sub-list for method output_type
+	22, // [22:31] is the sub-list for method input_type
+	22, // [22:22] is the sub-list for extension type_name
+	22, // [22:22] is the sub-list for extension extendee
+	0,  // [0:22] is the sub-list for field type_name
+}
+
+func init() { file_renamer_v1_renamer_proto_init() }
+func file_renamer_v1_renamer_proto_init() {
+	if File_renamer_v1_renamer_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_renamer_v1_renamer_proto_rawDesc), len(file_renamer_v1_renamer_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   26,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_renamer_v1_renamer_proto_goTypes,
+		DependencyIndexes: file_renamer_v1_renamer_proto_depIdxs,
+		MessageInfos:      file_renamer_v1_renamer_proto_msgTypes,
+	}.Build()
+	File_renamer_v1_renamer_proto = out.File
+	file_renamer_v1_renamer_proto_goTypes = nil
+	file_renamer_v1_renamer_proto_depIdxs = nil
+}
@@ -0,0 +1,463 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.6.2
+// - protoc             (unknown)
+// source: renamer/v1/renamer.proto
+
+// The gRPC API of 'serve -grpc-addr': the operations of its HTTP API, for
+// services that drive renames from code. Every call but those of the
+// grpc.health.v1 service needs the API token as "authorization: Bearer
+// <token>" metadata. The messages have the fields of the HTTP API's JSON,
+// under the same names.
+
+package renamerpb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	RenamerService_UploadPlan_FullMethodName    = "/renamer.v1.RenamerService/UploadPlan"
+	RenamerService_ListPlans_FullMethodName     = "/renamer.v1.RenamerService/ListPlans"
+	RenamerService_GetPlan_FullMethodName       = "/renamer.v1.RenamerService/GetPlan"
+	RenamerService_ValidatePlan_FullMethodName  = "/renamer.v1.RenamerService/ValidatePlan"
+	RenamerService_ApplyPlan_FullMethodName     = "/renamer.v1.RenamerService/ApplyPlan"
+	RenamerService_ListRuns_FullMethodName      = "/renamer.v1.RenamerService/ListRuns"
+	RenamerService_GetRun_FullMethodName        = "/renamer.v1.RenamerService/GetRun"
+	RenamerService_WatchRun_FullMethodName      = "/renamer.v1.RenamerService/WatchRun"
+	RenamerService_GetRunResults_FullMethodName = "/renamer.v1.RenamerService/GetRunResults"
+)
+
+// RenamerServiceClient is the client API for RenamerService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type RenamerServiceClient interface {
+	// UploadPlan stores a plan file. Its format is taken from format, or else
+	// from the extension of name, as for -plan; it defaults to CSV.
+	UploadPlan(ctx context.Context, in *UploadPlanRequest, opts ...grpc.CallOption) (*UploadPlanResponse, error)
+	// ListPlans lists the uploaded plans, oldest first.
+	ListPlans(ctx context.Context, in *ListPlansRequest, opts ...grpc.CallOption) (*ListPlansResponse, error)
+	GetPlan(ctx context.Context, in *GetPlanRequest, opts ...grpc.CallOption) (*GetPlanResponse, error)
+	// ValidatePlan validates a plan against the live channels and returns the
+	// entries that would run. It fails with FAILED_PRECONDITION while a run
+	// holds the history database.
+	ValidatePlan(ctx context.Context, in *ValidatePlanRequest, opts ...grpc.CallOption) (*ValidatePlanResponse, error)
+	// ApplyPlan validates a plan again and starts applying it in the
+	// background. It fails with FAILED_PRECONDITION while another run is in
+	// progress.
+	ApplyPlan(ctx context.Context, in *ApplyPlanRequest, opts ...grpc.CallOption) (*ApplyPlanResponse, error)
+	// ListRuns lists the runs, oldest first, without their results.
+	ListRuns(ctx context.Context, in *ListRunsRequest, opts ...grpc.CallOption) (*ListRunsResponse, error)
+	// GetRun returns a run's status and counts so far, without its results.
+	GetRun(ctx context.Context, in *GetRunRequest, opts ...grpc.CallOption) (*GetRunResponse, error)
+	// WatchRun sends the run as GetRun returns it, then again each time it
+	// changes, until it has finished.
+	WatchRun(ctx context.Context, in *WatchRunRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[WatchRunResponse], error)
+	// GetRunResults returns the outcome of every entry of a run finished so far.
+	GetRunResults(ctx context.Context, in *GetRunResultsRequest, opts ...grpc.CallOption) (*GetRunResultsResponse, error)
+}
+
+type renamerServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewRenamerServiceClient(cc grpc.ClientConnInterface) RenamerServiceClient {
+	return &renamerServiceClient{cc}
+}
+
+func (c *renamerServiceClient) UploadPlan(ctx context.Context, in *UploadPlanRequest, opts ...grpc.CallOption) (*UploadPlanResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(UploadPlanResponse)
+	err := c.cc.Invoke(ctx, RenamerService_UploadPlan_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *renamerServiceClient) ListPlans(ctx context.Context, in *ListPlansRequest, opts ...grpc.CallOption) (*ListPlansResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListPlansResponse)
+	err := c.cc.Invoke(ctx, RenamerService_ListPlans_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *renamerServiceClient) GetPlan(ctx context.Context, in *GetPlanRequest, opts ...grpc.CallOption) (*GetPlanResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetPlanResponse)
+	err := c.cc.Invoke(ctx, RenamerService_GetPlan_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *renamerServiceClient) ValidatePlan(ctx context.Context, in *ValidatePlanRequest, opts ...grpc.CallOption) (*ValidatePlanResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ValidatePlanResponse)
+	err := c.cc.Invoke(ctx, RenamerService_ValidatePlan_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *renamerServiceClient) ApplyPlan(ctx context.Context, in *ApplyPlanRequest, opts ...grpc.CallOption) (*ApplyPlanResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ApplyPlanResponse)
+	err := c.cc.Invoke(ctx, RenamerService_ApplyPlan_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *renamerServiceClient) ListRuns(ctx context.Context, in *ListRunsRequest, opts ...grpc.CallOption) (*ListRunsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListRunsResponse)
+	err := c.cc.Invoke(ctx, RenamerService_ListRuns_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *renamerServiceClient) GetRun(ctx context.Context, in *GetRunRequest, opts ...grpc.CallOption) (*GetRunResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetRunResponse)
+	err := c.cc.Invoke(ctx, RenamerService_GetRun_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *renamerServiceClient) WatchRun(ctx context.Context, in *WatchRunRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[WatchRunResponse], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &RenamerService_ServiceDesc.Streams[0], RenamerService_WatchRun_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[WatchRunRequest, WatchRunResponse]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type RenamerService_WatchRunClient = grpc.ServerStreamingClient[WatchRunResponse]
+
+func (c *renamerServiceClient) GetRunResults(ctx context.Context, in *GetRunResultsRequest, opts ...grpc.CallOption) (*GetRunResultsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetRunResultsResponse)
+	err := c.cc.Invoke(ctx, RenamerService_GetRunResults_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// RenamerServiceServer is the server API for RenamerService service.
+// All implementations must embed UnimplementedRenamerServiceServer
+// for forward compatibility.
+type RenamerServiceServer interface {
+	// UploadPlan stores a plan file. Its format is taken from format, or else
+	// from the extension of name, as for -plan; it defaults to CSV.
+	UploadPlan(context.Context, *UploadPlanRequest) (*UploadPlanResponse, error)
+	// ListPlans lists the uploaded plans, oldest first.
+	ListPlans(context.Context, *ListPlansRequest) (*ListPlansResponse, error)
+	GetPlan(context.Context, *GetPlanRequest) (*GetPlanResponse, error)
+	// ValidatePlan validates a plan against the live channels and returns the
+	// entries that would run. It fails with FAILED_PRECONDITION while a run
+	// holds the history database.
+	ValidatePlan(context.Context, *ValidatePlanRequest) (*ValidatePlanResponse, error)
+	// ApplyPlan validates a plan again and starts applying it in the
+	// background. It fails with FAILED_PRECONDITION while another run is in
+	// progress.
+	ApplyPlan(context.Context, *ApplyPlanRequest) (*ApplyPlanResponse, error)
+	// ListRuns lists the runs, oldest first, without their results.
+	ListRuns(context.Context, *ListRunsRequest) (*ListRunsResponse, error)
+	// GetRun returns a run's status and counts so far, without its results.
+	GetRun(context.Context, *GetRunRequest) (*GetRunResponse, error)
+	// WatchRun sends the run as GetRun returns it, then again each time it
+	// changes, until it has finished.
+	WatchRun(*WatchRunRequest, grpc.ServerStreamingServer[WatchRunResponse]) error
+	// GetRunResults returns the outcome of every entry of a run finished so far.
+	GetRunResults(context.Context, *GetRunResultsRequest) (*GetRunResultsResponse, error)
+	mustEmbedUnimplementedRenamerServiceServer()
+}
+
+// UnimplementedRenamerServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedRenamerServiceServer struct{}
+
+func (UnimplementedRenamerServiceServer) UploadPlan(context.Context, *UploadPlanRequest) (*UploadPlanResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method UploadPlan not implemented")
+}
+func (UnimplementedRenamerServiceServer) ListPlans(context.Context, *ListPlansRequest) (*ListPlansResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method ListPlans not implemented")
+}
+func (UnimplementedRenamerServiceServer) GetPlan(context.Context, *GetPlanRequest) (*GetPlanResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method GetPlan not implemented")
+}
+func (UnimplementedRenamerServiceServer) ValidatePlan(context.Context, *ValidatePlanRequest) (*ValidatePlanResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method ValidatePlan not implemented")
+}
+func (UnimplementedRenamerServiceServer) ApplyPlan(context.Context, *ApplyPlanRequest) (*ApplyPlanResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method ApplyPlan not implemented")
+}
+func (UnimplementedRenamerServiceServer) ListRuns(context.Context, *ListRunsRequest) (*ListRunsResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method ListRuns not implemented")
+}
+func (UnimplementedRenamerServiceServer) GetRun(context.Context, *GetRunRequest) (*GetRunResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method GetRun not implemented")
+}
+func (UnimplementedRenamerServiceServer) WatchRun(*WatchRunRequest, grpc.ServerStreamingServer[WatchRunResponse]) error {
+	return status.Error(codes.Unimplemented, "method WatchRun not implemented")
+}
+func (UnimplementedRenamerServiceServer) GetRunResults(context.Context, *GetRunResultsRequest) (*GetRunResultsResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method GetRunResults not implemented")
+}
+func (UnimplementedRenamerServiceServer) mustEmbedUnimplementedRenamerServiceServer() {}
+func (UnimplementedRenamerServiceServer) testEmbeddedByValue()                        {}
+
+// UnsafeRenamerServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to RenamerServiceServer will
+// result in compilation errors.
+type UnsafeRenamerServiceServer interface {
+	mustEmbedUnimplementedRenamerServiceServer()
+}
+
+func RegisterRenamerServiceServer(s grpc.ServiceRegistrar, srv RenamerServiceServer) {
+	// If the following call panics, it indicates UnimplementedRenamerServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&RenamerService_ServiceDesc, srv)
+}
+
+func _RenamerService_UploadPlan_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UploadPlanRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RenamerServiceServer).UploadPlan(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: RenamerService_UploadPlan_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RenamerServiceServer).UploadPlan(ctx, req.(*UploadPlanRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RenamerService_ListPlans_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListPlansRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RenamerServiceServer).ListPlans(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: RenamerService_ListPlans_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RenamerServiceServer).ListPlans(ctx, req.(*ListPlansRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RenamerService_GetPlan_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetPlanRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RenamerServiceServer).GetPlan(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: RenamerService_GetPlan_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RenamerServiceServer).GetPlan(ctx, req.(*GetPlanRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RenamerService_ValidatePlan_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ValidatePlanRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RenamerServiceServer).ValidatePlan(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: RenamerService_ValidatePlan_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RenamerServiceServer).ValidatePlan(ctx, req.(*ValidatePlanRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RenamerService_ApplyPlan_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ApplyPlanRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RenamerServiceServer).ApplyPlan(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: RenamerService_ApplyPlan_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RenamerServiceServer).ApplyPlan(ctx, req.(*ApplyPlanRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RenamerService_ListRuns_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListRunsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RenamerServiceServer).ListRuns(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: RenamerService_ListRuns_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RenamerServiceServer).ListRuns(ctx, req.(*ListRunsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RenamerService_GetRun_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetRunRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RenamerServiceServer).GetRun(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: RenamerService_GetRun_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RenamerServiceServer).GetRun(ctx, req.(*GetRunRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RenamerService_WatchRun_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchRunRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(RenamerServiceServer).WatchRun(m, &grpc.GenericServerStream[WatchRunRequest, WatchRunResponse]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type RenamerService_WatchRunServer = grpc.ServerStreamingServer[WatchRunResponse]
+
+func _RenamerService_GetRunResults_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetRunResultsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RenamerServiceServer).GetRunResults(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: RenamerService_GetRunResults_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RenamerServiceServer).GetRunResults(ctx, req.(*GetRunResultsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// RenamerService_ServiceDesc is the grpc.ServiceDesc for RenamerService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var RenamerService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "renamer.v1.RenamerService",
+	HandlerType: (*RenamerServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "UploadPlan",
+			Handler:    _RenamerService_UploadPlan_Handler,
+		},
+		{
+			MethodName: "ListPlans",
+			Handler:    _RenamerService_ListPlans_Handler,
+		},
+		{
+			MethodName: "GetPlan",
+			Handler:    _RenamerService_GetPlan_Handler,
+		},
+		{
+			MethodName: "ValidatePlan",
+			Handler:    _RenamerService_ValidatePlan_Handler,
+		},
+		{
+			MethodName: "ApplyPlan",
+			Handler:    _RenamerService_ApplyPlan_Handler,
+		},
+		{
+			MethodName: "ListRuns",
+			Handler:    _RenamerService_ListRuns_Handler,
+		},
+		{
+			MethodName: "GetRun",
+			Handler:    _RenamerService_GetRun_Handler,
+		},
+		{
+			MethodName: "GetRunResults",
+			Handler:    _RenamerService_GetRunResults_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "WatchRun",
+			Handler:       _RenamerService_WatchRun_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "renamer/v1/renamer.proto",
+}
@@ -0,0 +1,185 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+)
+
+// replaying is set by -replay; commands then run without a token.
+var replaying bool
+
+// replayToken stands in for the token while replaying.
+const replayToken = "xoxp-replay"
+
+// cassette is the fixture file of -record and -replay: the Slack API calls of
+// a run in the order they were made. Requests are keyed by endpoint and
+// parameters, with the token redacted as in -debug logs.
+type cassette struct {
+	Interactions []interaction `json:"interactions"`
+}
+
+type interaction struct {
+	Method   string            `json:"method"`
+	Endpoint string            `json:"endpoint"`
+	Params   string            `json:"params"`
+	Status   int               `json:"status"`
+	Header   map[string]string `json:"header,omitempty"`
+	Body     string            `json:"body"`
+}
+
+// key identifies the request an interaction answers.
+func (i interaction) key() string {
+	return i.Method + " " + i.Endpoint + "?" + i.Params
+}
+
+// recordedHeaders are the response headers kept in a cassette: the ones the
+// tool reads.
+var recordedHeaders = []string{"Content-Type", "Retry-After", "X-OAuth-Scopes"}
+
+// newInteraction describes req, whose parameters are params, and its
+// response. The response body is read and replaced.
+func newInteraction(req *http.Request, params string, resp *http.Response) (interaction, error) {
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return interaction{}, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	i := interaction{
+		Method:   req.Method,
+		Endpoint: strings.TrimPrefix(req.URL.Path, "/api/"),
+		Params:   params,
+		Status:   resp.StatusCode,
+		Body:     string(body),
+	}
+	for _, name := range recordedHeaders {
+		if v := resp.Header.Get(name); v != "" {
+			if i.Header == nil {
+				i.Header = make(map[string]string)
+			}
+			i.Header[name] = v
+		}
+	}
+	return i, nil
+}
+
+// recordTransport passes Slack API calls on to base and writes each one, with
+// its response, to a cassette file. The file is rewritten after every call so
+// that an interrupted run keeps what it recorded.
+type recordTransport struct {
+	base http.RoundTripper
+	path string
+
+	mu sync.Mutex
+	c  cassette
+}
+
+func (t *recordTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	params, err := requestParams(req)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	i, err := newInteraction(req, params, resp)
+	if err != nil {
+		return nil, err
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.c.Interactions = append(t.c.Interactions, i)
+	b, err := json.MarshalIndent(t.c, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(t.path, append(b, '\n'), 0o600); err != nil {
+		slog.Error("failed to write the recording", "file", t.path, "err", err)
+	}
+	return resp, nil
+}
+
+// replayTransport answers Slack API calls from a cassette instead of Slack.
+// Calls with the same key get the recorded responses in order, and the last
+// one again once they run out, so that a run that polls more often than the
+// recorded one still ends. A call that was never recorded fails.
+type replayTransport struct {
+	mu        sync.Mutex
+	responses map[string][]interaction
+	used      map[string]int
+}
+
+func loadReplay(path string) (*replayTransport, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read recording: %w", err)
+	}
+	var c cassette
+	if err := json.Unmarshal(b, &c); err != nil {
+		return nil, fmt.Errorf("parse recording %s: %w", path, err)
+	}
+	t := &replayTransport{responses: make(map[string][]interaction), used: make(map[string]int)}
+	for _, i := range c.Interactions {
+		t.responses[i.key()] = append(t.responses[i.key()], i)
+	}
+	slog.Info("replaying Slack API calls", "file", path, "calls", len(c.Interactions))
+	return t, nil
+}
+
+func (t *replayTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	params, err := requestParams(req)
+	if err != nil {
+		return nil, err
+	}
+	key := interaction{Method: req.Method, Endpoint: strings.TrimPrefix(req.URL.Path, "/api/"), Params: params}.key()
+	t.mu.Lock()
+	recorded := t.responses[key]
+	n := t.used[key]
+	t.used[key]++
+	t.mu.Unlock()
+	if len(recorded) == 0 {
+		return nil, fmt.Errorf("replay: no recorded response for %s", key)
+	}
+	i := recorded[min(n, len(recorded)-1)]
+	resp := &http.Response{
+		Status:     fmt.Sprintf("%d %s", i.Status, http.StatusText(i.Status)),
+		StatusCode: i.Status,
+		Proto:      "HTTP/1.1", ProtoMajor: 1, ProtoMinor: 1,
+		Header:  make(http.Header),
+		Body:    io.NopCloser(strings.NewReader(i.Body)),
+		Request: req,
+	}
+	for name, v := range i.Header {
+		resp.Header.Set(name, v)
+	}
+	return resp, nil
+}
+
+// setRecord and setReplay implement the -record and -replay flags.
+func setRecord(path string) error {
+	if replaying {
+		return fmt.Errorf("-record cannot be used with -replay")
+	}
+	slackTransport = &recordTransport{base: slackTransport, path: path}
+	return nil
+}
+
+func setReplay(path string) error {
+	if _, ok := slackTransport.(*recordTransport); ok {
+		return fmt.Errorf("-replay cannot be used with -record")
+	}
+	t, err := loadReplay(path)
+	if err != nil {
+		return err
+	}
+	slackTransport, replaying = t, true
+	return nil
+}
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"net/http"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestRecordReplay(t *testing.T) {
+	serveAuthTest(t, "channels:read,channels:write")
+	path := filepath.Join(t.TempDir(), "calls.json")
+	old := slackTransport
+	t.Cleanup(func() { slackTransport, replaying = old, false })
+
+	slackTransport = &recordTransport{base: http.DefaultTransport, path: path}
+	s := newSession("", "xoxp-test", channelOptions{})
+	want, err := s.authTest(cmdCtx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	slackTransport = old
+	if err := setReplay(path); err != nil {
+		t.Fatal(err)
+	}
+	apiURL = "http://replay.invalid/api/" // nothing answers here but the recording
+	for range 2 {
+		got, err := s.authTest(cmdCtx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got.TeamID != want.TeamID || !slices.Equal(got.scopes, want.scopes) {
+			t.Errorf("replayed %+v, recorded %+v", got, want)
+		}
+	}
+
+	if _, err := slackHTTPClient().Post(apiURL+"conversations.list", "", nil); err == nil {
+		t.Error("unrecorded call: want an error")
+	}
+}
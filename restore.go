@@ -0,0 +1,262 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/kiddikn/slack-channel-renamer/plan"
+	"github.com/kiddikn/slack-channel-renamer/slackops"
+)
+
+// maxRestorePasses bounds the passes of 'restore'. A channel needs a second
+// pass when one entry cannot put it all back: one renamed and then archived,
+// or unarchived and then given its topic.
+const maxRestorePasses = 2
+
+// writeChannelSnapshot writes the name, topic, purpose and archived state of
+// every channel the runs are about to change to dir, in the format of
+// 'export -format json', and returns the path written, or "" when no entry
+// touches an existing channel. With references, the channels whose topic or
+// purpose mentions a channel the runs rename are included, since
+// -references update rewrites them.
+func writeChannelSnapshot(dir string, runs []workspaceRun, references bool) (string, error) {
+	var rows []exportRow
+	for _, r := range runs {
+		touched := make(map[string]channelInfo)
+		renames := make(map[string]string)
+		for _, e := range r.plan {
+			if e.Action == actionRename {
+				renames[e.Asis] = e.Tobe
+			}
+			if ch := plan.EntryChannel(r.channels, e); ch.ID != "" {
+				touched[e.Asis] = ch
+			}
+		}
+		if references && len(renames) > 0 {
+			for name, ch := range r.channels {
+				if _, topic := replaceMentions(slackops.Unescape(ch.Topic), renames); topic != nil {
+					touched[name] = ch
+				} else if _, purpose := replaceMentions(slackops.Unescape(ch.Purpose), renames); purpose != nil {
+					touched[name] = ch
+				}
+			}
+		}
+		rows = append(rows, exportRows(r.workspace, touched)...)
+	}
+	if len(rows) == 0 {
+		return "", nil
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", fmt.Errorf("create %q: %w", dir, err)
+	}
+	path := filepath.Join(dir, "snapshot-"+time.Now().UTC().Format("20060102T150405Z")+".json")
+	b, err := json.MarshalIndent(rows, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("encode snapshot: %w", err)
+	}
+	if err := os.WriteFile(path, append(b, '\n'), 0o644); err != nil {
+		return "", fmt.Errorf("write %q: %w", path, err)
+	}
+	return path, nil
+}
+
+// readChannelSnapshot reads a snapshot written by writeChannelSnapshot or
+// 'export -format json'. Every channel must have an ID, by which 'restore'
+// finds it whatever it is called now.
+func readChannelSnapshot(path string) ([]exportRow, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read snapshot: %w", err)
+	}
+	var rows []exportRow
+	if err := json.Unmarshal(b, &rows); err != nil {
+		return nil, fmt.Errorf("parse snapshot %s (want one written by 'apply -snapshot-dir' or 'export -format json'): %w", path, err)
+	}
+	for i, r := range rows {
+		if r.ID == "" || r.Name == "" {
+			return nil, fmt.Errorf("snapshot %s: channel %d needs a name and an id", path, i+1)
+		}
+	}
+	return rows, nil
+}
+
+// restorePlan looks up each channel of the snapshot rows, read from path, by
+// ID and returns the entries that bring it back to its name, topic, purpose
+// and archived state, at most one per channel, along with the lookup errors.
+// later counts the channels that will still differ once the entries have run.
+func restorePlan(sessions []*session, ws workspaceOptions, rows []exportRow, path string) (entries []planEntry, later int, errs []string) {
+	var probes []planEntry
+	byID := make(map[string]exportRow)
+	for i, r := range rows {
+		probes = append(probes, planEntry{Action: actionRename, Asis: r.ID, ChannelID: r.ID, Workspace: r.Workspace, Source: fmt.Sprintf("%s channel %d", path, i+1), File: path})
+		byID[r.ID] = r
+	}
+	split, errs := splitByWorkspace(probes, sessions, ws.workspace)
+	for i, s := range sessions {
+		channels := make(map[string]channelInfo)
+		found, idErrs := plan.ResolveIDs(cmdCtx, s.client, s.stats, split[i], channels, s.channelOpts.list())
+		for _, e := range idErrs {
+			errs = append(errs, s.label()+e)
+		}
+		for _, e := range found {
+			entry, more := restoreEntry(byID[e.ChannelID], e, channels[e.Asis])
+			if entry.Action != "" {
+				entries = append(entries, entry)
+			}
+			if more {
+				later++
+			}
+		}
+	}
+	return entries, later, errs
+}
+
+// restoreEntry returns the entry that moves the channel of probe, found as ch
+// under the name probe.Asis, towards how the snapshot row r has it, or one
+// without an action when it already matches, and whether the channel will
+// still differ afterwards. Slack cannot set an empty topic or purpose, so
+// those are left alone.
+func restoreEntry(r exportRow, probe planEntry, ch channelInfo) (planEntry, bool) {
+	e := planEntry{Asis: probe.Asis, ChannelID: r.ID, Workspace: probe.Workspace, Source: probe.Source, File: probe.File}
+	renamed := probe.Asis != r.Name
+	if ch.ID != r.ID {
+		// An active channel holds the name; the snapshot's is its archived
+		// twin, which only an unarchive can tell apart from it.
+		if !r.Archived {
+			e.Action = actionUnarchive
+			if renamed {
+				e.Tobe = r.Name
+			}
+		} else if renamed {
+			slog.Warn(e.At()+"an archived channel that shares its name with an active one is not renamed back", "channel", probe.Asis, "channel_id", r.ID, "snapshot_name", r.Name)
+		}
+		return e, false
+	}
+	archived := ch.IsArchived
+	topic, purpose := slackops.Unescape(r.Topic), slackops.Unescape(r.Purpose)
+	if topic == slackops.Unescape(ch.Topic) {
+		topic = ""
+	}
+	if purpose == slackops.Unescape(ch.Purpose) {
+		purpose = ""
+	}
+	switch {
+	case archived && !r.Archived:
+		e.Action = actionUnarchive
+		if renamed {
+			e.Tobe = r.Name
+		}
+		return e, topic != "" || purpose != ""
+	case renamed:
+		e.Action, e.Tobe, e.Topic, e.Purpose, e.Rearchive = actionRename, r.Name, topic, purpose, archived
+		return e, !archived && r.Archived
+	case archived && (topic != "" || purpose != ""):
+		slog.Warn(e.At()+"the topic and purpose of an archived channel are not restored", "channel", r.Name, "channel_id", r.ID)
+	case topic != "" || purpose != "":
+		e.Action, e.Topic, e.Purpose = actionSetTopic, topic, purpose
+		return e, r.Archived
+	case !archived && r.Archived:
+		e.Action = actionArchive
+	}
+	return e, false
+}
+
+func cmdRestore(args []string) int {
+	fs := newFlagSet("restore")
+	var channelOpts channelOptions
+	channelOpts.register(fs)
+	var ws workspaceOptions
+	ws.register(fs)
+	snapshot := fs.String("snapshot", "", "the snapshot to restore, written by 'apply -snapshot-dir' or 'export -format json'")
+	dryRun := fs.Bool("dry-run", false, "print the restore plan without changing anything")
+	detailedExit := fs.Bool("detailed-exitcode", false, "with -dry-run, exit with 2 when there are changes to make, 0 when there are none and 1 on errors")
+	verify := fs.Bool("verify", false, "re-read each channel after renaming and fail if its name does not match")
+	verifyPass := fs.Bool("verify-pass", true, "re-fetch the channels once the run is done and fail renames whose channel does not carry the new name")
+	staleCheck := fs.Bool("stale-check", true, "re-read each channel just before renaming it and skip it if it no longer has its planned name")
+	history := fs.String("history-db", defaultHistoryDB, "record every change in this history database (empty to disable)")
+	metricsFile := fs.String("metrics-file", "", "write run metrics in Prometheus textfile format to this path")
+	var pool poolOptions
+	pool.register(fs)
+	var webhook webhookOptions
+	webhook.register(fs)
+	var lock lockOptions
+	lock.register(fs)
+	var protect protectOptions
+	protect.register(fs)
+	registerOutput(fs)
+	parseFlags(fs, args)
+	if *snapshot == "" {
+		slog.Error("-snapshot is required")
+		return 2
+	}
+	if channelOpts.admin {
+		slog.Error("restore cannot be used with -admin")
+		return 2
+	}
+	if err := pool.check(); err != nil {
+		slog.Error(err.Error())
+		return 2
+	}
+	if err := webhook.check(); err != nil {
+		slog.Error(err.Error())
+		return 2
+	}
+
+	rows, err := readChannelSnapshot(*snapshot)
+	if err != nil {
+		slog.Error(err.Error())
+		return 1
+	}
+	sessions, err := ws.newSessions(channelOpts)
+	if err != nil {
+		slog.Error(err.Error())
+		return 1
+	}
+	defer flushMetrics(*metricsFile, sessions)
+
+	for pass := 1; ; pass++ {
+		plan, later, errs := restorePlan(sessions, ws, rows, *snapshot)
+		if len(errs) > 0 {
+			reportValidation(errs, nil)
+			return 1
+		}
+		if len(plan) == 0 {
+			if pass == 1 {
+				slog.Info("every channel already matches the snapshot, nothing to restore", "channels", len(rows))
+			}
+			return 0
+		}
+		if pass > maxRestorePasses {
+			slog.Error(fmt.Sprintf("channels still differ from the snapshot after %d passes", maxRestorePasses), "entries", len(plan))
+			return 1
+		}
+		runs, err := preparePlan(sessions, ws, plan, prepareOptions{resolved: true, historyDB: *history, protect: protect})
+		if err != nil {
+			if !errors.Is(err, errValidation) {
+				slog.Error(err.Error())
+			}
+			return 1
+		}
+		printReversePlan(runs, "restore")
+		if *dryRun {
+			if later > 0 {
+				slog.Info("some channels need a second pass once this plan is applied", "channels", later)
+			}
+			return dryRunExitCode(runs, *detailedExit)
+		}
+		res, err := executeRuns(runs, runOptions{verb: "restore", verify: *verify, verifyPass: *verifyPass, staleCheck: *staleCheck, history: *history, source: *snapshot, webhook: webhook, pool: pool, lock: lock})
+		if err != nil {
+			slog.Error(err.Error())
+			return 1
+		}
+		if code := res.exitCode(); code != 0 || later == 0 {
+			return code
+		}
+		slog.Info("restoring what the first pass could not", "channels", later)
+	}
+}
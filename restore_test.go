@@ -0,0 +1,87 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRestore(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "workspace.json")
+	workspace := `[{"name":"alpha","id":"C1","topic":"Alpha talk"},{"name":"beta","id":"C2"},{"name":"gamma","id":"C3","archived":true},{"name":"delta","id":"C4","purpose":"Delta"},{"name":"other","id":"C5"}]`
+	if err := os.WriteFile(path, []byte(workspace), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	sessions, err := (workspaceOptions{simulate: path}).newSessions(channelOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	pool := poolOptions{concurrency: 1, perMinute: 60}
+	run := func(plan []planEntry) {
+		t.Helper()
+		runs, errs, _, err := validateRuns(sessions, workspaceOptions{}, plan, prepareOptions{resolved: true})
+		if err != nil || len(errs) > 0 {
+			t.Fatalf("validateRuns: %v %q", err, errs)
+		}
+		if _, err := executeRuns(runs, runOptions{verb: "restore", pool: pool}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	plan := []planEntry{
+		{Action: actionRename, Asis: "alpha", Tobe: "alpha-new", Topic: "New topic"},
+		{Action: actionArchive, Asis: "beta"},
+		{Action: actionUnarchive, Asis: "gamma", Tobe: "gamma-new"},
+		{Action: actionSetTopic, Asis: "delta", Purpose: "Changed"},
+	}
+	runs, errs, _, err := validateRuns(sessions, workspaceOptions{}, plan, prepareOptions{})
+	if err != nil || len(errs) > 0 {
+		t.Fatalf("validateRuns: %v %q", err, errs)
+	}
+	snapshot, err := writeChannelSnapshot(dir, runs, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := executeRuns(runs, runOptions{verb: "rename", pool: pool}); err != nil {
+		t.Fatal(err)
+	}
+	rows, err := readChannelSnapshot(snapshot)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 4 {
+		t.Fatalf("the snapshot holds %d channels, want the plan's 4: %+v", len(rows), rows)
+	}
+
+	// The first pass cannot archive gamma until it has its name back.
+	restore, later, errs := restorePlan(sessions, workspaceOptions{}, rows, snapshot)
+	if len(errs) > 0 || len(restore) != 4 || later != 1 {
+		t.Fatalf("first pass: %d entries, %d later, errors %q", len(restore), later, errs)
+	}
+	run(restore)
+	restore, later, errs = restorePlan(sessions, workspaceOptions{}, rows, snapshot)
+	if len(errs) > 0 || len(restore) != 1 || restore[0].Action != actionArchive || later != 0 {
+		t.Fatalf("second pass: %+v, %d later, errors %q", restore, later, errs)
+	}
+	run(restore)
+	if restore, _, _ = restorePlan(sessions, workspaceOptions{}, rows, snapshot); len(restore) != 0 {
+		t.Errorf("channels still differ from the snapshot: %+v", restore)
+	}
+
+	w := sessions[0].client.(*simWorkspace)
+	for _, want := range []struct {
+		id, name, topic, purpose string
+		archived                 bool
+	}{
+		{"C1", "alpha", "Alpha talk", "", false},
+		{"C2", "beta", "", "", false},
+		{"C3", "gamma", "", "", true},
+		{"C4", "delta", "", "Delta", false},
+	} {
+		ch, _ := w.channel(want.id)
+		if ch.Name != want.name || ch.Topic.Value != want.topic || ch.Purpose.Value != want.purpose || ch.IsArchived != want.archived {
+			t.Errorf("%s = %s %q %q archived %v, want %+v", want.id, ch.Name, ch.Topic.Value, ch.Purpose.Value, ch.IsArchived, want)
+		}
+	}
+}
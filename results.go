@@ -0,0 +1,78 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// resultsFile is the JSON form of -results-file.
+type resultsFile struct {
+	Results []entryResult `json:"results"`
+	API     apiStats      `json:"api"`
+}
+
+// writeResultsFile writes results and the run's API statistics as JSON when
+// path ends in .json. Otherwise it writes the results as CSV and the
+// statistics, which have no place in its rows, next to it as JSON (see
+// apiStatsPath).
+func writeResultsFile(path string, results []entryResult, api apiStats) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("create %q: %w", path, err)
+	}
+	defer f.Close()
+
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		enc := json.NewEncoder(f)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(resultsFile{Results: append([]entryResult{}, results...), API: api}); err != nil {
+			return fmt.Errorf("write %q: %w", path, err)
+		}
+		return f.Close()
+	}
+
+	if err := writeResultsCSV(f, results); err != nil {
+		return fmt.Errorf("write %q: %w", path, err)
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	b, err := json.MarshalIndent(api, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode API statistics: %w", err)
+	}
+	if err := os.WriteFile(apiStatsPath(path), append(b, '\n'), 0o644); err != nil {
+		return fmt.Errorf("write %q: %w", apiStatsPath(path), err)
+	}
+	return nil
+}
+
+// apiStatsPath returns where the API statistics of a CSV results file are
+// written: results.csv has them in results.api.json.
+func apiStatsPath(path string) string {
+	return strings.TrimSuffix(path, filepath.Ext(path)) + ".api.json"
+}
+
+// writeResultsCSV writes results in the CSV format of -results-file.
+func writeResultsCSV(w io.Writer, results []entryResult) error {
+	formatTime := func(t time.Time) string {
+		if t.IsZero() {
+			return ""
+		}
+		return t.Format(time.RFC3339Nano)
+	}
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"source", "workspace", "action", "asis", "tobe", "status", "error", "started", "finished", "channel_id", "channel_name", "edits"})
+	for _, r := range results {
+		cw.Write([]string{r.Source, r.Workspace, r.Action, r.Asis, r.Tobe, r.Status, r.Error,
+			formatTime(r.Started), formatTime(r.Finished), r.ChannelID, r.ChannelName, strings.Join(r.Edits, "; ")})
+	}
+	cw.Flush()
+	return cw.Error()
+}
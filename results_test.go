@@ -0,0 +1,46 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestWriteResultsFileAPIStats(t *testing.T) {
+	dir := t.TempDir()
+	results := []entryResult{{Action: actionRename, Asis: "a", Tobe: "b", Status: resultOK}}
+	api := apiStats{Seconds: 1.5, Calls: 2, RateLimitRetries: 1, P50: 120, P95: 480, Endpoints: []endpointStats{{Method: "conversations.rename", Calls: 2}}}
+
+	path := filepath.Join(dir, "results.json")
+	if err := writeResultsFile(path, results, api); err != nil {
+		t.Fatal(err)
+	}
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got resultsFile
+	if err := json.Unmarshal(b, &got); err != nil {
+		t.Fatal(err)
+	}
+	if len(got.Results) != 1 || got.Results[0].Tobe != "b" || got.API.Calls != 2 || got.API.P95 != 480 || len(got.API.Endpoints) != 1 {
+		t.Errorf("results file = %s", b)
+	}
+
+	path = filepath.Join(dir, "results.csv")
+	if err := writeResultsFile(path, results, api); err != nil {
+		t.Fatal(err)
+	}
+	if b, err = os.ReadFile(path); err != nil || strings.Count(string(b), "\n") != 2 {
+		t.Errorf("results CSV = %q, %v; want a header and one row", b, err)
+	}
+	if b, err = os.ReadFile(filepath.Join(dir, "results.api.json")); err != nil {
+		t.Fatal(err)
+	}
+	var sidecar apiStats
+	if err := json.Unmarshal(b, &sidecar); err != nil || sidecar.Calls != 2 || sidecar.RateLimitRetries != 1 {
+		t.Errorf("results.api.json = %s, %v", b, err)
+	}
+}
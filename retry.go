@@ -0,0 +1,54 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"time"
+
+	"github.com/kiddikn/slack-channel-renamer/slackops"
+)
+
+func registerRetry(fs *flag.FlagSet) {
+	fs.DurationVar(&slackops.Retry.Timeout, "api-timeout", slackops.Retry.Timeout, "time limit of each Slack API call attempt")
+	fs.IntVar(&slackops.Retry.Attempts, "max-attempts", slackops.Retry.Attempts, "attempts at each Slack API call before giving up on rate limits and transient errors")
+	fs.DurationVar(&slackops.Retry.RateLimitWait, "rate-limit-wait", slackops.Retry.RateLimitWait, "wait this long after a rate-limit error that has no Retry-After")
+	fs.DurationVar(&slackops.Retry.InitialWait, "retry-wait", slackops.Retry.InitialWait, "first wait after a transient error; it doubles on each retry")
+	fs.DurationVar(&slackops.Retry.MaxWait, "retry-max-wait", slackops.Retry.MaxWait, "longest wait between retries of a transient error")
+	fs.DurationVar(&slackops.Retry.MaxElapsed, "retry-max-elapsed", slackops.Retry.MaxElapsed, "start no retry of a call this long after its first attempt")
+	fs.DurationVar(&slackops.Retry.FetchTimeout, "fetch-timeout", 0, "time limit of each attempt at listing or looking up channels (default -api-timeout)")
+	fs.IntVar(&slackops.Retry.FetchAttempts, "fetch-max-attempts", 0, "attempts at listing or looking up channels (default -max-attempts)")
+}
+
+// checkRetry reports settings no call could succeed with.
+func checkRetry(p slackops.RetryPolicy) error {
+	switch {
+	case p.Timeout <= 0 || p.FetchTimeout < 0:
+		return errors.New("-api-timeout and -fetch-timeout must be positive")
+	case p.Attempts < 1 || p.FetchAttempts < 0:
+		return errors.New("-max-attempts and -fetch-max-attempts must be at least 1")
+	case p.RateLimitWait < 0 || p.InitialWait <= 0 || p.MaxWait < p.InitialWait || p.MaxElapsed < 0:
+		return errors.New("-retry-wait must be positive, -retry-max-wait at least -retry-wait, and -rate-limit-wait and -retry-max-elapsed not negative")
+	}
+	return nil
+}
+
+// callObserver feeds every Slack call to the audit log, the adaptive pacer of
+// the run and the API statistics.
+type callObserver struct{}
+
+func init() { slackops.Observe(callObserver{}) }
+
+func (callObserver) Attempted(ctx context.Context, desc string, attempt int, took time.Duration, err error, attrs []any) {
+	auditLog.call(desc, attempt, took, err, attrs)
+	if err == nil {
+		pacerOf(ctx).succeeded()
+	}
+}
+
+func (callObserver) Retrying(ctx context.Context, rateLimited bool, wait time.Duration) {
+	if rateLimited {
+		pacerOf(ctx).rateLimited(wait)
+	}
+	apiRecorders.retry(ctx, rateLimited)
+}
@@ -0,0 +1,19 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kiddikn/slack-channel-renamer/slackops"
+)
+
+func TestCheckRetry(t *testing.T) {
+	p := slackops.RetryPolicy{Timeout: 15 * time.Second, Attempts: 3, InitialWait: time.Second, MaxWait: 30 * time.Second, FetchTimeout: time.Minute}
+	if err := checkRetry(p); err != nil {
+		t.Fatal(err)
+	}
+	p.Attempts = 0
+	if err := checkRetry(p); err == nil {
+		t.Error("check accepted 0 attempts")
+	}
+}
@@ -0,0 +1,215 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// errReviewAborted is returned by reviewPlan when the operator quits without
+// approving the plan.
+var errReviewAborted = errors.New("review aborted, nothing was changed")
+
+// reviewRow is one validated entry in the review screen.
+type reviewRow struct {
+	label    string
+	entry    planEntry
+	channel  channelInfo
+	target   channelInfo
+	targetOK bool
+	on       bool
+}
+
+// reviewModel is the bubbletea model behind apply -review. Entries that
+// share a source row, such as the two halves of a rename through a temporary
+// name, are toggled together.
+type reviewModel struct {
+	rows     []reviewRow
+	skipped  int
+	cursor   int
+	offset   int
+	height   int
+	approved bool
+}
+
+// reviewPlan lets the operator go through the validated runs in a terminal
+// UI, turn rows off and approve the rest. It returns the sources of the
+// approved rows, which may be empty.
+func reviewPlan(runs []workspaceRun) (map[string]bool, error) {
+	if !isTerminal(os.Stdin) || !isTerminal(os.Stdout) {
+		return nil, errors.New("-review needs a terminal")
+	}
+	m := &reviewModel{height: 20}
+	for _, r := range runs {
+		m.skipped += r.stats.Skipped
+		for _, e := range r.plan {
+			target, ok := r.channels[e.Tobe]
+			m.rows = append(m.rows, reviewRow{
+				label:    r.label(),
+				entry:    e,
+				channel:  r.channels[e.Asis],
+				target:   target,
+				targetOK: ok,
+				on:       true,
+			})
+		}
+	}
+	final, err := tea.NewProgram(m, tea.WithAltScreen()).Run()
+	if err != nil {
+		return nil, fmt.Errorf("review: %w", err)
+	}
+	m = final.(*reviewModel)
+	if !m.approved {
+		return nil, errReviewAborted
+	}
+	approved := make(map[string]bool)
+	for _, row := range m.rows {
+		if row.on {
+			approved[row.entry.Source] = true
+		}
+	}
+	return approved, nil
+}
+
+func (m *reviewModel) Init() tea.Cmd {
+	return nil
+}
+
+func (m *reviewModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		// Leave room for the header, the details pane and the help line.
+		m.height = max(msg.Height-14, 3)
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "ctrl+c", "q", "esc":
+			return m, tea.Quit
+		case "up", "k":
+			m.cursor = max(m.cursor-1, 0)
+		case "down", "j":
+			m.cursor = min(m.cursor+1, len(m.rows)-1)
+		case "pgup":
+			m.cursor = max(m.cursor-m.height, 0)
+		case "pgdown":
+			m.cursor = min(m.cursor+m.height, len(m.rows)-1)
+		case " ", "x":
+			if len(m.rows) > 0 {
+				m.toggle(m.rows[m.cursor].entry.Source, !m.rows[m.cursor].on)
+			}
+		case "a":
+			all := m.count() < len(m.rows)
+			for i := range m.rows {
+				m.rows[i].on = all
+			}
+		case "enter", "y":
+			m.approved = true
+			return m, tea.Quit
+		}
+	}
+	if m.cursor < m.offset {
+		m.offset = m.cursor
+	}
+	if m.cursor >= m.offset+m.height {
+		m.offset = m.cursor - m.height + 1
+	}
+	return m, nil
+}
+
+// toggle turns every row read from source on or off.
+func (m *reviewModel) toggle(source string, on bool) {
+	for i := range m.rows {
+		if m.rows[i].entry.Source == source {
+			m.rows[i].on = on
+		}
+	}
+}
+
+func (m *reviewModel) count() int {
+	n := 0
+	for _, row := range m.rows {
+		if row.on {
+			n++
+		}
+	}
+	return n
+}
+
+func (m *reviewModel) View() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Review plan: %d of %d entries selected, all valid", m.count(), len(m.rows))
+	if m.skipped > 0 {
+		fmt.Fprintf(&b, " (%d skipped during validation)", m.skipped)
+	}
+	b.WriteString("\n\n")
+
+	end := min(m.offset+m.height, len(m.rows))
+	for i := m.offset; i < end; i++ {
+		row := m.rows[i]
+		cursor, box := " ", "[ ]"
+		if i == m.cursor {
+			cursor = ">"
+		}
+		if row.on {
+			box = "[x]"
+		}
+		fmt.Fprintf(&b, "%s %s %s%s  %s\n", cursor, box, row.label, row.entry, row.entry.Source)
+	}
+	for i := end - m.offset; i < m.height; i++ {
+		b.WriteString("\n")
+	}
+
+	b.WriteString("\n")
+	if len(m.rows) > 0 {
+		b.WriteString(m.rows[m.cursor].details())
+	}
+	b.WriteString("\nspace: toggle  a: toggle all  enter: apply selected  q: quit without changes\n")
+	return b.String()
+}
+
+// details describes the selected row's channel as listed at the start of the run.
+func (row reviewRow) details() string {
+	var b strings.Builder
+	ch := row.channel
+	fmt.Fprintf(&b, "channel    %s (%s)\n", row.entry.Asis, ch.ID)
+	var flags []string
+	if ch.IsPrivate {
+		flags = append(flags, "private")
+	}
+	if ch.IsArchived {
+		flags = append(flags, "archived")
+	}
+	if ch.IsGeneral {
+		flags = append(flags, "general")
+	}
+	if len(flags) == 0 {
+		flags = append(flags, "public")
+	}
+	fmt.Fprintf(&b, "status     %s, %d members\n", strings.Join(flags, ", "), ch.NumMembers)
+	created := "unknown"
+	if !ch.Created.IsZero() {
+		created = ch.Created.Format(time.DateOnly)
+	}
+	fmt.Fprintf(&b, "created    %s by %s\n", created, orDash(ch.Creator))
+	fmt.Fprintf(&b, "topic      %s\n", orDash(ch.Topic))
+	switch {
+	case row.entry.Tobe == "":
+	case row.entry.Action == actionMerge:
+		fmt.Fprintf(&b, "merges into %s (%s), %d members\n", row.entry.Tobe, row.target.ID, row.target.NumMembers)
+	case row.targetOK:
+		fmt.Fprintf(&b, "target     %s is currently held by %s, which the plan frees first\n", row.entry.Tobe, row.target.ID)
+	default:
+		fmt.Fprintf(&b, "target     %s is free\n", row.entry.Tobe)
+	}
+	return b.String()
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
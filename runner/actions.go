@@ -0,0 +1,178 @@
+package runner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+
+	"github.com/kiddikn/slack-channel-renamer/plan"
+	"github.com/kiddikn/slack-channel-renamer/slackops"
+)
+
+// errRenamed is wrapped into the error of a rename whose follow-up steps
+// failed, so callers know the channel name did change.
+var errRenamed = errors.New("renamed")
+
+// errStale is wrapped into the error of a rename skipped because its channel
+// no longer has the name the plan was validated against.
+var errStale = errors.New("plan stale")
+
+// ChangedChannel reports whether an entry that returned err from perform still
+// changed its channel.
+func ChangedChannel(err error) bool {
+	return err == nil || errors.Is(err, errRenamed) || errors.Is(err, errCreated)
+}
+
+// perform executes one validated plan entry against its channel in channels.
+func (x *Runner) perform(ctx context.Context, channels map[string]slackops.Channel, entry plan.Entry) error {
+	ch := plan.EntryChannel(channels, entry)
+	if entry.Action == plan.ActionCreate && !x.Admin {
+		return x.performCreate(ctx, entry)
+	}
+	if x.RenamedOutside != nil && entry.Action != plan.ActionCreate {
+		if name, ok := x.RenamedOutside(ch.ID); ok {
+			return fmt.Errorf("%w: channel %s was renamed to %q outside this run, expected %q", errStale, ch.ID, name, entry.Asis)
+		}
+	}
+	if x.Admin {
+		return x.performAdmin(ctx, ch, entry)
+	}
+	client, stats := x.Client, x.Stats
+	if entry.Rearchive {
+		return x.performRearchived(ctx, ch, entry)
+	}
+	if x.Bot {
+		if err := x.Join(ctx, ch, entry.Asis, entry); err != nil {
+			return err
+		}
+		if entry.Action == plan.ActionMerge {
+			if err := x.Join(ctx, channels[entry.Tobe], entry.Tobe, entry); err != nil {
+				return err
+			}
+		}
+	}
+	switch entry.Action {
+	case plan.ActionArchive:
+		return slackops.Call(ctx, stats, fmt.Sprintf("archiving %s", entry.Asis), func(ctx context.Context) error {
+			return client.ArchiveConversationContext(ctx, ch.ID)
+		}, plan.EntryAttrs(ch, entry)...)
+	case plan.ActionUnarchive:
+		if holder := channels[entry.Asis]; holder.ID != ch.ID {
+			return x.performUnarchiveTaken(ctx, ch, holder, entry)
+		}
+		return x.performUnarchive(ctx, ch, entry)
+	case plan.ActionSetTopic:
+		return applyTopicAndPurpose(ctx, client, stats, ch, entry)
+	case plan.ActionMerge:
+		return x.performMerge(ctx, ch, channels[entry.Tobe], entry)
+	case plan.ActionInvite:
+		return x.performInvite(ctx, ch, entry)
+	}
+
+	if x.StaleCheck {
+		name, err := slackops.ChannelName(ctx, client, stats, ch, fmt.Sprintf("checking %s", entry.Asis))
+		if err != nil {
+			return fmt.Errorf("staleness check: %w", err)
+		}
+		if name != entry.Asis {
+			return fmt.Errorf("%w: channel %s is now named %q, expected %q", errStale, ch.ID, name, entry.Asis)
+		}
+	}
+	if err := slackops.Rename(ctx, client, stats, ch, entry.Asis, entry.Tobe); err != nil {
+		return err
+	}
+	if x.Verify {
+		if err := slackops.VerifyRename(ctx, client, stats, ch, entry.Tobe); err != nil {
+			return err
+		}
+	}
+	if err := applyTopicAndPurpose(ctx, client, stats, ch, entry); err != nil {
+		return fmt.Errorf("%w, but %w", errRenamed, err)
+	}
+	return nil
+}
+
+// performRearchived renames the archived channel ch: Slack does not rename
+// archived channels, so it is unarchived first and archived again afterwards,
+// whether or not the rename worked.
+func (x *Runner) performRearchived(ctx context.Context, ch slackops.Channel, entry plan.Entry) (err error) {
+	if x.StaleCheck {
+		name, err := slackops.ChannelName(ctx, x.Client, x.Stats, ch, fmt.Sprintf("checking %s", entry.Asis))
+		if err != nil {
+			return fmt.Errorf("staleness check: %w", err)
+		}
+		if name != entry.Asis {
+			return fmt.Errorf("%w: channel %s is now named %q, expected %q", errStale, ch.ID, name, entry.Asis)
+		}
+	}
+	err = slackops.Call(ctx, x.Stats, fmt.Sprintf("unarchiving %s", entry.Asis), func(ctx context.Context) error {
+		return x.Client.UnArchiveConversationContext(ctx, ch.ID)
+	}, plan.EntryAttrs(ch, entry)...)
+	if err != nil {
+		return fmt.Errorf("unarchiving for the rename: %w", err)
+	}
+	defer func() {
+		// Archive it again even if the run was interrupted meanwhile.
+		aerr := slackops.Call(context.WithoutCancel(ctx), x.Stats, fmt.Sprintf("archiving %s again", entry.Asis), func(ctx context.Context) error {
+			return x.Client.ArchiveConversationContext(ctx, ch.ID)
+		}, plan.EntryAttrs(ch, entry)...)
+		if aerr != nil {
+			aerr = fmt.Errorf("channel %s is left unarchived: %w", ch.ID, aerr)
+			if err == nil {
+				err = fmt.Errorf("%w, but %w", errRenamed, aerr)
+			} else {
+				err = errors.Join(err, aerr)
+			}
+		}
+	}()
+	if x.Bot {
+		if err := x.Join(ctx, slackops.Channel{ID: ch.ID, IsPrivate: ch.IsPrivate}, entry.Asis, entry); err != nil {
+			return err
+		}
+	}
+	if err := slackops.Rename(ctx, x.Client, x.Stats, ch, entry.Asis, entry.Tobe); err != nil {
+		return err
+	}
+	if x.Verify {
+		if err := slackops.VerifyRename(ctx, x.Client, x.Stats, ch, entry.Tobe); err != nil {
+			return err
+		}
+	}
+	if err := applyTopicAndPurpose(ctx, x.Client, x.Stats, ch, entry); err != nil {
+		return fmt.Errorf("%w, but %w", errRenamed, err)
+	}
+	return nil
+}
+
+// performUnarchive unarchives ch and, if the entry has a tobe, renames it.
+func (x *Runner) performUnarchive(ctx context.Context, ch slackops.Channel, entry plan.Entry) error {
+	err := slackops.Call(ctx, x.Stats, fmt.Sprintf("unarchiving %s", entry.Asis), func(ctx context.Context) error {
+		return x.Client.UnArchiveConversationContext(ctx, ch.ID)
+	}, plan.EntryAttrs(ch, entry)...)
+	if err != nil || entry.Tobe == "" {
+		return err
+	}
+	if err := slackops.Rename(ctx, x.Client, x.Stats, ch, entry.Asis, entry.Tobe); err != nil {
+		return fmt.Errorf("unarchived, but %w", err)
+	}
+	return nil
+}
+
+// performUnarchiveTaken unarchives ch, whose name the active channel holder
+// has taken, and renames it to the entry's tobe. Slack refuses to unarchive a
+// channel into a taken name, so holder is moved to a temporary name until ch
+// has been renamed out of its way.
+func (x *Runner) performUnarchiveTaken(ctx context.Context, ch, holder slackops.Channel, entry plan.Entry) (err error) {
+	tmp := fmt.Sprintf("tmp-%08x", rand.Uint32())
+	if err := slackops.Rename(ctx, x.Client, x.Stats, holder, entry.Asis, tmp); err != nil {
+		return fmt.Errorf("moving %s out of the way: %w", holder.ID, err)
+	}
+	defer func() {
+		// Give the name back even if the run was interrupted meanwhile.
+		if rerr := slackops.Rename(context.WithoutCancel(ctx), x.Client, x.Stats, holder, tmp, entry.Asis); rerr != nil {
+			err = errors.Join(err, fmt.Errorf("channel %s is left named %q: %w", holder.ID, tmp, rerr))
+		}
+	}()
+	return x.performUnarchive(ctx, ch, entry)
+}
@@ -0,0 +1,64 @@
+package runner
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/kiddikn/slack-channel-renamer/plan"
+	"github.com/kiddikn/slack-channel-renamer/slackops"
+	"github.com/slack-go/slack"
+)
+
+// performAdmin executes one plan entry with the admin.conversations.* APIs,
+// which work on any channel in the org without the token's user joining it.
+func (x *Runner) performAdmin(ctx context.Context, ch slackops.Channel, entry plan.Entry) error {
+	client, stats := x.Client, x.Stats
+	switch entry.Action {
+	case plan.ActionArchive:
+		return slackops.Call(ctx, stats, fmt.Sprintf("archiving %s", entry.Asis), func(ctx context.Context) error {
+			return client.AdminConversationsArchive(ctx, ch.ID)
+		}, plan.EntryAttrs(ch, entry)...)
+	case plan.ActionUnarchive:
+		err := slackops.Call(ctx, stats, fmt.Sprintf("unarchiving %s", entry.Asis), func(ctx context.Context) error {
+			return client.AdminConversationsUnarchive(ctx, ch.ID)
+		}, plan.EntryAttrs(ch, entry)...)
+		if err != nil || entry.Tobe == "" {
+			return err
+		}
+		// Renamed on unarchive, like a rename from here on.
+		fallthrough
+	case plan.ActionRename:
+	default:
+		return fmt.Errorf("%s is not supported in -admin mode", entry.Action)
+	}
+
+	if x.StaleCheck {
+		// admin.conversations has no lookup by ID, so confirm that a search
+		// for the planned name still finds this channel.
+		matches, err := slackops.FindAdmin(ctx, client, stats, entry.Asis, "")
+		if err != nil {
+			return fmt.Errorf("staleness check: %w", err)
+		}
+		if !slices.ContainsFunc(matches, func(c slack.AdminConversation) bool { return c.ID == ch.ID }) {
+			return fmt.Errorf("%w: channel %s is no longer named %q", errStale, ch.ID, entry.Asis)
+		}
+	}
+	err := slackops.Call(ctx, stats, fmt.Sprintf("renaming %s -> %s", entry.Asis, entry.Tobe), func(ctx context.Context) error {
+		return client.AdminConversationsRename(ctx, ch.ID, entry.Tobe)
+	}, plan.EntryAttrs(ch, entry)...)
+	if err != nil || !x.Verify {
+		return err
+	}
+
+	matches, err := slackops.FindAdmin(ctx, client, stats, entry.Tobe, "")
+	if err != nil {
+		return fmt.Errorf("verify: %w", err)
+	}
+	for _, c := range matches {
+		if c.ID == ch.ID {
+			return nil
+		}
+	}
+	return fmt.Errorf("verify: no channel named %q with ID %s found", entry.Tobe, ch.ID)
+}
@@ -0,0 +1,39 @@
+package runner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/kiddikn/slack-channel-renamer/plan"
+	"github.com/kiddikn/slack-channel-renamer/slackops"
+)
+
+// errBotNotMember is the error of an entry for a private channel whose bot
+// token cannot act on it because the bot has not been invited in.
+var ErrBotNotMember = errors.New("the bot is not a member")
+
+// Join makes the bot behind x's token a member of ch, named name, if it is
+// not one yet. Bots can join public channels themselves; private channels
+// must invite them.
+func (x *Runner) Join(ctx context.Context, ch slackops.Channel, name string, entry plan.Entry) error {
+	if ch.IsMember {
+		return nil
+	}
+	switch {
+	case ch.IsPrivate:
+		return fmt.Errorf("%w of private channel %s; invite it there first", ErrBotNotMember, name)
+	case ch.IsArchived:
+		return fmt.Errorf("%w of %s, and archived channels cannot be joined; use a user token", ErrBotNotMember, name)
+	}
+	err := slackops.Call(ctx, x.Stats, fmt.Sprintf("joining %s", name), func(ctx context.Context) error {
+		_, _, _, err := x.Client.JoinConversationContext(ctx, ch.ID)
+		return err
+	}, plan.EntryAttrs(ch, entry)...)
+	if err != nil {
+		return fmt.Errorf("join %s: %w", name, err)
+	}
+	slog.Info("joined channel to act on it with the bot token", "channel", name, "channel_id", ch.ID)
+	return nil
+}
@@ -0,0 +1,103 @@
+package runner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+
+	"github.com/kiddikn/slack-channel-renamer/plan"
+	"github.com/kiddikn/slack-channel-renamer/slackops"
+	"github.com/slack-go/slack"
+)
+
+// errCreated is wrapped into the error of a create whose follow-up steps
+// failed, so callers know the channel was made.
+var errCreated = errors.New("created")
+
+// resolveUsers returns the user IDs of members, looking up email addresses
+// with users.lookupByEmail.
+func resolveUsers(ctx context.Context, client slackops.API, stats *slackops.Stats, members []string) ([]string, error) {
+	ids := make([]string, 0, len(members))
+	for _, m := range members {
+		if plan.UserIDRe.MatchString(m) {
+			ids = append(ids, m)
+			continue
+		}
+		var user *slack.User
+		err := slackops.Call(ctx, stats, "looking up "+m, func(ctx context.Context) error {
+			var err error
+			user, err = client.GetUserByEmailContext(ctx, m)
+			return err
+		}, "email", m)
+		if err != nil {
+			return nil, fmt.Errorf("look up %s: %w", m, err)
+		}
+		ids = append(ids, user.ID)
+	}
+	slices.Sort(ids)
+	return slices.Compact(ids), nil
+}
+
+// performCreate creates the channel of a create entry, then sets its topic
+// and purpose and invites its members.
+func (x *Runner) performCreate(ctx context.Context, entry plan.Entry) error {
+	var created *slack.Channel
+	err := slackops.Call(ctx, x.Stats, "creating "+entry.Asis, func(ctx context.Context) error {
+		var err error
+		created, err = x.Client.CreateConversationContext(ctx, slack.CreateConversationParams{ChannelName: entry.Asis, IsPrivate: entry.Private})
+		return err
+	}, plan.EntryAttrs(slackops.Channel{}, entry)...)
+	if err != nil {
+		return err
+	}
+	x.created.Store(entry.Asis, created.ID)
+	ch := slackops.Channel{ID: created.ID, IsPrivate: entry.Private, IsMember: true}
+	if err := applyTopicAndPurpose(ctx, x.Client, x.Stats, ch, entry); err != nil {
+		return fmt.Errorf("%w, but %w", errCreated, err)
+	}
+	if members := plan.MemberList(entry.Members); len(members) > 0 {
+		users, err := resolveUsers(ctx, x.Client, x.Stats, members)
+		if err == nil {
+			err = inviteUsers(ctx, x.Client, x.Stats, ch, entry.Asis, entry, users)
+		}
+		if err != nil {
+			return fmt.Errorf("%w, but invite members: %w", errCreated, err)
+		}
+	}
+	return nil
+}
+
+// channelOf returns the channel entry acts on, including the one a create
+// entry made.
+func (x *Runner) channelOf(channels map[string]slackops.Channel, entry plan.Entry) slackops.Channel {
+	if entry.Action == plan.ActionCreate {
+		if id, ok := x.created.Load(entry.Asis); ok {
+			return slackops.Channel{ID: id.(string), IsPrivate: entry.Private}
+		}
+	}
+	return plan.EntryChannel(channels, entry)
+}
+
+// performInvite invites the members of an invite entry to ch, leaving out
+// those already in it.
+func (x *Runner) performInvite(ctx context.Context, ch slackops.Channel, entry plan.Entry) error {
+	users, err := resolveUsers(ctx, x.Client, x.Stats, plan.MemberList(entry.Members))
+	if err != nil {
+		return err
+	}
+	existing, err := channelMembers(ctx, x.Client, x.Stats, ch, entry.Asis)
+	if err != nil {
+		return err
+	}
+	users = slices.DeleteFunc(users, func(u string) bool { return slices.Contains(existing, u) })
+	if len(users) == 0 {
+		slog.Info(entry.At()+"everyone listed is already a member", plan.EntryAttrs(ch, entry)...)
+		return nil
+	}
+	if err := inviteUsers(ctx, x.Client, x.Stats, ch, entry.Asis, entry, users); err != nil {
+		return fmt.Errorf("invite members: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,32 @@
+package runner
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/kiddikn/slack-channel-renamer/plan"
+	"github.com/kiddikn/slack-channel-renamer/slackops"
+)
+
+// logEntryResult logs the outcome of one entry: failures as errors, skips as
+// warnings and entries left pending by an interruption at debug level.
+func logEntryResult(ch slackops.Channel, entry plan.Entry, status string, err error, took time.Duration) {
+	level := slog.LevelInfo
+	switch status {
+	case StatusFailed:
+		level = slog.LevelError
+	case StatusSkipped:
+		level = slog.LevelWarn
+	case StatusPending:
+		level = slog.LevelDebug
+	}
+	attrs := append(plan.EntryAttrs(ch, entry), "status", status)
+	if status != StatusPending {
+		attrs = append(attrs, "duration", took.Round(time.Millisecond))
+	}
+	if err != nil {
+		attrs = append(attrs, "err", err)
+	}
+	slog.Log(context.Background(), level, "entry finished", attrs...)
+}
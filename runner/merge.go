@@ -0,0 +1,92 @@
+package runner
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/kiddikn/slack-channel-renamer/plan"
+	"github.com/kiddikn/slack-channel-renamer/slackops"
+	"github.com/slack-go/slack"
+)
+
+// inviteBatch is the most users conversations.invite accepts in one call.
+const inviteBatch = 1000
+
+// inviteUsers invites users to ch, named name, inviteBatch at a time.
+func inviteUsers(ctx context.Context, client slackops.API, stats *slackops.Stats, ch slackops.Channel, name string, entry plan.Entry, users []string) error {
+	for batch := range slices.Chunk(users, inviteBatch) {
+		err := slackops.Call(ctx, stats, fmt.Sprintf("inviting %d members to %s", len(batch), name), func(ctx context.Context) error {
+			_, err := client.InviteUsersToConversationContext(ctx, ch.ID, batch...)
+			return err
+		}, plan.EntryAttrs(ch, entry)...)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// mergeMessage is posted in a merged channel before it is archived.
+const mergeMessage = "This channel has been merged into <#%s>. Please continue the conversation there."
+
+// performMerge consolidates from into into: it posts a redirect message in
+// from, invites from's members who are not yet in into, then archives from.
+func (x *Runner) performMerge(ctx context.Context, from, into slackops.Channel, entry plan.Entry) error {
+	client, stats := x.Client, x.Stats
+	err := slackops.Call(ctx, stats, fmt.Sprintf("posting redirect in %s", entry.Asis), func(ctx context.Context) error {
+		_, _, err := client.PostMessageContext(ctx, from.ID, slack.MsgOptionText(fmt.Sprintf(mergeMessage, into.ID), false))
+		return err
+	}, plan.EntryAttrs(from, entry)...)
+	if err != nil {
+		return fmt.Errorf("post redirect: %w", err)
+	}
+
+	members, err := channelMembers(ctx, client, stats, from, entry.Asis)
+	if err != nil {
+		return err
+	}
+	existing, err := channelMembers(ctx, client, stats, into, entry.Tobe)
+	if err != nil {
+		return err
+	}
+	members = slices.DeleteFunc(members, func(u string) bool { return slices.Contains(existing, u) })
+	if err := inviteUsers(ctx, client, stats, into, entry.Tobe, entry, members); err != nil {
+		return fmt.Errorf("invite members: %w", err)
+	}
+
+	err = slackops.Call(ctx, stats, fmt.Sprintf("archiving %s", entry.Asis), func(ctx context.Context) error {
+		return client.ArchiveConversationContext(ctx, from.ID)
+	}, plan.EntryAttrs(from, entry)...)
+	if err != nil {
+		return fmt.Errorf("archive: %w", err)
+	}
+	return nil
+}
+
+// channelMembers returns the user IDs of every member of ch.
+func channelMembers(ctx context.Context, client slackops.API, stats *slackops.Stats, ch slackops.Channel, name string) ([]string, error) {
+	var members []string
+	cursor := ""
+	for {
+		var page []string
+		var nextCursor string
+		err := slackops.Call(ctx, stats, fmt.Sprintf("listing members of %s", name), func(ctx context.Context) error {
+			var err error
+			page, nextCursor, err = client.GetUsersInConversationContext(ctx, &slack.GetUsersInConversationParameters{
+				ChannelID: ch.ID,
+				Cursor:    cursor,
+				Limit:     1000,
+			})
+			return err
+		}, "channel_id", ch.ID)
+		if err != nil {
+			return nil, fmt.Errorf("list members of %s: %w", name, err)
+		}
+		members = append(members, page...)
+		if nextCursor == "" {
+			return members, nil
+		}
+		cursor = nextCursor
+	}
+}
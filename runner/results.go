@@ -0,0 +1,75 @@
+package runner
+
+import (
+	"time"
+
+	"github.com/kiddikn/slack-channel-renamer/plan"
+	"github.com/kiddikn/slack-channel-renamer/slackops"
+)
+
+// Statuses of a Result.
+const (
+	StatusOK      = "ok"
+	StatusFailed  = "failed"
+	StatusSkipped = "skipped"
+	StatusPending = "pending"
+)
+
+// Result is the outcome of one plan entry, as the CLI writes it to -results-file.
+type Result struct {
+	Source      string    `json:"source,omitempty"`
+	Workspace   string    `json:"workspace,omitempty"`
+	Action      string    `json:"action"`
+	Asis        string    `json:"asis"`
+	Tobe        string    `json:"tobe,omitempty"`
+	Status      string    `json:"status"`
+	Error       string    `json:"error,omitempty"`
+	Started     time.Time `json:"started,omitzero"`
+	Finished    time.Time `json:"finished,omitzero"`
+	ChannelID   string    `json:"channel_id,omitempty"`
+	ChannelName string    `json:"channel_name,omitempty"`
+	// Rearchived marks a rename of an archived channel, which was unarchived
+	// for it and archived again.
+	Rearchived bool `json:"rearchived,omitempty"`
+	// Edits lists what the CLI's -update-bookmarks and -references changed
+	// after the rename, found or could not change.
+	Edits []string `json:"edits,omitempty"`
+
+	// Entry is the plan entry the result is for.
+	Entry plan.Entry `json:"-"`
+}
+
+// NewResult describes the outcome err of entry, run on ch between
+// started and finished. The channel name is the one the entry left it with.
+func NewResult(entry plan.Entry, ch slackops.Channel, status string, err error, started, finished time.Time) Result {
+	r := Result{
+		Source:      entry.Source,
+		Workspace:   entry.Workspace,
+		Action:      entry.Action,
+		Asis:        entry.Asis,
+		Tobe:        entry.Tobe,
+		Status:      status,
+		Started:     started.UTC(),
+		Finished:    finished.UTC(),
+		ChannelID:   ch.ID,
+		ChannelName: entry.Asis,
+		Rearchived:  entry.Rearchive,
+		Entry:       entry,
+	}
+	if err != nil && status != StatusPending {
+		r.Error = err.Error()
+	}
+	if (entry.Action == plan.ActionRename || entry.Action == plan.ActionUnarchive && entry.Tobe != "") && ChangedChannel(err) && status != StatusPending {
+		r.ChannelName = entry.Tobe
+	}
+	return r
+}
+
+// PendingResults describes entries that were never started.
+func PendingResults(entries []plan.Entry, channels map[string]slackops.Channel) []Result {
+	results := make([]Result, 0, len(entries))
+	for _, e := range entries {
+		results = append(results, NewResult(e, channels[e.Asis], StatusPending, nil, time.Time{}, time.Time{}))
+	}
+	return results
+}
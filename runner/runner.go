@@ -0,0 +1,331 @@
+// Package runner applies validated rename plans to a Slack workspace: every
+// entry is executed through slackops, with entries for the same channel run
+// in plan order and the others spread over a number of workers.
+package runner
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/kiddikn/slack-channel-renamer/plan"
+	"github.com/kiddikn/slack-channel-renamer/slackops"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"golang.org/x/time/rate"
+)
+
+// Runner executes plan entries against a workspace.
+type Runner struct {
+	Options
+
+	// Client makes the Slack calls.
+	Client slackops.API
+
+	// Changed collects the entries that changed their channel, for a rollback
+	// plan; Pending collects those an interrupt kept from starting.
+	Changed []plan.Entry
+	Pending []plan.Entry
+
+	// Renamed collects the renames that fully succeeded, for a verification pass.
+	Renamed []plan.Entry
+
+	// Results holds the outcome of every entry, in plan order.
+	Results []Result
+
+	// created maps the name of every channel a create entry made to its ID.
+	created sync.Map
+}
+
+// Options configure a Runner.
+type Options struct {
+	// Stats counts the entries and API calls; Verify reads every renamed
+	// channel back to check its new name.
+	Stats  *slackops.Stats
+	Verify bool
+
+	// List selects the channels Apply validates the plan against.
+	List slackops.ListOptions
+
+	// History, when set, reports the renames already made, which Apply skips.
+	History plan.History
+
+	// Admin routes operations through the admin.conversations.* APIs.
+	Admin bool
+
+	// Bot is set for a bot token, which joins public channels it is not in
+	// before changing them.
+	Bot bool
+
+	// StaleCheck re-reads each channel before renaming it and skips the
+	// rename if the channel no longer has its planned name.
+	StaleCheck bool
+
+	// Concurrency is the number of workers; Limiter paces the start of
+	// every entry across all of them.
+	Concurrency int
+	Limiter     *rate.Limiter
+
+	// Wait, when set, is called before every entry starts, after Limiter;
+	// an error keeps the entry from starting, as an interrupt does.
+	Wait func(ctx context.Context) error
+
+	// RenamedOutside, when set, reports the name a channel was given
+	// outside the run, which skips the entries planned for its old name.
+	RenamedOutside func(channelID string) (string, bool)
+
+	// Finished, when set, is called as every started entry finishes, in the
+	// order they finish, with its channel and error.
+	Finished func(ch slackops.Channel, entry plan.Entry, err error)
+
+	// Observe, when set, is called with the outcome of every entry, in plan
+	// order, before it is logged.
+	Observe func(Result)
+}
+
+var tracer = otel.Tracer("github.com/kiddikn/slack-channel-renamer/runner")
+
+// New returns a Runner making its calls with client. Unset Stats and
+// Limiter mean fresh counters and no pacing.
+func New(client slackops.API, opts Options) *Runner {
+	if opts.Stats == nil {
+		opts.Stats = &slackops.Stats{Start: time.Now()}
+	}
+	if opts.Limiter == nil {
+		opts.Limiter = rate.NewLimiter(rate.Inf, 1)
+	}
+	return &Runner{Options: opts, Client: client}
+}
+
+// ValidationError is the error of Apply for a plan that failed validation,
+// of which no entry was applied.
+type ValidationError struct {
+	// Errors lists every problem found.
+	Errors []string
+}
+
+func (e ValidationError) Error() string {
+	return fmt.Sprintf("plan failed validation: %s", strings.Join(e.Errors, "; "))
+}
+
+// Apply applies entries to the workspace as the CLI's run does: it lists the
+// channels selected by x.List, or the org's with x.Admin, resolves the entries
+// that name a channel by ID, validates them and orders them for chained and
+// swapped renames, leaving out those that change a shared channel or that
+// x.History shows were made. A plan that fails validation is not applied and
+// returns a ValidationError. Otherwise Apply returns the outcome of every
+// entry, and an error when any failed or ctx stopped some from starting.
+func (x *Runner) Apply(ctx context.Context, entries []plan.Entry) ([]Result, error) {
+	var channels map[string]slackops.Channel
+	var err error
+	if x.Admin {
+		channels, err = slackops.ListAdminChannels(ctx, x.Client, x.Stats, x.List.TeamID)
+	} else {
+		channels, err = slackops.ListChannels(ctx, x.Client, x.Stats, x.List)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("fetch channels: %w", err)
+	}
+
+	var errs []string
+	if !x.Admin {
+		entries, errs = plan.ResolveIDs(ctx, x.Client, x.Stats, entries, channels, x.List)
+	}
+	entries = plan.MarkChannelTeams(entries, channels, x.List.TeamID != "" || x.Admin)
+	entries, skipped := plan.SkipShared(entries, channels, false)
+	active, valErrs, valSkipped := plan.Validate(entries, channels, "", x.History)
+	if errs = append(errs, valErrs...); len(errs) > 0 {
+		return nil, ValidationError{Errors: errs}
+	}
+	for _, s := range append(skipped, valSkipped...) {
+		slog.Info("entry skipped", "reason", s)
+		x.Stats.Skipped++
+	}
+
+	first, pending := len(x.Results), len(x.Pending)
+	failures := x.ApplyEntries(ctx, channels, active)
+	results := x.Results[first:]
+	if failures > 0 {
+		return results, fmt.Errorf("%d of %d entries failed", failures, len(active))
+	}
+	if n := len(x.Pending) - pending; n > 0 && ctx.Err() != nil {
+		return results, fmt.Errorf("%d entries not started: %w", n, ctx.Err())
+	} else if n > 0 {
+		return results, fmt.Errorf("%d entries not started", n)
+	}
+	return results, nil
+}
+
+// errNotStarted marks entries that were never started because the run was interrupted.
+var errNotStarted = errors.New("not started")
+
+// ApplyEntries executes entries, validated against channels, with up to
+// x.Concurrency workers, starting them no faster than x.Limiter allows. Entries for the same channel run one
+// after another in plan order. Results are passed to x.Observe and logged in
+// plan order as soon as every earlier entry has finished, so the report does not depend on
+// scheduling. Once ctx is cancelled no new entries start; they are collected
+// in x.Pending while in-flight calls finish. It returns the number of failures.
+func (x *Runner) ApplyEntries(ctx context.Context, channels map[string]slackops.Channel, entries []plan.Entry) int {
+	stats := x.Stats
+	var mu sync.Mutex
+	results := make([]error, len(entries))
+	started := make([]time.Time, len(entries))
+	finishedAt := make([]time.Time, len(entries))
+	finished := make([]bool, len(entries))
+	next, failures := 0, 0
+	finish := func(i int, err error) {
+		mu.Lock()
+		defer mu.Unlock()
+		entry := entries[i]
+		results[i], finished[i], finishedAt[i] = err, true, time.Now()
+		if err != errNotStarted && x.Finished != nil {
+			x.Finished(x.channelOf(channels, entry), entry, err)
+		}
+		for ; next < len(entries) && finished[next]; next++ {
+			entry, err := entries[next], results[next]
+			ch := x.channelOf(channels, entry)
+			result := func(status string) {
+				r := NewResult(entry, ch, status, err, started[next], finishedAt[next])
+				x.Results = append(x.Results, r)
+				if x.Observe != nil {
+					x.Observe(r)
+				}
+				logEntryResult(ch, entry, status, err, finishedAt[next].Sub(started[next]))
+			}
+			if err == errNotStarted {
+				x.Pending = append(x.Pending, entry)
+				result(StatusPending)
+				continue
+			}
+			if errors.Is(err, errStale) {
+				stats.Skipped++
+				result(StatusSkipped)
+				continue
+			}
+			stats.Attempted++
+			if ChangedChannel(err) {
+				if entry.Action == plan.ActionCreate {
+					// The rollback plan archives the channel by its ID.
+					entry.ChannelID = ch.ID
+				}
+				x.Changed = append(x.Changed, entry)
+			}
+			if err != nil {
+				stats.Failed++
+				failures++
+				result(StatusFailed)
+			} else {
+				result(StatusOK)
+				stats.Succeeded++
+				if entry.Action == plan.ActionRename {
+					x.Renamed = append(x.Renamed, entry)
+				}
+			}
+		}
+	}
+
+	// Queue the entries of each channel together, and each rename with the
+	// entries of the channel holding its target name, so that no entry
+	// overtakes one it depends on.
+	root := make([]int, len(entries))
+	find := func(i int) int {
+		for root[i] != i {
+			root[i] = root[root[i]]
+			i = root[i]
+		}
+		return i
+	}
+	firstOf := make(map[string]int)
+	for i, e := range entries {
+		root[i] = i
+		if j, ok := firstOf[channels[e.Asis].ID]; ok {
+			root[find(i)] = find(j)
+		} else {
+			firstOf[channels[e.Asis].ID] = i
+		}
+	}
+	for i, e := range entries {
+		target, exists := channels[e.Tobe]
+		if j, ok := firstOf[target.ID]; ok && exists && e.Action == plan.ActionRename {
+			root[find(i)] = find(j)
+		}
+	}
+	var queues [][]int
+	queueOf := make(map[int]int)
+	for i := range entries {
+		r := find(i)
+		q, ok := queueOf[r]
+		if !ok {
+			q = len(queues)
+			queueOf[r] = q
+			queues = append(queues, nil)
+		}
+		queues[q] = append(queues[q], i)
+	}
+
+	work := make(chan []int)
+	var wg sync.WaitGroup
+	for range min(max(x.Concurrency, 1), len(queues)) {
+		wg.Go(func() {
+			for q := range work {
+				for _, i := range q {
+					if ctx.Err() != nil || x.Limiter.Wait(ctx) != nil || x.Wait != nil && x.Wait(ctx) != nil {
+						finish(i, errNotStarted)
+						continue
+					}
+					started[i] = time.Now()
+					finish(i, x.traced(ctx, channels, entries[i]))
+				}
+			}
+		})
+	}
+	for _, q := range queues {
+		work <- q
+	}
+	close(work)
+	wg.Wait()
+	return failures
+}
+
+// traced performs entry in a span of its own, with its plan file in ctx for
+// API statistics kept per plan file.
+func (x *Runner) traced(ctx context.Context, channels map[string]slackops.Channel, entry plan.Entry) error {
+	ctx, span := tracer.Start(plan.WithFile(ctx, entry.File), entry.Action, trace.WithAttributes(slackops.SpanAttrs(plan.EntryAttrs(channels[entry.Asis], entry))...))
+	err := x.perform(ctx, channels, entry)
+	spanErr := err
+	if errors.Is(err, errStale) {
+		span.SetAttributes(attribute.String("skipped", err.Error()))
+		spanErr = nil
+	}
+	slackops.EndSpan(span, spanErr)
+	return err
+}
+
+// applyTopicAndPurpose sets the entry's topic and purpose on the channel, if given.
+func applyTopicAndPurpose(ctx context.Context, client slackops.API, stats *slackops.Stats, ch slackops.Channel, entry plan.Entry) error {
+	if entry.Topic != "" {
+		err := slackops.Call(ctx, stats, fmt.Sprintf("setting topic of %s", cmp.Or(entry.Tobe, entry.Asis)), func(ctx context.Context) error {
+			_, err := client.SetTopicOfConversationContext(ctx, ch.ID, entry.Topic)
+			return err
+		}, plan.EntryAttrs(ch, entry)...)
+		if err != nil {
+			return fmt.Errorf("set topic: %w", err)
+		}
+	}
+	if entry.Purpose != "" {
+		err := slackops.Call(ctx, stats, fmt.Sprintf("setting purpose of %s", cmp.Or(entry.Tobe, entry.Asis)), func(ctx context.Context) error {
+			_, err := client.SetPurposeOfConversationContext(ctx, ch.ID, entry.Purpose)
+			return err
+		}, plan.EntryAttrs(ch, entry)...)
+		if err != nil {
+			return fmt.Errorf("set purpose: %w", err)
+		}
+	}
+	return nil
+}
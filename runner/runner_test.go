@@ -0,0 +1,78 @@
+package runner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/kiddikn/slack-channel-renamer/plan"
+	"github.com/kiddikn/slack-channel-renamer/slackops"
+	"github.com/slack-go/slack"
+)
+
+// fakeAPI is a workspace of public channels that can be listed and renamed.
+// Calling a method it does not implement panics through the nil embedded
+// interface.
+type fakeAPI struct {
+	slackops.API
+	channels []slack.Channel
+}
+
+func (f *fakeAPI) GetConversationsContext(ctx context.Context, params *slack.GetConversationsParameters) ([]slack.Channel, string, error) {
+	return f.channels, "", nil
+}
+
+func (f *fakeAPI) RenameConversationContext(ctx context.Context, channelID, channelName string) (*slack.Channel, error) {
+	for i := range f.channels {
+		if f.channels[i].ID == channelID {
+			f.channels[i].Name = channelName
+			return &f.channels[i], nil
+		}
+	}
+	return nil, slack.SlackErrorResponse{Err: "channel_not_found"}
+}
+
+func newFakeAPI(names ...string) *fakeAPI {
+	f := &fakeAPI{}
+	for i, name := range names {
+		var ch slack.Channel
+		ch.ID, ch.Name = fmt.Sprintf("C%d", i+1), name
+		f.channels = append(f.channels, ch)
+	}
+	return f
+}
+
+func TestApply(t *testing.T) {
+	f := newFakeAPI("a", "b")
+	results, err := New(f, Options{}).Apply(context.Background(), []plan.Entry{
+		{Action: plan.ActionRename, Asis: "a", Tobe: "b"},
+		{Action: plan.ActionRename, Asis: "b", Tobe: "a"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f.channels[0].Name != "b" || f.channels[1].Name != "a" {
+		t.Errorf("channels = %q, %q, want the names swapped", f.channels[0].Name, f.channels[1].Name)
+	}
+	if len(results) == 0 {
+		t.Error("no results")
+	}
+	for _, r := range results {
+		if r.Status != StatusOK {
+			t.Errorf("%s -> %s: status %q (%s), want ok", r.Asis, r.Tobe, r.Status, r.Error)
+		}
+	}
+}
+
+func TestApplyInvalidPlan(t *testing.T) {
+	f := newFakeAPI("a")
+	_, err := New(f, Options{}).Apply(context.Background(), []plan.Entry{{Action: plan.ActionRename, Asis: "missing", Tobe: "x"}})
+	var verr ValidationError
+	if !errors.As(err, &verr) || len(verr.Errors) != 1 {
+		t.Fatalf("Apply = %v, want a ValidationError with one error", err)
+	}
+	if f.channels[0].Name != "a" {
+		t.Errorf("channel renamed to %q by an invalid plan", f.channels[0].Name)
+	}
+}
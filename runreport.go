@@ -0,0 +1,235 @@
+package main
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// runReport is the shareable summary of an apply written by -report: the
+// plan's entries with their names before and after, their outcomes and
+// timing.
+type runReport struct {
+	Source      string
+	Started     time.Time
+	Finished    time.Time
+	Interrupted bool
+	Aborted     bool
+	Results     []entryResult
+	// API sums up the run's Slack API calls; in the reports of -per-plan,
+	// those made by the plan's entries.
+	API apiStats
+}
+
+func newRunReport(source string, started time.Time, res runResult) runReport {
+	return runReport{Source: source, Started: started.UTC(), Finished: time.Now().UTC(), Interrupted: res.interrupted, Aborted: res.aborted, Results: res.results, API: res.api}
+}
+
+// Outcome describes how the run ended, in a few words.
+func (r runReport) Outcome() string {
+	switch {
+	case r.Interrupted:
+		return "interrupted"
+	case r.Aborted:
+		return "stopped after too many failures"
+	case r.Count(resultFailed) > 0:
+		return "finished with failures"
+	}
+	return "finished"
+}
+
+// Count returns the number of entries with the given status.
+func (r runReport) Count(status string) int {
+	n := 0
+	for _, e := range r.Results {
+		if e.Status == status {
+			n++
+		}
+	}
+	return n
+}
+
+// Failures returns the entries that failed.
+func (r runReport) Failures() []entryResult {
+	var failed []entryResult
+	for _, e := range r.Results {
+		if e.Status == resultFailed {
+			failed = append(failed, e)
+		}
+	}
+	return failed
+}
+
+// Duration is how long the run took, to the second.
+func (r runReport) Duration() time.Duration {
+	return r.Finished.Sub(r.Started).Round(time.Second)
+}
+
+// reportAfter is the name the entry left its channel with, or nothing when
+// it did not change it.
+func reportAfter(e entryResult) string {
+	if e.ChannelName == e.Asis {
+		return ""
+	}
+	return e.ChannelName
+}
+
+// reportAction is the entry's action, marking a rename of an archived
+// channel.
+func reportAction(e entryResult) string {
+	if e.Rearchived {
+		return e.Action + " (archived)"
+	}
+	return e.Action
+}
+
+// reportTook is how long an entry ran, or nothing when it did not start.
+func reportTook(e entryResult) string {
+	if e.Started.IsZero() {
+		return ""
+	}
+	return e.Finished.Sub(e.Started).Round(time.Millisecond).String()
+}
+
+// reportFormat returns the format -report writes path in, from its extension.
+func reportFormat(path string) (string, error) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".html", ".htm":
+		return "html", nil
+	case ".md", ".markdown":
+		return "markdown", nil
+	}
+	return "", fmt.Errorf("report %q must end in .html or .md", path)
+}
+
+// writeRunReport writes r to path, as HTML or Markdown by its extension.
+func writeRunReport(path string, r runReport) error {
+	format, err := reportFormat(path)
+	if err != nil {
+		return err
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("create %q: %w", path, err)
+	}
+	defer f.Close()
+	if format == "html" {
+		err = r.writeHTML(f)
+	} else {
+		err = r.writeMarkdown(f)
+	}
+	if err != nil {
+		return fmt.Errorf("write %q: %w", path, err)
+	}
+	return f.Close()
+}
+
+var reportHTML = template.Must(template.New("report").Funcs(template.FuncMap{
+	"action":     reportAction,
+	"after":      reportAfter,
+	"capitalize": capitalize,
+	"millis":     formatMillis,
+	"took":       reportTook,
+	"time":       func(t time.Time) string { return t.Format(time.RFC3339) },
+}).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>Channel rename run: {{.Source}}</title>
+<style>
+body { font-family: sans-serif; margin: 2em; }
+table { border-collapse: collapse; }
+th, td { border: 1px solid #ccc; padding: 0.3em 0.6em; text-align: left; }
+th { background: #f4f4f4; }
+.failed { background: #fde2e2; }
+.skipped, .pending { background: #fff6d6; }
+</style>
+</head>
+<body>
+<h1>Channel rename run: {{.Source}}</h1>
+<p>{{capitalize .Outcome}}: {{.Count "ok"}} succeeded, {{.Count "failed"}} failed, {{.Count "skipped"}} skipped, {{.Count "pending"}} not started.</p>
+<p>Started {{time .Started}}, finished {{time .Finished}} ({{.Duration}}).</p>
+{{- with .Failures}}
+<h2>Failures</h2>
+<ul>
+{{- range .}}
+<li><code>{{.Source}}</code> {{action .}} {{.Asis}}{{with .Tobe}} &rarr; {{.}}{{end}}: {{.Error}}</li>
+{{- end}}
+</ul>
+{{- end}}
+{{- with .API}}{{if .Calls}}
+<h2>Slack API</h2>
+<p>{{.Calls}} calls, {{.RateLimited}} rate limited, {{.RateLimitRetries}} rate-limit retries, {{.TransientRetries}} transient retries; p50 {{millis .P50}}, p95 {{millis .P95}}.</p>
+<table>
+<tr><th>Method</th><th>Calls</th><th>Rate limited</th><th>p50</th><th>p95</th></tr>
+{{- range .Endpoints}}
+<tr><td>{{.Method}}</td><td>{{.Calls}}</td><td>{{.RateLimited}}</td><td>{{millis .P50}}</td><td>{{millis .P95}}</td></tr>
+{{- end}}
+</table>
+{{- end}}{{end}}
+<h2>Plan</h2>
+<table>
+<tr><th>Source</th><th>Workspace</th><th>Action</th><th>Before</th><th>After</th><th>Channel ID</th><th>Status</th><th>Duration</th><th>Reason</th></tr>
+{{- range .Results}}
+<tr class="{{.Status}}"><td>{{.Source}}</td><td>{{.Workspace}}</td><td>{{action .}}</td><td>{{.Asis}}</td><td>{{after .}}</td><td>{{.ChannelID}}</td><td>{{.Status}}</td><td>{{took .}}</td><td>{{.Error}}</td></tr>
+{{- end}}
+</table>
+</body>
+</html>
+`))
+
+func (r runReport) writeHTML(w io.Writer) error {
+	return reportHTML.Execute(w, r)
+}
+
+// markdownCell escapes s for a Markdown table cell.
+func markdownCell(s string) string {
+	return strings.NewReplacer("|", `\|`, "\n", " ", "\r", "").Replace(s)
+}
+
+func (r runReport) writeMarkdown(w io.Writer) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# Channel rename run: %s\n\n", markdownCell(r.Source))
+	fmt.Fprintf(&b, "%s: %d succeeded, %d failed, %d skipped, %d not started.\n\n",
+		capitalize(r.Outcome()), r.Count(resultOK), r.Count(resultFailed), r.Count(resultSkipped), r.Count(resultPending))
+	fmt.Fprintf(&b, "Started %s, finished %s (%s).\n", r.Started.Format(time.RFC3339), r.Finished.Format(time.RFC3339), r.Duration())
+	if failed := r.Failures(); len(failed) > 0 {
+		b.WriteString("\n## Failures\n\n")
+		for _, e := range failed {
+			fmt.Fprintf(&b, "- `%s%s`: %s\n", e.Entry.At(), e.Entry, markdownCell(e.Error))
+		}
+	}
+	if a := r.API; a.Calls > 0 {
+		b.WriteString("\n## Slack API\n\n")
+		fmt.Fprintf(&b, "%d calls, %d rate limited, %d rate-limit retries, %d transient retries; p50 %s, p95 %s.\n\n",
+			a.Calls, a.RateLimited, a.RateLimitRetries, a.TransientRetries, formatMillis(a.P50), formatMillis(a.P95))
+		b.WriteString("| Method | Calls | Rate limited | p50 | p95 |\n|---|---|---|---|---|\n")
+		for _, e := range a.Endpoints {
+			fmt.Fprintf(&b, "| %s | %d | %d | %s | %s |\n", markdownCell(e.Method), e.Calls, e.RateLimited, formatMillis(e.P50), formatMillis(e.P95))
+		}
+	}
+	b.WriteString("\n## Plan\n\n")
+	b.WriteString("| Source | Workspace | Action | Before | After | Channel ID | Status | Duration | Reason |\n")
+	b.WriteString("|---|---|---|---|---|---|---|---|---|\n")
+	for _, e := range r.Results {
+		cells := []string{e.Source, e.Workspace, reportAction(e), e.Asis, reportAfter(e), e.ChannelID, e.Status, reportTook(e), e.Error}
+		for i, c := range cells {
+			cells[i] = markdownCell(c)
+		}
+		fmt.Fprintf(&b, "| %s |\n", strings.Join(cells, " | "))
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// capitalize returns s with its first letter in upper case.
+func capitalize(s string) string {
+	if s == "" {
+		return s
+	}
+	return strings.ToUpper(s[:1]) + s[1:]
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kiddikn/slack-channel-renamer/runner"
+)
+
+func TestRunReport(t *testing.T) {
+	start := time.Date(2026, 10, 14, 12, 30, 0, 0, time.UTC)
+	ok := planEntry{Action: actionRename, Asis: "old", Tobe: "new", Source: "plan.csv:2"}
+	bad := planEntry{Action: actionRename, Asis: "a|b", Tobe: "<b>", Source: "plan.csv:3"}
+	r := runReport{
+		Source:   "plan.csv",
+		Started:  start,
+		Finished: start.Add(90 * time.Second),
+		Results: []entryResult{
+			runner.NewResult(ok, channelInfo{ID: "C1"}, resultOK, nil, start, start.Add(time.Second)),
+			runner.NewResult(bad, channelInfo{ID: "C2"}, resultFailed, errors.New("name_taken"), start, start.Add(time.Second)),
+		},
+	}
+
+	var md strings.Builder
+	if err := r.writeMarkdown(&md); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"Finished with failures: 1 succeeded, 1 failed, 0 skipped, 0 not started.",
+		"(1m30s)",
+		"- `plan.csv:3: a|b -> <b>`: name_taken",
+		`| plan.csv:3 |  | rename | a\|b |  | C2 | failed | 1s | name_taken |`,
+		"| plan.csv:2 |  | rename | old | new | C1 | ok | 1s |  |",
+	} {
+		if !strings.Contains(md.String(), want) {
+			t.Errorf("Markdown report lacks %q:\n%s", want, md.String())
+		}
+	}
+
+	var html strings.Builder
+	if err := r.writeHTML(&html); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(html.String(), "&lt;b&gt;") || strings.Contains(html.String(), "<b>") {
+		t.Errorf("HTML report does not escape the names:\n%s", html.String())
+	}
+	if strings.Contains(md.String(), "Slack API") || strings.Contains(html.String(), "Slack API") {
+		t.Error("a report without API calls has a Slack API section")
+	}
+
+	r.API = apiStats{Calls: 2, P50: 120, P95: 480, Endpoints: []endpointStats{{Method: "conversations.rename", Calls: 2, P50: 120, P95: 480}}}
+	md.Reset()
+	html.Reset()
+	if err := r.writeMarkdown(&md); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.writeHTML(&html); err != nil {
+		t.Fatal(err)
+	}
+	if want := "| conversations.rename | 2 | 0 | 120ms | 480ms |"; !strings.Contains(md.String(), want) {
+		t.Errorf("Markdown report lacks %q:\n%s", want, md.String())
+	}
+	if want := "<td>conversations.rename</td><td>2</td>"; !strings.Contains(html.String(), want) {
+		t.Errorf("HTML report lacks %q:\n%s", want, html.String())
+	}
+	if _, err := reportFormat("report.txt"); err == nil {
+		t.Error("reportFormat accepted a .txt file")
+	}
+}
@@ -0,0 +1,307 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kiddikn/slack-channel-renamer/plan"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// cronSchedule is a parsed five-field cron expression: minute, hour, day of
+// month, month and day of week. Each field is a bit set of the values it
+// matches.
+type cronSchedule struct {
+	minute, hour, dom, month, dow uint64
+	// domAny and dowAny record a "*" day field: as in cron, when both day
+	// fields are restricted a day matching either one matches.
+	domAny, dowAny bool
+	loc            *time.Location
+}
+
+var (
+	cronMonths = []string{"jan", "feb", "mar", "apr", "may", "jun", "jul", "aug", "sep", "oct", "nov", "dec"}
+	cronDays   = []string{"sun", "mon", "tue", "wed", "thu", "fri", "sat"}
+)
+
+// parseCron parses a cron expression such as "0 2 * * 6" (02:00 every
+// Saturday). Fields take *, numbers, ranges (1-5), steps (*/15, 0-30/10),
+// comma-separated lists and, for months and days of the week, three-letter
+// names. Sunday is 0 or 7. A leading CRON_TZ=Zone evaluates the expression in
+// that time zone instead of the local one.
+func parseCron(spec string) (cronSchedule, error) {
+	c := cronSchedule{loc: time.Local}
+	fields := strings.Fields(spec)
+	if len(fields) > 0 && strings.HasPrefix(fields[0], "CRON_TZ=") {
+		loc, err := time.LoadLocation(strings.TrimPrefix(fields[0], "CRON_TZ="))
+		if err != nil {
+			return c, fmt.Errorf("schedule %q: %w", spec, err)
+		}
+		c.loc, fields = loc, fields[1:]
+	}
+	if len(fields) != 5 {
+		return c, fmt.Errorf("schedule %q: want 5 fields (minute hour day-of-month month day-of-week), got %d", spec, len(fields))
+	}
+	var err error
+	parse := func(field string, lo, hi int, names []string, nameBase int) uint64 {
+		if err != nil {
+			return 0
+		}
+		var bits uint64
+		bits, err = parseCronField(field, lo, hi, names, nameBase)
+		if err != nil {
+			err = fmt.Errorf("schedule %q: %w", spec, err)
+		}
+		return bits
+	}
+	c.minute = parse(fields[0], 0, 59, nil, 0)
+	c.hour = parse(fields[1], 0, 23, nil, 0)
+	c.dom = parse(fields[2], 1, 31, nil, 0)
+	c.month = parse(fields[3], 1, 12, cronMonths, 1)
+	c.dow = parse(fields[4], 0, 7, cronDays, 0)
+	if err != nil {
+		return c, err
+	}
+	if c.dow&(1<<7) != 0 {
+		c.dow |= 1
+	}
+	c.domAny, c.dowAny = fields[2] == "*", fields[4] == "*"
+	return c, nil
+}
+
+// parseCronField returns the values between lo and hi that field matches.
+// names, when set, spell the values from nameBase up.
+func parseCronField(field string, lo, hi int, names []string, nameBase int) (uint64, error) {
+	value := func(s string) (int, error) {
+		for i, name := range names {
+			if strings.EqualFold(s, name) {
+				return nameBase + i, nil
+			}
+		}
+		n, err := strconv.Atoi(s)
+		if err != nil || n < lo || n > hi {
+			return 0, fmt.Errorf("%q is not a value from %d to %d", s, lo, hi)
+		}
+		return n, nil
+	}
+	var bits uint64
+	for part := range strings.SplitSeq(field, ",") {
+		rng, stepStr, hasStep := strings.Cut(part, "/")
+		step := 1
+		if hasStep {
+			var err error
+			if step, err = strconv.Atoi(stepStr); err != nil || step < 1 {
+				return 0, fmt.Errorf("%q: bad step", part)
+			}
+		}
+		first, last := lo, hi
+		if rng != "*" {
+			a, b, isRange := strings.Cut(rng, "-")
+			var err error
+			if first, err = value(a); err != nil {
+				return 0, err
+			}
+			last = first
+			if isRange {
+				if last, err = value(b); err != nil {
+					return 0, err
+				}
+			} else if hasStep {
+				last = hi
+			}
+			if last < first {
+				return 0, fmt.Errorf("%q: range ends before it starts", part)
+			}
+		}
+		for v := first; v <= last; v += step {
+			bits |= 1 << v
+		}
+	}
+	return bits, nil
+}
+
+// next returns the first time after t that c matches, to the minute, or the
+// zero time if there is none within five years (for a date such as 30 Feb).
+func (c cronSchedule) next(t time.Time) time.Time {
+	t = t.In(c.loc).Truncate(time.Minute).Add(time.Minute)
+	limit := t.AddDate(5, 0, 0)
+	for t.Before(limit) {
+		y, m, d := t.Date()
+		switch {
+		case c.month&(1<<m) == 0:
+			t = time.Date(y, m+1, 1, 0, 0, 0, 0, c.loc)
+		case !c.dayMatches(t):
+			t = time.Date(y, m, d+1, 0, 0, 0, 0, c.loc)
+		case c.hour&(1<<t.Hour()) == 0:
+			t = time.Date(y, m, d, t.Hour()+1, 0, 0, 0, c.loc)
+		case c.minute&(1<<t.Minute()) == 0:
+			t = t.Add(time.Minute)
+		default:
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+func (c cronSchedule) dayMatches(t time.Time) bool {
+	dom := c.dom&(1<<t.Day()) != 0
+	dow := c.dow&(1<<t.Weekday()) != 0
+	if c.domAny || c.dowAny {
+		return dom && dow
+	}
+	return dom || dow
+}
+
+// runScheduled calls run at every time spec matches until interrupted, so
+// that 'apply -schedule' can stay up instead of being started by cron. Runs
+// never overlap: a time that passes while a run is still going is skipped.
+// A failed run is logged and the schedule carries on.
+func runScheduled(spec string, run func() int) int {
+	sched, err := parseCron(spec)
+	if err != nil {
+		slog.Error(err.Error())
+		return 2
+	}
+	ctx, stop := interruptContext(cmdCtx)
+	defer stop()
+	root := cmdCtx
+	defer func() { cmdCtx = root }()
+	scheduledHolds = &heldRows{}
+	defer func() { scheduledHolds = nil }()
+	for {
+		at := sched.next(time.Now())
+		if at.IsZero() {
+			slog.Error("the schedule never matches", "schedule", spec)
+			return 2
+		}
+		if due := scheduledHolds.take(); !due.IsZero() && due.Before(at) {
+			at = due
+			slog.Info("waiting to run the rows held until their not_before time", "at", at.Format(time.RFC3339))
+		} else {
+			slog.Info("waiting for the next scheduled run", "schedule", spec, "at", at.Format(time.RFC3339))
+		}
+		timer := time.NewTimer(time.Until(at))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return 0
+		case <-timer.C:
+		}
+
+		// Each run gets a span of its own under the command's.
+		var span trace.Span
+		cmdCtx, span = tracer.Start(root, "scheduled run", trace.WithAttributes(attribute.String("scheduled_at", at.Format(time.RFC3339))))
+		code := run()
+		span.SetAttributes(attribute.Int("exit_code", code))
+		span.End()
+		if code != 0 {
+			slog.Warn("scheduled run failed", "exit_code", code)
+		}
+		if ctx.Err() != nil {
+			return code
+		}
+		if missed := sched.next(at); !missed.IsZero() && missed.Before(time.Now()) {
+			slog.Warn("skipping the scheduled times that passed during the run", "first", missed.Format(time.RFC3339))
+		}
+	}
+}
+
+// heldRows records, during 'apply -schedule', the earliest not_before time of
+// the rows a run held back, so that runScheduled runs again then rather than
+// at the next time the expression matches.
+type heldRows struct {
+	earliest time.Time
+}
+
+// scheduledHolds is set while apply -schedule runs; it is nil otherwise.
+var scheduledHolds *heldRows
+
+func (h *heldRows) hold(t time.Time) {
+	if h != nil && (h.earliest.IsZero() || t.Before(h.earliest)) {
+		h.earliest = t
+	}
+}
+
+// take returns the earliest time held since the last call, or zero.
+func (h *heldRows) take() time.Time {
+	if h == nil {
+		return time.Time{}
+	}
+	t := h.earliest
+	h.earliest = time.Time{}
+	return t
+}
+
+// holdUntilDue leaves out the entries whose not_before time is after
+// now, and the renames waiting for one of them to free their target name. It
+// returns the rest and a message for each entry left out: skipped in a
+// one-shot apply, held for a later run under -schedule.
+func holdUntilDue(entries []planEntry, channels map[string]channelInfo, now time.Time) ([]planEntry, []string) {
+	until := make([]time.Time, len(entries))
+	waits := make([]int, len(entries))
+	for i, e := range entries {
+		waits[i] = -1
+		if e.NotBefore.After(now) {
+			until[i] = e.NotBefore
+		}
+	}
+	dep := plan.RenameDeps(entries)
+	for changed := true; changed; {
+		changed = false
+		for i, j := range dep {
+			if j < 0 || !until[i].IsZero() || until[j].IsZero() {
+				continue
+			}
+			if target, exists := channels[entries[i].Tobe]; exists && !target.IsArchived {
+				until[i], waits[i], changed = until[j], j, true
+			}
+		}
+	}
+
+	var kept []planEntry
+	var msgs []string
+	for i, e := range entries {
+		if until[i].IsZero() {
+			kept = append(kept, e)
+			continue
+		}
+		scheduledHolds.hold(until[i])
+		then := "skipping"
+		if scheduledHolds != nil {
+			then = "holding it for a later run"
+		}
+		if j := waits[i]; j >= 0 {
+			msgs = append(msgs, e.At()+fmt.Sprintf("waits for %s, which is not before %s, %s", entries[j], until[i].Format(time.RFC3339), then))
+		} else {
+			msgs = append(msgs, e.At()+fmt.Sprintf("not before %s, %s", until[i].Format(time.RFC3339), then))
+		}
+	}
+	return kept, msgs
+}
+
+// dropFlag removes every use of the flag name, and its value, from args.
+func dropFlag(args []string, name string) []string {
+	var out []string
+	for i := 0; i < len(args); i++ {
+		a := args[i]
+		if a == "--" {
+			return append(out, args[i:]...)
+		}
+		bare := strings.TrimLeft(a, "-")
+		if len(bare) < len(a) && len(a)-len(bare) <= 2 {
+			if bare == name {
+				i++ // the value
+				continue
+			}
+			if strings.HasPrefix(bare, name+"=") {
+				continue
+			}
+		}
+		out = append(out, a)
+	}
+	return out
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestCronNext(t *testing.T) {
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	if err != nil {
+		t.Skip(err)
+	}
+	// A Wednesday.
+	from := time.Date(2026, 10, 14, 10, 30, 0, 0, time.UTC)
+	for _, tc := range []struct {
+		spec string
+		want time.Time
+	}{
+		{"*/15 * * * *", time.Date(2026, 10, 14, 10, 45, 0, 0, time.UTC)},
+		{"0 2 * * 6", time.Date(2026, 10, 17, 2, 0, 0, 0, time.UTC)},
+		{"0 2 * * sat", time.Date(2026, 10, 17, 2, 0, 0, 0, time.UTC)},
+		{"0 0 1 jan *", time.Date(2027, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{"30 9 1-5 * *", time.Date(2026, 11, 1, 9, 30, 0, 0, time.UTC)},
+		// Both day fields restricted: either one matches.
+		{"0 12 20 * 7", time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)},
+		{"0 19,22 * * mon-fri", time.Date(2026, 10, 14, 19, 0, 0, 0, time.UTC)},
+		{"CRON_TZ=Asia/Tokyo 0 2 * * 6", time.Date(2026, 10, 17, 2, 0, 0, 0, tokyo)},
+	} {
+		c, err := parseCron(tc.spec)
+		if err != nil {
+			t.Errorf("parseCron(%q): %v", tc.spec, err)
+			continue
+		}
+		if c.loc == time.Local {
+			// Keep the test independent of the machine's time zone.
+			c.loc = time.UTC
+		}
+		if got := c.next(from); !got.Equal(tc.want) {
+			t.Errorf("%q: next after %v = %v, want %v", tc.spec, from, got, tc.want)
+		}
+	}
+}
+
+func TestCronNeverMatches(t *testing.T) {
+	c, err := parseCron("0 0 30 feb *")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := c.next(time.Now()); !got.IsZero() {
+		t.Errorf("30 Feb matched %v", got)
+	}
+}
+
+func TestParseCronErrors(t *testing.T) {
+	for _, spec := range []string{"", "* * * *", "60 * * * *", "* 24 * * *", "* * 0 * *", "*/0 * * * *", "5-1 * * * *", "* * * foo *", "CRON_TZ=Nowhere/City * * * * *"} {
+		if _, err := parseCron(spec); err == nil {
+			t.Errorf("parseCron(%q) succeeded", spec)
+		}
+	}
+}
+
+func TestDropFlag(t *testing.T) {
+	args := []string{"-plan", "a.csv", "-schedule", "0 2 * * 6", "--schedule=x", "-verify", "-metrics-addr=:9090"}
+	got := dropFlag(dropFlag(args, "schedule"), "metrics-addr")
+	if want := []string{"-plan", "a.csv", "-verify"}; !slices.Equal(got, want) {
+		t.Errorf("dropFlag = %q, want %q", got, want)
+	}
+}
+
+func TestHoldUntilDue(t *testing.T) {
+	now := time.Date(2026, 4, 1, 9, 0, 0, 0, time.UTC)
+	channels := map[string]channelInfo{"a": {ID: "C1"}, "b": {ID: "C2"}, "c": {ID: "C3"}}
+	plan := []planEntry{
+		{Action: actionRename, Asis: "a", Tobe: "b", Source: "plan.csv:2"},
+		{Action: actionRename, Asis: "b", Tobe: "b2", Source: "plan.csv:3", NotBefore: now.Add(time.Hour)},
+		{Action: actionRename, Asis: "c", Tobe: "c2", Source: "plan.csv:4", NotBefore: now.Add(-time.Hour)},
+	}
+	kept, msgs := holdUntilDue(plan, channels, now)
+	if len(kept) != 1 || kept[0].Asis != "c" {
+		t.Errorf("kept = %v, want only c -> c2", kept)
+	}
+	want := []string{
+		"plan.csv:2: waits for b -> b2, which is not before 2026-04-01T10:00:00Z, skipping",
+		"plan.csv:3: not before 2026-04-01T10:00:00Z, skipping",
+	}
+	if !slices.Equal(msgs, want) {
+		t.Errorf("messages = %q, want %q", msgs, want)
+	}
+
+	scheduledHolds = &heldRows{}
+	defer func() { scheduledHolds = nil }()
+	holdUntilDue(plan, channels, now)
+	if got := scheduledHolds.take(); !got.Equal(now.Add(time.Hour)) {
+		t.Errorf("held until %v, want %v", got, now.Add(time.Hour))
+	}
+}
@@ -0,0 +1,335 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// planSchemaJSON describes the JSON and YAML plan formats: a list of entries
+// with the columns of a CSV plan. Unknown properties are allowed, as unknown
+// CSV columns are.
+const planSchemaJSON = `{
+  "$schema": "https://json-schema.org/draft/2020-12/schema",
+  "title": "slack-channel-renamer plan",
+  "description": "Channels to rename, archive, unarchive, merge, create or invite members to, one entry per row.",
+  "type": "array",
+  "minItems": 1,
+  "items": {
+    "type": "object",
+    "required": ["asis"],
+    "properties": {
+      "action": {
+        "description": "What to do with the channel; rename when empty.",
+        "enum": ["", "rename", "archive", "unarchive", "set-topic", "merge", "create", "invite"]
+      },
+      "asis": {"type": "string", "minLength": 1, "description": "The channel's current name, without the #, or its ID."},
+      "tobe": {"type": "string", "description": "The new name, or the channel to merge into; may use {{.OldName}} and the other name templates."},
+      "owner": {"type": "string", "description": "Who answers for the row, for -by-group."},
+      "topic": {"type": "string", "description": "The topic to set after the change."},
+      "purpose": {"type": "string", "description": "The purpose to set after the change."},
+      "members": {"type": "string", "description": "User IDs or email addresses to invite, separated by spaces or commas."},
+      "channel_id": {"type": "string", "pattern": "^[CG][A-Z0-9]+$", "description": "The channel's ID, which finds it even after a rename."},
+      "workspace": {"type": "string", "description": "The -config workspace the row runs against."},
+      "skip": {"type": "boolean", "description": "Leave the row out of the run."},
+      "priority": {"type": "integer", "minimum": 0, "description": "Rows with a lower priority run first."},
+      "order": {"type": "integer", "minimum": 0, "description": "Another name for priority."},
+      "not_before": {"type": "string", "description": "Hold the row until this time, such as 2026-04-01T09:00:00+09:00 or 2026-04-01 09:00."}
+    }
+  }
+}
+`
+
+// configSchemaJSON describes the -config file of workspaces and their tokens.
+const configSchemaJSON = `{
+  "$schema": "https://json-schema.org/draft/2020-12/schema",
+  "title": "slack-channel-renamer workspaces",
+  "description": "The workspaces a plan can run against and where each one's token comes from.",
+  "type": "object",
+  "required": ["workspaces"],
+  "additionalProperties": false,
+  "properties": {
+    "workspaces": {
+      "type": "object",
+      "minProperties": 1,
+      "additionalProperties": {
+        "type": "object",
+        "additionalProperties": false,
+        "properties": {
+          "token": {"type": "string", "description": "The token itself; prefer token_env, token_file or token_ref."},
+          "token_env": {"type": "string", "description": "The environment variable holding the token."},
+          "token_file": {"type": "string", "description": "The file holding the token."},
+          "token_ref": {"type": "string", "description": "A secret manager reference such as op://vault/item/field."},
+          "team": {"type": "string", "description": "The workspace name, domain or ID the token must belong to."},
+          "team_id": {"type": "string", "description": "For a Grid org token, the workspace whose channels this one matches."}
+        }
+      }
+    }
+  }
+}
+`
+
+// schemas are the schemas 'schema' prints, by name.
+var schemas = map[string]string{
+	"plan":   planSchemaJSON,
+	"config": configSchemaJSON,
+	"api":    openAPIJSON,
+}
+
+var (
+	planSchema   = mustParseSchema(planSchemaJSON)
+	configSchema = mustParseSchema(configSchemaJSON)
+)
+
+// jsonSchema is the part of JSON Schema the tool's own schemas use, which is
+// all validateSchema checks.
+type jsonSchema struct {
+	Type          string                 `json:"type"`
+	Enum          []string               `json:"enum"`
+	Pattern       string                 `json:"pattern"`
+	MinLength     int                    `json:"minLength"`
+	Minimum       *int                   `json:"minimum"`
+	Items         *jsonSchema            `json:"items"`
+	MinItems      int                    `json:"minItems"`
+	Properties    map[string]*jsonSchema `json:"properties"`
+	Required      []string               `json:"required"`
+	MinProperties int                    `json:"minProperties"`
+	// AdditionalProperties is false or the schema of the properties not
+	// listed in Properties.
+	AdditionalProperties json.RawMessage `json:"additionalProperties"`
+
+	pattern    *regexp.Regexp
+	additional *jsonSchema
+	closed     bool
+}
+
+func mustParseSchema(s string) *jsonSchema {
+	var js jsonSchema
+	if err := json.Unmarshal([]byte(s), &js); err != nil {
+		panic(err)
+	}
+	js.compile()
+	return &js
+}
+
+func (s *jsonSchema) compile() {
+	if s.Pattern != "" {
+		s.pattern = regexp.MustCompile(s.Pattern)
+	}
+	switch a := strings.TrimSpace(string(s.AdditionalProperties)); a {
+	case "", "true":
+	case "false":
+		s.closed = true
+	default:
+		s.additional = mustParseSchema(a)
+	}
+	if s.Items != nil {
+		s.Items.compile()
+	}
+	for _, p := range s.Properties {
+		p.compile()
+	}
+}
+
+// validateSchema checks the document n, parsed from the file path as YAML or
+// JSON, against s. It returns one problem per mismatch, each with the line
+// and the JSON Pointer of the value, as in "plan.yaml:4: /1/priority: ...".
+// YAML plain scalars and empty values pass as strings, since they decode to
+// them; JSON values must have the schema's type.
+func validateSchema(s *jsonSchema, path string, n *yaml.Node, isJSON bool) []string {
+	v := schemaValidator{path: path, isJSON: isJSON}
+	if n.Kind == yaml.DocumentNode && len(n.Content) > 0 {
+		n = n.Content[0]
+	}
+	v.check(s, n, "")
+	return v.problems
+}
+
+type schemaValidator struct {
+	path     string
+	isJSON   bool
+	problems []string
+}
+
+func (v *schemaValidator) fail(n *yaml.Node, pointer, format string, args ...any) {
+	if pointer == "" {
+		pointer = "/"
+	}
+	v.problems = append(v.problems, fmt.Sprintf("%s:%d: %s: %s", v.path, n.Line, pointer, fmt.Sprintf(format, args...)))
+}
+
+// kind names the JSON type of a node, for messages.
+func (v *schemaValidator) kind(n *yaml.Node) string {
+	switch n.Kind {
+	case yaml.MappingNode:
+		return "an object"
+	case yaml.SequenceNode:
+		return "an array"
+	}
+	switch n.Tag {
+	case "!!null":
+		return "null"
+	case "!!bool":
+		return "a boolean"
+	case "!!int", "!!float":
+		return "a number"
+	}
+	return "a string"
+}
+
+func (v *schemaValidator) check(s *jsonSchema, n *yaml.Node, pointer string) {
+	if n.Kind == yaml.AliasNode {
+		n = n.Alias
+	}
+	if !v.hasType(s.Type, n) {
+		v.fail(n, pointer, "want %s, got %s", typeName(s.Type), v.kind(n))
+		return
+	}
+	if s.Enum != nil {
+		if val := v.scalar(n); !slices.Contains(s.Enum, val) {
+			v.fail(n, pointer, "%q is not one of %s", val, strings.Join(slices.DeleteFunc(slices.Clone(s.Enum), func(e string) bool { return e == "" }), ", "))
+		}
+	}
+	switch n.Kind {
+	case yaml.ScalarNode:
+		val := v.scalar(n)
+		if len(val) < s.MinLength {
+			v.fail(n, pointer, "must not be empty")
+		}
+		if s.pattern != nil && val != "" && !s.pattern.MatchString(val) {
+			v.fail(n, pointer, "%q does not match %s", val, s.Pattern)
+		}
+		if s.Minimum != nil && n.Tag == "!!int" {
+			var i int
+			if err := n.Decode(&i); err == nil && i < *s.Minimum {
+				v.fail(n, pointer, "must be at least %d, got %d", *s.Minimum, i)
+			}
+		}
+	case yaml.SequenceNode:
+		if len(n.Content) < s.MinItems {
+			v.fail(n, pointer, "must not be empty")
+		}
+		if s.Items != nil {
+			for i, item := range n.Content {
+				v.check(s.Items, item, fmt.Sprintf("%s/%d", pointer, i))
+			}
+		}
+	case yaml.MappingNode:
+		if len(n.Content)/2 < s.MinProperties {
+			v.fail(n, pointer, "must not be empty")
+		}
+		seen := make(map[string]bool)
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			key, val := n.Content[i].Value, n.Content[i+1]
+			seen[key] = true
+			child := pointer + "/" + strings.NewReplacer("~", "~0", "/", "~1").Replace(key)
+			switch p, ok := s.Properties[key]; {
+			case ok:
+				v.check(p, val, child)
+			case s.additional != nil:
+				v.check(s.additional, val, child)
+			case s.closed:
+				v.fail(n.Content[i], child, "unknown property%s", suggestProperty(key, s.Properties))
+			}
+		}
+		for _, r := range s.Required {
+			if !seen[r] {
+				v.fail(n, pointer, "missing required property %q", r)
+			}
+		}
+	}
+}
+
+// hasType reports whether n is of the JSON type t; any node is when t is
+// empty.
+func (v *schemaValidator) hasType(t string, n *yaml.Node) bool {
+	switch t {
+	case "":
+		return true
+	case "object":
+		return n.Kind == yaml.MappingNode
+	case "array":
+		return n.Kind == yaml.SequenceNode
+	}
+	if n.Kind != yaml.ScalarNode {
+		return false
+	}
+	switch t {
+	case "boolean":
+		return n.Tag == "!!bool"
+	case "integer":
+		return n.Tag == "!!int"
+	case "string":
+		return n.Tag == "!!str" || !v.isJSON
+	}
+	return false
+}
+
+// scalar returns the value of a scalar node as it decodes into a string.
+func (v *schemaValidator) scalar(n *yaml.Node) string {
+	if n.Kind != yaml.ScalarNode || n.Tag == "!!null" {
+		return ""
+	}
+	return n.Value
+}
+
+func typeName(t string) string {
+	switch t {
+	case "object", "array", "integer":
+		return "an " + t
+	}
+	return "a " + t
+}
+
+// suggestProperty names the known property closest to a mistyped one.
+func suggestProperty(key string, properties map[string]*jsonSchema) string {
+	best, bestDist := "", 3
+	for _, p := range slices.Sorted(maps.Keys(properties)) {
+		if d := editDistance(key, p); d < bestDist {
+			best, bestDist = p, d
+		}
+	}
+	if best == "" {
+		return ""
+	}
+	return fmt.Sprintf(" (did you mean %q?)", best)
+}
+
+// checkSchema parses data, read from path, as YAML (of which JSON is a
+// subset) and checks it against s, reporting every mismatch. Data that does
+// not parse is left for the caller's decoder to report.
+func checkSchema(s *jsonSchema, path string, data []byte, isJSON bool) error {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil || doc.Kind == 0 {
+		return nil
+	}
+	if problems := validateSchema(s, path, &doc, isJSON); len(problems) > 0 {
+		return errors.New(strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// cmdSchema prints the JSON Schema of a plan or config file, for editors to
+// check and complete them, or the OpenAPI description of 'serve'.
+func cmdSchema(args []string) int {
+	fs := newFlagSet("schema")
+	parseFlags(fs, args)
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return 2
+	}
+	s, ok := schemas[fs.Arg(0)]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "unknown schema %q (want plan, config or api)\n", fs.Arg(0))
+		return 2
+	}
+	fmt.Print(s)
+	return 0
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/kiddikn/slack-channel-renamer/plan"
+)
+
+func TestCheckSchema(t *testing.T) {
+	for _, c := range []struct {
+		name   string
+		schema *jsonSchema
+		data   string
+		isJSON bool
+		want   string
+	}{
+		{"valid yaml plan", planSchema, "- asis: old\n  tobe: 2024\n  owner:\n", false, ""},
+		{"yaml plan", planSchema, "- asis: old\n  tobe: new\n- asis: b\n  action: renme\n  priority: -1\n- tobe: c\n", false,
+			`plan.yaml:4: /1/action: "renme" is not one of rename, archive, unarchive, set-topic, merge, create, invite; ` +
+				"plan.yaml:5: /1/priority: must be at least 0, got -1; " +
+				`plan.yaml:6: /2: missing required property "asis"`},
+		{"json plan", planSchema, "[\n  {\"asis\": \"old\", \"priority\": \"high\"},\n  {\"asis\": \"b\", \"channel_id\": \"c123\"}\n]", true,
+			`plan.yaml:2: /0/priority: want an integer, got a string; plan.yaml:3: /1/channel_id: "c123" does not match ^[CG][A-Z0-9]+$`},
+		{"config", configSchema, "workspaces:\n  acme:\n    token_evn: ACME_TOKEN\n", false,
+			`plan.yaml:3: /workspaces/acme/token_evn: unknown property (did you mean "token_env"?)`},
+		{"config not a mapping", configSchema, "workspaces: [acme]\n", false, "plan.yaml:1: /workspaces: want an object, got an array"},
+	} {
+		err := checkSchema(c.schema, "plan.yaml", []byte(c.data), c.isJSON)
+		if got := errString(err); got != c.want {
+			t.Errorf("%s: got %q, want %q", c.name, got, c.want)
+		}
+	}
+}
+
+func TestPlanSchemaCoversRecord(t *testing.T) {
+	rt := reflect.TypeFor[plan.Record]()
+	for i := range rt.NumField() {
+		name, _, _ := strings.Cut(rt.Field(i).Tag.Get("json"), ",")
+		if planSchema.Items.Properties[name] == nil {
+			t.Errorf("the plan schema does not describe %q", name)
+		}
+	}
+}
+
+func errString(err error) string {
+	if err == nil {
+		return ""
+	}
+	return err.Error()
+}
@@ -0,0 +1,679 @@
+package main
+
+import (
+	"cmp"
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"maps"
+	"net"
+	"net/http"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/kiddikn/slack-channel-renamer/plan"
+	"google.golang.org/grpc"
+)
+
+const (
+	// maxPlanUpload caps the size of an uploaded plan.
+	maxPlanUpload = 32 << 20
+	// defaultUploadName is the name of a plan uploaded without one.
+	defaultUploadName = "upload.csv"
+	// serveShutdownTimeout bounds how long 'serve' waits for open requests
+	// once it is told to stop; a run in progress is always waited for.
+	serveShutdownTimeout = 10 * time.Second
+	// readyCheckTTL is how long the outcome of a readiness check is reused,
+	// so that frequent probes do not each call Slack; readyCheckTimeout
+	// bounds one check.
+	readyCheckTTL     = 30 * time.Second
+	readyCheckTimeout = 10 * time.Second
+)
+
+// Statuses of a run started through the API.
+const (
+	runValidating  = "validating"
+	runRunning     = "running"
+	runSucceeded   = "succeeded"
+	runFailed      = "failed"
+	runInterrupted = "interrupted"
+	// runError is a run that stopped before applying anything, for example
+	// because its plan no longer validates.
+	runError = "error"
+)
+
+// server is the state behind 'serve': the plans uploaded to it and the runs
+// started from them, kept in memory for the life of the process. Only one
+// run applies changes at a time.
+type server struct {
+	ws          workspaceOptions
+	channelOpts channelOptions
+	prep        prepareOptions
+	run         runOptions
+	limits      planLimits
+	rollbackDir string
+	token       string
+	// ui serves the web dashboard at /.
+	ui bool
+
+	// validating serialises validations, which each open the history
+	// database.
+	validating sync.Mutex
+
+	mu     sync.Mutex
+	plans  map[string]*servedPlan
+	runs   map[string]*servedRun
+	active *servedRun
+	wg     sync.WaitGroup
+
+	// readiness is the last readiness check, reused for readyCheckTTL.
+	readiness struct {
+		sync.Mutex
+		checked time.Time
+		err     error
+	}
+}
+
+// servedPlan is an uploaded plan.
+type servedPlan struct {
+	ID         string            `json:"id"`
+	Name       string            `json:"name"`
+	Uploaded   time.Time         `json:"uploaded"`
+	Entries    int               `json:"entries"`
+	Validation *validationReport `json:"validation,omitempty"`
+
+	entries []planEntry
+}
+
+// servedRun is an apply started through the API.
+type servedRun struct {
+	ID           string        `json:"id"`
+	Plan         string        `json:"plan"`
+	Status       string        `json:"status"`
+	Started      time.Time     `json:"started"`
+	Finished     time.Time     `json:"finished,omitzero"`
+	Entries      int           `json:"entries"`
+	Error        string        `json:"error,omitempty"`
+	Summary      runSummary    `json:"summary"`
+	Progress     *runProgress  `json:"progress,omitempty"`
+	RollbackFile string        `json:"rollback_file,omitempty"`
+	Results      []entryResult `json:"results,omitempty"`
+
+	// running is when the run started applying, after validation.
+	running time.Time
+	done    chan struct{}
+}
+
+// runProgress is how far a run has got, for dashboards polling it.
+type runProgress struct {
+	// Done counts the entries that have finished, whatever their outcome,
+	// out of Total; Failed those of them that failed.
+	Done   int `json:"done"`
+	Failed int `json:"failed"`
+	Total  int `json:"total"`
+	// ETA estimates the time left, from the throughput so far, while the
+	// run is applying.
+	ETA string `json:"eta,omitempty"`
+	// LastError is the error of the latest failed entry, or of the run.
+	LastError string `json:"last_error,omitempty"`
+}
+
+// serverOptions are the flags of 'serve' and 'bot' that configure how
+// plans are validated and applied, the same as those of apply.
+type serverOptions struct {
+	channelOpts channelOptions
+	ws          workspaceOptions
+	verify      bool
+	verifyPass  bool
+	staleCheck  bool
+	rollbackDir string
+	history     string
+	pool        poolOptions
+	webhook     webhookOptions
+	limits      planLimits
+	onConflict  conflictPolicy
+	protect     protectOptions
+	autoFix     bool
+	nfkc        bool
+}
+
+func (o *serverOptions) register(fs *flag.FlagSet) {
+	o.channelOpts.register(fs)
+	o.ws.register(fs)
+	fs.BoolVar(&o.verify, "verify", false, "re-read each channel after renaming and fail if its name does not match")
+	fs.BoolVar(&o.verifyPass, "verify-pass", true, "re-fetch the channels once the run is done and fail renames whose channel does not carry the new name")
+	fs.BoolVar(&o.staleCheck, "stale-check", true, "re-read each channel just before renaming it and skip it if it no longer has its planned name")
+	fs.StringVar(&o.rollbackDir, "rollback-dir", ".", "write a reverse plan of the changes each run makes to this directory (empty to disable)")
+	fs.StringVar(&o.history, "history-db", defaultHistoryDB, "record every change in this history database and skip renames it shows were already made (empty to disable)")
+	o.pool.register(fs)
+	o.webhook.register(fs)
+	o.limits.register(fs)
+	o.onConflict.register(fs)
+	o.protect.register(fs)
+	fs.BoolVar(&o.autoFix, "auto-fix", false, "rewrite target names to the form Slack would store them in (lowercase, spaces to hyphens, illegal characters removed)")
+	fs.BoolVar(&o.nfkc, "normalize-unicode", false, "rewrite target names to Unicode NFKC form (full-width letters and digits become ASCII)")
+}
+
+func (o *serverOptions) check() error {
+	if err := o.pool.check(); err != nil {
+		return err
+	}
+	return o.webhook.check()
+}
+
+// newServer returns a server with no plans or runs. It opens the sessions
+// once, to fail at startup rather than on the first request if the tokens
+// are missing; each validation opens its own.
+func (o *serverOptions) newServer() (*server, []*session, error) {
+	sessions, err := o.ws.newSessions(o.channelOpts)
+	if err != nil {
+		return nil, nil, err
+	}
+	return &server{
+		ws:          o.ws,
+		channelOpts: o.channelOpts,
+		prep:        prepareOptions{historyDB: o.history, onConflict: o.onConflict, protect: o.protect, autoFix: o.autoFix, normalizeUnicode: o.nfkc},
+		run:         runOptions{verb: "rename", verify: o.verify, verifyPass: o.verifyPass, staleCheck: o.staleCheck, history: o.history, webhook: o.webhook, pool: o.pool},
+		limits:      o.limits,
+		rollbackDir: o.rollbackDir,
+		plans:       make(map[string]*servedPlan),
+		runs:        make(map[string]*servedRun),
+	}, sessions, nil
+}
+
+func cmdServe(args []string) int {
+	fs := newFlagSet("serve")
+	addr := fs.String("addr", "localhost:8080", "listen on this address")
+	grpcAddr := fs.String("grpc-addr", "", "also serve the gRPC API on this address")
+	tokenEnv := fs.String("api-token-env", "RENAMER_API_TOKEN", "environment variable holding the bearer token API clients must send")
+	ui := fs.Bool("ui", false, "also serve a web dashboard at / to upload, review and apply plans and follow their runs")
+	var opts serverOptions
+	opts.register(fs)
+	parseFlags(fs, args)
+	if err := opts.check(); err != nil {
+		slog.Error(err.Error())
+		return 2
+	}
+	token := os.Getenv(*tokenEnv)
+	if token == "" {
+		slog.Error("set an API token for clients to authenticate with", "env", *tokenEnv)
+		return 2
+	}
+	s, _, err := opts.newServer()
+	if err != nil {
+		slog.Error(err.Error())
+		return 1
+	}
+	s.token, s.ui = token, *ui
+	srv := &http.Server{Addr: *addr, Handler: s.routes(), ReadHeaderTimeout: 10 * time.Second}
+	errc := make(chan error, 2)
+	go func() { errc <- srv.ListenAndServe() }()
+	slog.Info("serving the API", "addr", *addr)
+	if s.ui {
+		slog.Info("serving the dashboard at /", "addr", *addr)
+	}
+	var g *grpc.Server
+	if *grpcAddr != "" {
+		lis, err := net.Listen("tcp", *grpcAddr)
+		if err != nil {
+			slog.Error("cannot serve the gRPC API", "err", err)
+			srv.Close()
+			return 1
+		}
+		g = s.grpcServer()
+		go func() { errc <- g.Serve(lis) }()
+		slog.Info("serving the gRPC API", "addr", lis.Addr().String())
+	}
+
+	ctx, stop := interruptContext(cmdCtx)
+	defer stop()
+	select {
+	case err := <-errc:
+		slog.Error("server stopped", "err", err)
+		return 1
+	case <-ctx.Done():
+	}
+	shutdown, cancel := context.WithTimeout(context.Background(), serveShutdownTimeout)
+	defer cancel()
+	if g != nil {
+		// WatchRun streams end with their run, so do not wait past the
+		// HTTP server's deadline for them.
+		stopped := make(chan struct{})
+		go func() { g.GracefulStop(); close(stopped) }()
+		defer func() {
+			select {
+			case <-stopped:
+			case <-shutdown.Done():
+				g.Stop()
+			}
+		}()
+	}
+	if err := srv.Shutdown(shutdown); err != nil {
+		slog.Warn("closing open connections", "err", err)
+	}
+	// The run in progress saw the same signal and stops starting entries.
+	s.wg.Wait()
+	return 0
+}
+
+func (s *server) routes() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /healthz", func(w http.ResponseWriter, _ *http.Request) { io.WriteString(w, "ok\n") })
+	mux.HandleFunc("GET /readyz", s.ready)
+	mux.HandleFunc("GET /v1/openapi.json", serveOpenAPI)
+	mux.Handle("GET /metrics", live)
+	mux.HandleFunc("POST /v1/plans", s.authorized(s.uploadPlan))
+	mux.HandleFunc("GET /v1/plans", s.authorized(s.listPlans))
+	mux.HandleFunc("GET /v1/plans/{id}", s.authorized(s.getPlan))
+	mux.HandleFunc("POST /v1/plans/{id}/validate", s.authorized(s.validatePlan))
+	mux.HandleFunc("POST /v1/plans/{id}/apply", s.authorized(s.applyPlan))
+	mux.HandleFunc("GET /v1/runs", s.authorized(s.listRuns))
+	mux.HandleFunc("GET /v1/runs/{id}", s.authorized(s.getRun))
+	mux.HandleFunc("GET /runs/{id}", s.authorized(s.getRun))
+	mux.HandleFunc("GET /v1/runs/{id}/results", s.authorized(s.getResults))
+	if s.ui {
+		mux.HandleFunc("GET /{$}", serveUIPage)
+		mux.HandleFunc("GET /ui.js", serveUIScript)
+	}
+	return mux
+}
+
+// errUnauthorized is the error of a request without the API token.
+var errUnauthorized = errors.New("missing or wrong bearer token")
+
+// authorized rejects requests that do not carry the API token as a bearer token.
+func (s *server) authorized(h http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !s.bearer(r.Header.Get("Authorization")) {
+			writeError(w, http.StatusUnauthorized, errUnauthorized)
+			return
+		}
+		h(w, r)
+	}
+}
+
+// bearer reports whether an Authorization value carries the API token.
+func (s *server) bearer(auth string) bool {
+	got, ok := strings.CutPrefix(auth, "Bearer ")
+	return ok && subtle.ConstantTimeCompare([]byte(got), []byte(s.token)) == 1
+}
+
+// uploadPlan stores the plan in the request body. Its format is taken from
+// the format query parameter, or else from the extension of the name
+// parameter, as for -plan; it defaults to CSV.
+func (s *server) uploadPlan(w http.ResponseWriter, r *http.Request) {
+	name := cmp.Or(r.URL.Query().Get("name"), defaultUploadName)
+	load, err := planInput{format: r.URL.Query().Get("format")}.loaderFor(name)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	data, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxPlanUpload))
+	if err != nil {
+		writeError(w, http.StatusRequestEntityTooLarge, err)
+		return
+	}
+	entries, err := load(name, data, plan.Options{})
+	if err != nil {
+		writeError(w, http.StatusUnprocessableEntity, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, s.addPlan(name, entries))
+}
+
+// addPlan stores a plan read from name.
+func (s *server) addPlan(name string, entries []planEntry) *servedPlan {
+	p := &servedPlan{ID: rand.Text(), Name: name, Uploaded: time.Now().UTC(), Entries: len(entries), entries: entries}
+	s.mu.Lock()
+	s.plans[p.ID] = p
+	s.mu.Unlock()
+	slog.Info("plan uploaded", "plan", p.ID, "name", name, "entries", len(entries))
+	return p
+}
+
+func (s *server) listPlans(w http.ResponseWriter, _ *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	writeJSON(w, http.StatusOK, s.sortedPlans())
+}
+
+// sortedPlans returns the plans, oldest first. The caller holds s.mu.
+func (s *server) sortedPlans() []*servedPlan {
+	return slices.SortedFunc(maps.Values(s.plans), func(a, b *servedPlan) int { return a.Uploaded.Compare(b.Uploaded) })
+}
+
+func (s *server) getPlan(w http.ResponseWriter, r *http.Request) {
+	if p := s.plan(w, r); p != nil {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		writeJSON(w, http.StatusOK, p)
+	}
+}
+
+// plan returns the plan named in the request path, or writes a 404.
+func (s *server) plan(w http.ResponseWriter, r *http.Request) *servedPlan {
+	p, err := s.planByID(r.PathValue("id"))
+	if err != nil {
+		writeError(w, http.StatusNotFound, err)
+	}
+	return p
+}
+
+// planByID returns the plan with the ID, or an error if there is none.
+func (s *server) planByID(id string) (*servedPlan, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if p := s.plans[id]; p != nil {
+		return p, nil
+	}
+	return nil, fmt.Errorf("no plan %q", id)
+}
+
+// validatePlan validates a plan against the live channels and returns the
+// validation report together with the entries that would run, with their
+// channels' stats when the stats query parameter is given.
+func (s *server) validatePlan(w http.ResponseWriter, r *http.Request) {
+	p := s.plan(w, r)
+	if p == nil {
+		return
+	}
+	if err := s.canValidate(); err != nil {
+		writeError(w, http.StatusConflict, err)
+		return
+	}
+	runs, v, err := s.validate(p)
+	if err != nil {
+		writeError(w, http.StatusBadGateway, err)
+		return
+	}
+	if r.URL.Query().Has("stats") {
+		addChannelStats(r.Context(), runs)
+	}
+	writeJSON(w, http.StatusOK, struct {
+		*validationReport
+		Plan []planReportEntry `json:"plan"`
+	}{v, planReport(runs)})
+}
+
+// canValidate returns an error while a validation would have to wait for
+// the run in progress: the run holds the history database open until it
+// finishes.
+func (s *server) canValidate() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.active != nil && s.prep.historyDB != "" {
+		return fmt.Errorf("run %s is still in progress", s.active.ID)
+	}
+	return nil
+}
+
+// validate opens fresh sessions, validates p and records the outcome on it.
+func (s *server) validate(p *servedPlan) ([]workspaceRun, *validationReport, error) {
+	s.validating.Lock()
+	defer s.validating.Unlock()
+	sessions, err := s.ws.newSessions(s.channelOpts)
+	if err != nil {
+		return nil, nil, err
+	}
+	runs, errs, skipped, err := validateRuns(sessions, s.ws, p.entries, s.prep)
+	if err != nil {
+		return nil, nil, err
+	}
+	v := &validationReport{Passed: len(errs) == 0, Errors: append([]string{}, errs...), Skipped: append([]string{}, skipped...)}
+	s.mu.Lock()
+	p.Validation = v
+	s.mu.Unlock()
+	return runs, v, nil
+}
+
+// applyPlan starts applying a plan in the background and returns the new
+// run. The plan is validated again first, against the channels as they are
+// then.
+func (s *server) applyPlan(w http.ResponseWriter, r *http.Request) {
+	p := s.plan(w, r)
+	if p == nil {
+		return
+	}
+	run, err := s.start(p)
+	if err != nil {
+		writeError(w, http.StatusConflict, err)
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	writeJSON(w, http.StatusAccepted, run)
+}
+
+// start starts applying p in the background, unless another run is in
+// progress. The returned run's done channel is closed once it finishes.
+func (s *server) start(p *servedPlan) (*servedRun, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.active != nil {
+		return nil, fmt.Errorf("run %s is still in progress", s.active.ID)
+	}
+	run := &servedRun{ID: rand.Text(), Plan: p.ID, Status: runValidating, Started: time.Now().UTC(), done: make(chan struct{})}
+	s.runs[run.ID] = run
+	s.active = run
+	s.wg.Add(1)
+	go s.execute(run, p)
+	return run, nil
+}
+
+// execute validates and applies p for run, updating run as it goes.
+func (s *server) execute(run *servedRun, p *servedPlan) {
+	defer s.wg.Done()
+	status, errMsg := runError, ""
+	defer func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		run.Status, run.Error, run.Finished = status, errMsg, time.Now().UTC()
+		run.Summary = summarizeResults(run.Results, status == runInterrupted)
+		s.active = nil
+		close(run.done)
+		slog.Info("run finished", "run", run.ID, "plan", p.ID, "status", status)
+	}()
+
+	runs, v, err := s.validate(p)
+	switch {
+	case err != nil:
+		errMsg = err.Error()
+		return
+	case !v.Passed:
+		errMsg = fmt.Sprintf("validation failed: %s", strings.Join(v.Errors, "; "))
+		return
+	}
+	if err := s.limits.check(countEntries(runs)); err != nil {
+		errMsg = err.Error()
+		return
+	}
+	s.mu.Lock()
+	run.Status, run.Entries, run.running = runRunning, countEntries(runs), time.Now()
+	s.mu.Unlock()
+
+	opts := s.run
+	opts.source = fmt.Sprintf("%s (plan %s)", p.Name, p.ID)
+	opts.observe = func(r entryResult) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		run.Results = append(run.Results, r)
+	}
+	res, err := executeRuns(runs, opts)
+	if err != nil {
+		errMsg = err.Error()
+		return
+	}
+	s.mu.Lock()
+	// The final results include the verification pass's verdicts and the
+	// entries that never started.
+	run.Results = res.results
+	s.mu.Unlock()
+	switch res.exitCode() {
+	case 0:
+		status = runSucceeded
+	case 130:
+		status = runInterrupted
+	default:
+		status = runFailed
+	}
+	if s.rollbackDir != "" && len(res.changed) > 0 {
+		path, err := writeRollbackFile(s.rollbackDir, opts.source, res.changed)
+		if err != nil {
+			slog.Error("failed to write rollback file", "run", run.ID, "err", err)
+			return
+		}
+		s.mu.Lock()
+		run.RollbackFile = path
+		s.mu.Unlock()
+	}
+}
+
+// idle returns a channel that is closed once no run is in progress.
+func (s *server) idle() <-chan struct{} {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.active != nil {
+		return s.active.done
+	}
+	done := make(chan struct{})
+	close(done)
+	return done
+}
+
+func (s *server) listRuns(w http.ResponseWriter, _ *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	writeJSON(w, http.StatusOK, s.runStatuses())
+}
+
+// runStatuses returns the status of every run, oldest first. The caller
+// holds s.mu.
+func (s *server) runStatuses() []servedRun {
+	runs := make([]servedRun, 0, len(s.runs))
+	for _, run := range s.runs {
+		runs = append(runs, s.runStatus(run))
+	}
+	slices.SortFunc(runs, func(a, b servedRun) int { return a.Started.Compare(b.Started) })
+	return runs
+}
+
+// getRun returns a run's status and counts so far, without its results.
+func (s *server) getRun(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	run, err := s.runByID(r.PathValue("id"))
+	if err != nil {
+		writeError(w, http.StatusNotFound, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, s.runStatus(run))
+}
+
+// runByID returns the run with the ID, or an error if there is none. The
+// caller holds s.mu.
+func (s *server) runByID(id string) (*servedRun, error) {
+	if run := s.runs[id]; run != nil {
+		return run, nil
+	}
+	return nil, fmt.Errorf("no run %q", id)
+}
+
+// runStatus returns run with its counts and progress so far and without its
+// results. The caller holds s.mu.
+func (s *server) runStatus(run *servedRun) servedRun {
+	status := *run
+	if status.Finished.IsZero() {
+		status.Summary = summarizeResults(run.Results, false)
+	}
+	status.Progress = s.progress(run)
+	status.Results = nil
+	return status
+}
+
+// progress reports how far run has got. The caller holds s.mu.
+func (s *server) progress(run *servedRun) *runProgress {
+	p := &runProgress{Total: run.Entries, LastError: run.Error}
+	for _, r := range run.Results {
+		if r.Status == resultPending {
+			continue
+		}
+		p.Done++
+		if r.Status == resultFailed {
+			p.Failed++
+			p.LastError = r.Error
+		}
+	}
+	if run.Status == runRunning {
+		// Entries start no faster than -rate allows.
+		_, left := (&progress{total: p.Total, maxRate: s.run.pool.perMinute / 60}).eta(p.Done, time.Since(run.running))
+		p.ETA = left.String()
+	}
+	return p
+}
+
+// ready replies 200 once the server can take work: every workspace's token
+// resolves and Slack accepts it. Otherwise it replies 503 with the reason.
+func (s *server) ready(w http.ResponseWriter, _ *http.Request) {
+	s.readiness.Lock()
+	if time.Since(s.readiness.checked) > readyCheckTTL {
+		s.readiness.err = s.checkReady()
+		s.readiness.checked = time.Now()
+	}
+	err := s.readiness.err
+	s.readiness.Unlock()
+	if err != nil {
+		writeError(w, http.StatusServiceUnavailable, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]bool{"ready": true})
+}
+
+func (s *server) checkReady() error {
+	sessions, err := s.ws.newSessions(s.channelOpts)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(cmdCtx, readyCheckTimeout)
+	defer cancel()
+	for _, sess := range sessions {
+		if _, err := sess.client.AuthTestContext(ctx); err != nil {
+			return fmt.Errorf("%sauth.test failed: %w", sess.label(), err)
+		}
+	}
+	return nil
+}
+
+// getResults returns the outcome of every entry of a run that has finished so far.
+func (s *server) getResults(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	run, err := s.runByID(r.PathValue("id"))
+	if err != nil {
+		writeError(w, http.StatusNotFound, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, append([]entryResult{}, run.Results...))
+}
+
+func writeJSON(w http.ResponseWriter, code int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(v); err != nil {
+		slog.Warn("failed to write response", "err", err)
+	}
+}
+
+func writeError(w http.ResponseWriter, code int, err error) {
+	writeJSON(w, code, map[string]string{"error": err.Error()})
+}
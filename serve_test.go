@@ -0,0 +1,212 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newTestServer() *server {
+	return &server{
+		token: "secret",
+		plans: make(map[string]*servedPlan),
+		runs:  make(map[string]*servedRun),
+	}
+}
+
+func serveRequest(t *testing.T, h http.Handler, method, path, token, body string) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestServeAuthorization(t *testing.T) {
+	h := newTestServer().routes()
+	for _, tc := range []struct {
+		name, token string
+		want        int
+	}{
+		{"no token", "", http.StatusUnauthorized},
+		{"wrong token", "guess", http.StatusUnauthorized},
+		{"right token", "secret", http.StatusOK},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if rec := serveRequest(t, h, "GET", "/v1/plans", tc.token, ""); rec.Code != tc.want {
+				t.Errorf("GET /v1/plans: status %d, want %d", rec.Code, tc.want)
+			}
+		})
+	}
+	if rec := serveRequest(t, h, "GET", "/healthz", "", ""); rec.Code != http.StatusOK {
+		t.Errorf("GET /healthz: status %d, want %d", rec.Code, http.StatusOK)
+	}
+}
+
+func TestServeUploadPlan(t *testing.T) {
+	h := newTestServer().routes()
+	rec := serveRequest(t, h, "POST", "/v1/plans?name=teams.csv", "secret", "asis,tobe\nold-a,new-a\nold-b,new-b\n")
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("upload: status %d, body %s", rec.Code, rec.Body)
+	}
+	var p servedPlan
+	if err := json.Unmarshal(rec.Body.Bytes(), &p); err != nil {
+		t.Fatal(err)
+	}
+	if p.ID == "" || p.Name != "teams.csv" || p.Entries != 2 {
+		t.Errorf("upload returned %+v, want an ID, name teams.csv and 2 entries", p)
+	}
+
+	rec = serveRequest(t, h, "GET", "/v1/plans/"+p.ID, "secret", "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("get plan: status %d", rec.Code)
+	}
+	var got servedPlan
+	if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+	if got.ID != p.ID || got.Entries != 2 {
+		t.Errorf("get plan returned %+v, want %+v", got, p)
+	}
+
+	rec = serveRequest(t, h, "GET", "/v1/plans", "secret", "")
+	var list []servedPlan
+	if err := json.Unmarshal(rec.Body.Bytes(), &list); err != nil {
+		t.Fatal(err)
+	}
+	if len(list) != 1 || list[0].ID != p.ID {
+		t.Errorf("list returned %+v, want the uploaded plan only", list)
+	}
+}
+
+func TestServeUploadErrors(t *testing.T) {
+	h := newTestServer().routes()
+	for _, tc := range []struct {
+		name, path, body string
+		want             int
+	}{
+		{"unknown format", "/v1/plans?format=toml", "asis,tobe\n", http.StatusBadRequest},
+		{"missing column", "/v1/plans?name=plan.csv", "name\nold-a\n", http.StatusUnprocessableEntity},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if rec := serveRequest(t, h, "POST", tc.path, "secret", tc.body); rec.Code != tc.want {
+				t.Errorf("status %d, want %d (body %s)", rec.Code, tc.want, rec.Body)
+			}
+		})
+	}
+}
+
+func TestServeNotFound(t *testing.T) {
+	h := newTestServer().routes()
+	for _, path := range []string{"/v1/plans/nope", "/v1/runs/nope", "/v1/runs/nope/results"} {
+		if rec := serveRequest(t, h, "GET", path, "secret", ""); rec.Code != http.StatusNotFound {
+			t.Errorf("GET %s: status %d, want %d", path, rec.Code, http.StatusNotFound)
+		}
+	}
+	if rec := serveRequest(t, h, "POST", "/v1/plans/nope/apply", "secret", ""); rec.Code != http.StatusNotFound {
+		t.Errorf("POST apply of an unknown plan: status %d, want %d", rec.Code, http.StatusNotFound)
+	}
+}
+
+func TestServeApplyConflict(t *testing.T) {
+	s := newTestServer()
+	h := s.routes()
+	rec := serveRequest(t, h, "POST", "/v1/plans", "secret", "asis,tobe\nold-a,new-a\n")
+	var p servedPlan
+	if err := json.Unmarshal(rec.Body.Bytes(), &p); err != nil {
+		t.Fatal(err)
+	}
+	s.active = &servedRun{ID: "busy"}
+	if rec := serveRequest(t, h, "POST", "/v1/plans/"+p.ID+"/apply", "secret", ""); rec.Code != http.StatusConflict {
+		t.Errorf("apply during a run: status %d, want %d", rec.Code, http.StatusConflict)
+	}
+}
+
+func TestServeReady(t *testing.T) {
+	t.Setenv("SLACK_USER_TOKEN", "")
+	t.Setenv("SLACK_TOKEN_REF", "")
+	s := newTestServer()
+	if rec := serveRequest(t, s.routes(), "GET", "/readyz", "", ""); rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("GET /readyz without a token: status %d, want %d", rec.Code, http.StatusServiceUnavailable)
+	}
+
+	path := filepath.Join(t.TempDir(), "snapshot.json")
+	if err := os.WriteFile(path, []byte(`[{"name":"general","id":"C1"}]`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	s = newTestServer()
+	s.ws = workspaceOptions{simulate: path}
+	if rec := serveRequest(t, s.routes(), "GET", "/readyz", "", ""); rec.Code != http.StatusOK {
+		t.Errorf("GET /readyz: status %d, want %d (body %s)", rec.Code, http.StatusOK, rec.Body)
+	}
+}
+
+func TestServeRunProgress(t *testing.T) {
+	s := newTestServer()
+	s.run.pool.perMinute = 60
+	s.runs["r1"] = &servedRun{ID: "r1", Status: runRunning, Entries: 4, running: time.Now().Add(-2 * time.Second), Results: []entryResult{
+		{Status: resultOK},
+		{Status: resultFailed, Error: "name_taken"},
+	}}
+	rec := serveRequest(t, s.routes(), "GET", "/runs/r1", "secret", "")
+	var run servedRun
+	if err := json.Unmarshal(rec.Body.Bytes(), &run); err != nil {
+		t.Fatal(err)
+	}
+	want := runProgress{Done: 2, Failed: 1, Total: 4, ETA: "2s", LastError: "name_taken"}
+	if run.Progress == nil || *run.Progress != want {
+		t.Errorf("progress = %+v, want %+v", run.Progress, want)
+	}
+}
+
+func TestServeUI(t *testing.T) {
+	s := newTestServer()
+	if rec := serveRequest(t, s.routes(), "GET", "/", "", ""); rec.Code != http.StatusNotFound {
+		t.Errorf("GET / without -ui: status %d, want %d", rec.Code, http.StatusNotFound)
+	}
+	s.ui = true
+	h := s.routes()
+	for _, path := range []string{"/", "/ui.js"} {
+		rec := serveRequest(t, h, "GET", path, "", "")
+		if rec.Code != http.StatusOK || rec.Header().Get("Content-Security-Policy") == "" {
+			t.Errorf("GET %s: status %d, headers %v", path, rec.Code, rec.Header())
+		}
+	}
+	if rec := serveRequest(t, h, "GET", "/v1/plans", "", ""); rec.Code != http.StatusUnauthorized {
+		t.Errorf("GET /v1/plans with -ui and no token: status %d, want %d", rec.Code, http.StatusUnauthorized)
+	}
+}
+
+func TestServeValidateStats(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "snapshot.json")
+	if err := os.WriteFile(path, []byte(`[{"name":"old-a","id":"C1","members":42}]`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	s := newTestServer()
+	s.ws = workspaceOptions{simulate: path}
+	h := s.routes()
+	rec := serveRequest(t, h, "POST", "/v1/plans", "secret", "asis,tobe\nold-a,new-a\n")
+	var p servedPlan
+	if err := json.Unmarshal(rec.Body.Bytes(), &p); err != nil {
+		t.Fatal(err)
+	}
+	rec = serveRequest(t, h, "POST", "/v1/plans/"+p.ID+"/validate?stats=1", "secret", "")
+	var v struct {
+		Passed bool              `json:"passed"`
+		Plan   []planReportEntry `json:"plan"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &v); err != nil {
+		t.Fatal(err)
+	}
+	if !v.Passed || len(v.Plan) != 1 || v.Plan[0].Stats == nil || v.Plan[0].Stats.Members != 42 {
+		t.Errorf("validate?stats=1 returned %s", rec.Body)
+	}
+}
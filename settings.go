@@ -0,0 +1,179 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/kiddikn/slack-channel-renamer/slackops"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	settingsFileName = "renamer.yaml"
+	settingsEnv      = "RENAMER_SETTINGS"
+	// flagEnvPrefix starts the environment variables that set flags, as
+	// RENAMER_NOTIFY_CHANNEL sets -notify-channel.
+	flagEnvPrefix = "RENAMER_"
+)
+
+// settingsFile is a renamer.yaml file, which gives flags their values so
+// that a team's settings need not be repeated on every command line:
+//
+//	defaults:           # every command with these flags
+//	  rate: 30
+//	  protect: [general, random]
+//	commands:
+//	  apply:
+//	    verify: true
+//	    notify-channel: "#ops"
+//
+// Keys are flag names without the dash. A list gives a repeatable flag
+// several values.
+type settingsFile struct {
+	Defaults map[string]any            `yaml:"defaults"`
+	Commands map[string]map[string]any `yaml:"commands"`
+}
+
+// settingsPath is the -settings flag.
+var settingsPath string
+
+// findSettings returns the settings file to read: -settings, then
+// $RENAMER_SETTINGS, then renamer.yaml in the working directory or the user
+// config directory. It returns an empty path when there is none.
+func findSettings() string {
+	if settingsPath != "" {
+		return settingsPath
+	}
+	if p := os.Getenv(settingsEnv); p != "" {
+		return p
+	}
+	candidates := []string{settingsFileName}
+	if dir, err := os.UserConfigDir(); err == nil {
+		candidates = append(candidates, filepath.Join(dir, "slack-channel-renamer", settingsFileName))
+	}
+	for _, p := range candidates {
+		if _, err := os.Stat(p); err == nil {
+			return p
+		}
+	}
+	return ""
+}
+
+func loadSettings(path string) (*settingsFile, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read settings: %w", err)
+	}
+	dec := yaml.NewDecoder(bytes.NewReader(b))
+	dec.KnownFields(true)
+	var s settingsFile
+	if err := dec.Decode(&s); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("parse %s: %w", path, err)
+	}
+	return &s, nil
+}
+
+// parseFlags parses args into fs like fs.Parse, then gives every flag left
+// off the command line its value from the environment or the settings file.
+// The command line wins over RENAMER_<FLAG>, which wins over the command's
+// section of the settings file, which wins over its defaults. Like fs.Parse
+// with flag.ExitOnError, it exits with status 2 on a bad value.
+func parseFlags(fs *flag.FlagSet, args []string) {
+	if describing {
+		panic(describedFlags{fs})
+	}
+	fs.Parse(args)
+	if err := applySettings(fs); err != nil {
+		fmt.Fprintln(fs.Output(), err)
+		os.Exit(2)
+	}
+	if err := errors.Join(checkRetry(slackops.Retry), egress.check()); err != nil {
+		fmt.Fprintln(fs.Output(), err)
+		os.Exit(2)
+	}
+}
+
+func applySettings(fs *flag.FlagSet) error {
+	set := make(map[string]bool)
+	fs.Visit(func(f *flag.Flag) { set[f.Name] = true })
+
+	var file *settingsFile
+	path := findSettings()
+	if path != "" {
+		var err error
+		if file, err = loadSettings(path); err != nil {
+			return err
+		}
+		for name := range file.Commands[fs.Name()] {
+			if fs.Lookup(name) == nil {
+				return fmt.Errorf("%s: %s has no flag -%s", path, fs.Name(), name)
+			}
+		}
+	}
+
+	var err error
+	fs.VisitAll(func(f *flag.Flag) {
+		if err != nil || set[f.Name] || f.Name == "settings" {
+			return
+		}
+		if v, ok := os.LookupEnv(flagEnvName(f.Name)); ok {
+			if e := fs.Set(f.Name, v); e != nil {
+				err = fmt.Errorf("%s: %w", flagEnvName(f.Name), e)
+			}
+			return
+		}
+		if file == nil {
+			return
+		}
+		v, ok := file.Commands[fs.Name()][f.Name]
+		if !ok {
+			v, ok = file.Defaults[f.Name]
+		}
+		if !ok {
+			return
+		}
+		values, e := settingValues(v)
+		for _, s := range values {
+			if e == nil {
+				e = fs.Set(f.Name, s)
+			}
+		}
+		if e != nil {
+			err = fmt.Errorf("%s: %s: %w", path, f.Name, e)
+		}
+	})
+	return err
+}
+
+// flagEnvName returns the environment variable that sets the flag name.
+func flagEnvName(name string) string {
+	return flagEnvPrefix + strings.ToUpper(strings.ReplaceAll(name, "-", "_"))
+}
+
+// settingValues returns the flag values a settings file value stands for.
+func settingValues(v any) ([]string, error) {
+	switch v := v.(type) {
+	case nil:
+		return nil, nil
+	case []any:
+		var out []string
+		for _, item := range v {
+			values, err := settingValues(item)
+			if err != nil {
+				return nil, err
+			}
+			out = append(out, values...)
+		}
+		return out, nil
+	case map[string]any:
+		return nil, errors.New("want a value or a list, not a mapping")
+	default:
+		return []string{fmt.Sprint(v)}, nil
+	}
+}
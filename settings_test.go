@@ -0,0 +1,55 @@
+package main
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestApplySettings(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "renamer.yaml")
+	settings := `
+defaults:
+  rate: 30
+  concurrency: 2
+  protect: [general, random]
+  workers-everywhere: 1
+commands:
+  apply:
+    concurrency: 4
+    verify: true
+`
+	if err := os.WriteFile(path, []byte(settings), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	old := settingsPath
+	t.Cleanup(func() { settingsPath = old })
+	settingsPath = path
+	t.Setenv("RENAMER_RATE", "45")
+
+	fs := flag.NewFlagSet("apply", flag.ContinueOnError)
+	rate := fs.Float64("rate", 60, "")
+	concurrency := fs.Int("concurrency", 1, "")
+	verify := fs.Bool("verify", false, "")
+	dryRun := fs.Bool("dry-run", false, "")
+	var protect stringList
+	fs.Var(&protect, "protect", "")
+	if err := fs.Parse([]string{"-dry-run"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := applySettings(fs); err != nil {
+		t.Fatal(err)
+	}
+	if *rate != 45 || *concurrency != 4 || !*verify || !*dryRun || !slices.Equal(protect, stringList{"general", "random"}) {
+		t.Errorf("rate=%v concurrency=%v verify=%v dry-run=%v protect=%q; want 45, 4, true, true, [general random]", *rate, *concurrency, *verify, *dryRun, protect)
+	}
+
+	// A command section naming a flag the command lacks is a mistake.
+	other := flag.NewFlagSet("apply", flag.ContinueOnError)
+	other.Float64("rate", 60, "")
+	if err := applySettings(other); err == nil {
+		t.Error("unknown flag in the apply section: want an error")
+	}
+}
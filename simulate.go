@@ -0,0 +1,433 @@
+package main
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"maps"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/kiddikn/slack-channel-renamer/plan"
+	"github.com/slack-go/slack"
+)
+
+// simulateToken stands in for the token of a -simulate session.
+const simulateToken = "xoxp-simulate"
+
+// errNotSimulated is returned for the calls -simulate has no model for.
+var errNotSimulated = errors.New("not available in a simulation")
+
+// simWorkspace is an in-memory Slack workspace, loaded from a snapshot
+// written by 'export -format json', that -simulate runs plans against. It
+// answers channel calls the way Slack does, including name_taken for a name
+// another unarchived channel holds, so that conflicts, swaps and chains play
+// out as they would for real. Messages are accepted and dropped.
+type simWorkspace struct {
+	mu       sync.Mutex
+	channels []*slack.Channel
+	// members holds the member IDs of the channels whose members were asked
+	// for, made up from their member counts.
+	members map[string][]string
+	// pins holds the timestamps of the messages pinned in each channel.
+	pins map[string][]string
+	// bookmarks holds the bookmarks of each channel; snapshots have none.
+	bookmarks map[string][]slack.Bookmark
+	ts        int
+}
+
+// loadSnapshot reads a snapshot written by 'export -format json' and returns
+// its workspaces by name; channels of an export made without -config are
+// under "".
+func loadSnapshot(path string) (map[string]*simWorkspace, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read snapshot: %w", err)
+	}
+	var rows []exportRow
+	if err := json.Unmarshal(b, &rows); err != nil {
+		return nil, fmt.Errorf("parse snapshot %s (want the output of 'export -format json'): %w", path, err)
+	}
+	workspaces := make(map[string]*simWorkspace)
+	for i, r := range rows {
+		if r.Name == "" {
+			return nil, fmt.Errorf("snapshot %s: channel %d has no name", path, i+1)
+		}
+		w := workspaces[r.Workspace]
+		if w == nil {
+			w = &simWorkspace{members: make(map[string][]string)}
+			workspaces[r.Workspace] = w
+		}
+		ch := &slack.Channel{}
+		ch.ID, ch.Name, ch.IsArchived, ch.IsPrivate = cmp.Or(r.ID, fmt.Sprintf("C%06d", i+1)), r.Name, r.Archived, r.Private
+		ch.Creator, ch.NumMembers, ch.Topic.Value, ch.IsMember = r.Creator, r.Members, r.Topic, true
+		ch.Purpose.Value, ch.IsExtShared, ch.IsOrgShared = r.Purpose, r.ExtShared, r.OrgShared
+		ch.Created = slack.JSONTime(r.Created.Unix())
+		w.channels = append(w.channels, ch)
+	}
+	return workspaces, nil
+}
+
+// simulatedSessions returns a session per workspace of the snapshot at path,
+// in name order, or only the one named workspace when it is set.
+func simulatedSessions(path, workspace string, channelOpts channelOptions) ([]*session, error) {
+	workspaces, err := loadSnapshot(path)
+	if err != nil {
+		return nil, err
+	}
+	if workspace != "" && workspaces[workspace] == nil {
+		return nil, fmt.Errorf("workspace %q is not in the snapshot %s", workspace, path)
+	}
+	var sessions []*session
+	for _, name := range slices.Sorted(maps.Keys(workspaces)) {
+		if workspace != "" && name != workspace {
+			continue
+		}
+		s := newSession(name, simulateToken, channelOpts)
+		s.client, s.simulated = workspaces[name], true
+		sessions = append(sessions, s)
+	}
+	return sessions, nil
+}
+
+// channel returns the channel with ID id. The caller holds w.mu.
+func (w *simWorkspace) channel(id string) (*slack.Channel, error) {
+	for _, ch := range w.channels {
+		if ch.ID == id {
+			return ch, nil
+		}
+	}
+	return nil, slack.SlackErrorResponse{Err: "channel_not_found"}
+}
+
+// active returns the channel with ID id if it can be changed.
+func (w *simWorkspace) active(id string) (*slack.Channel, error) {
+	ch, err := w.channel(id)
+	if err == nil && ch.IsArchived {
+		return nil, slack.SlackErrorResponse{Err: "is_archived"}
+	}
+	return ch, err
+}
+
+func (w *simWorkspace) AuthTestContext(ctx context.Context) (*slack.AuthTestResponse, error) {
+	return &slack.AuthTestResponse{User: "simulation", UserID: "USIMULATE"}, nil
+}
+
+func (w *simWorkspace) GetConversationsContext(ctx context.Context, params *slack.GetConversationsParameters) ([]slack.Channel, string, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	private := slices.Contains(params.Types, "private_channel")
+	var out []slack.Channel
+	for _, ch := range w.channels {
+		if (ch.IsPrivate && !private) || (ch.IsArchived && params.ExcludeArchived) {
+			continue
+		}
+		out = append(out, *ch)
+	}
+	return out, "", nil
+}
+
+func (w *simWorkspace) GetConversationInfoContext(ctx context.Context, input *slack.GetConversationInfoInput) (*slack.Channel, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	ch, err := w.channel(input.ChannelID)
+	if err != nil {
+		return nil, err
+	}
+	c := *ch
+	return &c, nil
+}
+
+func (w *simWorkspace) GetUsersInConversationContext(ctx context.Context, params *slack.GetUsersInConversationParameters) ([]string, string, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	ch, err := w.channel(params.ChannelID)
+	if err != nil {
+		return nil, "", err
+	}
+	return slices.Clone(w.memberIDs(ch)), "", nil
+}
+
+// memberIDs returns the members of ch, making them up on first use. The
+// caller holds w.mu.
+func (w *simWorkspace) memberIDs(ch *slack.Channel) []string {
+	if m, ok := w.members[ch.ID]; ok {
+		return m
+	}
+	m := make([]string, ch.NumMembers)
+	for i := range m {
+		m[i] = fmt.Sprintf("U%s%04d", ch.ID, i+1)
+	}
+	w.members[ch.ID] = m
+	return m
+}
+
+func (w *simWorkspace) RenameConversationContext(ctx context.Context, id, name string) (*slack.Channel, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	ch, err := w.active(id)
+	if err != nil {
+		return nil, err
+	}
+	if !plan.ChannelNameRe.MatchString(name) {
+		return nil, slack.SlackErrorResponse{Err: "invalid_name_specials"}
+	}
+	for _, other := range w.channels {
+		if other.Name == name && other.ID != id && !other.IsArchived {
+			return nil, slack.SlackErrorResponse{Err: "name_taken"}
+		}
+	}
+	ch.Name = name
+	c := *ch
+	return &c, nil
+}
+
+func (w *simWorkspace) ArchiveConversationContext(ctx context.Context, id string) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	ch, err := w.channel(id)
+	switch {
+	case err != nil:
+		return err
+	case ch.IsArchived:
+		return slack.SlackErrorResponse{Err: "already_archived"}
+	case ch.IsGeneral:
+		return slack.SlackErrorResponse{Err: "cant_archive_general"}
+	}
+	ch.IsArchived = true
+	return nil
+}
+
+func (w *simWorkspace) UnArchiveConversationContext(ctx context.Context, id string) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	ch, err := w.channel(id)
+	switch {
+	case err != nil:
+		return err
+	case !ch.IsArchived:
+		return slack.SlackErrorResponse{Err: "not_archived"}
+	}
+	for _, other := range w.channels {
+		if other.Name == ch.Name && other.ID != id && !other.IsArchived {
+			return slack.SlackErrorResponse{Err: "name_taken"}
+		}
+	}
+	ch.IsArchived = false
+	return nil
+}
+
+func (w *simWorkspace) SetTopicOfConversationContext(ctx context.Context, id, topic string) (*slack.Channel, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	ch, err := w.active(id)
+	if err != nil {
+		return nil, err
+	}
+	ch.Topic.Value = topic
+	c := *ch
+	return &c, nil
+}
+
+func (w *simWorkspace) SetPurposeOfConversationContext(ctx context.Context, id, purpose string) (*slack.Channel, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	ch, err := w.active(id)
+	if err != nil {
+		return nil, err
+	}
+	ch.Purpose.Value = purpose
+	c := *ch
+	return &c, nil
+}
+
+func (w *simWorkspace) JoinConversationContext(ctx context.Context, id string) (*slack.Channel, string, []string, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	ch, err := w.active(id)
+	if err != nil {
+		return nil, "", nil, err
+	}
+	ch.IsMember = true
+	c := *ch
+	return &c, "", nil, nil
+}
+
+func (w *simWorkspace) InviteUsersToConversationContext(ctx context.Context, id string, users ...string) (*slack.Channel, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	ch, err := w.active(id)
+	if err != nil {
+		return nil, err
+	}
+	members := w.memberIDs(ch)
+	for _, u := range users {
+		if !slices.Contains(members, u) {
+			members = append(members, u)
+		}
+	}
+	w.members[ch.ID], ch.NumMembers = members, len(members)
+	c := *ch
+	return &c, nil
+}
+
+func (w *simWorkspace) OpenConversationContext(ctx context.Context, params *slack.OpenConversationParameters) (*slack.Channel, bool, bool, error) {
+	ch := &slack.Channel{}
+	ch.ID = "D" + strings.Join(params.Users, "")
+	return ch, false, false, nil
+}
+
+// The admin calls work on the same channels, as admin mode does on a real
+// workspace.
+
+// AdminConversationsSearch returns every channel: slack-go keeps the query to
+// itself, and callers filter the results as they must for Slack's loose
+// matching anyway.
+func (w *simWorkspace) AdminConversationsSearch(ctx context.Context, options ...slack.AdminConversationsSearchOption) (*slack.AdminConversationsSearchResponse, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	resp := &slack.AdminConversationsSearchResponse{}
+	for _, ch := range w.channels {
+		resp.Conversations = append(resp.Conversations, slack.AdminConversation{
+			ID: ch.ID, Name: ch.Name, IsArchived: ch.IsArchived, IsPrivate: ch.IsPrivate,
+			CreatorID: ch.Creator, Created: int64(ch.Created), MemberCount: ch.NumMembers,
+			IsExtShared: ch.IsExtShared, IsOrgShared: ch.IsOrgShared, ContextTeamID: ch.ContextTeamID,
+		})
+	}
+	return resp, nil
+}
+
+func (w *simWorkspace) AdminConversationsRename(ctx context.Context, id, name string) error {
+	_, err := w.RenameConversationContext(ctx, id, name)
+	return err
+}
+
+func (w *simWorkspace) AdminConversationsArchive(ctx context.Context, id string) error {
+	return w.ArchiveConversationContext(ctx, id)
+}
+
+func (w *simWorkspace) AdminConversationsUnarchive(ctx context.Context, id string) error {
+	return w.UnArchiveConversationContext(ctx, id)
+}
+
+// timestamp returns a new message timestamp.
+func (w *simWorkspace) timestamp() string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.ts++
+	return strconv.FormatInt(time.Now().Unix(), 10) + fmt.Sprintf(".%06d", w.ts)
+}
+
+func (w *simWorkspace) PostMessageContext(ctx context.Context, channelID string, options ...slack.MsgOption) (string, string, error) {
+	return channelID, w.timestamp(), nil
+}
+
+func (w *simWorkspace) PostEphemeralContext(ctx context.Context, channelID, userID string, options ...slack.MsgOption) (string, error) {
+	return w.timestamp(), nil
+}
+
+func (w *simWorkspace) UpdateMessageContext(ctx context.Context, channelID, timestamp string, options ...slack.MsgOption) (string, string, string, error) {
+	return channelID, timestamp, "", nil
+}
+
+func (w *simWorkspace) GetReactionsContext(ctx context.Context, item slack.ItemRef, params slack.GetReactionsParameters) (slack.ReactedItem, error) {
+	return slack.ReactedItem{}, errNotSimulated
+}
+
+func (w *simWorkspace) GetConversationHistoryContext(ctx context.Context, params *slack.GetConversationHistoryParameters) (*slack.GetConversationHistoryResponse, error) {
+	return nil, errNotSimulated
+}
+
+func (w *simWorkspace) AddPinContext(ctx context.Context, channel string, item slack.ItemRef) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.pins == nil {
+		w.pins = make(map[string][]string)
+	}
+	w.pins[channel] = append(w.pins[channel], item.Timestamp)
+	return nil
+}
+
+func (w *simWorkspace) RemovePinContext(ctx context.Context, channel string, item slack.ItemRef) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	i := slices.Index(w.pins[channel], item.Timestamp)
+	if i < 0 {
+		return slack.SlackErrorResponse{Err: "no_pin"}
+	}
+	w.pins[channel] = slices.Delete(w.pins[channel], i, i+1)
+	return nil
+}
+
+func (w *simWorkspace) ListBookmarksContext(ctx context.Context, channelID string) ([]slack.Bookmark, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return slices.Clone(w.bookmarks[channelID]), nil
+}
+
+func (w *simWorkspace) EditBookmarkContext(ctx context.Context, channelID, bookmarkID string, params slack.EditBookmarkParameters) (slack.Bookmark, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for i, b := range w.bookmarks[channelID] {
+		if b.ID != bookmarkID {
+			continue
+		}
+		if params.Title != nil {
+			b.Title = *params.Title
+		}
+		b.Link = cmp.Or(params.Link, b.Link)
+		w.bookmarks[channelID][i] = b
+		return b, nil
+	}
+	return slack.Bookmark{}, slack.SlackErrorResponse{Err: "not_found"}
+}
+
+// ListPinsContext is not simulated, as the simulation keeps no message text.
+func (w *simWorkspace) ListPinsContext(ctx context.Context, channel string) ([]slack.Item, *slack.Paging, error) {
+	return nil, nil, errNotSimulated
+}
+
+func (w *simWorkspace) GetFileInfoContext(ctx context.Context, fileID string, count, page int) (*slack.File, []slack.Comment, *slack.Paging, error) {
+	return nil, nil, nil, errNotSimulated
+}
+
+func (w *simWorkspace) GetFilesContext(ctx context.Context, params slack.GetFilesParameters) ([]slack.File, *slack.Paging, error) {
+	return nil, nil, errNotSimulated
+}
+
+func (w *simWorkspace) GetFileContext(ctx context.Context, downloadURL string, writer io.Writer) error {
+	return errNotSimulated
+}
+
+func (w *simWorkspace) CreateConversationContext(ctx context.Context, params slack.CreateConversationParams) (*slack.Channel, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if !plan.ChannelNameRe.MatchString(params.ChannelName) {
+		return nil, slack.SlackErrorResponse{Err: "invalid_name_specials"}
+	}
+	for _, other := range w.channels {
+		if other.Name == params.ChannelName {
+			return nil, slack.SlackErrorResponse{Err: "name_taken"}
+		}
+	}
+	ch := &slack.Channel{}
+	ch.ID, ch.Name, ch.IsPrivate, ch.IsMember = fmt.Sprintf("CSIM%06d", len(w.channels)+1), params.ChannelName, params.IsPrivate, true
+	ch.Created, ch.NumMembers = slack.JSONTime(time.Now().Unix()), 1
+	w.channels = append(w.channels, ch)
+	c := *ch
+	return &c, nil
+}
+
+// GetUserByEmailContext makes up a user for every address, since a snapshot
+// has no users.
+func (w *simWorkspace) GetUserByEmailContext(ctx context.Context, email string) (*slack.User, error) {
+	return &slack.User{ID: fmt.Sprintf("USIM%08X", crc32.ChecksumIEEE([]byte(email))), Profile: slack.UserProfile{Email: email}}, nil
+}
@@ -0,0 +1,158 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSimulate(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "snapshot.json")
+	snapshot := `[{"name":"alpha","id":"C1","members":3},{"name":"beta","id":"C2"},{"name":"old","id":"C3","archived":true},{"name":"gamma","id":"C4"}]`
+	if err := os.WriteFile(path, []byte(snapshot), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	sessions, err := (workspaceOptions{simulate: path}).newSessions(channelOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	plan := []planEntry{
+		{Action: actionRename, Asis: "alpha", Tobe: "beta"},
+		{Action: actionRename, Asis: "beta", Tobe: "alpha"},
+		{Action: actionRename, Asis: "gamma", Tobe: "old"},
+		{Action: actionArchive, Asis: "alpha"},
+	}
+	runs, errs, _, err := validateRuns(sessions, workspaceOptions{}, plan, prepareOptions{})
+	if err != nil || len(errs) > 0 {
+		t.Fatalf("validateRuns: %v %q", err, errs)
+	}
+	res, err := executeRuns(runs, runOptions{verb: "rename", verifyPass: true, pool: poolOptions{concurrency: 1, perMinute: 60}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.failures != 0 || len(res.changed) != 5 {
+		t.Errorf("failures = %d, changed = %d; want 0 and 5", res.failures, len(res.changed))
+	}
+
+	w := sessions[0].client.(*simWorkspace)
+	want := map[string]string{"C1": "beta", "C2": "alpha", "C3": "old", "C4": "old"}
+	for _, ch := range w.channels {
+		if ch.Name != want[ch.ID] {
+			t.Errorf("%s is named %s, want %s", ch.ID, ch.Name, want[ch.ID])
+		}
+	}
+	// Plan rows name channels as they were before the run.
+	if ch, _ := w.channel("C1"); !ch.IsArchived {
+		t.Error("the channel that was alpha was not archived")
+	}
+}
+
+func TestSimulateIncludeArchived(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "snapshot.json")
+	snapshot := `[{"name":"alpha","id":"C1"},{"name":"old","id":"C2","archived":true}]`
+	if err := os.WriteFile(path, []byte(snapshot), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	sessions, err := (workspaceOptions{simulate: path}).newSessions(channelOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	plan := []planEntry{{Action: actionRename, Asis: "old", Tobe: "old-2023"}}
+	_, _, skipped, err := validateRuns(sessions, workspaceOptions{}, plan, prepareOptions{})
+	if err != nil || len(skipped) != 1 {
+		t.Fatalf("without -include-archived: %v, skipped %q", err, skipped)
+	}
+
+	plan = []planEntry{{Action: actionRename, Asis: "old", Tobe: "old-2023"}}
+	runs, errs, skipped, err := validateRuns(sessions, workspaceOptions{}, plan, prepareOptions{includeArchived: true})
+	if err != nil || len(errs) > 0 || len(skipped) > 0 {
+		t.Fatalf("validateRuns: %v %q %q", err, errs, skipped)
+	}
+	if e := runs[0].plan[0]; !e.Rearchive {
+		t.Fatalf("entry %s is not marked for re-archiving", e)
+	}
+	res, err := executeRuns(runs, runOptions{verb: "rename", verifyPass: true, pool: poolOptions{concurrency: 1, perMinute: 60}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.failures != 0 || !res.results[0].Rearchived {
+		t.Errorf("failures = %d, results = %+v", res.failures, res.results)
+	}
+	ch, _ := sessions[0].client.(*simWorkspace).channel("C2")
+	if ch.Name != "old-2023" || !ch.IsArchived {
+		t.Errorf("C2 is %q, archived %v; want old-2023, archived", ch.Name, ch.IsArchived)
+	}
+}
+
+func TestSimulateSharedChannels(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "snapshot.json")
+	snapshot := `[{"name":"partner","id":"C1","ext_shared":true},{"name":"grid","id":"C2","org_shared":true},{"name":"local","id":"C3"}]`
+	if err := os.WriteFile(path, []byte(snapshot), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	sessions, err := (workspaceOptions{simulate: path}).newSessions(channelOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	plan := func() []planEntry {
+		return []planEntry{
+			{Action: actionRename, Asis: "partner", Tobe: "ext-partner"},
+			{Action: actionArchive, Asis: "grid"},
+			{Action: actionMerge, Asis: "local", Tobe: "partner"},
+		}
+	}
+	runs, errs, skipped, err := validateRuns(sessions, workspaceOptions{}, plan(), prepareOptions{})
+	if err != nil || len(errs) > 0 {
+		t.Fatalf("validateRuns: %v %q", err, errs)
+	}
+	if len(skipped) != 3 || len(runs[0].plan) != 0 {
+		t.Errorf("without -include-shared: skipped %q, kept %v", skipped, runs[0].plan)
+	}
+
+	runs, errs, skipped, err = validateRuns(sessions, workspaceOptions{}, plan(), prepareOptions{includeShared: true})
+	if err != nil || len(errs) > 0 || len(skipped) > 0 {
+		t.Fatalf("validateRuns: %v %q %q", err, errs, skipped)
+	}
+	for _, e := range runs[0].plan {
+		if e.Shared == "" {
+			t.Errorf("entry %s is not marked as shared", e)
+		}
+	}
+}
+
+func TestSimulatePinOldName(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "snapshot.json")
+	if err := os.WriteFile(path, []byte(`[{"name":"alpha","id":"C1"}]`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	sessions, err := (workspaceOptions{simulate: path}).newSessions(channelOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	w := sessions[0].client.(*simWorkspace)
+	pool := poolOptions{concurrency: 1, perMinute: 60}
+
+	runs, errs, _, err := validateRuns(sessions, workspaceOptions{}, []planEntry{{Action: actionRename, Asis: "alpha", Tobe: "beta"}}, prepareOptions{})
+	if err != nil || len(errs) > 0 {
+		t.Fatalf("validateRuns: %v %q", err, errs)
+	}
+	res, err := executeRuns(runs, runOptions{verb: "rename", announce: announceOptions{pinOldName: true}, pool: pool})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(w.pins["C1"]) != 1 || len(res.changed) != 1 || res.changed[0].PinnedNote != w.pins["C1"][0] {
+		t.Fatalf("pins = %v, changed = %+v; want the pinned note recorded on the rename", w.pins, res.changed)
+	}
+
+	reverse := reverseEntries(res.changed)
+	runs, errs, _, err = validateRuns(sessions, workspaceOptions{}, reverse, prepareOptions{})
+	if err != nil || len(errs) > 0 {
+		t.Fatalf("validateRuns of the rollback: %v %q", err, errs)
+	}
+	if _, err := executeRuns(runs, runOptions{verb: "rollback", pool: pool}); err != nil {
+		t.Fatal(err)
+	}
+	if len(w.pins["C1"]) != 0 {
+		t.Errorf("rollback left the pins %v", w.pins["C1"])
+	}
+}
@@ -0,0 +1,37 @@
+package main
+
+import (
+	"github.com/kiddikn/slack-channel-renamer/plan"
+	"github.com/kiddikn/slack-channel-renamer/runner"
+	"github.com/kiddikn/slack-channel-renamer/slackops"
+)
+
+// channelInfo is a channel as listed or looked up by slackops, by the name
+// the CLI's code has always used for it.
+type channelInfo = slackops.Channel
+
+// planEntry is one entry of a plan, as loaded and validated by the plan
+// package.
+type planEntry = plan.Entry
+
+// Plan actions, as the plan package names them.
+const (
+	actionRename    = plan.ActionRename
+	actionArchive   = plan.ActionArchive
+	actionUnarchive = plan.ActionUnarchive
+	actionSetTopic  = plan.ActionSetTopic
+	actionMerge     = plan.ActionMerge
+	actionCreate    = plan.ActionCreate
+	actionInvite    = plan.ActionInvite
+)
+
+// entryResult is the outcome of one plan entry, as the runner reports it.
+type entryResult = runner.Result
+
+// Statuses of an entryResult, as the runner names them.
+const (
+	resultOK      = runner.StatusOK
+	resultFailed  = runner.StatusFailed
+	resultSkipped = runner.StatusSkipped
+	resultPending = runner.StatusPending
+)
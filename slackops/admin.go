@@ -0,0 +1,106 @@
+package slackops
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/slack-go/slack"
+)
+
+// adminSearchLimit is the page size for admin.conversations.search.
+const adminSearchLimit = 20
+
+// SearchAdmin pages through admin.conversations.search for query and
+// returns every conversation in the org it matches, or only in the workspace
+// teamID when it is set. An empty query matches all of them.
+func SearchAdmin(ctx context.Context, client API, stats *Stats, query, teamID string) ([]slack.AdminConversation, error) {
+	var all []slack.AdminConversation
+	cursor := ""
+	for {
+		var resp *slack.AdminConversationsSearchResponse
+		err := Call(ctx, stats, fmt.Sprintf("searching for %q", query), func(ctx context.Context) error {
+			opts := []slack.AdminConversationsSearchOption{
+				slack.AdminConversationsSearchOptionQuery(query),
+				slack.AdminConversationsSearchOptionLimit(adminSearchLimit),
+				slack.AdminConversationsSearchOptionCursor(cursor),
+			}
+			if teamID != "" {
+				opts = append(opts, slack.AdminConversationsSearchOptionTeamIDs([]string{teamID}))
+			}
+			var err error
+			resp, err = client.AdminConversationsSearch(ctx, opts...)
+			return err
+		})
+		if err != nil {
+			return nil, fmt.Errorf("admin.conversations.search %q: %w", query, err)
+		}
+		all = append(all, resp.Conversations...)
+		if resp.NextCursor == "" {
+			return all, nil
+		}
+		cursor = resp.NextCursor
+	}
+}
+
+// FindAdmin returns the conversations in the org, or in the workspace
+// teamID, named exactly name. admin.conversations.search matches loosely, so
+// results are filtered.
+func FindAdmin(ctx context.Context, client API, stats *Stats, name, teamID string) ([]slack.AdminConversation, error) {
+	results, err := SearchAdmin(ctx, client, stats, name, teamID)
+	if err != nil {
+		return nil, err
+	}
+	var matches []slack.AdminConversation
+	for _, c := range results {
+		if c.Name == name {
+			matches = append(matches, c)
+		}
+	}
+	return matches, nil
+}
+
+// AdminChannel converts a conversation found by admin.conversations.search.
+func AdminChannel(c slack.AdminConversation) Channel {
+	return Channel{
+		ID:          c.ID,
+		IsArchived:  c.IsArchived,
+		IsPrivate:   c.IsPrivate,
+		IsGeneral:   c.IsGeneral,
+		IsExtShared: c.IsExtShared,
+		IsOrgShared: c.IsOrgShared,
+		TeamID:      AdminTeam(c),
+		Creator:     c.CreatorID,
+		Created:     time.Unix(c.Created, 0).UTC(),
+		NumMembers:  c.MemberCount,
+		Purpose:     c.Purpose,
+	}
+}
+
+// AdminTeam returns the workspace a conversation of the org belongs to: the
+// one it was created in, or the first it is shared with.
+func AdminTeam(c slack.AdminConversation) string {
+	if c.ContextTeamID != "" || len(c.InternalTeamIDs) == 0 {
+		return c.ContextTeamID
+	}
+	return c.InternalTeamIDs[0]
+}
+
+// ListAdminChannels lists every conversation in the org, or in the
+// workspace teamID, keyed by name. A name used in several workspaces is
+// logged, since only one of its channels is kept.
+func ListAdminChannels(ctx context.Context, client API, stats *Stats, teamID string) (map[string]Channel, error) {
+	results, err := SearchAdmin(ctx, client, stats, "", teamID)
+	if err != nil {
+		return nil, err
+	}
+	channels := make(map[string]Channel, len(results))
+	for _, c := range results {
+		if other, ok := channels[c.Name]; ok && other.TeamID != AdminTeam(c) {
+			slog.Warn("channel name is used in more than one workspace of the org; pass -team-id to keep to one", "channel", c.Name, "teams", other.TeamID+","+AdminTeam(c))
+		}
+		channels[c.Name] = AdminChannel(c)
+	}
+	return channels, nil
+}
@@ -0,0 +1,53 @@
+// Package slackops makes the Slack calls of the renamer: listing and looking
+// up channels and renaming them, each through Call's retries.
+package slackops
+
+import (
+	"context"
+	"io"
+
+	"github.com/slack-go/slack"
+)
+
+// API is the part of *slack.Client the tool calls. Runners and sessions
+// hold one, so tests can substitute a fake workspace.
+type API interface {
+	AuthTestContext(ctx context.Context) (*slack.AuthTestResponse, error)
+
+	GetConversationsContext(ctx context.Context, params *slack.GetConversationsParameters) ([]slack.Channel, string, error)
+	GetConversationInfoContext(ctx context.Context, input *slack.GetConversationInfoInput) (*slack.Channel, error)
+	GetConversationHistoryContext(ctx context.Context, params *slack.GetConversationHistoryParameters) (*slack.GetConversationHistoryResponse, error)
+	GetUsersInConversationContext(ctx context.Context, params *slack.GetUsersInConversationParameters) ([]string, string, error)
+	RenameConversationContext(ctx context.Context, channelID, channelName string) (*slack.Channel, error)
+	ArchiveConversationContext(ctx context.Context, channelID string) error
+	UnArchiveConversationContext(ctx context.Context, channelID string) error
+	SetTopicOfConversationContext(ctx context.Context, channelID, topic string) (*slack.Channel, error)
+	SetPurposeOfConversationContext(ctx context.Context, channelID, purpose string) (*slack.Channel, error)
+	JoinConversationContext(ctx context.Context, channelID string) (*slack.Channel, string, []string, error)
+	InviteUsersToConversationContext(ctx context.Context, channelID string, users ...string) (*slack.Channel, error)
+	OpenConversationContext(ctx context.Context, params *slack.OpenConversationParameters) (*slack.Channel, bool, bool, error)
+	CreateConversationContext(ctx context.Context, params slack.CreateConversationParams) (*slack.Channel, error)
+	GetUserByEmailContext(ctx context.Context, email string) (*slack.User, error)
+
+	AdminConversationsSearch(ctx context.Context, options ...slack.AdminConversationsSearchOption) (*slack.AdminConversationsSearchResponse, error)
+	AdminConversationsRename(ctx context.Context, channelID, name string) error
+	AdminConversationsArchive(ctx context.Context, channelID string) error
+	AdminConversationsUnarchive(ctx context.Context, channelID string) error
+
+	PostMessageContext(ctx context.Context, channelID string, options ...slack.MsgOption) (string, string, error)
+	PostEphemeralContext(ctx context.Context, channelID, userID string, options ...slack.MsgOption) (string, error)
+	UpdateMessageContext(ctx context.Context, channelID, timestamp string, options ...slack.MsgOption) (string, string, string, error)
+	GetReactionsContext(ctx context.Context, item slack.ItemRef, params slack.GetReactionsParameters) (slack.ReactedItem, error)
+	AddPinContext(ctx context.Context, channel string, item slack.ItemRef) error
+	RemovePinContext(ctx context.Context, channel string, item slack.ItemRef) error
+	ListPinsContext(ctx context.Context, channel string) ([]slack.Item, *slack.Paging, error)
+
+	ListBookmarksContext(ctx context.Context, channelID string) ([]slack.Bookmark, error)
+	EditBookmarkContext(ctx context.Context, channelID, bookmarkID string, params slack.EditBookmarkParameters) (slack.Bookmark, error)
+
+	GetFileInfoContext(ctx context.Context, fileID string, count, page int) (*slack.File, []slack.Comment, *slack.Paging, error)
+	GetFilesContext(ctx context.Context, params slack.GetFilesParameters) ([]slack.File, *slack.Paging, error)
+	GetFileContext(ctx context.Context, downloadURL string, writer io.Writer) error
+}
+
+var _ API = (*slack.Client)(nil)
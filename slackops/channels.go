@@ -0,0 +1,196 @@
+package slackops
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/slack-go/slack"
+)
+
+// Channel is a listed or looked-up channel, by name in ListChannels' map.
+type Channel struct {
+	ID         string
+	IsArchived bool
+	IsPrivate  bool
+	IsGeneral  bool
+	// IsMember is whether the token's user is in the channel; a bot token
+	// must join a channel before changing it.
+	IsMember bool
+	// IsExtShared is whether the channel is shared with other organizations
+	// through Slack Connect, and IsOrgShared whether it is shared with other
+	// workspaces of an Enterprise Grid org.
+	IsExtShared bool `json:",omitempty"`
+	IsOrgShared bool `json:",omitempty"`
+	// TeamID is the workspace the channel belongs to, which on Enterprise
+	// Grid need not be the token's own.
+	TeamID string `json:",omitempty"`
+
+	// ArchivedTwin is the ID of an archived channel with the same name as
+	// this active one, which 'unarchive' can bring back under another name.
+	ArchivedTwin string `json:",omitempty"`
+
+	// Inventory details for export, filled in by the channel listings.
+	Creator    string
+	Created    time.Time
+	NumMembers int
+	Topic      string
+	Purpose    string `json:",omitempty"`
+}
+
+// Stats accumulates per-run counters for the summary and the metrics file.
+type Stats struct {
+	Start            time.Time
+	Attempted        int
+	Succeeded        int
+	Failed           int
+	Skipped          int
+	RateLimitRetries atomic.Int64 // updated by concurrent workers
+	TransientRetries atomic.Int64
+}
+
+// InTeam reports whether a listed channel belongs to the workspace teamID,
+// or is shared with it; every channel does when teamID is empty. A channel
+// whose listing names no workspace is kept, as outside Enterprise Grid.
+func InTeam(ch slack.Channel, teamID string) bool {
+	if teamID == "" || ch.ContextTeamID == "" || ch.ContextTeamID == teamID {
+		return true
+	}
+	return slices.Contains(ch.SharedTeamIDs, teamID) || slices.Contains(ch.InternalTeamIDs, teamID)
+}
+
+// Sharing describes how the channel is shared beyond its workspace, or
+// returns "" for a channel that is not.
+func (ch Channel) Sharing() string {
+	switch {
+	case ch.IsExtShared:
+		return "shared with external organizations through Slack Connect"
+	case ch.IsOrgShared:
+		return "shared with other workspaces of the org"
+	}
+	return ""
+}
+
+// ListOptions selects the channels ListChannels lists.
+type ListOptions struct {
+	// IncludePrivate adds the private channels visible to the token.
+	IncludePrivate bool
+	// TeamID, on Enterprise Grid, keeps to the channels of one workspace.
+	TeamID string
+}
+
+// ListChannels retrieves all public channels (including archived), plus the
+// private channels visible to the token with opts.IncludePrivate, and returns
+// a map of channel name to Channel.
+func ListChannels(ctx context.Context, client API, stats *Stats, opts ListOptions) (map[string]Channel, error) {
+	channels := make(map[string]Channel)
+	cursor := ""
+	types := []string{"public_channel"}
+	if opts.IncludePrivate {
+		types = append(types, "private_channel")
+	}
+
+	for {
+		var result []slack.Channel
+		var nextCursor string
+		err := Call(ctx, stats, "fetching channels", func(ctx context.Context) error {
+			var err error
+			result, nextCursor, err = client.GetConversationsContext(ctx, &slack.GetConversationsParameters{
+				Cursor:          cursor,
+				ExcludeArchived: false,
+				Types:           types,
+				Limit:           200,
+				TeamID:          opts.TeamID,
+			})
+			return err
+		})
+		if err != nil {
+			return nil, fmt.Errorf("GetConversationsContext: %w", err)
+		}
+
+		for _, ch := range result {
+			if !InTeam(ch, opts.TeamID) {
+				continue
+			}
+			info := Channel{
+				ID:          ch.ID,
+				IsArchived:  ch.IsArchived,
+				IsPrivate:   ch.IsPrivate,
+				IsGeneral:   ch.IsGeneral,
+				IsMember:    ch.IsMember,
+				IsExtShared: ch.IsExtShared,
+				IsOrgShared: ch.IsOrgShared,
+				TeamID:      ch.ContextTeamID,
+				Creator:     ch.Creator,
+				Created:     ch.Created.Time().UTC(),
+				NumMembers:  ch.NumMembers,
+				Topic:       ch.Topic.Value,
+				Purpose:     ch.Purpose.Value,
+			}
+			// A name can be held by an active channel and an archived one.
+			// The active channel keeps it, since that is the one every
+			// action but unarchive means.
+			if other, ok := channels[ch.Name]; ok && other.IsArchived == info.IsArchived && other.TeamID != info.TeamID {
+				slog.Warn("channel name is used in more than one workspace of the org; pass -team-id to keep to one", "channel", ch.Name, "teams", other.TeamID+","+info.TeamID)
+			}
+			if other, ok := channels[ch.Name]; ok && other.IsArchived != info.IsArchived {
+				if info.IsArchived {
+					info, other = other, info
+				}
+				info.ArchivedTwin = other.ID
+			}
+			channels[ch.Name] = info
+		}
+
+		if nextCursor == "" {
+			break
+		}
+		cursor = nextCursor
+	}
+
+	return channels, nil
+}
+
+// Rename renames a channel with retry on rate-limit errors.
+func Rename(ctx context.Context, client API, stats *Stats, ch Channel, asis, tobe string) error {
+	return Call(ctx, stats, fmt.Sprintf("renaming %s -> %s", asis, tobe), func(ctx context.Context) error {
+		_, err := client.RenameConversationContext(ctx, ch.ID, tobe)
+		return err
+	}, "action", "rename", "channel_id", ch.ID, "asis", asis, "tobe", tobe)
+}
+
+// ChannelName re-reads the channel with conversations.info and returns its live name.
+func ChannelName(ctx context.Context, client API, stats *Stats, ch Channel, desc string) (string, error) {
+	var name string
+	err := Call(ctx, stats, desc, func(ctx context.Context) error {
+		info, err := client.GetConversationInfoContext(ctx, &slack.GetConversationInfoInput{ChannelID: ch.ID})
+		if err != nil {
+			return err
+		}
+		name = info.Name
+		return nil
+	}, "channel_id", ch.ID)
+	return name, err
+}
+
+// VerifyRename re-reads the channel and confirms its live name equals tobe.
+func VerifyRename(ctx context.Context, client API, stats *Stats, ch Channel, tobe string) error {
+	name, err := ChannelName(ctx, client, stats, ch, fmt.Sprintf("verifying %s", tobe))
+	if err != nil {
+		return fmt.Errorf("verify: %w", err)
+	}
+	if name != tobe {
+		return fmt.Errorf("verify: channel is named %q, expected %q", name, tobe)
+	}
+	return nil
+}
+
+// Unescape undoes the escaping of &, < and > that Slack applies to the
+// topics and purposes it returns.
+func Unescape(s string) string {
+	return strings.NewReplacer("&lt;", "<", "&gt;", ">", "&amp;", "&").Replace(s)
+}
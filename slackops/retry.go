@@ -0,0 +1,168 @@
+package slackops
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"math/rand/v2"
+	"net"
+	"slices"
+	"syscall"
+	"time"
+
+	"github.com/slack-go/slack"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// RetryPolicy is how long a Slack call may take and how it is retried. The
+// fetch settings, when set, override timeout and attempts for listing and
+// looking up channels, which can need more patience than the changes.
+type RetryPolicy struct {
+	Timeout  time.Duration
+	Attempts int
+	// rateLimitWait is the wait after a rate-limit error without a
+	// Retry-After.
+	RateLimitWait time.Duration
+	// Backoff for transient errors: the wait doubles from initialWait up to
+	// maxWait, with jitter, and no retry starts after maxElapsed.
+	InitialWait time.Duration
+	MaxWait     time.Duration
+	MaxElapsed  time.Duration
+
+	FetchTimeout  time.Duration
+	FetchAttempts int
+}
+
+// Retry is the policy of every Slack call. The CLI sets it from the flags
+// of every command.
+var Retry = RetryPolicy{
+	Timeout:       15 * time.Second,
+	Attempts:      3,
+	RateLimitWait: 5 * time.Second,
+	InitialWait:   time.Second,
+	MaxWait:       30 * time.Second,
+	MaxElapsed:    2 * time.Minute,
+}
+
+// An Observer is told about every attempt Call makes at a call and
+// every retry it waits for.
+type Observer interface {
+	Attempted(ctx context.Context, desc string, attempt int, took time.Duration, err error, attrs []any)
+	Retrying(ctx context.Context, rateLimited bool, wait time.Duration)
+}
+
+// observers are the Observers added with Observe.
+var observers []Observer
+
+// Observe adds o to the observers of every call. It is meant to be called
+// while the program starts, before any call is made.
+func Observe(o Observer) {
+	observers = append(observers, o)
+}
+
+type fetchingKey struct{}
+
+// Fetching marks ctx as listing or looking up channels, for the fetch
+// settings of the policy.
+func Fetching(ctx context.Context) context.Context {
+	return context.WithValue(ctx, fetchingKey{}, true)
+}
+
+// limits returns the timeout and attempts of a call made with ctx.
+func (p RetryPolicy) limits(ctx context.Context) (time.Duration, int) {
+	timeout, attempts := p.Timeout, p.Attempts
+	if ctx.Value(fetchingKey{}) != nil {
+		timeout, attempts = cmp.Or(p.FetchTimeout, timeout), cmp.Or(p.FetchAttempts, attempts)
+	}
+	return timeout, attempts
+}
+
+// Call calls fn with a per-attempt timeout, making up to the policy's
+// attempts. Rate-limit errors wait as long as Slack asks; transient errors
+// (timeouts, connection resets, 5xx responses) back off exponentially with
+// jitter. Other errors are returned at once. desc describes the operation in
+// log messages, and attrs are slog key-value pairs logged along with it.
+//
+// Each attempt's context carries the values of ctx but not its cancellation,
+// so a call that has started is not cut short by an interrupt. Retries are
+// recorded as events on ctx's span.
+func Call(ctx context.Context, stats *Stats, desc string, fn func(ctx context.Context) error, attrs ...any) error {
+	start := time.Now()
+	backoff := Retry.InitialWait
+	timeout, maxAttempts := Retry.limits(ctx)
+	span := trace.SpanFromContext(ctx)
+	for attempt := 1; ; attempt++ {
+		fields := slices.Concat([]any{"op", desc, "attempt", attempt}, attrs)
+		slog.Debug("calling Slack", fields...)
+		actx, cancel := context.WithTimeout(context.WithoutCancel(ctx), timeout)
+		called := time.Now()
+		err := fn(actx)
+		cancel()
+		for _, o := range observers {
+			o.Attempted(ctx, desc, attempt, time.Since(called), err, attrs)
+		}
+
+		if err == nil {
+			return nil
+		}
+		if attempt >= maxAttempts {
+			return fmt.Errorf("exceeded max retries (%d) %s: %w", maxAttempts, desc, err)
+		}
+
+		var wait time.Duration
+		var reason string
+		var rle *slack.RateLimitedError
+		switch {
+		case errors.As(err, &rle):
+			wait, reason = rle.RetryAfter, "rate_limited"
+			if wait <= 0 {
+				wait = Retry.RateLimitWait
+			}
+			slog.Warn("rate limited, retrying", append(fields, "wait", wait, "max_attempts", maxAttempts)...)
+			stats.RateLimitRetries.Add(1)
+		case IsTransient(err):
+			wait, reason = Jitter(backoff), "transient"
+			backoff = min(2*backoff, Retry.MaxWait)
+			slog.Warn("transient error, retrying", append(fields, "err", err, "wait", wait.Round(time.Millisecond), "max_attempts", maxAttempts)...)
+			stats.TransientRetries.Add(1)
+		default:
+			return err
+		}
+		for _, o := range observers {
+			o.Retrying(ctx, reason == "rate_limited", wait)
+		}
+
+		if elapsed := time.Since(start); elapsed+wait > Retry.MaxElapsed {
+			return fmt.Errorf("giving up %s after %v: %w", desc, elapsed.Round(time.Second), err)
+		}
+		span.AddEvent("retry", trace.WithAttributes(SpanAttrs(slices.Concat(fields, []any{"reason", reason, "err", err.Error(), "wait", wait.String()}))...))
+		time.Sleep(wait)
+	}
+}
+
+// IsTransient reports whether err is a failure worth retrying that is not a
+// rate limit: a timeout, a dropped connection or a 5xx response from Slack.
+func IsTransient(err error) bool {
+	var sce slack.StatusCodeError
+	if errors.As(err, &sce) {
+		return sce.Code >= 500
+	}
+	if errors.Is(err, context.DeadlineExceeded) ||
+		errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, syscall.ECONNREFUSED) ||
+		errors.Is(err, syscall.EPIPE) ||
+		errors.Is(err, io.ErrUnexpectedEOF) {
+		return true
+	}
+	var ne net.Error
+	return errors.As(err, &ne) && ne.Timeout()
+}
+
+// Jitter returns a random duration between d/2 and d, so that concurrent
+// workers hitting the same failure do not retry in lockstep.
+func Jitter(d time.Duration) time.Duration {
+	return d/2 + rand.N(d/2+1)
+}
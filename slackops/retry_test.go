@@ -0,0 +1,86 @@
+package slackops
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/slack-go/slack"
+)
+
+func TestRetryPolicyLimits(t *testing.T) {
+	p := RetryPolicy{Timeout: 15 * time.Second, Attempts: 3, InitialWait: time.Second, MaxWait: 30 * time.Second, FetchTimeout: time.Minute}
+	if timeout, attempts := p.limits(context.Background()); timeout != 15*time.Second || attempts != 3 {
+		t.Errorf("limits = %v, %d, want the defaults", timeout, attempts)
+	}
+	if timeout, attempts := p.limits(Fetching(context.Background())); timeout != time.Minute || attempts != 3 {
+		t.Errorf("fetch limits = %v, %d, want 1m and the default attempts", timeout, attempts)
+	}
+}
+
+func TestCall(t *testing.T) {
+	rateLimited := &slack.RateLimitedError{RetryAfter: 1}
+	fatal := slack.SlackErrorResponse{Err: "not_authorized"}
+	for _, tc := range []struct {
+		name     string
+		errs     []error // returned by successive attempts; then nil
+		attempts int
+		wantErr  error
+		retries  int64
+	}{
+		{"success", nil, 1, nil, 0},
+		{"rate limit then success", []error{rateLimited}, 2, nil, 1},
+		{"a Slack error is not retried", []error{fatal}, 1, fatal, 0},
+		{"gives up after the attempts", []error{rateLimited, rateLimited, rateLimited}, Retry.Attempts, rateLimited, int64(Retry.Attempts) - 1},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			stats := &Stats{}
+			attempts := 0
+			err := Call(context.Background(), stats, "testing", func(ctx context.Context) error {
+				attempts++
+				if attempts <= len(tc.errs) {
+					return tc.errs[attempts-1]
+				}
+				return nil
+			})
+			// SlackErrorResponse is not comparable, so errors are matched
+			// by message.
+			switch {
+			case tc.wantErr == nil && err != nil:
+				t.Errorf("err = %v, want nil", err)
+			case tc.wantErr != nil && (err == nil || !strings.Contains(err.Error(), tc.wantErr.Error())):
+				t.Errorf("err = %v, want %v", err, tc.wantErr)
+			}
+			if attempts != tc.attempts {
+				t.Errorf("%d attempts, want %d", attempts, tc.attempts)
+			}
+			if got := stats.RateLimitRetries.Load(); got != tc.retries {
+				t.Errorf("%d rate-limit retries counted, want %d", got, tc.retries)
+			}
+		})
+	}
+}
+
+func TestIsTransient(t *testing.T) {
+	for _, tc := range []struct {
+		err  error
+		want bool
+	}{
+		{slack.StatusCodeError{Code: 503}, true},
+		{slack.StatusCodeError{Code: 404}, false},
+		{fmt.Errorf("post: %w", syscall.ECONNRESET), true},
+		{io.ErrUnexpectedEOF, true},
+		{context.DeadlineExceeded, true},
+		{slack.SlackErrorResponse{Err: "channel_not_found"}, false},
+		{errors.New("something else"), false},
+	} {
+		if got := IsTransient(tc.err); got != tc.want {
+			t.Errorf("IsTransient(%v) = %v, want %v", tc.err, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,291 @@
+package main
+
+import (
+	"cmp"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/kiddikn/slack-channel-renamer/plan"
+	"github.com/kiddikn/slack-channel-renamer/slackops"
+)
+
+// The states 'status' reports a plan row in.
+const (
+	statusPending    = "pending"
+	statusApplied    = "applied"
+	statusConflicted = "conflicted"
+	statusStale      = "stale"
+)
+
+var statusOrder = []string{statusPending, statusApplied, statusConflicted, statusStale}
+
+// rowStatus is how a plan row compares with the live workspace.
+type rowStatus struct {
+	Status    string `json:"status"`
+	Action    string `json:"action"`
+	Asis      string `json:"asis"`
+	Tobe      string `json:"tobe,omitempty"`
+	Detail    string `json:"detail,omitempty"`
+	Source    string `json:"source,omitempty"`
+	Workspace string `json:"workspace,omitempty"`
+}
+
+// liveState is a workspace's channels, by name and by ID, with the renames
+// recorded in the history, to compare plan rows with.
+type liveState struct {
+	channels map[string]channelInfo
+	byID     map[string]string
+	past     renameHistory
+	// renamedAway are the names a rename or archive in the plan frees.
+	renamedAway map[string]bool
+}
+
+func newLiveState(plan []planEntry, channels map[string]channelInfo, past renameHistory) liveState {
+	st := liveState{channels: channels, byID: make(map[string]string), past: past, renamedAway: make(map[string]bool)}
+	for name, ch := range channels {
+		st.byID[ch.ID] = name
+		if ch.ArchivedTwin != "" {
+			st.byID[ch.ArchivedTwin] = name
+		}
+	}
+	for _, e := range plan {
+		if e.Action == actionRename || e.Action == actionArchive {
+			st.renamedAway[e.Asis] = true
+		}
+	}
+	return st
+}
+
+// locate returns the current name and state of e's channel: the one with its
+// channel ID when the row has one, otherwise the one named asis or, failing
+// that, the one the history shows was renamed away from asis.
+func (st liveState) locate(e planEntry) (string, channelInfo, bool) {
+	if e.ChannelID != "" {
+		name, ok := st.byID[e.ChannelID]
+		if !ok {
+			return "", channelInfo{}, false
+		}
+		ch := st.channels[name]
+		if ch.ArchivedTwin == e.ChannelID {
+			ch = channelInfo{ID: e.ChannelID, IsArchived: true}
+		}
+		return name, ch, true
+	}
+	if _, ok := st.channels[e.Asis]; ok {
+		return e.Asis, plan.EntryChannel(st.channels, e), true
+	}
+	for _, changes := range st.past {
+		c := changes[0]
+		if c.Workspace != e.Workspace || !st.renamedFrom(changes, e.Asis) {
+			continue
+		}
+		if name, ok := st.byID[c.ChannelID]; ok {
+			return name, st.channels[name], true
+		}
+	}
+	return "", channelInfo{}, false
+}
+
+func (st liveState) renamedFrom(changes []historyChange, name string) bool {
+	for _, c := range changes {
+		if c.OldName == name {
+			return true
+		}
+	}
+	return false
+}
+
+// holder returns the active channel other than id that holds name, unless
+// the plan frees it.
+func (st liveState) holder(name, id string) (channelInfo, bool) {
+	ch, ok := st.channels[name]
+	if !ok || ch.IsArchived || ch.ID == id || st.renamedAway[name] {
+		return channelInfo{}, false
+	}
+	return ch, true
+}
+
+// status compares e with the live workspace.
+func (st liveState) status(e planEntry) (string, string) {
+	if e.Action == actionCreate {
+		if _, ok := st.channels[e.Asis]; ok {
+			return statusApplied, "the channel exists"
+		}
+		return statusPending, ""
+	}
+	name, ch, ok := st.locate(e)
+	if !ok {
+		if _, exists := st.channels[e.Tobe]; exists && e.Action == actionRename && e.ChannelID == "" {
+			return statusApplied, fmt.Sprintf("no channel is named %q, and one is named %q", e.Asis, e.Tobe)
+		}
+		return statusStale, fmt.Sprintf("channel %q no longer exists", cmp.Or(e.ChannelID, e.Asis))
+	}
+	moved := func() (string, string) {
+		return statusStale, fmt.Sprintf("channel %s is now named %q", ch.ID, name)
+	}
+
+	switch e.Action {
+	case actionRename:
+		switch {
+		case name == e.Tobe:
+			return statusApplied, ""
+		case name != e.Asis:
+			return moved()
+		case ch.IsArchived:
+			return statusStale, "the channel is archived"
+		}
+		if other, taken := st.holder(plan.NormalizeName(e.Tobe), ch.ID); taken {
+			return statusConflicted, fmt.Sprintf("channel %s holds %q", other.ID, e.Tobe)
+		}
+	case actionArchive:
+		if name != e.Asis {
+			return moved()
+		}
+		if ch.IsArchived {
+			return statusApplied, ""
+		}
+	case actionUnarchive:
+		target := cmp.Or(e.Tobe, e.Asis)
+		switch {
+		case !ch.IsArchived && name == target:
+			return statusApplied, ""
+		case !ch.IsArchived:
+			return moved()
+		}
+		if other, taken := st.holder(target, ch.ID); taken {
+			return statusConflicted, fmt.Sprintf("channel %s holds %q", other.ID, target)
+		}
+	case actionMerge:
+		into, ok := st.channels[e.Tobe]
+		switch {
+		case ch.IsArchived && ok && !into.IsArchived:
+			return statusApplied, ""
+		case ch.IsArchived:
+			return statusStale, "the channel is archived"
+		case !ok || into.IsArchived:
+			return statusConflicted, fmt.Sprintf("merge target %q is missing or archived", e.Tobe)
+		}
+	case actionSetTopic:
+		if ch.IsArchived {
+			return statusStale, "the channel is archived"
+		}
+		if (e.Topic == "" || e.Topic == slackops.Unescape(ch.Topic)) && (e.Purpose == "" || e.Purpose == slackops.Unescape(ch.Purpose)) {
+			return statusApplied, ""
+		}
+	case actionInvite:
+		if ch.IsArchived {
+			return statusStale, "the channel is archived"
+		}
+		return statusPending, "members are not compared"
+	}
+	return statusPending, ""
+}
+
+// planStatus returns the status of every row of plan, in plan order.
+func (s *session) planStatus(plan []planEntry, past renameHistory) ([]rowStatus, error) {
+	channels, err := s.listChannels(cmdCtx)
+	if err != nil {
+		return nil, err
+	}
+	plan, errs := expandTemplates(plan, time.Now())
+	for _, e := range errs {
+		slog.Warn(s.label() + e)
+	}
+	st := newLiveState(plan, channels, past)
+	rows := make([]rowStatus, 0, len(plan))
+	for _, e := range plan {
+		status, detail := st.status(e)
+		rows = append(rows, rowStatus{Status: status, Action: e.Action, Asis: e.Asis, Tobe: e.Tobe, Detail: detail, Source: e.Source, Workspace: s.workspace})
+	}
+	return rows, nil
+}
+
+func printStatus(w io.Writer, rows []rowStatus) error {
+	counts := make(map[string]int)
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "STATUS\tWORKSPACE\tACTION\tASIS\tTOBE\tDETAIL\tSOURCE")
+	for _, r := range rows {
+		counts[r.Status]++
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n", strings.ToUpper(r.Status), r.Workspace, r.Action, r.Asis, r.Tobe, r.Detail, r.Source)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	var parts []string
+	for _, s := range statusOrder {
+		parts = append(parts, fmt.Sprintf("%d %s", counts[s], s))
+	}
+	_, err := fmt.Fprintf(w, "status: %s\n", strings.Join(parts, ", "))
+	return err
+}
+
+// cmdStatus runs 'status', which compares every row of a plan with the live
+// workspace without validating or changing anything.
+func cmdStatus(args []string) int {
+	fs := newFlagSet("status")
+	var channelOpts channelOptions
+	channelOpts.register(fs)
+	var ws workspaceOptions
+	ws.register(fs)
+	var in planInput
+	in.register(fs)
+	planFile := fs.String("plan-file", "", "compare a resolved plan written by 'plan -out' instead of reading the CSV")
+	history := fs.String("history-db", defaultHistoryDB, "history database used to follow renames of rows without a channel ID (empty to disable)")
+	detailedExit := fs.Bool("detailed-exitcode", false, "exit with 2 when any row is not applied, 0 when all are and 1 on errors")
+	registerOutput(fs)
+	parseFlags(fs, args)
+	if *planFile != "" && len(in.paths) > 0 {
+		slog.Error("-plan and -plan-file cannot be used together")
+		return 2
+	}
+
+	sessions, err := ws.newSessions(channelOpts)
+	if err != nil {
+		slog.Error(err.Error())
+		return 1
+	}
+	plan, err := loadPlan(in, *planFile)
+	if err != nil {
+		slog.Error(err.Error())
+		return 1
+	}
+	past, err := loadRenameHistory(*history)
+	if err != nil {
+		slog.Error(err.Error())
+		return 1
+	}
+	split, errs := splitByWorkspace(plan, sessions, ws.workspace)
+	if len(errs) > 0 {
+		for _, e := range errs {
+			slog.Error(e)
+		}
+		return 1
+	}
+	var rows []rowStatus
+	for i, s := range sessions {
+		sessionRows, err := s.planStatus(split[i], past)
+		if err != nil {
+			slog.Error(err.Error())
+			return 1
+		}
+		rows = append(rows, sessionRows...)
+	}
+
+	if output == outputJSON {
+		report.Status = rows
+	} else if err := printStatus(os.Stdout, rows); err != nil {
+		slog.Error("failed to write status", "err", err)
+		return 1
+	}
+	for _, r := range rows {
+		if *detailedExit && r.Status != statusApplied {
+			return 2
+		}
+	}
+	return 0
+}
@@ -0,0 +1,33 @@
+package main
+
+import "testing"
+
+func TestRowStatus(t *testing.T) {
+	channels := map[string]channelInfo{
+		"new-a":   {ID: "C1"},
+		"b":       {ID: "C2"},
+		"taken":   {ID: "C3"},
+		"old":     {ID: "C4", IsArchived: true},
+		"renamed": {ID: "C5"},
+		"topics":  {ID: "C6", Topic: "R&amp;D"},
+	}
+	past := renameHistory{"\x00C5": {{ChannelID: "C5", OldName: "elsewhere", NewName: "renamed"}}}
+	plan := []planEntry{
+		{Action: actionRename, Asis: "a", Tobe: "new-a"},
+		{Action: actionRename, Asis: "b", Tobe: "new-b"},
+		{Action: actionRename, Asis: "b", Tobe: "taken"},
+		{Action: actionRename, Asis: "elsewhere", Tobe: "final"},
+		{Action: actionRename, Asis: "x", Tobe: "y", ChannelID: "C1"},
+		{Action: actionRename, Asis: "gone", Tobe: "nowhere"},
+		{Action: actionArchive, Asis: "old"},
+		{Action: actionSetTopic, Asis: "topics", Topic: "R&D"},
+		{Action: actionCreate, Asis: "b"},
+	}
+	want := []string{statusApplied, statusPending, statusConflicted, statusStale, statusStale, statusStale, statusApplied, statusApplied, statusApplied}
+	st := newLiveState(plan, channels, past)
+	for i, e := range plan {
+		if got, detail := st.status(e); got != want[i] {
+			t.Errorf("%s %s -> %s: status %s (%s), want %s", e.Action, e.Asis, e.Tobe, got, detail, want[i])
+		}
+	}
+}
@@ -0,0 +1,203 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"log/slog"
+	"maps"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/kiddikn/slack-channel-renamer/plan"
+	"gopkg.in/yaml.v3"
+)
+
+// suggestRules is the -rules file of 'suggest':
+//
+//	rules:
+//	  - match: '^proj-(.*)-(\d{4})$'
+//	    tobe: 'project-$1-$2'
+//	  - match: '^tmp-'
+//	    tobe: 'zz-{{.OldName}}'
+//	glossary:
+//	  eng: engineering
+//	  mktg: marketing
+type suggestRules struct {
+	// Rules are tried in order; the first whose match matches a channel name
+	// gives the new name. tobe expands $1, ${name} etc. to submatches, as in
+	// 'generate', and then the expressions of target name templates.
+	Rules []suggestRule `yaml:"rules"`
+	// Glossary replaces whole words of the name, between separators, after
+	// the rules, such as a team's shorthand with its full name.
+	Glossary map[string]string `yaml:"glossary"`
+}
+
+type suggestRule struct {
+	Match string `yaml:"match"`
+	Tobe  string `yaml:"tobe"`
+
+	re *regexp.Regexp
+}
+
+func loadSuggestRules(path string) (*suggestRules, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read %q: %w", path, err)
+	}
+	dec := yaml.NewDecoder(bytes.NewReader(b))
+	dec.KnownFields(true)
+	var r suggestRules
+	if err := dec.Decode(&r); err != nil {
+		return nil, fmt.Errorf("parse %q: %w", path, err)
+	}
+	for i := range r.Rules {
+		rule := &r.Rules[i]
+		if rule.Match == "" || rule.Tobe == "" {
+			return nil, fmt.Errorf("%s: rule %d needs match and tobe", path, i+1)
+		}
+		if rule.re, err = regexp.Compile(rule.Match); err != nil {
+			return nil, fmt.Errorf("%s: rule %d: %w", path, i+1, err)
+		}
+	}
+	if len(r.Rules) == 0 && len(r.Glossary) == 0 {
+		return nil, fmt.Errorf("%s has no rules and no glossary", path)
+	}
+	return &r, nil
+}
+
+// suggest returns the name the rules give the channel called name, and what
+// they did; the name is unchanged when no rule or glossary word applies.
+func (r *suggestRules) suggest(name string, now time.Time) (string, []string, error) {
+	tobe := name
+	var why []string
+	for i, rule := range r.Rules {
+		m := rule.re.FindStringSubmatchIndex(name)
+		if m == nil {
+			continue
+		}
+		tobe = string(rule.re.ExpandString(nil, rule.Tobe, name, m))
+		if strings.Contains(tobe, "{{") {
+			expanded, errs := expandTemplates([]planEntry{{Asis: name, Tobe: tobe}}, now)
+			if len(errs) > 0 {
+				return "", nil, fmt.Errorf("rule %d: %s", i+1, errs[0])
+			}
+			tobe = expanded[0].Tobe
+		}
+		why = append(why, fmt.Sprintf("rule %d (%s)", i+1, rule.Match))
+		break
+	}
+
+	var b strings.Builder
+	word := func(w string) {
+		if full, ok := r.Glossary[w]; ok && full != w {
+			why = append(why, fmt.Sprintf("glossary %s -> %s", w, full))
+			w = full
+		}
+		b.WriteString(w)
+	}
+	start := 0
+	for i, c := range tobe {
+		if c == '-' || c == '_' {
+			word(tobe[start:i])
+			b.WriteRune(c)
+			start = i + 1
+		}
+	}
+	word(tobe[start:])
+	return b.String(), why, nil
+}
+
+// suggestRows returns the asis/tobe rows the rules propose, in name order.
+// With a policy only the channels breaking it get a row, and a proposed name
+// that still breaks it is replaced by the policy's own suggestion; without
+// one, every channel the rules change does. Archived channels are left out
+// unless includeArchived is set.
+func suggestRows(channels map[string]channelInfo, rules *suggestRules, policy *lintPolicy, includeArchived bool, now time.Time) ([][2]string, error) {
+	var rows [][2]string
+	for _, name := range slices.Sorted(maps.Keys(channels)) {
+		if ch := channels[name]; ch.IsGeneral || ch.IsArchived && !includeArchived {
+			continue
+		}
+		var problems []string
+		if policy != nil {
+			if problems, _ = policy.check(name, ""); len(problems) == 0 {
+				continue
+			}
+		}
+		tobe, why, err := rules.suggest(name, now)
+		if err != nil {
+			return nil, fmt.Errorf("channel %s: %w", name, err)
+		}
+		if policy != nil {
+			if left, fixed := policy.check(tobe, ""); len(left) > 0 {
+				why = append(why, "policy: "+strings.Join(left, "; "))
+				tobe = fixed
+			}
+		}
+		if tobe == name {
+			continue
+		}
+		if plan.NormalizeName(tobe) != tobe || !plan.ChannelNameRe.MatchString(tobe) {
+			slog.Warn("suggested name is not a valid channel name; edit it before applying", "asis", name, "tobe", tobe)
+		}
+		slog.Info("suggesting a new name", "asis", name, "tobe", tobe, "problems", strings.Join(problems, "; "), "by", strings.Join(why, ", "))
+		rows = append(rows, [2]string{name, tobe})
+	}
+	return rows, nil
+}
+
+func cmdSuggest(args []string) int {
+	fs := newFlagSet("suggest")
+	var channelOpts channelOptions
+	channelOpts.register(fs)
+	var ws workspaceOptions
+	ws.register(fs)
+	rulesFile := fs.String("rules", "", "YAML file of rename rules (match and tobe) and glossary words")
+	policyFile := fs.String("policy", "", "only suggest names for the channels breaking this lint policy, and keep the suggestions to it")
+	includeArchived := fs.Bool("include-archived", false, "also suggest names for archived channels")
+	out := fs.String("out", "", "write to this file instead of stdout")
+	parseFlags(fs, args)
+	if *rulesFile == "" {
+		slog.Error("suggest needs -rules")
+		return 2
+	}
+	rules, err := loadSuggestRules(*rulesFile)
+	if err != nil {
+		slog.Error("failed to load rules", "err", err)
+		return 1
+	}
+	var policy *lintPolicy
+	if *policyFile != "" {
+		if policy, err = loadLintPolicy(*policyFile); err != nil {
+			slog.Error("failed to load policy", "err", err)
+			return 1
+		}
+	}
+
+	sessions, err := ws.newSessions(channelOpts)
+	if err != nil {
+		slog.Error(err.Error())
+		return 1
+	}
+	now := time.Now()
+	rowsBySession := make([][][2]string, len(sessions))
+	for i, s := range sessions {
+		channels, err := s.listChannels(cmdCtx)
+		if err != nil {
+			slog.Error(err.Error())
+			return 1
+		}
+		if rowsBySession[i], err = suggestRows(channels, rules, policy, *includeArchived, now); err != nil {
+			slog.Error(err.Error())
+			return 1
+		}
+	}
+	if err := writeGeneratedPlan(*out, sessions, rowsBySession, ws.config != ""); err != nil {
+		slog.Error(err.Error())
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestSuggestRows(t *testing.T) {
+	dir := t.TempDir()
+	rulesFile := filepath.Join(dir, "rules.yaml")
+	rules := `rules:
+  - match: '^proj-(.*)-(\d{4})$'
+    tobe: 'project-$1-$2'
+  - match: '^tmp-'
+    tobe: 'zz-{{.OldName}}'
+glossary:
+  eng: engineering
+`
+	if err := os.WriteFile(rulesFile, []byte(rules), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	r, err := loadSuggestRules(rulesFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	channels := map[string]channelInfo{
+		"general":          {ID: "C0", IsGeneral: true},
+		"proj-apollo-2024": {ID: "C1"},
+		"tmp-offsite":      {ID: "C2"},
+		"eng-backend":      {ID: "C3"},
+		"engineering-qa":   {ID: "C4"},
+	}
+	now := time.Date(2026, 10, 14, 0, 0, 0, 0, time.UTC)
+	got, err := suggestRows(channels, r, nil, false, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := [][2]string{{"eng-backend", "engineering-backend"}, {"proj-apollo-2024", "project-apollo-2024"}, {"tmp-offsite", "zz-tmp-offsite"}}
+	if !slices.Equal(got, want) {
+		t.Errorf("rows = %q, want %q", got, want)
+	}
+
+	// With a policy only the channels breaking it are renamed, and kept to it.
+	policy := &lintPolicy{Teams: map[string][]string{"eng": {"engineering-"}}, MaxLength: 80}
+	got, err = suggestRows(channels, r, policy, false, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want = [][2]string{{"eng-backend", "engineering-backend"}, {"proj-apollo-2024", "engineering-project-apollo-2024"}, {"tmp-offsite", "engineering-zz-tmp-offsite"}}
+	if !slices.Equal(got, want) {
+		t.Errorf("rows with a policy = %q, want %q", got, want)
+	}
+}
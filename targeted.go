@@ -0,0 +1,59 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+	"slices"
+
+	"github.com/kiddikn/slack-channel-renamer/plan"
+	"github.com/kiddikn/slack-channel-renamer/slackops"
+)
+
+// canTarget reports whether the channels of plan can be looked up one by one
+// with conversations.info instead of listing the workspace: every entry must
+// name its channel by ID, no merge may need its target found by name, no
+// unarchive may need the listing to see whether its name was taken, and a
+// taken target name must be left for Slack to refuse, as -on-conflict fail
+// does. It is never the case with -full-list, in admin mode, which searches
+// by name already, or in a simulation.
+func (s *session) canTarget(plan []planEntry, onConflict conflictPolicy) bool {
+	if s.channelOpts.fullList || s.channelOpts.admin || s.simulated || len(plan) == 0 {
+		return false
+	}
+	if onConflict != "" && onConflict != conflictFail {
+		return false
+	}
+	return !slices.ContainsFunc(plan, func(e planEntry) bool {
+		return e.ChannelID == "" || e.Action == actionMerge || e.Action == actionUnarchive
+	})
+}
+
+// targetedChannels looks up the channels of probes by ID and returns them keyed
+// by their live names, along with the entries that could not be resolved.
+func (s *session) targetedChannels(ctx context.Context, probes []planEntry) ([]planEntry, map[string]channelInfo, []string) {
+	channels := make(map[string]channelInfo)
+	entries, errs := plan.ResolveIDs(ctx, s.client, s.stats, probes, channels, s.channelOpts.list())
+	slog.Info(s.label()+"looked up the plan's channels by ID instead of listing the workspace (pass -full-list to list it)", "count", len(channels))
+	return entries, channels, errs
+}
+
+// fetchRenamedByID looks up every channel in x.Renamed by ID, for the
+// verification pass of a run whose channels were looked up by ID. channels
+// is the channel list the run started from.
+func (x *executor) fetchRenamedByID(ctx context.Context, channels map[string]channelInfo) (map[string]channelInfo, error) {
+	live := make(map[string]channelInfo)
+	seen := make(map[string]bool)
+	for _, e := range x.Renamed {
+		ch := channels[e.Asis]
+		if seen[ch.ID] {
+			continue
+		}
+		seen[ch.ID] = true
+		name, err := slackops.ChannelName(ctx, x.Client, x.Stats, ch, "verifying "+e.Tobe)
+		if err != nil {
+			return nil, err
+		}
+		live[name] = ch
+	}
+	return live, nil
+}
@@ -0,0 +1,35 @@
+package main
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/kiddikn/slack-channel-renamer/slackops"
+)
+
+func TestTargetedLookup(t *testing.T) {
+	w := newFakeWorkspace("alpha", "beta", "gamma")
+	s := &session{client: w, stats: &slackops.Stats{}}
+	plan := []planEntry{
+		{Action: actionRename, Asis: "C1", Tobe: "beta", ChannelID: "C1"},
+		{Action: actionRename, Asis: "beta", Tobe: "alpha", ChannelID: "C2"},
+	}
+	if !s.canTarget(plan, conflictFail) {
+		t.Fatal("canTarget = false for a plan of IDs")
+	}
+	if s.canTarget(plan, conflictSuffix) || s.canTarget(append(plan, planEntry{Action: actionRename, Asis: "gamma", Tobe: "g"}), conflictFail) {
+		t.Error("canTarget = true with -on-conflict suffix or a row without an ID")
+	}
+
+	s.targeted = true
+	entries, channels, errs, err := s.lookupChannels(plan, false)
+	if err != nil || len(errs) > 0 {
+		t.Fatalf("lookupChannels: %v %q", err, errs)
+	}
+	if entries[0].Asis != "alpha" || len(channels) != 2 || channels["beta"].ID != "C2" {
+		t.Errorf("entries = %v, channels = %v", entries, channels)
+	}
+	if want := []string{"[info C1]", "[info C2]"}; !slices.Equal(w.calls, want) {
+		t.Errorf("calls = %q, want only %q", w.calls, want)
+	}
+}
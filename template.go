@@ -0,0 +1,86 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// defaultDateLayout is the layout of {{.Date}} without an argument.
+const defaultDateLayout = "2006-01-02"
+
+// nameTemplateData is what a tobe template such as
+// "archive-{{.OldName}}-{{.Date "2006-01"}}" can refer to.
+type nameTemplateData struct {
+	// OldName is the channel's current name, i.e. asis.
+	OldName string
+	// Owner is the row's owner column.
+	Owner string
+
+	now time.Time
+	// dated, when set, records that the template called Date.
+	dated *bool
+}
+
+// Date formats the time of the run with layout, or as 2006-01-02 by default.
+func (d nameTemplateData) Date(layout ...string) string {
+	if d.dated != nil {
+		*d.dated = true
+	}
+	if len(layout) == 0 {
+		return d.now.Format(defaultDateLayout)
+	}
+	return d.now.Format(strings.Join(layout, ""))
+}
+
+// TeamPrefix is the part of OldName before its first hyphen, e.g. "eng" for
+// "eng-backend", or the whole name if it has no hyphen.
+func (d nameTemplateData) TeamPrefix() string {
+	prefix, _, _ := strings.Cut(d.OldName, "-")
+	return prefix
+}
+
+// expandTemplates expands every tobe that holds a template expression for its
+// own row, so that one pattern can rename many channels. It returns the plan
+// with the expanded names, leaving out the rows whose template fails, and an
+// error for each of those.
+func expandTemplates(plan []planEntry, now time.Time) ([]planEntry, []string) {
+	return expandTemplatesFor(plan, now, false)
+}
+
+// expandUndatedTemplates is expandTemplates for reversing a plan: a row whose
+// template uses .Date is an error, since expanding it now may not give back
+// the name it produced when the plan was applied.
+func expandUndatedTemplates(plan []planEntry, now time.Time) ([]planEntry, []string) {
+	return expandTemplatesFor(plan, now, true)
+}
+
+func expandTemplatesFor(plan []planEntry, now time.Time, rejectDated bool) ([]planEntry, []string) {
+	expanded := make([]planEntry, 0, len(plan))
+	var errs []string
+	for _, e := range plan {
+		if !strings.Contains(e.Tobe, "{{") {
+			expanded = append(expanded, e)
+			continue
+		}
+		tmpl, err := template.New("tobe").Option("missingkey=error").Parse(e.Tobe)
+		if err != nil {
+			errs = append(errs, e.At()+fmt.Sprintf("tobe template %q: %v", e.Tobe, err))
+			continue
+		}
+		var b strings.Builder
+		var dated bool
+		if err := tmpl.Execute(&b, nameTemplateData{OldName: e.Asis, Owner: e.Owner, now: now, dated: &dated}); err != nil {
+			errs = append(errs, e.At()+fmt.Sprintf("tobe template %q: %v", e.Tobe, err))
+			continue
+		}
+		if dated && rejectDated {
+			errs = append(errs, e.At()+fmt.Sprintf("tobe template %q depends on the date and cannot be reversed from the plan; roll back with 'rollback -plan-file' and the reverse plan written by apply", e.Tobe))
+			continue
+		}
+		e.Tobe = b.String()
+		expanded = append(expanded, e)
+	}
+	return expanded, errs
+}
@@ -0,0 +1,245 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/kiddikn/slack-channel-renamer/slackops"
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/google"
+)
+
+const (
+	gcpScope         = "https://www.googleapis.com/auth/cloud-platform"
+	gcpSecretURL     = "https://secretmanager.googleapis.com/v1/%s:access"
+	keyringService   = "slack-channel-renamer"
+	defaultVaultAddr = "https://127.0.0.1:8200"
+)
+
+// resolveTokenRef returns the token a secret reference points to, so that it
+// need not sit in a plain environment variable:
+//
+//	file:///path/to/token
+//	keyring://service/account    (service defaults to slack-channel-renamer)
+//	aws-sm://secret-id[#json-key]
+//	gcp-sm://projects/P/secrets/S[/versions/V][#json-key]
+//	vault://mount/path[#field]   (field defaults to token)
+//
+// A #json-key picks one field of a secret stored as a JSON object.
+func resolveTokenRef(ref string) (string, error) {
+	scheme, rest, ok := strings.Cut(ref, "://")
+	if !ok {
+		return "", fmt.Errorf("token reference %q: want scheme://..., such as aws-sm://slack/renamer", ref)
+	}
+	rest, key, _ := strings.Cut(rest, "#")
+	ctx, cancel := context.WithTimeout(context.Background(), slackops.Retry.Timeout)
+	defer cancel()
+
+	var secret string
+	var err error
+	switch scheme {
+	case "file":
+		secret, err = readTokenFile(rest)
+	case "keyring":
+		secret, err = keyringToken(ctx, rest)
+	case "aws-sm":
+		secret, err = runSecretCommand(ctx, "aws", "secretsmanager", "get-secret-value", "--secret-id", rest, "--query", "SecretString", "--output", "text")
+	case "gcp-sm":
+		secret, err = gcpSecret(ctx, rest)
+	case "vault":
+		if key == "" {
+			key = "token"
+		}
+		secret, err = vaultSecret(ctx, rest, key)
+		key = "" // vaultSecret picks the field itself
+	default:
+		return "", fmt.Errorf("token reference %q: unknown scheme %q (want file, keyring, aws-sm, gcp-sm or vault)", ref, scheme)
+	}
+	if err != nil {
+		return "", fmt.Errorf("token reference %q: %w", ref, err)
+	}
+	if key != "" {
+		if secret, err = jsonField(secret, key); err != nil {
+			return "", fmt.Errorf("token reference %q: %w", ref, err)
+		}
+	}
+	secret = strings.TrimSpace(secret)
+	if secret == "" {
+		return "", fmt.Errorf("token reference %q: the secret is empty", ref)
+	}
+	return secret, nil
+}
+
+// readTokenFile returns the token in a file, warning when other users can
+// read it.
+func readTokenFile(path string) (string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", err
+	}
+	if runtime.GOOS != "windows" && info.Mode().Perm()&0o077 != 0 {
+		slog.Warn("the token file can be read by other users; chmod 600 it", "file", path, "mode", info.Mode().Perm().String())
+	}
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	token := strings.TrimSpace(string(b))
+	if token == "" {
+		return "", fmt.Errorf("%s is empty", path)
+	}
+	return token, nil
+}
+
+// keyringToken reads a password from the OS keyring: the login keychain on
+// macOS, or the Secret Service (GNOME Keyring, KWallet) through secret-tool
+// elsewhere.
+func keyringToken(ctx context.Context, ref string) (string, error) {
+	service, account, ok := strings.Cut(ref, "/")
+	if !ok {
+		service, account = keyringService, ref
+	}
+	if account == "" {
+		return "", errors.New("no keyring account given")
+	}
+	if runtime.GOOS == "darwin" {
+		return runSecretCommand(ctx, "security", "find-generic-password", "-s", service, "-a", account, "-w")
+	}
+	return runSecretCommand(ctx, "secret-tool", "lookup", "service", service, "account", account)
+}
+
+// runSecretCommand runs a secret manager's CLI and returns what it prints.
+// Its stderr is kept for the error, as it explains most failures.
+func runSecretCommand(ctx context.Context, name string, args ...string) (string, error) {
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("%s: %w: %s", name, err, msg)
+		}
+		return "", fmt.Errorf("%s: %w", name, err)
+	}
+	return stdout.String(), nil
+}
+
+// gcpSecret reads a Secret Manager secret version through its REST API with
+// Application Default Credentials. Without a version, the latest is read.
+func gcpSecret(ctx context.Context, name string) (string, error) {
+	if !strings.Contains(name, "/versions/") {
+		name += "/versions/latest"
+	}
+	client, err := google.DefaultClient(context.WithValue(ctx, oauth2.HTTPClient, netClient()), gcpScope)
+	if err != nil {
+		return "", fmt.Errorf("find Google credentials: %w", err)
+	}
+	body, err := getJSON(ctx, client, fmt.Sprintf(gcpSecretURL, name), nil)
+	if err != nil {
+		return "", err
+	}
+	var resp struct {
+		Payload struct {
+			Data string `json:"data"`
+		} `json:"payload"`
+	}
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return "", fmt.Errorf("parse response: %w", err)
+	}
+	data, err := base64.StdEncoding.DecodeString(resp.Payload.Data)
+	if err != nil {
+		return "", fmt.Errorf("decode payload: %w", err)
+	}
+	return string(data), nil
+}
+
+// vaultSecret reads one field of a HashiCorp Vault secret from VAULT_ADDR,
+// with VAULT_TOKEN or the token 'vault login' leaves in ~/.vault-token. Both
+// KV version 1 and 2 engines are understood; for version 2 the path includes
+// data/, as in secret/data/slack.
+func vaultSecret(ctx context.Context, path, field string) (string, error) {
+	addr := os.Getenv("VAULT_ADDR")
+	if addr == "" {
+		addr = defaultVaultAddr
+	}
+	token := os.Getenv("VAULT_TOKEN")
+	if token == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", errors.New("VAULT_TOKEN is not set")
+		}
+		b, err := os.ReadFile(filepath.Join(home, ".vault-token"))
+		if err != nil {
+			return "", errors.New("VAULT_TOKEN is not set and there is no ~/.vault-token")
+		}
+		token = strings.TrimSpace(string(b))
+	}
+	body, err := getJSON(ctx, netClient(), strings.TrimSuffix(addr, "/")+"/v1/"+strings.TrimPrefix(path, "/"),
+		http.Header{"X-Vault-Token": {token}})
+	if err != nil {
+		return "", err
+	}
+	var resp struct {
+		Data map[string]any `json:"data"`
+	}
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return "", fmt.Errorf("parse response: %w", err)
+	}
+	data := resp.Data
+	if inner, ok := data["data"].(map[string]any); ok {
+		data = inner // KV version 2
+	}
+	v, ok := data[field].(string)
+	if !ok {
+		return "", fmt.Errorf("the secret has no string field %q", field)
+	}
+	return v, nil
+}
+
+// getJSON fetches url and returns the body of a 200 response.
+func getJSON(ctx context.Context, client *http.Client, url string, header http.Header) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s: %s", resp.Status, bytes.TrimSpace(body))
+	}
+	return body, nil
+}
+
+// jsonField returns the string field key of a secret stored as a JSON object.
+func jsonField(secret, key string) (string, error) {
+	var fields map[string]any
+	if err := json.Unmarshal([]byte(secret), &fields); err != nil {
+		return "", fmt.Errorf("#%s given but the secret is not a JSON object", key)
+	}
+	v, ok := fields[key].(string)
+	if !ok {
+		return "", fmt.Errorf("the secret has no string field %q", key)
+	}
+	return v, nil
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"os"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+// tracer starts every span. Until setupTracing installs an exporter it is a
+// no-op.
+var tracer = otel.Tracer("github.com/kiddikn/slack-channel-renamer")
+
+// cmdCtx carries the running command's span. Spans and Slack calls that are
+// not part of a plan entry are parented on it.
+var cmdCtx = context.Background()
+
+// tracingShutdownTimeout bounds how long exiting waits for spans to be exported.
+const tracingShutdownTimeout = 5 * time.Second
+
+// setupTracing exports spans over OTLP/HTTP when OTEL_EXPORTER_OTLP_ENDPOINT
+// or OTEL_EXPORTER_OTLP_TRACES_ENDPOINT is set. The exporter is configured by
+// the standard OTEL_* environment variables. It returns a function that flushes
+// the remaining spans.
+func setupTracing() func() {
+	if os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT") == "" && os.Getenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT") == "" ||
+		os.Getenv("OTEL_TRACES_EXPORTER") == "none" {
+		return func() {}
+	}
+	ctx := context.Background()
+	exporter, err := otlptracehttp.New(ctx)
+	if err != nil {
+		slog.Warn("tracing disabled: cannot create the OTLP exporter", "err", err)
+		return func() {}
+	}
+	// Attributes from OTEL_SERVICE_NAME and OTEL_RESOURCE_ATTRIBUTES win.
+	res, err := resource.New(ctx,
+		resource.WithAttributes(attribute.String("service.name", "slack-channel-renamer")),
+		resource.WithTelemetrySDK(),
+		resource.WithFromEnv())
+	if err != nil && !errors.Is(err, resource.ErrPartialResource) {
+		slog.Warn("tracing: cannot detect the resource", "err", err)
+	}
+	provider := sdktrace.NewTracerProvider(sdktrace.WithBatcher(exporter), sdktrace.WithResource(res))
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	return func() {
+		ctx, cancel := context.WithTimeout(context.Background(), tracingShutdownTimeout)
+		defer cancel()
+		if err := provider.Shutdown(ctx); err != nil {
+			slog.Warn("failed to export spans", "err", err)
+		}
+	}
+}
+
+// parentContext continues the trace named by the TRACEPARENT and TRACESTATE
+// environment variables, so that a run started by an orchestrator shows up
+// inside the orchestrator's trace.
+func parentContext() context.Context {
+	carrier := propagation.MapCarrier{
+		"traceparent": os.Getenv("TRACEPARENT"),
+		"tracestate":  os.Getenv("TRACESTATE"),
+	}
+	return otel.GetTextMapPropagator().Extract(context.Background(), carrier)
+}
@@ -0,0 +1,208 @@
+package main
+
+import (
+	"io"
+	"net/http"
+)
+
+// The dashboard 'serve -ui' hosts: one page that drives the /v1 API from the
+// browser with the API token the user enters, so it needs no session of its
+// own. Everything it shows is set as text, never parsed as HTML.
+const uiHTML = `<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>Channel renamer</title>
+<style>
+body { font-family: sans-serif; margin: 2em; max-width: 80em; }
+section { margin-bottom: 2em; }
+table { border-collapse: collapse; margin-top: 0.5em; }
+th, td { border: 1px solid #ccc; padding: 0.3em 0.6em; text-align: left; }
+th { background: #f4f4f4; }
+.failed, .error { background: #fde2e2; }
+.skipped, .pending { background: #fff6d6; }
+.ok { background: #e4f6e4; }
+#message { color: #a00; }
+progress { width: 30em; }
+[hidden] { display: none; }
+</style>
+</head>
+<body>
+<h1>Channel renamer</h1>
+<p id="message" role="alert"></p>
+
+<section>
+<h2>1. Sign in</h2>
+<label>API token <input id="token" type="password" size="40" autocomplete="off"></label>
+<button id="save-token">Use token</button>
+</section>
+
+<section>
+<h2>2. Choose a plan</h2>
+<input id="file" type="file" accept=".csv,.tsv,.txt,.json,.yaml,.yml,.xlsx">
+<button id="upload">Upload</button>
+<p>or pick an uploaded plan: <select id="plans"></select> <button id="refresh">Refresh</button></p>
+</section>
+
+<section id="review" hidden>
+<h2>3. Review</h2>
+<p id="plan-name"></p>
+<button id="validate">Validate against the live channels</button>
+<div id="validation" hidden>
+<p id="verdict"></p>
+<ul id="errors"></ul>
+<h3 id="skipped-title" hidden>Skipped</h3>
+<ul id="skipped"></ul>
+<table id="entries">
+<thead><tr><th>Workspace</th><th>Action</th><th>Channel</th><th>New name</th><th>Members</th><th>Created</th><th>Last activity</th><th>Shared</th><th>Needs confirmation</th><th>Source</th></tr></thead>
+<tbody></tbody>
+</table>
+<p><button id="apply" disabled>Apply</button></p>
+</div>
+</section>
+
+<section id="run" hidden>
+<h2>4. Run</h2>
+<p id="run-status"></p>
+<progress id="run-progress" value="0" max="1"></progress>
+<p id="run-detail"></p>
+<table id="results">
+<thead><tr><th>Workspace</th><th>Action</th><th>Channel</th><th>New name</th><th>Status</th><th>Error</th></tr></thead>
+<tbody></tbody>
+</table>
+</section>
+
+<script src="ui.js"></script>
+</body>
+</html>
+`
+
+const uiJS = `"use strict";
+const $ = (id) => document.getElementById(id);
+let token = sessionStorage.getItem("renamer-token") || "";
+let planID = "";
+let polling = 0;
+
+function say(text) { $("message").textContent = text || ""; }
+
+async function api(method, path, body) {
+  const res = await fetch(path, {method, body, headers: {"Authorization": "Bearer " + token}});
+  const data = await res.json().catch(() => ({}));
+  if (!res.ok) {
+    throw new Error(data.error || res.status + " " + res.statusText);
+  }
+  return data;
+}
+
+function row(tbody, cells, cls) {
+  const tr = tbody.insertRow();
+  if (cls) tr.className = cls;
+  for (const c of cells) tr.insertCell().textContent = c == null ? "" : String(c);
+}
+
+function day(t) { return t ? t.slice(0, 10) : ""; }
+
+async function loadPlans() {
+  const plans = await api("GET", "/v1/plans");
+  const sel = $("plans");
+  sel.replaceChildren(new Option("", ""));
+  for (const p of plans) {
+    sel.add(new Option(p.name + " (" + p.entries + " entries, " + p.uploaded.slice(0, 16).replace("T", " ") + ")", p.id));
+  }
+  sel.value = planID;
+}
+
+function choose(p) {
+  planID = p.id;
+  $("plan-name").textContent = p.name + ": " + p.entries + " entries";
+  $("review").hidden = false;
+  $("validation").hidden = true;
+  $("apply").disabled = true;
+}
+
+async function validate() {
+  say("Validating...");
+  const v = await api("POST", "/v1/plans/" + planID + "/validate?stats=1");
+  say("");
+  $("validation").hidden = false;
+  $("verdict").textContent = v.passed ? "Validation passed: " + v.plan.length + " entries will run." : "Validation failed:";
+  $("errors").replaceChildren(...v.errors.map((e) => Object.assign(document.createElement("li"), {textContent: e})));
+  $("skipped-title").hidden = v.skipped.length === 0;
+  $("skipped").replaceChildren(...v.skipped.map((e) => Object.assign(document.createElement("li"), {textContent: e})));
+  const tbody = $("entries").tBodies[0];
+  tbody.replaceChildren();
+  for (const e of v.plan) {
+    const st = e.stats || {};
+    row(tbody, [e.workspace, e.action, e.asis, e.tobe, st.members, day(st.created), day(st.last_activity), e.shared, e.needs_confirm, e.source]);
+  }
+  $("apply").disabled = !v.passed || v.plan.length === 0;
+}
+
+async function apply() {
+  if (!confirm("Apply this plan to Slack now?")) return;
+  const run = await api("POST", "/v1/plans/" + planID + "/apply");
+  $("apply").disabled = true;
+  $("run").hidden = false;
+  clearInterval(polling);
+  polling = setInterval(() => follow(run.id).catch((e) => say(e.message)), 2000);
+  await follow(run.id);
+}
+
+async function follow(id) {
+  const run = await api("GET", "/v1/runs/" + id);
+  const p = run.progress || {};
+  $("run-status").textContent = "Run " + run.id + ": " + run.status;
+  $("run-progress").max = Math.max(p.total || 0, 1);
+  $("run-progress").value = p.done || 0;
+  const detail = [(p.done || 0) + " of " + (p.total || 0) + " done", (p.failed || 0) + " failed"];
+  if (p.eta) detail.push("about " + p.eta + " left");
+  if (p.last_error) detail.push("last error: " + p.last_error);
+  if (run.rollback_file) detail.push("rollback plan: " + run.rollback_file);
+  $("run-detail").textContent = detail.join(", ");
+  const results = await api("GET", "/v1/runs/" + id + "/results");
+  const tbody = $("results").tBodies[0];
+  tbody.replaceChildren();
+  for (const r of results) row(tbody, [r.workspace, r.action, r.asis, r.tobe, r.status, r.error], r.status);
+  if (run.finished) clearInterval(polling);
+}
+
+function guard(f) { return () => f().catch((e) => say(e.message)); }
+
+$("token").value = token;
+$("save-token").onclick = guard(async () => {
+  token = $("token").value.trim();
+  sessionStorage.setItem("renamer-token", token);
+  await loadPlans();
+  say("");
+});
+$("upload").onclick = guard(async () => {
+  const f = $("file").files[0];
+  if (!f) throw new Error("Choose a plan file first.");
+  const p = await api("POST", "/v1/plans?name=" + encodeURIComponent(f.name), f);
+  choose(p);
+  await loadPlans();
+});
+$("plans").onchange = guard(async () => {
+  const id = $("plans").value;
+  if (id) choose(await api("GET", "/v1/plans/" + id));
+});
+$("refresh").onclick = guard(loadPlans);
+$("validate").onclick = guard(validate);
+$("apply").onclick = guard(apply);
+if (token) guard(loadPlans)();
+`
+
+// uiSecurityPolicy keeps the dashboard to its own page and script.
+const uiSecurityPolicy = "default-src 'self'; style-src 'self' 'unsafe-inline'; frame-ancestors 'none'"
+
+func serveUIPage(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Security-Policy", uiSecurityPolicy)
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	io.WriteString(w, uiHTML)
+}
+
+func serveUIScript(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Security-Policy", uiSecurityPolicy)
+	w.Header().Set("Content-Type", "text/javascript; charset=utf-8")
+	io.WriteString(w, uiJS)
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"fmt"
+	"runtime"
+	"runtime/debug"
+)
+
+// version is set at build time with
+//
+//	go build -ldflags "-X main.version=v1.2.3"
+//
+// and otherwise taken from the module version go install records.
+var version string
+
+// buildInfo describes the binary for bug reports and support tickets.
+type buildInfo struct {
+	Version  string `json:"version"`
+	Commit   string `json:"commit,omitempty"`
+	Time     string `json:"time,omitempty"`
+	Modified bool   `json:"modified,omitempty"`
+	Go       string `json:"go"`
+	Platform string `json:"platform"`
+}
+
+func readBuildInfo() buildInfo {
+	b := buildInfo{Version: version, Go: runtime.Version(), Platform: runtime.GOOS + "/" + runtime.GOARCH}
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return b
+	}
+	if b.Version == "" {
+		b.Version = info.Main.Version
+	}
+	for _, s := range info.Settings {
+		switch s.Key {
+		case "vcs.revision":
+			b.Commit = s.Value
+		case "vcs.time":
+			b.Time = s.Value
+		case "vcs.modified":
+			b.Modified = s.Value == "true"
+		}
+	}
+	if b.Version == "" || b.Version == "(devel)" {
+		b.Version = "devel"
+	}
+	return b
+}
+
+func (b buildInfo) String() string {
+	s := "slack-channel-renamer " + b.Version
+	if b.Commit != "" {
+		s += " (" + b.Commit
+		if b.Modified {
+			s += ", modified"
+		}
+		if b.Time != "" {
+			s += ", " + b.Time
+		}
+		s += ")"
+	}
+	return s + fmt.Sprintf(" %s %s", b.Go, b.Platform)
+}
+
+func cmdVersion(args []string) int {
+	fs := newFlagSet("version")
+	registerOutput(fs)
+	parseFlags(fs, args)
+	b := readBuildInfo()
+	if output == outputJSON {
+		report.Version = &b
+		return 0
+	}
+	fmt.Println(b)
+	return 0
+}
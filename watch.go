@@ -0,0 +1,207 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"log/slog"
+	"os"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/slack-go/slack"
+	"github.com/slack-go/slack/slackevents"
+	"github.com/slack-go/slack/socketmode"
+)
+
+// watchOptions controls -watch-renames.
+type watchOptions struct {
+	enabled bool
+	// after keeps listening this long once the run is done.
+	after time.Duration
+}
+
+func (o *watchOptions) register(fs *flag.FlagSet) {
+	fs.BoolVar(&o.enabled, "watch-renames", false, "follow channel_rename events over Socket Mode (SLACK_APP_TOKEN) during the run and skip rows whose channel is renamed outside it")
+	fs.DurationVar(&o.after, "watch-after", 0, "with -watch-renames, keep listening this long after the run before recording the renames seen in the history")
+}
+
+// externalRename is a rename made outside the run, seen as an event.
+type externalRename struct {
+	workspace string
+	channelID string
+	oldName   string
+	newName   string
+	time      time.Time
+}
+
+// renameWatch follows the channel_rename and group_rename events of the
+// workspaces being changed, so that a row whose channel is renamed by someone
+// else while the run lasts is skipped before it starts rather than failing.
+type renameWatch struct {
+	mu sync.Mutex
+	// names holds each channel's latest name by ID, from the channel lists
+	// and the events since.
+	names map[string]string
+	// expected holds, by channel ID, the names the plan itself gives it.
+	expected map[string][]string
+	// planned holds the sources of the rows that act on each channel.
+	planned map[string][]string
+	// moved holds the names channels were last given outside the run.
+	moved map[string]string
+	// aside holds the channels the run may move to temporary names.
+	aside      map[string]bool
+	workspaces map[string]string
+	seen       []externalRename
+}
+
+// startRenameWatch connects with Socket Mode and follows rename events until
+// ctx is done. It returns nil, with a warning, without SLACK_APP_TOKEN.
+func startRenameWatch(ctx context.Context, runs []workspaceRun) *renameWatch {
+	appToken := os.Getenv("SLACK_APP_TOKEN")
+	if appToken == "" {
+		slog.Warn("-watch-renames needs SLACK_APP_TOKEN, an app-level token with connections:write; not watching")
+		return nil
+	}
+	w := newRenameWatch(runs)
+	client := socketmode.New(slack.New("", append(slackOptions(), slack.OptionAppLevelToken(appToken))...))
+	go func() {
+		if err := client.RunContext(ctx); err != nil && ctx.Err() == nil {
+			slog.Warn("Socket Mode stopped, no longer watching renames", "err", err)
+		}
+	}()
+	go func() {
+		for {
+			var evt socketmode.Event
+			select {
+			case <-ctx.Done():
+				return
+			case evt = <-client.Events:
+			}
+			switch evt.Type {
+			case socketmode.EventTypeInvalidAuth:
+				slog.Warn("SLACK_APP_TOKEN was rejected, not watching renames")
+				return
+			case socketmode.EventTypeEventsAPI:
+				client.Ack(*evt.Request)
+				ev, ok := evt.Data.(slackevents.EventsAPIEvent)
+				if !ok {
+					continue
+				}
+				switch inner := ev.InnerEvent.Data.(type) {
+				case *slackevents.ChannelRenameEvent:
+					w.renamed(ev.TeamID, inner.Channel.ID, inner.Channel.Name)
+				case *slackevents.GroupRenameEvent:
+					w.renamed(ev.TeamID, inner.Channel.ID, inner.Channel.Name)
+				}
+			}
+		}
+	}()
+	slog.Info("watching for channels renamed outside the run")
+	return w
+}
+
+// newRenameWatch returns a watch knowing the channels of runs and the names
+// their plans give them.
+func newRenameWatch(runs []workspaceRun) *renameWatch {
+	w := &renameWatch{names: make(map[string]string), expected: make(map[string][]string), planned: make(map[string][]string), moved: make(map[string]string), aside: make(map[string]bool), workspaces: make(map[string]string)}
+	for _, r := range runs {
+		if r.auth != nil {
+			w.workspaces[r.auth.TeamID] = r.workspace
+		}
+		for name, ch := range r.channels {
+			w.names[ch.ID] = name
+		}
+		for _, e := range resolveIDs(r.plan, r.channels) {
+			w.expected[e.ChannelID] = append(w.expected[e.ChannelID], e.Asis, e.Tobe)
+			if e.Via != "" {
+				w.expected[e.ChannelID] = append(w.expected[e.ChannelID], e.Via)
+			}
+			w.planned[e.ChannelID] = append(w.planned[e.ChannelID], e.Source)
+			if holder := r.channels[e.Asis]; e.Action == actionUnarchive && holder.ID != e.ChannelID {
+				// Unarchiving into a taken name moves the holder of the name
+				// aside, under a temporary name.
+				w.aside[holder.ID] = true
+			}
+		}
+	}
+	return w
+}
+
+// renamed records that the channel with the given ID is now named name, and
+// flags the plan rows it invalidates unless the plan gave it that name.
+func (w *renameWatch) renamed(team, id, name string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	old := w.names[id]
+	w.names[id] = name
+	if slices.Contains(w.expected[id], name) || w.aside[id] {
+		delete(w.moved, id)
+		return
+	}
+	w.moved[id] = name
+	workspace := w.workspaces[team]
+	w.seen = append(w.seen, externalRename{workspace: workspace, channelID: id, oldName: old, newName: name, time: time.Now().UTC()})
+	if rows := w.planned[id]; len(rows) > 0 {
+		slog.Warn(labelFor(workspace)+"channel renamed outside this run; rows acting on it that have not started will be skipped", "channel_id", id, "old", old, "new", name, "rows", rows)
+	} else {
+		slog.Info(labelFor(workspace)+"channel renamed outside this run", "channel_id", id, "old", old, "new", name)
+	}
+}
+
+// renamedTo returns the name the channel with the given ID was given outside
+// the run, if its latest rename was.
+func (w *renameWatch) renamedTo(id string) (string, bool) {
+	if w == nil {
+		return "", false
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	name, ok := w.moved[id]
+	return name, ok
+}
+
+// finish keeps listening for after, or until ctx is done or interrupted, then stores the
+// renames seen outside the run in the history, as a run of their own so that
+// reverting the apply run leaves them alone.
+func (w *renameWatch) finish(ctx context.Context, after time.Duration, store *historyStore) {
+	if w == nil {
+		return
+	}
+	if after > 0 {
+		slog.Info("still watching for channels renamed outside the run; interrupt to stop", "for", after)
+		ctx, stop := interruptContext(ctx)
+		defer stop()
+		select {
+		case <-ctx.Done():
+		case <-time.After(after):
+		}
+	}
+	if store == nil {
+		return
+	}
+	w.mu.Lock()
+	seen := slices.Clone(w.seen)
+	w.mu.Unlock()
+	if len(seen) == 0 {
+		return
+	}
+	run, err := store.startRun("renames seen outside slack-channel-renamer")
+	if err == nil {
+		for _, r := range seen {
+			if err = store.record(historyChange{Run: run.ID, Time: r.time, Workspace: r.workspace, ChannelID: r.channelID, Action: actionRename, OldName: r.oldName, NewName: r.newName}); err != nil {
+				break
+			}
+		}
+	}
+	if err != nil {
+		slog.Warn("failed to record the renames seen outside the run", "err", err)
+		return
+	}
+	slog.Info("recorded the renames seen outside the run", "run", run.ID, "count", len(seen))
+}
+
+// labelFor is session.label for a workspace name.
+func labelFor(workspace string) string {
+	return (&session{workspace: workspace}).label()
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"context"
+	"maps"
+	"slices"
+	"testing"
+
+	"github.com/kiddikn/slack-channel-renamer/plan"
+	"github.com/kiddikn/slack-channel-renamer/slackops"
+	"golang.org/x/time/rate"
+)
+
+func TestRenameWatch(t *testing.T) {
+	w := newFakeWorkspace("a", "b", "c")
+	s := &session{client: w, stats: &slackops.Stats{}}
+	channels, err := s.fetchChannels(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	entries := []planEntry{{Action: actionRename, Asis: "a", Tobe: "x"}, {Action: actionRename, Asis: "b", Tobe: "y"}}
+	active, errs, _ := plan.Validate(entries, channels, "", nil)
+	if len(errs) > 0 {
+		t.Fatalf("validation errors: %q", errs)
+	}
+	watch := newRenameWatch([]workspaceRun{{session: s, plan: active, channels: channels}})
+	watch.renamed("", "C1", "x")
+	watch.renamed("", "C2", "b-legacy")
+	watch.renamed("", "C3", "z")
+	if _, ok := watch.renamedTo("C1"); ok {
+		t.Error("the plan's own rename of C1 was taken for an outside one")
+	}
+	if name, ok := watch.renamedTo("C2"); !ok || name != "b-legacy" {
+		t.Errorf("renamedTo(C2) = %q, %v, want b-legacy", name, ok)
+	}
+	if got := len(watch.seen); got != 2 {
+		t.Errorf("seen %d outside renames, want 2", got)
+	}
+
+	x := s.executor(false)
+	x.Limiter = rate.NewLimiter(rate.Inf, 1)
+	x.watch = watch
+	if failures := x.applyEntries(context.Background(), channels, active); failures != 0 {
+		t.Errorf("failures = %d, want 0", failures)
+	}
+	if s.stats.Skipped != 1 {
+		t.Errorf("skipped = %d, want 1", s.stats.Skipped)
+	}
+	if slices.Contains(w.calls, "rename C2") {
+		t.Errorf("the row of the moved channel called Slack: %q", w.calls)
+	}
+	if got, want := w.names(), map[string]string{"C1": "x", "C2": "b", "C3": "c"}; !maps.Equal(got, want) {
+		t.Errorf("channels = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,243 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/kiddikn/slack-channel-renamer/slackops"
+)
+
+// Lifecycle events sent to -webhook-url.
+const (
+	eventRunStarted   = "run-started"
+	eventRowSucceeded = "row-succeeded"
+	eventRowFailed    = "row-failed"
+	eventRunCompleted = "run-completed"
+)
+
+// Webhook delivery: each event gets webhookAttempts tries, webhookTimeout
+// each, and whatever is still queued when the run ends gets webhookDrain to
+// go out.
+const (
+	webhookAttempts = 3
+	webhookTimeout  = 10 * time.Second
+	webhookDrain    = 30 * time.Second
+)
+
+// webhookOptions sends a JSON webhook for every lifecycle event of a run,
+// for systems such as a CMDB that track channel names.
+type webhookOptions struct {
+	url       string
+	secretEnv string
+	unsigned  bool
+}
+
+func (o *webhookOptions) register(fs *flag.FlagSet) {
+	fs.StringVar(&o.url, "webhook-url", "", "POST a JSON event to this URL when the run starts, for every succeeded or failed row and when it completes")
+	fs.StringVar(&o.secretEnv, "webhook-secret-env", "RENAMER_WEBHOOK_SECRET", "environment variable holding the HMAC-SHA256 key the webhook bodies are signed with")
+	fs.BoolVar(&o.unsigned, "webhook-unsigned", false, "send webhooks unsigned when the secret environment variable is not set")
+}
+
+// secret returns the HMAC key read from the -webhook-secret-env variable.
+func (o webhookOptions) secret() []byte {
+	if o.secretEnv == "" {
+		return nil
+	}
+	return []byte(os.Getenv(o.secretEnv))
+}
+
+// check refuses -webhook-url without a signing secret, unless unsigned
+// webhooks were asked for with -webhook-unsigned.
+func (o webhookOptions) check() error {
+	if o.url == "" || o.unsigned || len(o.secret()) > 0 {
+		return nil
+	}
+	if o.secretEnv == "" {
+		return errors.New("-webhook-url needs -webhook-secret-env to sign the webhooks (pass -webhook-unsigned to send them unsigned)")
+	}
+	return fmt.Errorf("-webhook-url needs a signing secret in $%s (pass -webhook-unsigned to send the webhooks unsigned)", o.secretEnv)
+}
+
+// webhookEvent is the body of one webhook.
+type webhookEvent struct {
+	Event  string    `json:"event"`
+	Time   time.Time `json:"time"`
+	RunID  string    `json:"run_id"`
+	Verb   string    `json:"verb"`
+	Source string    `json:"source"`
+	// HistoryRun is the run's number in the history database, if recorded.
+	HistoryRun uint64 `json:"history_run,omitempty"`
+
+	// Entries is the number of entries of a run-started event.
+	Entries int `json:"entries,omitempty"`
+	// Row is the outcome of the row of a row-succeeded or row-failed event.
+	Row *entryResult `json:"row,omitempty"`
+	// Summary holds the counts of a run-completed event.
+	Summary *runSummary `json:"summary,omitempty"`
+}
+
+type runSummary struct {
+	Succeeded   int  `json:"succeeded"`
+	Failed      int  `json:"failed"`
+	Skipped     int  `json:"skipped"`
+	NotStarted  int  `json:"not_started"`
+	Interrupted bool `json:"interrupted"`
+}
+
+// summarizeResults counts the results of a run by status.
+func summarizeResults(results []entryResult, interrupted bool) runSummary {
+	sum := runSummary{Interrupted: interrupted}
+	for _, r := range results {
+		switch r.Status {
+		case resultOK:
+			sum.Succeeded++
+		case resultFailed:
+			sum.Failed++
+		case resultSkipped:
+			sum.Skipped++
+		case resultPending:
+			sum.NotStarted++
+		}
+	}
+	return sum
+}
+
+// webhookSender delivers events in order from a background goroutine, so
+// that a slow receiver does not hold up the run. A nil sender sends nothing.
+type webhookSender struct {
+	url    string
+	secret []byte
+	client *http.Client
+	base   webhookEvent
+	queue  chan webhookEvent
+	done   chan struct{}
+}
+
+// start returns a sender for a run described by verb and source, or nil
+// without -webhook-url. Events carry a random run ID, and historyRun, the
+// run's number in the history database, if it has one.
+func (o webhookOptions) start(historyRun uint64, verb, source string) *webhookSender {
+	if o.url == "" {
+		return nil
+	}
+	s := &webhookSender{
+		url:    o.url,
+		client: &http.Client{Transport: netTransport, Timeout: webhookTimeout},
+		base:   webhookEvent{RunID: rand.Text(), HistoryRun: historyRun, Verb: verb, Source: source},
+		secret: o.secret(),
+		queue:  make(chan webhookEvent, 1024),
+		done:   make(chan struct{}),
+	}
+	go func() {
+		defer close(s.done)
+		for ev := range s.queue {
+			if err := s.deliver(ev); err != nil {
+				slog.Warn("failed to deliver webhook", "event", ev.Event, "err", err)
+			}
+		}
+	}()
+	return s
+}
+
+func (s *webhookSender) send(ev webhookEvent) {
+	if s == nil {
+		return
+	}
+	base := s.base
+	base.Event, base.Time = ev.Event, time.Now().UTC()
+	base.Entries, base.Row, base.Summary = ev.Entries, ev.Row, ev.Summary
+	s.queue <- base
+}
+
+// row sends the event for one finished entry. Skipped and pending entries
+// have no event of their own; they are counted in run-completed.
+func (s *webhookSender) row(r entryResult) {
+	switch r.Status {
+	case resultOK:
+		s.send(webhookEvent{Event: eventRowSucceeded, Row: &r})
+	case resultFailed:
+		s.send(webhookEvent{Event: eventRowFailed, Row: &r})
+	}
+}
+
+// complete sends run-completed for results and waits up to webhookDrain
+// for the queued events to be delivered.
+func (s *webhookSender) complete(results []entryResult, interrupted bool) {
+	if s == nil {
+		return
+	}
+	sum := summarizeResults(results, interrupted)
+	s.send(webhookEvent{Event: eventRunCompleted, Summary: &sum})
+	close(s.queue)
+	select {
+	case <-s.done:
+	case <-time.After(webhookDrain):
+		slog.Warn("gave up waiting for webhooks to be delivered", "after", webhookDrain)
+	}
+}
+
+// deliver POSTs ev, retrying on network errors and 5xx responses. The body
+// is signed with HMAC-SHA256 in the X-Renamer-Signature header.
+func (s *webhookSender) deliver(ev webhookEvent) error {
+	body, err := json.Marshal(ev)
+	if err != nil {
+		return fmt.Errorf("encode %s: %w", ev.Event, err)
+	}
+	backoff := time.Second
+	for attempt := 1; ; attempt++ {
+		err = s.post(ev.Event, body)
+		var se webhookStatusError
+		retry := slackops.IsTransient(err) || errors.As(err, &se) && se.code >= 500
+		if err == nil || attempt == webhookAttempts || !retry {
+			return err
+		}
+		time.Sleep(slackops.Jitter(backoff))
+		backoff *= 2
+	}
+}
+
+func (s *webhookSender) post(event string, body []byte) error {
+	ctx, cancel := context.WithTimeout(context.Background(), webhookTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Renamer-Event", event)
+	if len(s.secret) > 0 {
+		mac := hmac.New(sha256.New, s.secret)
+		mac.Write(body)
+		req.Header.Set("X-Renamer-Signature", "sha256="+hex.EncodeToString(mac.Sum(nil)))
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return webhookStatusError{resp.StatusCode}
+	}
+	return nil
+}
+
+// webhookStatusError is a non-2xx response from the webhook receiver.
+type webhookStatusError struct {
+	code int
+}
+
+func (e webhookStatusError) Error() string {
+	return fmt.Sprintf("webhook receiver returned %d %s", e.code, http.StatusText(e.code))
+}
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+)
+
+// execWindow is a weekly time window in which entries may start, such as
+// "Mon-Fri 19:00-23:00 Asia/Tokyo". A window whose end is before its start
+// runs past midnight into the next day.
+type execWindow struct {
+	spec       string
+	days       [7]bool // by time.Weekday, the days the window opens on
+	start, end int     // minutes after midnight
+	loc        *time.Location
+
+	// mu makes the workers wait for the window one at a time, so that a
+	// closed window is logged once.
+	mu sync.Mutex
+}
+
+// parseWindow parses a window of days, a time range and an optional time
+// zone: "Mon-Fri 19:00-23:00 Asia/Tokyo", "Sat,Sun 00:00-06:00" or
+// "daily 22:00-02:00". Without a zone the window is in local time.
+func parseWindow(spec string) (*execWindow, error) {
+	fields := strings.Fields(spec)
+	if len(fields) != 2 && len(fields) != 3 {
+		return nil, fmt.Errorf("window %q: want DAYS HH:MM-HH:MM [ZONE]", spec)
+	}
+	w := &execWindow{spec: spec, loc: time.Local}
+	if len(fields) == 3 {
+		loc, err := time.LoadLocation(fields[2])
+		if err != nil {
+			return nil, fmt.Errorf("window %q: %w", spec, err)
+		}
+		w.loc = loc
+	}
+	if strings.EqualFold(fields[0], "daily") || fields[0] == "*" {
+		w.days = [7]bool{true, true, true, true, true, true, true}
+	} else {
+		bits, err := parseCronField(fields[0], 0, 7, cronDays, 0)
+		if err != nil {
+			return nil, fmt.Errorf("window %q: days: %w", spec, err)
+		}
+		for d := range 8 {
+			if bits&(1<<d) != 0 {
+				w.days[d%7] = true
+			}
+		}
+	}
+	from, to, ok := strings.Cut(fields[1], "-")
+	if !ok {
+		return nil, fmt.Errorf("window %q: want a time range such as 19:00-23:00", spec)
+	}
+	var err error
+	if w.start, err = parseClock(from); err != nil {
+		return nil, fmt.Errorf("window %q: %w", spec, err)
+	}
+	if w.end, err = parseClock(to); err != nil {
+		return nil, fmt.Errorf("window %q: %w", spec, err)
+	}
+	if w.start == w.end || w.start == 24*60 {
+		return nil, fmt.Errorf("window %q: the range is empty", spec)
+	}
+	return w, nil
+}
+
+// parseClock returns the minutes after midnight of an "HH:MM" time; 24:00 is
+// the end of the day.
+func parseClock(s string) (int, error) {
+	t, err := time.Parse("15:04", s)
+	if err != nil {
+		if s == "24:00" {
+			return 24 * 60, nil
+		}
+		return 0, fmt.Errorf("%q is not a time of day (HH:MM)", s)
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+// contains reports whether the window is open at t.
+func (w *execWindow) contains(t time.Time) bool {
+	t = t.In(w.loc)
+	m := t.Hour()*60 + t.Minute()
+	day := t.Weekday()
+	if w.start < w.end {
+		return w.days[day] && m >= w.start && m < w.end
+	}
+	return w.days[day] && m >= w.start || w.days[(day+6)%7] && m < w.end
+}
+
+// nextOpen returns when the window next opens after t.
+func (w *execWindow) nextOpen(t time.Time) time.Time {
+	t = t.In(w.loc)
+	y, mo, d := t.Date()
+	for i := range 8 {
+		open := time.Date(y, mo, d+i, 0, w.start, 0, 0, w.loc)
+		if open.After(t) && w.days[open.Weekday()] {
+			return open
+		}
+	}
+	return time.Time{} // unreachable: parseWindow requires a day
+}
+
+// wait returns once the window is open, or with ctx's error if ctx is done
+// first. A nil window is always open.
+func (w *execWindow) wait(ctx context.Context) error {
+	if w == nil {
+		return nil
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for !w.contains(time.Now()) {
+		open := w.nextOpen(time.Now())
+		slog.Warn("outside the execution window, pausing", "window", w.spec, "resumes", open.Format(time.RFC3339))
+		timer := time.NewTimer(time.Until(open))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+		slog.Info("execution window open, resuming", "window", w.spec)
+	}
+	return nil
+}
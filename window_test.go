@@ -0,0 +1,65 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestExecWindow(t *testing.T) {
+	w, err := parseWindow("Mon-Fri 19:00-23:00 UTC")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 2026-10-14 is a Wednesday.
+	at := func(day, hour, min int) time.Time { return time.Date(2026, 10, day, hour, min, 0, 0, time.UTC) }
+	for _, tc := range []struct {
+		t    time.Time
+		want bool
+	}{
+		{at(14, 18, 59), false},
+		{at(14, 19, 0), true},
+		{at(14, 22, 59), true},
+		{at(14, 23, 0), false},
+		{at(17, 20, 0), false}, // Saturday
+	} {
+		if got := w.contains(tc.t); got != tc.want {
+			t.Errorf("contains(%v) = %v, want %v", tc.t, got, tc.want)
+		}
+	}
+	if got, want := w.nextOpen(at(14, 23, 30)), at(15, 19, 0); !got.Equal(want) {
+		t.Errorf("nextOpen after Wednesday's window = %v, want %v", got, want)
+	}
+	if got, want := w.nextOpen(at(16, 23, 30)), at(19, 19, 0); !got.Equal(want) {
+		t.Errorf("nextOpen after Friday's window = %v, want %v", got, want)
+	}
+}
+
+func TestExecWindowPastMidnight(t *testing.T) {
+	w, err := parseWindow("Sat 22:00-02:00 UTC")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 2026-10-17 is a Saturday.
+	for _, tc := range []struct {
+		t    time.Time
+		want bool
+	}{
+		{time.Date(2026, 10, 17, 21, 59, 0, 0, time.UTC), false},
+		{time.Date(2026, 10, 17, 23, 0, 0, 0, time.UTC), true},
+		{time.Date(2026, 10, 18, 1, 59, 0, 0, time.UTC), true},
+		{time.Date(2026, 10, 18, 2, 0, 0, 0, time.UTC), false},
+		{time.Date(2026, 10, 17, 1, 0, 0, 0, time.UTC), false}, // Friday night
+	} {
+		if got := w.contains(tc.t); got != tc.want {
+			t.Errorf("contains(%v) = %v, want %v", tc.t, got, tc.want)
+		}
+	}
+}
+
+func TestParseWindowErrors(t *testing.T) {
+	for _, spec := range []string{"", "Mon-Fri", "Mon-Fri 19:00", "Mon-Fri 19:00-19:00", "Funday 19:00-20:00", "Mon 25:00-26:00", "Mon 19:00-23:00 Nowhere/City"} {
+		if _, err := parseWindow(spec); err == nil {
+			t.Errorf("parseWindow(%q) succeeded", spec)
+		}
+	}
+}
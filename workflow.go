@@ -0,0 +1,91 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/kiddikn/slack-channel-renamer/slackops"
+	"github.com/slack-go/slack"
+	"github.com/slack-go/slack/slackevents"
+)
+
+// Inputs and outputs of the workflow step; they must match the function
+// declared in the app manifest.
+const (
+	stepInputChannel  = "channel_id"
+	stepInputNewName  = "new_name"
+	stepOutputOldName = "old_name"
+	stepOutputNewName = "new_name"
+)
+
+// runFunction renames the channel given to a workflow step, so that the
+// rename can sit behind the approvals of a Workflow Builder workflow. The
+// rename goes through the same validation and run as a posted plan, waiting
+// for any run in progress to finish first. The step completes with the old
+// and new names, or with the reason it failed.
+func (b *planBot) runFunction(ctx context.Context, fn *slackevents.FunctionExecutedEvent) {
+	// Steps are completed with the token Slack issues for the execution.
+	client := slack.New(fn.BotAccessToken, slackOptions()...)
+	complete := func(outputs map[string]string, err error) {
+		cerr := slackops.Call(cmdCtx, b.session.stats, "completing the workflow step", func(ctx context.Context) error {
+			if err != nil {
+				return client.FunctionCompleteErrorContext(ctx, fn.FunctionExecutionID, err.Error())
+			}
+			return client.FunctionCompleteSuccessContext(ctx, fn.FunctionExecutionID, slack.FunctionCompleteSuccessRequestOptionOutput(outputs))
+		}, "function_execution_id", fn.FunctionExecutionID)
+		if cerr != nil {
+			slog.Error("failed to complete the workflow step", "workflow", fn.WorkflowExecutionID, "err", cerr)
+		}
+	}
+
+	channelID, _ := fn.Inputs[stepInputChannel].(string)
+	newName, _ := fn.Inputs[stepInputNewName].(string)
+	newName = strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(newName), "#"))
+	if channelID == "" || newName == "" {
+		complete(nil, fmt.Errorf("the step needs both %s and %s", stepInputChannel, stepInputNewName))
+		return
+	}
+	source := "workflow " + fn.WorkflowExecutionID
+	p := b.srv.addPlan(source, []planEntry{{Action: actionRename, Asis: channelID, ChannelID: channelID, Tobe: newName, Source: source}})
+	defer func() {
+		b.srv.mu.Lock()
+		delete(b.srv.plans, p.ID)
+		b.srv.mu.Unlock()
+	}()
+
+	var run *servedRun
+	for {
+		var err error
+		if run, err = b.srv.start(p); err == nil {
+			break
+		}
+		select {
+		case <-ctx.Done():
+			complete(nil, errors.New("the renamer is shutting down"))
+			return
+		case <-b.srv.idle():
+		}
+	}
+	slog.Info("rename started from a workflow", "workflow", fn.WorkflowExecutionID, "run", run.ID, "channel_id", channelID, "tobe", newName)
+	<-run.done
+
+	b.srv.mu.Lock()
+	defer b.srv.mu.Unlock()
+	switch {
+	case run.Status == runSucceeded && len(run.Results) == 1:
+		r := run.Results[0]
+		complete(map[string]string{stepOutputOldName: r.Asis, stepOutputNewName: r.Tobe}, nil)
+	case run.Status == runSucceeded:
+		// Validation found nothing to do: the channel already has the name.
+		complete(map[string]string{stepOutputOldName: newName, stepOutputNewName: newName}, nil)
+	case run.Error != "":
+		complete(nil, errors.New(run.Error))
+	case len(run.Results) > 0 && run.Results[0].Error != "":
+		complete(nil, errors.New(run.Results[0].Error))
+	default:
+		complete(nil, fmt.Errorf("run %s", run.Status))
+	}
+}
@@ -0,0 +1,229 @@
+package main
+
+import (
+	"bytes"
+	"cmp"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"maps"
+	"os"
+	"slices"
+
+	"gopkg.in/yaml.v3"
+)
+
+// workspaceConfig is the -config file that maps workspace names to tokens:
+//
+//	workspaces:
+//	  eng:
+//	    token_env: SLACK_ENG_TOKEN
+//	  sales:
+//	    token_env: SLACK_SALES_TOKEN
+type workspaceConfig struct {
+	Workspaces map[string]workspaceToken `yaml:"workspaces"`
+}
+
+// workspaceToken says where a workspace's user token comes from. token_env,
+// token_file or token_ref (see resolveTokenRef) are preferred so that the
+// config file can be committed without secrets.
+type workspaceToken struct {
+	Token     string `yaml:"token"`
+	TokenEnv  string `yaml:"token_env"`
+	TokenFile string `yaml:"token_file"`
+	TokenRef  string `yaml:"token_ref"`
+
+	// Team, when set, is the Slack workspace name, domain or ID the token
+	// must belong to, so that a token pasted into the wrong variable fails
+	// before anything runs.
+	Team string `yaml:"team"`
+	// TeamID, for a Grid org token shared by several workspaces, is the
+	// workspace whose channels this one matches, as -team-id.
+	TeamID string `yaml:"team_id"`
+}
+
+func loadWorkspaceConfig(path string) (*workspaceConfig, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read %q: %w", path, err)
+	}
+	if err := checkSchema(configSchema, path, b, false); err != nil {
+		return nil, err
+	}
+	dec := yaml.NewDecoder(bytes.NewReader(b))
+	dec.KnownFields(true)
+	var cfg workspaceConfig
+	if err := dec.Decode(&cfg); err != nil {
+		return nil, fmt.Errorf("parse %q: %w", path, err)
+	}
+	if len(cfg.Workspaces) == 0 {
+		return nil, fmt.Errorf("%s: no workspaces configured", path)
+	}
+	for name, t := range cfg.Workspaces {
+		set := 0
+		for _, v := range []string{t.Token, t.TokenEnv, t.TokenFile, t.TokenRef} {
+			if v != "" {
+				set++
+			}
+		}
+		if set != 1 {
+			return nil, fmt.Errorf("%s: workspace %q needs exactly one of token, token_env, token_file or token_ref", path, name)
+		}
+	}
+	return &cfg, nil
+}
+
+// token returns the workspace's user token.
+func (t workspaceToken) token(name string) (string, error) {
+	switch {
+	case t.Token != "":
+		return t.Token, nil
+	case t.TokenFile != "":
+		token, err := readTokenFile(t.TokenFile)
+		if err != nil {
+			return "", fmt.Errorf("workspace %q: %w", name, err)
+		}
+		return token, nil
+	case t.TokenRef != "":
+		token, err := resolveTokenRef(t.TokenRef)
+		if err != nil {
+			return "", fmt.Errorf("workspace %q: %w", name, err)
+		}
+		return token, nil
+	}
+	token := os.Getenv(t.TokenEnv)
+	if token == "" {
+		return "", fmt.Errorf("workspace %q: %s environment variable is not set", name, t.TokenEnv)
+	}
+	return token, nil
+}
+
+// workspaceOptions selects the workspaces a command runs against. Without
+// -config the single workspace behind -token-file, -token-ref,
+// SLACK_USER_TOKEN or SLACK_TOKEN_REF is used. With -simulate, the workspaces of a snapshot
+// stand in for Slack.
+type workspaceOptions struct {
+	config    string
+	workspace string
+	tokenFile string
+	tokenRef  string
+	simulate  string
+}
+
+func (o *workspaceOptions) register(fs *flag.FlagSet) {
+	fs.StringVar(&o.config, "config", "", "YAML file mapping workspace names to tokens, for plans that span several workspaces")
+	fs.StringVar(&o.workspace, "workspace", "", "only run against this workspace from -config; also the default for rows without a workspace column")
+	fs.StringVar(&o.simulate, "simulate", "", "run against an in-memory copy of the channels in this snapshot, written by 'export -format json', instead of Slack; no token is needed")
+	fs.StringVar(&o.tokenFile, "token-file", "", "read the user token from this file instead of SLACK_USER_TOKEN")
+	fs.StringVar(&o.tokenRef, "token-ref", "", "read the user token from this secret reference (see Token sources) instead of SLACK_USER_TOKEN")
+}
+
+// defaultToken returns the token of the workspace used without -config.
+func (o workspaceOptions) defaultToken() (string, error) {
+	if o.tokenFile != "" {
+		return readTokenFile(o.tokenFile)
+	}
+	if o.tokenRef != "" {
+		return resolveTokenRef(o.tokenRef)
+	}
+	if token := os.Getenv("SLACK_USER_TOKEN"); token != "" {
+		return token, nil
+	}
+	if ref := os.Getenv("SLACK_TOKEN_REF"); ref != "" {
+		return resolveTokenRef(ref)
+	}
+	if replaying {
+		return replayToken, nil
+	}
+	return "", errors.New("SLACK_USER_TOKEN environment variable is not set (or set SLACK_TOKEN_REF, or pass -token-file or -token-ref)")
+}
+
+// newSessions opens one session per selected workspace, in name order.
+func (o workspaceOptions) newSessions(channelOpts channelOptions) ([]*session, error) {
+	if o.simulate != "" {
+		if o.config != "" || o.tokenFile != "" || o.tokenRef != "" {
+			return nil, errors.New("-simulate cannot be used with -config, -token-file or -token-ref; the snapshot's workspace field names the workspaces")
+		}
+		return simulatedSessions(o.simulate, o.workspace, channelOpts)
+	}
+	if o.config == "" {
+		if o.workspace != "" {
+			return nil, errors.New("-workspace requires -config")
+		}
+		token, err := o.defaultToken()
+		if err != nil {
+			return nil, err
+		}
+		return []*session{newSession("", token, channelOpts)}, nil
+	}
+
+	if o.tokenFile != "" || o.tokenRef != "" {
+		return nil, errors.New("-token-file and -token-ref cannot be used with -config; give each workspace a token_file or token_ref instead")
+	}
+	cfg, err := loadWorkspaceConfig(o.config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load config: %w", err)
+	}
+	names := slices.Sorted(maps.Keys(cfg.Workspaces))
+	if o.workspace != "" {
+		if _, ok := cfg.Workspaces[o.workspace]; !ok {
+			return nil, fmt.Errorf("workspace %q is not in %s", o.workspace, o.config)
+		}
+		names = []string{o.workspace}
+	}
+	sessions := make([]*session, 0, len(names))
+	for _, name := range names {
+		token, err := cfg.Workspaces[name].token(name)
+		if err != nil {
+			return nil, err
+		}
+		s := newSession(name, token, channelOpts)
+		s.team = cfg.Workspaces[name].Team
+		s.channelOpts.teamID = cmp.Or(cfg.Workspaces[name].TeamID, channelOpts.teamID)
+		sessions = append(sessions, s)
+	}
+	return sessions, nil
+}
+
+// splitByWorkspace assigns every plan entry to one of sessions, returning the
+// entries for each session in the same order. Entries without a workspace go
+// to the only session; with -workspace, entries for other workspaces are
+// ignored.
+func splitByWorkspace(plan []planEntry, sessions []*session, selected string) ([][]planEntry, []string) {
+	index := make(map[string]int, len(sessions))
+	for i, s := range sessions {
+		index[s.workspace] = i
+	}
+
+	split := make([][]planEntry, len(sessions))
+	var errs []string
+	ignored := 0
+	for _, e := range plan {
+		name := e.Workspace
+		switch {
+		case name == "" && len(sessions) == 1:
+			name = sessions[0].workspace
+		case name == "":
+			errs = append(errs, e.At()+"no workspace given (add a workspace column or pass -workspace)")
+			continue
+		case sessions[0].workspace == "":
+			errs = append(errs, e.At()+fmt.Sprintf("workspace %q given but no -config was passed", name))
+			continue
+		}
+		i, ok := index[name]
+		switch {
+		case ok:
+			e.Workspace = name
+			split[i] = append(split[i], e)
+		case selected != "":
+			ignored++
+		default:
+			errs = append(errs, e.At()+fmt.Sprintf("workspace %q is not configured", name))
+		}
+	}
+	if ignored > 0 {
+		slog.Info("ignoring entries for other workspaces", "ignored", ignored, "workspace", selected)
+	}
+	return split, errs
+}